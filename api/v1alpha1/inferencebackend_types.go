@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -47,9 +49,10 @@ type KServeBackend struct {
 
 // ExternalBackend defines an external API backend
 type ExternalBackend struct {
-	// Base URL of the external API
-	// +required
-	URL string `json:"url"`
+	// Base URL of the external API. When empty, the base URL configured for
+	// the provider in the gateway configuration is used.
+	// +optional
+	URL string `json:"url,omitempty"`
 
 	// Provider type for API compatibility
 	// +kubebuilder:validation:Enum=openai;anthropic;cohere;custom
@@ -61,9 +64,27 @@ type ExternalBackend struct {
 	// +optional
 	APIKeySecret *corev1.SecretKeySelector `json:"apiKeySecret,omitempty"`
 
-	// Model name to use for this backend
+	// Model name to use for this backend. It is injected into JSON request
+	// bodies that don't specify a model.
 	// +optional
 	Model string `json:"model,omitempty"`
+
+	// DefaultHeaders are set on every request sent to this backend, regardless
+	// of the route (e.g. API version or deployment ID headers)
+	// +optional
+	DefaultHeaders map[string]string `json:"defaultHeaders,omitempty"`
+
+	// PathVersionMap translates client API paths to the paths this backend
+	// expects, e.g. "/v1": "/openai/v1" or "/v1/chat/completions": "/v2/chat".
+	// Keys match whole path segments and the longest matching key wins.
+	// +optional
+	PathVersionMap map[string]string `json:"pathVersionMap,omitempty"`
+
+	// HostOverride is sent as the Host header instead of the URL host, for
+	// backends behind a shared gateway that routes on Host. Connections
+	// still go to the URL host.
+	// +optional
+	HostOverride string `json:"hostOverride,omitempty"`
 }
 
 // KubernetesBackend defines a Kubernetes Service backend
@@ -80,10 +101,39 @@ type KubernetesBackend struct {
 	// +kubebuilder:default=8080
 	// +optional
 	Port int32 `json:"port,omitempty"`
+
+	// Model name served by this Service
+	// +optional
+	Model string `json:"model,omitempty"`
+
+	// DirectEndpoints sends requests straight to the ready pods in the
+	// Service's EndpointSlices, balancing across them in the gateway, instead
+	// of through the Service's ClusterIP. The ClusterIP is used while no
+	// endpoints are known.
+	// +optional
+	DirectEndpoints bool `json:"directEndpoints,omitempty"`
 }
 
+// HealthCheckType defines how a backend is probed
+// +kubebuilder:validation:Enum=http;tcp
+type HealthCheckType string
+
+const (
+	// HealthCheckTypeHTTP probes the backend with an HTTP request
+	HealthCheckTypeHTTP HealthCheckType = "http"
+	// HealthCheckTypeTCP only opens a TCP connection to the serving port, for
+	// backends that don't speak HTTP
+	HealthCheckTypeTCP HealthCheckType = "tcp"
+)
+
 // HealthCheck defines health check configuration
 type HealthCheck struct {
+	// Type of health check. tcp marks the backend healthy when a connection
+	// to its serving port succeeds, and ignores Path.
+	// +kubebuilder:default=http
+	// +optional
+	Type HealthCheckType `json:"type,omitempty"`
+
 	// Path for health check endpoint
 	// +kubebuilder:default="/health"
 	// +optional
@@ -103,6 +153,50 @@ type HealthCheck struct {
 	// +kubebuilder:default=3
 	// +optional
 	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+
+	// Headers are set on every HTTP health check request, for health
+	// endpoints that require auth or a specific header
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// MaintenanceConfig takes a backend out of rotation without deleting it
+type MaintenanceConfig struct {
+	// Enabled puts the backend into maintenance immediately, regardless of
+	// the window
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Start of the maintenance window. When unset, the window is open-ended
+	// in the past.
+	// +optional
+	Start *metav1.Time `json:"start,omitempty"`
+
+	// End of the maintenance window. When unset, the window is open-ended
+	// in the future.
+	// +optional
+	End *metav1.Time `json:"end,omitempty"`
+}
+
+// IsActive reports whether maintenance is in effect at the given time. A
+// window is only considered when at least one of Start or End is set.
+func (m *MaintenanceConfig) IsActive(now time.Time) bool {
+	if m == nil {
+		return false
+	}
+	if m.Enabled {
+		return true
+	}
+	if m.Start == nil && m.End == nil {
+		return false
+	}
+	if m.Start != nil && now.Before(m.Start.Time) {
+		return false
+	}
+	if m.End != nil && !now.Before(m.End.Time) {
+		return false
+	}
+	return true
 }
 
 // CostConfig defines cost tracking configuration
@@ -123,6 +217,12 @@ type CostConfig struct {
 	// +kubebuilder:default="USD"
 	// +optional
 	Currency string `json:"currency,omitempty"`
+
+	// CostHeader is a response header in which the backend reports the cost
+	// of each request (e.g. "X-Cost"). When the header is present, its value
+	// is used instead of the cost computed from the token costs above.
+	// +optional
+	CostHeader string `json:"costHeader,omitempty"`
 }
 
 // InferenceBackendSpec defines the desired state of InferenceBackend
@@ -147,6 +247,11 @@ type InferenceBackendSpec struct {
 	// +optional
 	HealthCheck *HealthCheck `json:"healthCheck,omitempty"`
 
+	// AssumeHealthy skips health probing and always reports the backend as
+	// Healthy, for providers that shouldn't be probed
+	// +optional
+	AssumeHealthy bool `json:"assumeHealthy,omitempty"`
+
 	// Cost configuration for tracking
 	// +optional
 	Cost *CostConfig `json:"cost,omitempty"`
@@ -161,16 +266,33 @@ type InferenceBackendSpec struct {
 	// +optional
 	MaxConcurrency int32 `json:"maxConcurrency,omitempty"`
 
+	// MaxContextTokens is the largest context, in tokens, the backend's model
+	// accepts. Smart routing won't send requests that don't fit to it.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxContextTokens int32 `json:"maxContextTokens,omitempty"`
+
+	// AllowedModels are the models clients may request from a backend that
+	// serves several, such as vLLM with multiple models loaded. The client's
+	// model is taken from the body's model field, or else the X-Model header.
+	// When empty, any model is passed through.
+	// +optional
+	AllowedModels []string `json:"allowedModels,omitempty"`
+
 	// Priority for fallback ordering (higher = preferred)
 	// +kubebuilder:default=0
 	// +optional
 	Priority int32 `json:"priority,omitempty"`
+
+	// Maintenance takes the backend out of routing and fallback while active
+	// +optional
+	Maintenance *MaintenanceConfig `json:"maintenance,omitempty"`
 }
 
 // InferenceBackendStatus defines the observed state of InferenceBackend
 type InferenceBackendStatus struct {
 	// Health status of the backend
-	// +kubebuilder:validation:Enum=Healthy;Unhealthy;Unknown
+	// +kubebuilder:validation:Enum=Healthy;Unhealthy;Unknown;Maintenance
 	// +optional
 	Health string `json:"health,omitempty"`
 
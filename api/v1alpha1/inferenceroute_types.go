@@ -33,6 +33,11 @@ type RouteMatch struct {
 	// Model name pattern to match (supports wildcards)
 	// +optional
 	ModelPattern *string `json:"modelPattern,omitempty"`
+
+	// HTTP methods to match (all methods if empty). A rule that matches GET
+	// also matches HEAD.
+	// +optional
+	Methods []string `json:"methods,omitempty"`
 }
 
 // BackendRef references a backend for routing
@@ -41,12 +46,20 @@ type BackendRef struct {
 	// +required
 	Name string `json:"name"`
 
-	// Weight for weighted routing (0-100)
+	// Weight for weighted routing (0-100). A weight of 0 excludes the backend
+	// from weighted selection.
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Maximum=100
 	// +kubebuilder:default=100
 	// +optional
-	Weight int32 `json:"weight,omitempty"`
+	Weight *int32 `json:"weight,omitempty"`
+
+	// Fraction is the backend's share of traffic as a decimal between 0 and 1
+	// (e.g. "0.7"), used instead of Weight when set. Shares are normalized
+	// across the backends, so they don't need to add up to 1.
+	// +kubebuilder:validation:Pattern=`^(0(\.[0-9]+)?|1(\.0+)?)$`
+	// +optional
+	Fraction *string `json:"fraction,omitempty"`
 }
 
 // RouteRule defines a single routing rule
@@ -59,6 +72,143 @@ type RouteRule struct {
 	// +required
 	// +kubebuilder:validation:MinItems=1
 	Backends []BackendRef `json:"backends"`
+
+	// BreakerFallback is the backend used while the selected backend's
+	// circuit breaker is open, ahead of the route's fallback chain
+	// +optional
+	BreakerFallback *BackendRef `json:"breakerFallback,omitempty"`
+
+	// Canary progressively shifts traffic from a stable backend to a canary
+	// backend. When set, the effective weights of this rule are computed by
+	// the route controller and override the weights in Backends.
+	// +optional
+	Canary *CanaryConfig `json:"canary,omitempty"`
+
+	// RequestSchema is a JSON Schema document that request bodies matching
+	// this rule must satisfy. Invalid requests are rejected with 400 before
+	// any backend is called.
+	// +optional
+	RequestSchema string `json:"requestSchema,omitempty"`
+
+	// PromptCacheAffinity sends requests that share a model and prompt prefix
+	// to the same backend, so that provider prompt caches are reused. It
+	// takes precedence over weighted selection and smart routing.
+	// +optional
+	PromptCacheAffinity *PromptCacheAffinity `json:"promptCacheAffinity,omitempty"`
+}
+
+// PromptCacheAffinity selects a backend consistently from a hash of the
+// request's model and the start of its prompt
+type PromptCacheAffinity struct {
+	// PrefixLength is the number of leading prompt characters hashed with
+	// the model to pick a backend
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1024
+	// +optional
+	PrefixLength int32 `json:"prefixLength,omitempty"`
+}
+
+// CanaryConfig defines a progressive traffic shift between two backends
+type CanaryConfig struct {
+	// Stable backend name that receives the remaining traffic
+	// +required
+	StableBackend string `json:"stableBackend"`
+
+	// Canary backend name that traffic is shifted to
+	// +required
+	CanaryBackend string `json:"canaryBackend"`
+
+	// Percentages of traffic sent to the canary at each step, in order
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:Minimum=0
+	// +kubebuilder:validation:items:Maximum=100
+	Steps []int32 `json:"steps"`
+
+	// Seconds to wait between steps
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=300
+	// +optional
+	StepIntervalSeconds int32 `json:"stepIntervalSeconds,omitempty"`
+
+	// Analysis rolls the rollout back to the stable backend when the canary's
+	// error rate gets too high
+	// +optional
+	Analysis *CanaryAnalysis `json:"analysis,omitempty"`
+}
+
+// CanaryAnalysis defines when a canary's error rate triggers a rollback. The
+// error rate is measured from the requests served by the proxy running
+// alongside the controller, so analysis only runs when the manager is started
+// with --enable-canary-analysis, which requires a single replica.
+type CanaryAnalysis struct {
+	// MaxErrorRate is the share of canary requests that may fail over the
+	// window, as a decimal between 0 and 1 (e.g. "0.05"). Requests without a
+	// response or with a 5xx status count as failed.
+	// +kubebuilder:validation:Pattern=`^(0(\.[0-9]+)?|1(\.0+)?)$`
+	// +required
+	MaxErrorRate string `json:"maxErrorRate"`
+
+	// WindowSeconds is how far back the error rate is measured
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:validation:Maximum=3600
+	// +kubebuilder:default=60
+	// +optional
+	WindowSeconds int32 `json:"windowSeconds,omitempty"`
+
+	// MinRequests is the number of canary requests the window must contain
+	// before its error rate is acted on
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=20
+	// +optional
+	MinRequests int32 `json:"minRequests,omitempty"`
+}
+
+// Canary phase constants
+const (
+	CanaryPhaseProgressing = "Progressing"
+	CanaryPhaseCompleted   = "Completed"
+	CanaryPhaseRolledBack  = "RolledBack"
+)
+
+// Canary rollback reason constants
+const (
+	CanaryReasonUnhealthy         = "CanaryUnhealthy"
+	CanaryReasonErrorRateExceeded = "ErrorRateExceeded"
+)
+
+// CanaryStatus records the progress of a canary rollout
+type CanaryStatus struct {
+	// Index of the rule in spec.rules that owns the canary
+	RuleIndex int32 `json:"ruleIndex"`
+
+	// Canary backend name the progress refers to
+	CanaryBackend string `json:"canaryBackend"`
+
+	// Index of the current step in the canary steps
+	// +optional
+	Step int32 `json:"step,omitempty"`
+
+	// Percentage of traffic currently sent to the canary
+	// +optional
+	Weight int32 `json:"weight,omitempty"`
+
+	// Phase of the rollout
+	// +kubebuilder:validation:Enum=Progressing;Completed;RolledBack
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// Last time the rollout advanced to a new step
+	// +optional
+	LastStepTime *metav1.Time `json:"lastStepTime,omitempty"`
+
+	// Reason the rollout was rolled back
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message explaining the rollback
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // FallbackChain defines ordered fallback backends
@@ -72,6 +222,38 @@ type FallbackChain struct {
 	// +kubebuilder:default=30
 	// +optional
 	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// MaxAttempts caps how many backends are tried, including the primary,
+	// before the request fails. Backends skipped as unavailable don't count.
+	// Unset tries every backend in the chain.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxAttempts int32 `json:"maxAttempts,omitempty"`
+
+	// OnStatusCodes are 4xx response codes that try the next backend instead
+	// of being returned to the client, such as a 404 from a backend that
+	// doesn't have the model. Unlike retries, the same backend isn't tried
+	// again. If no other backend serves the request, the client gets the
+	// response.
+	// +kubebuilder:validation:items:Minimum=400
+	// +kubebuilder:validation:items:Maximum=499
+	// +optional
+	OnStatusCodes []int32 `json:"onStatusCodes,omitempty"`
+}
+
+// ModelGroup serves one model name from several backends in priority order.
+// Requests for the model go to the first healthy backend and fail over to the
+// next one in the list.
+type ModelGroup struct {
+	// Model is the model name requested via the X-Model header
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Model string `json:"model"`
+
+	// Backends serving the model, in failover priority order
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	Backends []string `json:"backends"`
 }
 
 // RateLimitConfig defines rate limiting settings
@@ -90,8 +272,37 @@ type RateLimitConfig struct {
 	// +kubebuilder:default="x-user-id"
 	// +optional
 	UserHeader string `json:"userHeader,omitempty"`
+
+	// Scope of per-user limits. route gives each user a separate quota on
+	// every route; global-user shares one quota per user across all routes
+	// with this scope, which should then set the same RequestsPerMinute.
+	// +kubebuilder:default=route
+	// +optional
+	Scope RateLimitScope `json:"scope,omitempty"`
 }
 
+// RateLimitScope defines what a per-user rate limit is shared across
+// +kubebuilder:validation:Enum=route;global-user
+type RateLimitScope string
+
+const (
+	// RateLimitScopeRoute limits each user separately on every route
+	RateLimitScopeRoute RateLimitScope = "route"
+	// RateLimitScopeGlobalUser limits each user across all routes
+	RateLimitScopeGlobalUser RateLimitScope = "global-user"
+)
+
+// SelectionMode defines how a backend is chosen among weighted candidates
+// +kubebuilder:validation:Enum=weighted;concurrency-aware
+type SelectionMode string
+
+const (
+	// SelectionModeWeighted picks backends by weight alone
+	SelectionModeWeighted SelectionMode = "weighted"
+	// SelectionModeConcurrencyAware skips backends at their MaxConcurrency
+	SelectionModeConcurrencyAware SelectionMode = "concurrency-aware"
+)
+
 // ABExperiment defines an A/B test configuration
 type ABExperiment struct {
 	// Name of the experiment
@@ -117,10 +328,77 @@ type ABExperiment struct {
 	// +kubebuilder:default="latency_p95"
 	// +optional
 	Metric string `json:"metric,omitempty"`
+
+	// Salt is mixed into the hash that assigns users to variants. Changing it
+	// re-randomizes assignments, e.g. when re-running an experiment under the
+	// same name.
+	// +optional
+	Salt string `json:"salt,omitempty"`
+
+	// Enabled is a kill switch for the experiment. Setting it to false sends
+	// all traffic to the control backend without removing the experiment.
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// FallbackToControl sends users assigned to the treatment to the control
+	// backend while the treatment backend is unhealthy, instead of failing
+	// their requests
+	// +kubebuilder:default=true
+	// +optional
+	FallbackToControl *bool `json:"fallbackToControl,omitempty"`
+}
+
+// IsEnabled reports whether the experiment is running. Experiments are
+// enabled unless Enabled is explicitly false.
+func (e *ABExperiment) IsEnabled() bool {
+	return e.Enabled == nil || *e.Enabled
+}
+
+// FallsBackToControl reports whether treatment requests go to control while
+// the treatment backend is unhealthy. This is the default.
+func (e *ABExperiment) FallsBackToControl() bool {
+	return e.FallbackToControl == nil || *e.FallbackToControl
+}
+
+// SessionAffinity routes a client back to the backend that served it, using a
+// cookie set on the first response
+type SessionAffinity struct {
+	// Name of the cookie that records the selected backend
+	// +kubebuilder:default="kortex-backend"
+	// +optional
+	CookieName string `json:"cookieName,omitempty"`
+
+	// Lifetime of the cookie in seconds (0 keeps it for the browser session)
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxAgeSeconds int32 `json:"maxAgeSeconds,omitempty"`
+}
+
+// AuditSampling captures the request and response bodies of a sample of
+// requests for audit logging
+type AuditSampling struct {
+	// Percent of requests whose bodies are captured (0-100)
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +required
+	Percent int32 `json:"percent"`
+
+	// RedactFields are JSON fields, matched at any depth and ignoring case,
+	// whose values are replaced before bodies are written (e.g. "api_key")
+	// +optional
+	RedactFields []string `json:"redactFields,omitempty"`
 }
 
 // InferenceRouteSpec defines the desired state of InferenceRoute
 type InferenceRouteSpec struct {
+	// Hostnames this route serves. Requests without an X-Route header are
+	// matched to the route by their Host header, across all namespaces.
+	// Pending and failed routes are skipped, and when several routes claim
+	// a hostname the oldest wins.
+	// +optional
+	Hostnames []string `json:"hostnames,omitempty"`
+
 	// Rules for routing requests to backends
 	// +optional
 	Rules []RouteRule `json:"rules,omitempty"`
@@ -133,6 +411,21 @@ type InferenceRouteSpec struct {
 	// +optional
 	Fallback *FallbackChain `json:"fallback,omitempty"`
 
+	// SelectionMode controls how weighted rules pick a backend. weighted
+	// uses the configured weights as-is; concurrency-aware treats each
+	// backend's MaxConcurrency as a soft cap and spills traffic to the
+	// rule's other backends while it is saturated, instead of queueing.
+	// concurrency-aware has no effect unless the proxy runs with --enable-backend-queue.
+	// +kubebuilder:default=weighted
+	// +optional
+	SelectionMode SelectionMode `json:"selectionMode,omitempty"`
+
+	// ModelGroups map model names to ordered backends. Requests for a group's
+	// model skip rule matching and fail over through the group's backends
+	// instead of the route's fallback chain.
+	// +optional
+	ModelGroups []ModelGroup `json:"modelGroups,omitempty"`
+
 	// Rate limiting configuration
 	// +optional
 	RateLimit *RateLimitConfig `json:"rateLimit,omitempty"`
@@ -150,6 +443,43 @@ type InferenceRouteSpec struct {
 	// +kubebuilder:default=false
 	// +optional
 	EnableLogging bool `json:"enableLogging,omitempty"`
+
+	// AuditSampling writes the bodies of a sample of requests to the audit
+	// sink, when the proxy has one
+	// +optional
+	AuditSampling *AuditSampling `json:"auditSampling,omitempty"`
+
+	// Cookie-based session affinity. When set, clients are routed back to
+	// the same backend while it stays healthy.
+	// +optional
+	SessionAffinity *SessionAffinity `json:"sessionAffinity,omitempty"`
+
+	// TraceSampleRate overrides the global trace sample rate for requests on
+	// this route, as a decimal between 0 and 1 (e.g. "0.01")
+	// +kubebuilder:validation:Pattern=`^(0(\.[0-9]+)?|1(\.0+)?)$`
+	// +optional
+	TraceSampleRate *string `json:"traceSampleRate,omitempty"`
+
+	// StreamHeartbeatSeconds sends an SSE keep-alive comment to streaming
+	// clients whenever the backend has been silent for this many seconds, so
+	// intermediaries don't close long generations as idle. 0 disables it.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StreamHeartbeatSeconds int32 `json:"streamHeartbeatSeconds,omitempty"`
+
+	// StreamIdleTimeoutSeconds aborts a streaming response when the backend
+	// sends nothing for this many seconds. A backend that stalls before its
+	// first byte fails over to the next backend. 0 disables it.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StreamIdleTimeoutSeconds int32 `json:"streamIdleTimeoutSeconds,omitempty"`
+
+	// SLOTarget is the share of requests on this route that should succeed,
+	// as a decimal between 0 and 1 (e.g. "0.999"). The proxy reports how fast
+	// the route spends its error budget against it.
+	// +kubebuilder:validation:Pattern=`^0\.[0-9]+$`
+	// +optional
+	SLOTarget *string `json:"sloTarget,omitempty"`
 }
 
 // InferenceRouteStatus defines the observed state of InferenceRoute
@@ -171,6 +501,14 @@ type InferenceRouteStatus struct {
 	// +optional
 	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
 
+	// Progress of canary rollouts configured on route rules
+	// +optional
+	Canaries []CanaryStatus `json:"canaries,omitempty"`
+
+	// Names of experiments turned off with their kill switch
+	// +optional
+	DisabledExperiments []string `json:"disabledExperiments,omitempty"`
+
 	// Conditions represent the current state of the InferenceRoute
 	// +listType=map
 	// +listMapKey=type
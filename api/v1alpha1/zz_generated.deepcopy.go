@@ -29,6 +29,16 @@ import (
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ABExperiment) DeepCopyInto(out *ABExperiment) {
 	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FallbackToControl != nil {
+		in, out := &in.FallbackToControl, &out.FallbackToControl
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ABExperiment.
@@ -41,9 +51,39 @@ func (in *ABExperiment) DeepCopy() *ABExperiment {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditSampling) DeepCopyInto(out *AuditSampling) {
+	*out = *in
+	if in.RedactFields != nil {
+		in, out := &in.RedactFields, &out.RedactFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditSampling.
+func (in *AuditSampling) DeepCopy() *AuditSampling {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditSampling)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackendRef) DeepCopyInto(out *BackendRef) {
 	*out = *in
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Fraction != nil {
+		in, out := &in.Fraction, &out.Fraction
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendRef.
@@ -56,6 +96,65 @@ func (in *BackendRef) DeepCopy() *BackendRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAnalysis.
+func (in *CanaryAnalysis) DeepCopy() *CanaryAnalysis {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryAnalysis)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryConfig) DeepCopyInto(out *CanaryConfig) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Analysis != nil {
+		in, out := &in.Analysis, &out.Analysis
+		*out = new(CanaryAnalysis)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryConfig.
+func (in *CanaryConfig) DeepCopy() *CanaryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
+	*out = *in
+	if in.LastStepTime != nil {
+		in, out := &in.LastStepTime, &out.LastStepTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryStatus.
+func (in *CanaryStatus) DeepCopy() *CanaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CostConfig) DeepCopyInto(out *CostConfig) {
 	*out = *in
@@ -79,6 +178,20 @@ func (in *ExternalBackend) DeepCopyInto(out *ExternalBackend) {
 		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DefaultHeaders != nil {
+		in, out := &in.DefaultHeaders, &out.DefaultHeaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PathVersionMap != nil {
+		in, out := &in.PathVersionMap, &out.PathVersionMap
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalBackend.
@@ -99,6 +212,11 @@ func (in *FallbackChain) DeepCopyInto(out *FallbackChain) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.OnStatusCodes != nil {
+		in, out := &in.OnStatusCodes, &out.OnStatusCodes
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FallbackChain.
@@ -114,6 +232,13 @@ func (in *FallbackChain) DeepCopy() *FallbackChain {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HealthCheck) DeepCopyInto(out *HealthCheck) {
 	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheck.
@@ -206,13 +331,23 @@ func (in *InferenceBackendSpec) DeepCopyInto(out *InferenceBackendSpec) {
 	if in.HealthCheck != nil {
 		in, out := &in.HealthCheck, &out.HealthCheck
 		*out = new(HealthCheck)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Cost != nil {
 		in, out := &in.Cost, &out.Cost
 		*out = new(CostConfig)
 		**out = **in
 	}
+	if in.AllowedModels != nil {
+		in, out := &in.AllowedModels, &out.AllowedModels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(MaintenanceConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InferenceBackendSpec.
@@ -313,6 +448,11 @@ func (in *InferenceRouteList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InferenceRouteSpec) DeepCopyInto(out *InferenceRouteSpec) {
 	*out = *in
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Rules != nil {
 		in, out := &in.Rules, &out.Rules
 		*out = make([]RouteRule, len(*in))
@@ -323,13 +463,20 @@ func (in *InferenceRouteSpec) DeepCopyInto(out *InferenceRouteSpec) {
 	if in.DefaultBackend != nil {
 		in, out := &in.DefaultBackend, &out.DefaultBackend
 		*out = new(BackendRef)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Fallback != nil {
 		in, out := &in.Fallback, &out.Fallback
 		*out = new(FallbackChain)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ModelGroups != nil {
+		in, out := &in.ModelGroups, &out.ModelGroups
+		*out = make([]ModelGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.RateLimit != nil {
 		in, out := &in.RateLimit, &out.RateLimit
 		*out = new(RateLimitConfig)
@@ -338,7 +485,29 @@ func (in *InferenceRouteSpec) DeepCopyInto(out *InferenceRouteSpec) {
 	if in.Experiments != nil {
 		in, out := &in.Experiments, &out.Experiments
 		*out = make([]ABExperiment, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AuditSampling != nil {
+		in, out := &in.AuditSampling, &out.AuditSampling
+		*out = new(AuditSampling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SessionAffinity != nil {
+		in, out := &in.SessionAffinity, &out.SessionAffinity
+		*out = new(SessionAffinity)
+		**out = **in
+	}
+	if in.TraceSampleRate != nil {
+		in, out := &in.TraceSampleRate, &out.TraceSampleRate
+		*out = new(string)
+		**out = **in
+	}
+	if in.SLOTarget != nil {
+		in, out := &in.SLOTarget, &out.SLOTarget
+		*out = new(string)
+		**out = **in
 	}
 }
 
@@ -359,6 +528,18 @@ func (in *InferenceRouteStatus) DeepCopyInto(out *InferenceRouteStatus) {
 		in, out := &in.LastUpdated, &out.LastUpdated
 		*out = (*in).DeepCopy()
 	}
+	if in.Canaries != nil {
+		in, out := &in.Canaries, &out.Canaries
+		*out = make([]CanaryStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DisabledExperiments != nil {
+		in, out := &in.DisabledExperiments, &out.DisabledExperiments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -408,6 +589,64 @@ func (in *KubernetesBackend) DeepCopy() *KubernetesBackend {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceConfig) DeepCopyInto(out *MaintenanceConfig) {
+	*out = *in
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		*out = (*in).DeepCopy()
+	}
+	if in.End != nil {
+		in, out := &in.End, &out.End
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceConfig.
+func (in *MaintenanceConfig) DeepCopy() *MaintenanceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelGroup) DeepCopyInto(out *ModelGroup) {
+	*out = *in
+	if in.Backends != nil {
+		in, out := &in.Backends, &out.Backends
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelGroup.
+func (in *ModelGroup) DeepCopy() *ModelGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromptCacheAffinity) DeepCopyInto(out *PromptCacheAffinity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromptCacheAffinity.
+func (in *PromptCacheAffinity) DeepCopy() *PromptCacheAffinity {
+	if in == nil {
+		return nil
+	}
+	out := new(PromptCacheAffinity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RateLimitConfig) DeepCopyInto(out *RateLimitConfig) {
 	*out = *in
@@ -443,6 +682,11 @@ func (in *RouteMatch) DeepCopyInto(out *RouteMatch) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Methods != nil {
+		in, out := &in.Methods, &out.Methods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteMatch.
@@ -466,7 +710,24 @@ func (in *RouteRule) DeepCopyInto(out *RouteRule) {
 	if in.Backends != nil {
 		in, out := &in.Backends, &out.Backends
 		*out = make([]BackendRef, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BreakerFallback != nil {
+		in, out := &in.BreakerFallback, &out.BreakerFallback
+		*out = new(BackendRef)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PromptCacheAffinity != nil {
+		in, out := &in.PromptCacheAffinity, &out.PromptCacheAffinity
+		*out = new(PromptCacheAffinity)
+		**out = **in
 	}
 }
 
@@ -479,3 +740,18 @@ func (in *RouteRule) DeepCopy() *RouteRule {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SessionAffinity) DeepCopyInto(out *SessionAffinity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionAffinity.
+func (in *SessionAffinity) DeepCopy() *SessionAffinity {
+	if in == nil {
+		return nil
+	}
+	out := new(SessionAffinity)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -20,13 +20,17 @@ import (
 	"context"
 	"crypto/tls"
 	"flag"
+	"math/rand"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -70,12 +74,54 @@ func main() {
 	var enableHTTP2 bool
 	var enableTracing bool
 	var otlpEndpoint string
+	var tracePropagateHeaders string
 	var enableSmartRouting bool
 	var smartRoutingLongContextThreshold int
 	var smartRoutingFastModelThreshold int
 	var smartRoutingLongContextBackend string
 	var smartRoutingFastModelBackend string
+	var smartRoutingEstimationFailureBackend string
 	var configPath string
+	var identitySources string
+	var forceVariantUsers string
+	var pinnableBackends string
+	var enableServiceDiscovery bool
+	var failureCacheTTL time.Duration
+	var maxInFlight int
+	var healthCheckConcurrency int
+	var latencySmoothingFactor float64
+	var corsAllowedOrigins string
+	var enableCompression bool
+	var enableIdempotency bool
+	var idempotencyTTL time.Duration
+	var serveModels bool
+	var maxHeaderBytes int
+	var maxHeaderCount int
+	var maxConcurrentRequests int
+	var enableRecheckEndpoint bool
+	var recheckTokenFile string
+	var maxBackendRedirects int
+	var backendRedirectHosts string
+	var backendSelectionSeed int64
+	var backendRecoveryRampUp time.Duration
+	var enableConversationCosts bool
+	var enableCanaryAnalysis bool
+	var enableCostTags bool
+	var costTagHeader string
+	var costLogFile string
+	var allowedPaths string
+	var deniedPaths string
+	var enableAuditLog bool
+	var enableProviderRateLimits bool
+	var startupGracePeriod time.Duration
+	var startupMinRoutes int
+	var readinessMinHealthyBackends int
+	var proxyTLSCertFile, proxyTLSKeyFile string
+	var proxyTLSMinVersion, proxyTLSCipherSuites string
+	var enableBackendQueue bool
+	var backendQueueSize int
+	var backendQueueTimeout time.Duration
+	var enableAdaptiveConcurrency bool
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&configPath, "config", "", "Path to kortex configuration file for hot-reload support.")
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
@@ -84,11 +130,119 @@ func main() {
 	flag.StringVar(&proxyAddr, "proxy-bind-address", ":8080", "The address the inference proxy binds to.")
 	flag.BoolVar(&enableTracing, "enable-tracing", false, "Enable OpenTelemetry tracing.")
 	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "localhost:4317", "OTLP collector endpoint for tracing.")
+	flag.StringVar(&tracePropagateHeaders, "trace-propagate-headers", "",
+		"Comma-separated request headers (e.g. X-Tenant) whose values are added to request spans as attributes.")
 	flag.BoolVar(&enableSmartRouting, "enable-smart-routing", false, "Enable smart routing based on request characteristics.")
 	flag.IntVar(&smartRoutingLongContextThreshold, "smart-routing-long-context-threshold", 4000, "Token count threshold for long-context routing.")
 	flag.IntVar(&smartRoutingFastModelThreshold, "smart-routing-fast-model-threshold", 500, "Token count threshold for fast model routing.")
 	flag.StringVar(&smartRoutingLongContextBackend, "smart-routing-long-context-backend", "", "Backend name for long-context requests.")
 	flag.StringVar(&smartRoutingFastModelBackend, "smart-routing-fast-model-backend", "", "Backend name for short/fast requests.")
+	flag.StringVar(&smartRoutingEstimationFailureBackend, "smart-routing-estimation-failure-backend", "",
+		"Backend name for requests whose body can't be read to estimate tokens. Empty uses the route's default backend.")
+	flag.StringVar(&identitySources, "identity-sources", "header:X-User-ID,remote-ip",
+		"Ordered, comma-separated sources used to identify users for experiments and rate limiting "+
+			"(header:<name>, jwt:<claim>, client-cert, remote-ip). Values of credential headers such as "+
+			"Authorization are hashed. JWT signatures are not verified, so only use jwt:<claim> behind an "+
+			"edge that authenticates the token.")
+	flag.StringVar(&forceVariantUsers, "force-variant-users", "",
+		"Comma-separated user identities (e.g. QA accounts) allowed to pick their A/B experiment variant "+
+			"with the X-Force-Variant header. Forcing is disabled when empty.")
+	flag.StringVar(&pinnableBackends, "pinnable-backends", "",
+		"Comma-separated backend names that requests may force with the X-Pin-Backend header, for debugging. "+
+			"Pinning is disabled when empty.")
+	flag.BoolVar(&enableServiceDiscovery, "enable-service-discovery", false,
+		"Create InferenceBackends automatically from Services annotated with kortex.io/backend: \"true\".")
+	flag.DurationVar(&failureCacheTTL, "failure-cache-ttl", proxy.DefaultHealthCacheTTL,
+		"How long a backend is deprioritized by the router after a failed request. Set to 0 to disable.")
+	flag.IntVar(&maxInFlight, "max-in-flight", 0,
+		"Maximum concurrent proxy requests. Near this limit, requests are shed by X-Priority "+
+			"(low first, high last). Set to 0 to disable admission control.")
+	flag.IntVar(&healthCheckConcurrency, "health-check-concurrency", 10,
+		"Maximum number of backend health probes that run at once. Set to 0 for no limit.")
+	flag.Float64Var(&latencySmoothingFactor, "latency-smoothing-factor", controller.DefaultLatencySmoothingFactor,
+		"Weight (0-1] of the newest health-check latency in a backend's average latency.")
+	flag.BoolVar(&enableBackendQueue, "enable-backend-queue", false,
+		"Enforce each backend's maxConcurrency, queuing requests beyond it instead of sending them.")
+	flag.IntVar(&backendQueueSize, "backend-queue-size", proxy.DefaultQueueConfig().MaxSize,
+		"Maximum requests waiting per backend when --enable-backend-queue is set. Overflow gets a 503.")
+	flag.DurationVar(&backendQueueTimeout, "backend-queue-timeout", proxy.DefaultQueueConfig().MaxWait,
+		"Maximum time a request waits in a backend queue before getting a 503.")
+	flag.BoolVar(&enableAdaptiveConcurrency, "enable-adaptive-concurrency", false,
+		"Adjust each backend's concurrency limit from observed latency instead of only using maxConcurrency. "+
+			"Requires --enable-backend-queue.")
+	flag.StringVar(&corsAllowedOrigins, "cors-allowed-origins", "",
+		"Comma-separated origins allowed to call the proxy from a browser (\"*\" for any). Empty disables CORS.")
+	flag.BoolVar(&enableCompression, "enable-response-compression", false,
+		"Gzip non-streaming proxy responses for clients that send Accept-Encoding: gzip.")
+	flag.BoolVar(&enableIdempotency, "enable-idempotency-keys", false,
+		"Replay the cached response to requests that repeat an Idempotency-Key instead of calling the backend again.")
+	flag.DurationVar(&idempotencyTTL, "idempotency-key-ttl", proxy.DefaultIdempotencyConfig().TTL,
+		"How long the response to an Idempotency-Key is replayed.")
+	flag.DurationVar(&startupGracePeriod, "startup-grace-period", proxy.DefaultWarmupConfig().Timeout,
+		"How long after startup unmatched requests get a 503 with Retry-After instead of a 404 while routes load. "+
+			"0 disables the grace period.")
+	flag.IntVar(&startupMinRoutes, "startup-min-routes", proxy.DefaultWarmupConfig().MinRoutes,
+		"Number of loaded routes that ends the startup grace period early.")
+	flag.IntVar(&readinessMinHealthyBackends, "readiness-min-healthy-backends", 0,
+		"Report not ready while fewer than this many backends are healthy across all namespaces. 0 disables the check.")
+	flag.BoolVar(&enableAuditLog, "enable-audit-log", false,
+		"Log the request and response bodies sampled by routes with auditSampling.")
+	flag.BoolVar(&enableProviderRateLimits, "enable-provider-rate-limits", false,
+		"Stop sending requests to a backend when its provider's rate limit headers report the limit is almost used up.")
+	flag.BoolVar(&enableRecheckEndpoint, "enable-recheck-endpoint", false,
+		"Serve POST /v1/backends/recheck, which health checks every backend in the X-Namespace namespace immediately. "+
+			"Requires --recheck-token-file.")
+	flag.StringVar(&recheckTokenFile, "recheck-token-file", "",
+		"File holding the admin token that callers of /v1/backends/recheck must send as a bearer token.")
+	flag.IntVar(&maxBackendRedirects, "max-backend-redirects", 0,
+		"Follow up to this many redirects from backends and return the final response instead of the redirect. "+
+			"0 passes redirects through to the client.")
+	flag.StringVar(&backendRedirectHosts, "backend-redirect-allowed-hosts", "",
+		"Comma-separated hosts, and their subdomains, that followed backend redirects may lead to besides the "+
+			"backend's own host. Redirects elsewhere are passed through to the client, and redirects to another host "+
+			"that resolves to a private address fail.")
+	flag.DurationVar(&backendRecoveryRampUp, "backend-recovery-ramp-up", 0,
+		"Gradually increase the weight of a backend that just became healthy to its full weight over this window, "+
+			"instead of sending it its full share at once. 0 disables the ramp-up.")
+	flag.Int64Var(&backendSelectionSeed, "backend-selection-seed", 0,
+		"Seed for weighted backend selection, making traffic splits reproducible across restarts. 0 uses a random seed.")
+	flag.BoolVar(&enableCanaryAnalysis, "enable-canary-analysis", false,
+		"Roll back canaries whose error rate exceeds their analysis.maxErrorRate. Error rates are measured from the "+
+			"requests this replica's proxy serves, so only enable it when the manager runs a single replica.")
+	flag.BoolVar(&enableConversationCosts, "enable-conversation-costs", false,
+		"Roll up the cost of requests sharing an X-Conversation-ID header, such as multi-turn tool-call flows, "+
+			"into a single conversation cost.")
+	flag.BoolVar(&enableCostTags, "enable-cost-tags", false,
+		"Aggregate cost by the team or project tag in --cost-tag-header, and add it as a tag label on cost metrics. "+
+			"Tags beyond the first 1000 are attributed to \"other\".")
+	flag.StringVar(&costTagHeader, "cost-tag-header", proxy.DefaultCostTagHeader,
+		"Header carrying the cost tag when --enable-cost-tags is set.")
+	flag.StringVar(&costLogFile, "cost-log-file", "",
+		"Append a JSON line with the route, backend, user, tokens and cost of every tracked request to this file. "+
+			"Empty disables the cost log.")
+	flag.StringVar(&allowedPaths, "allowed-paths", "",
+		"Comma-separated path prefixes or globs the proxy serves, e.g. /v1/chat/completions,/v1/embeddings. "+
+			"Other paths get a 404. Empty allows all paths.")
+	flag.StringVar(&deniedPaths, "denied-paths", "",
+		"Comma-separated path prefixes or globs the proxy never serves, even if allowed.")
+	flag.IntVar(&maxHeaderBytes, "max-header-bytes", 0,
+		"Maximum size of the request headers accepted by the inference proxy, in bytes. "+
+			"0 uses net/http's default of 1MB. Lower limits may reject requests existing clients send.")
+	flag.IntVar(&maxHeaderCount, "max-header-count", 0,
+		"Maximum number of request headers accepted by the inference proxy. Requests with more get a 431. "+
+			"0 disables the limit. Set it only after checking how many headers your clients send.")
+	flag.IntVar(&maxConcurrentRequests, "max-concurrent-requests", 0,
+		"Hard limit on the requests the inference proxy handles at once. Requests beyond it get a 503 "+
+			"regardless of priority. Set to 0 to disable.")
+	flag.BoolVar(&serveModels, "serve-models-endpoint", false,
+		"Answer GET /v1/models with the models of the healthy backends in the namespace instead of proxying it.")
+	flag.StringVar(&proxyTLSCertFile, "proxy-tls-cert-file", "",
+		"Certificate file for serving the inference proxy over HTTPS. Requires --proxy-tls-key-file.")
+	flag.StringVar(&proxyTLSKeyFile, "proxy-tls-key-file", "", "Key file for serving the inference proxy over HTTPS.")
+	flag.StringVar(&proxyTLSMinVersion, "proxy-tls-min-version", "1.2",
+		"Minimum TLS version accepted by the inference proxy (1.2 or 1.3).")
+	flag.StringVar(&proxyTLSCipherSuites, "proxy-tls-cipher-suites", "",
+		"Comma-separated TLS 1.2 cipher suites accepted by the inference proxy. Empty uses Go's defaults.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -202,13 +356,42 @@ func main() {
 	// Create shared components for controllers and proxy
 	routeCache := cache.NewStore()
 	healthChecker := health.NewChecker()
+	healthChecker.SetMaxConcurrency(healthCheckConcurrency)
+	metricsRecorder := proxy.NewMetricsRecorder()
+	if failureCacheTTL > 0 {
+		metricsRecorder.SetHealthCache(proxy.NewHealthCache(failureCacheTTL))
+	}
+	rateLimiter := proxy.NewRateLimiter()
 
 	// Setup InferenceBackend controller
+	// Operators can trigger immediate health checks through the proxy
+	var backendRechecker *controller.BackendRechecker
+	var recheckToken string
+	if enableRecheckEndpoint {
+		if recheckTokenFile == "" {
+			setupLog.Error(nil, "--enable-recheck-endpoint requires --recheck-token-file")
+			os.Exit(1)
+		}
+		token, err := os.ReadFile(recheckTokenFile)
+		if err != nil {
+			setupLog.Error(err, "unable to read recheck token", "recheck-token-file", recheckTokenFile)
+			os.Exit(1)
+		}
+		recheckToken = strings.TrimSpace(string(token))
+		if recheckToken == "" {
+			setupLog.Error(nil, "recheck token file is empty", "recheck-token-file", recheckTokenFile)
+			os.Exit(1)
+		}
+		backendRechecker = controller.NewBackendRechecker(mgr.GetClient())
+	}
+
 	if err := (&controller.InferenceBackendReconciler{
-		Client:        mgr.GetClient(),
-		Scheme:        mgr.GetScheme(),
-		HealthChecker: healthChecker,
-		Cache:         routeCache,
+		Client:                 mgr.GetClient(),
+		Scheme:                 mgr.GetScheme(),
+		HealthChecker:          healthChecker,
+		Cache:                  routeCache,
+		Metrics:                metricsRecorder,
+		LatencySmoothingFactor: latencySmoothingFactor,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "InferenceBackend")
 		os.Exit(1)
@@ -216,20 +399,72 @@ func main() {
 
 	// Setup InferenceRoute controller
 	if err := (&controller.InferenceRouteReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Cache:  routeCache,
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		Cache:          routeCache,
+		Metrics:        metricsRecorder,
+		RateLimiter:    rateLimiter,
+		CanaryAnalysis: enableCanaryAnalysis,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "InferenceRoute")
 		os.Exit(1)
 	}
+
+	// Setup Service discovery controller
+	if enableServiceDiscovery {
+		if err := (&controller.ServiceBackendReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ServiceBackend")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	// Create P2/P3 components for proxy server
-	metricsRecorder := proxy.NewMetricsRecorder()
-	rateLimiter := proxy.NewRateLimiter()
 	experimentManager := proxy.NewExperimentManager(metricsRecorder)
 	costTracker := proxy.NewCostTracker(metricsRecorder)
+	if enableConversationCosts {
+		costTracker.EnableConversationCosts(proxy.DefaultConversationCostConfig())
+	}
+	if enableCostTags {
+		costTagConfig := proxy.DefaultCostTagConfig()
+		costTagConfig.Header = costTagHeader
+		costTracker.EnableCostTags(costTagConfig)
+	}
+	if costLogFile != "" {
+		f, err := os.OpenFile(costLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			setupLog.Error(err, "unable to open cost log", "cost-log-file", costLogFile)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				setupLog.Error(err, "failed to close cost log")
+			}
+		}()
+		costTracker.SetCostSink(proxy.NewJSONLinesCostSink(f, ctrl.Log.WithName("proxy")))
+	}
+
+	// Shared user identity for experiments and rate limiting
+	sources, err := proxy.ParseIdentitySources(identitySources)
+	if err != nil {
+		setupLog.Error(err, "invalid identity sources", "identity-sources", identitySources)
+		os.Exit(1)
+	}
+	identityExtractor := proxy.NewIdentityExtractor(sources...)
+
+	// Let QA accounts force themselves into an experiment variant
+	if forceVariantUsers != "" {
+		var users []string
+		for _, user := range strings.Split(forceVariantUsers, ",") {
+			if user = strings.TrimSpace(user); user != "" {
+				users = append(users, user)
+			}
+		}
+		experimentManager.SetForceVariantUsers(users)
+	}
 
 	// Initialize OpenTelemetry tracer if enabled
 	var tracer *tracing.Tracer
@@ -242,6 +477,11 @@ func main() {
 			SampleRate:     1.0,
 			Insecure:       true,
 		}
+		for _, header := range strings.Split(tracePropagateHeaders, ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				tracingConfig.PropagateHeaders = append(tracingConfig.PropagateHeaders, header)
+			}
+		}
 		var err error
 		tracer, err = tracing.NewTracer(tracingConfig)
 		if err != nil {
@@ -260,11 +500,12 @@ func main() {
 	var smartRouter *proxy.SmartRouter
 	if enableSmartRouting {
 		smartRouterConfig := proxy.SmartRouterConfig{
-			LongContextThreshold:   smartRoutingLongContextThreshold,
-			FastModelThreshold:     smartRoutingFastModelThreshold,
-			LongContextBackend:     smartRoutingLongContextBackend,
-			FastModelBackend:       smartRoutingFastModelBackend,
-			EnableCostOptimization: false,
+			LongContextThreshold:     smartRoutingLongContextThreshold,
+			FastModelThreshold:       smartRoutingFastModelThreshold,
+			LongContextBackend:       smartRoutingLongContextBackend,
+			FastModelBackend:         smartRoutingFastModelBackend,
+			EstimationFailureBackend: smartRoutingEstimationFailureBackend,
+			EnableCostOptimization:   false,
 		}
 		smartRouter = proxy.NewSmartRouter(smartRouterConfig, ctrl.Log)
 		setupLog.Info("Smart routing enabled",
@@ -272,6 +513,7 @@ func main() {
 			"fast-model-threshold", smartRoutingFastModelThreshold,
 			"long-context-backend", smartRoutingLongContextBackend,
 			"fast-model-backend", smartRoutingFastModelBackend,
+			"estimation-failure-backend", smartRoutingEstimationFailureBackend,
 		)
 	}
 
@@ -284,20 +526,162 @@ func main() {
 		"smart-router", smartRouter != nil,
 	)
 
+	// Admission control sheds low priority requests near capacity
+	var admissionController *proxy.AdmissionController
+	if maxInFlight > 0 {
+		admissionController = proxy.NewAdmissionController(proxy.DefaultAdmissionConfig(maxInFlight))
+	}
+
+	// CORS lets browser clients call the proxy directly
+	var corsConfig *proxy.CORSConfig
+	if corsAllowedOrigins != "" {
+		var origins []string
+		for _, origin := range strings.Split(corsAllowedOrigins, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				origins = append(origins, origin)
+			}
+		}
+		cfg := proxy.DefaultCORSConfig(origins...)
+		corsConfig = &cfg
+	}
+
+	// Compress responses for clients on slow links
+	var compressionConfig *proxy.CompressionConfig
+	if enableCompression {
+		cfg := proxy.DefaultCompressionConfig()
+		compressionConfig = &cfg
+	}
+
+	// Deduplicate client retries that carry an Idempotency-Key
+	var idempotencyConfig *proxy.IdempotencyConfig
+	if enableIdempotency {
+		cfg := proxy.DefaultIdempotencyConfig()
+		cfg.TTL = idempotencyTTL
+		idempotencyConfig = &cfg
+	}
+
+	// Log sampled request and response bodies for routes that audit them
+	var auditLogger *proxy.AuditLogger
+	if enableAuditLog {
+		auditLogger = proxy.NewAuditLogger(proxy.NewLogAuditSink(ctrl.Log.WithName("proxy")))
+	}
+
+	// Throttle backends before their provider starts returning 429s
+	var providerRateLimiter *proxy.ProviderRateLimiter
+	if enableProviderRateLimits {
+		providerRateLimiter = proxy.NewProviderRateLimiter(proxy.DefaultProviderRateLimitConfig())
+	}
+
+	// Tell clients to retry while the controllers are still loading routes
+	var warmupConfig *proxy.WarmupConfig
+	if startupGracePeriod > 0 {
+		cfg := proxy.DefaultWarmupConfig()
+		cfg.Timeout = startupGracePeriod
+		cfg.MinRoutes = startupMinRoutes
+		warmupConfig = &cfg
+	}
+
+	// Queue requests for backends at their concurrency limit
+	var requestQueue *proxy.RequestQueue
+	if enableBackendQueue {
+		requestQueue = proxy.NewRequestQueue(proxy.QueueConfig{
+			MaxSize: backendQueueSize,
+			MaxWait: backendQueueTimeout,
+		})
+	}
+
+	// Adapt backend concurrency limits to observed latency
+	var adaptiveLimiter *proxy.AdaptiveLimiter
+	if enableAdaptiveConcurrency {
+		if requestQueue == nil {
+			setupLog.Info("--enable-adaptive-concurrency has no effect without --enable-backend-queue")
+		}
+		adaptiveLimiter = proxy.NewAdaptiveLimiter(proxy.DefaultAdaptiveLimiterConfig())
+	}
+
+	// Let operators force requests to a specific backend while debugging it
+	var pinnable []string
+	for _, name := range strings.Split(pinnableBackends, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			pinnable = append(pinnable, name)
+		}
+	}
+
+	var redirectHosts []string
+	for _, host := range strings.Split(backendRedirectHosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			redirectHosts = append(redirectHosts, host)
+		}
+	}
+
 	// Setup inference proxy server with all features
 	proxyConfig := proxy.DefaultConfig()
 	proxyConfig.Addr = proxyAddr
-	proxyServer := proxy.NewServer(
-		proxyConfig,
-		routeCache,
-		mgr.GetClient(),
-		ctrl.Log.WithName("proxy"),
+	proxyConfig.ServeModels = serveModels
+	proxyConfig.MaxHeaderBytes = maxHeaderBytes
+	proxyConfig.MaxHeaderCount = maxHeaderCount
+	proxyConfig.MaxConcurrentRequests = maxConcurrentRequests
+	if proxyConfig.AllowedPaths, err = proxy.ParsePathPatterns(allowedPaths); err != nil {
+		setupLog.Error(err, "invalid --allowed-paths")
+		os.Exit(1)
+	}
+	if proxyConfig.DeniedPaths, err = proxy.ParsePathPatterns(deniedPaths); err != nil {
+		setupLog.Error(err, "invalid --denied-paths")
+		os.Exit(1)
+	}
+	if proxyTLSCertFile != "" || proxyTLSKeyFile != "" {
+		minVersion, err := proxy.ParseTLSVersion(proxyTLSMinVersion)
+		if err != nil {
+			setupLog.Error(err, "invalid --proxy-tls-min-version")
+			os.Exit(1)
+		}
+		cipherSuites, err := proxy.ParseCipherSuites(proxyTLSCipherSuites)
+		if err != nil {
+			setupLog.Error(err, "invalid --proxy-tls-cipher-suites")
+			os.Exit(1)
+		}
+		proxyConfig.TLS = &proxy.TLSConfig{
+			CertFile:     proxyTLSCertFile,
+			KeyFile:      proxyTLSKeyFile,
+			MinVersion:   minVersion,
+			CipherSuites: cipherSuites,
+			TLSOpts:      tlsOpts,
+		}
+	}
+	proxyOptions := []proxy.ServerOption{
 		proxy.WithMetrics(metricsRecorder),
 		proxy.WithRateLimiter(rateLimiter),
 		proxy.WithExperiments(experimentManager),
 		proxy.WithCostTracker(costTracker),
 		proxy.WithTracer(tracer),
 		proxy.WithServerSmartRouter(smartRouter),
+		proxy.WithIdentityExtractor(identityExtractor),
+		proxy.WithAdmissionController(admissionController),
+		proxy.WithCORS(corsConfig),
+		proxy.WithCompression(compressionConfig),
+		proxy.WithIdempotency(idempotencyConfig),
+		proxy.WithWarmup(warmupConfig),
+		proxy.WithAuditLogger(auditLogger),
+		proxy.WithProviderRateLimiter(providerRateLimiter),
+		proxy.WithMaxBackendRedirects(maxBackendRedirects, redirectHosts...),
+		proxy.WithRequestQueue(requestQueue),
+		proxy.WithAdaptiveConcurrency(adaptiveLimiter),
+		proxy.WithPinnableBackends(pinnable),
+		proxy.WithRecoveryRampUp(backendRecoveryRampUp),
+	}
+	if backendRechecker != nil {
+		proxyOptions = append(proxyOptions, proxy.WithBackendRechecker(backendRechecker, recheckToken))
+	}
+	if backendSelectionSeed != 0 {
+		proxyOptions = append(proxyOptions,
+			proxy.WithSelectionRand(rand.New(rand.NewSource(backendSelectionSeed))))
+	}
+	proxyServer := proxy.NewServer(
+		proxyConfig,
+		routeCache,
+		mgr.GetClient(),
+		ctrl.Log.WithName("proxy"),
+		proxyOptions...,
 	)
 
 	// Add proxy server to manager as a runnable
@@ -314,6 +698,35 @@ func main() {
 			os.Exit(1)
 		}
 
+		// Apply provider defaults and namespace rate limits from the initial configuration
+		applyProviderConfig(configWatcher.GetConfig().Providers, proxyServer, healthChecker)
+		applyNamespaceRateLimits(configWatcher.GetConfig().RateLimits, routeCache)
+		applyWeightOverrides(configWatcher.GetConfig().WeightOverrides, routeCache)
+		applyTracingConfig(configWatcher.GetConfig().Observability.Tracing, tracer)
+
+		// Push metrics to the tracing OTLP collector if enabled
+		observability := configWatcher.GetConfig().Observability
+		if observability.Metrics.OTLP.Enabled {
+			meter, err := tracing.NewMeter(tracing.MeterConfig{
+				Endpoint:       observability.Tracing.Endpoint,
+				Insecure:       observability.Tracing.Insecure,
+				ServiceName:    "kortex-gateway",
+				ServiceVersion: "v0.1.0",
+				ExportInterval: time.Duration(observability.Metrics.OTLP.ExportInterval) * time.Second,
+			})
+			if err != nil {
+				setupLog.Error(err, "failed to initialize OTLP metrics exporter")
+				os.Exit(1)
+			}
+			defer func() {
+				if err := meter.Shutdown(context.Background()); err != nil {
+					setupLog.Error(err, "failed to shutdown OTLP metrics exporter")
+				}
+			}()
+			metricsRecorder.SetOTLPMeter(meter)
+			setupLog.Info("OTLP metrics export enabled", "endpoint", observability.Tracing.Endpoint)
+		}
+
 		// Register handlers for configuration changes
 		configWatcher.OnChange(func(newConfig *config.KortexConfig) {
 			setupLog.Info("Configuration changed, applying updates",
@@ -329,14 +742,23 @@ func main() {
 				}
 			}
 
+			// Update provider defaults, namespace rate limits and weight overrides
+			applyProviderConfig(newConfig.Providers, proxyServer, healthChecker)
+			applyNamespaceRateLimits(newConfig.RateLimits, routeCache)
+			applyWeightOverrides(newConfig.WeightOverrides, routeCache)
+
+			// Reconnect the tracer if the collector changed
+			applyTracingConfig(newConfig.Observability.Tracing, tracer)
+
 			// Update smart router settings
 			if smartRouter != nil && newConfig.SmartRouting.Enabled {
 				smartRouter.UpdateConfig(proxy.SmartRouterConfig{
-					LongContextThreshold:   newConfig.SmartRouting.LongContextThreshold,
-					FastModelThreshold:     newConfig.SmartRouting.FastModelThreshold,
-					LongContextBackend:     newConfig.SmartRouting.LongContextBackend,
-					FastModelBackend:       newConfig.SmartRouting.FastModelBackend,
-					EnableCostOptimization: newConfig.SmartRouting.EnableCostOptimization,
+					LongContextThreshold:     newConfig.SmartRouting.LongContextThreshold,
+					FastModelThreshold:       newConfig.SmartRouting.FastModelThreshold,
+					LongContextBackend:       newConfig.SmartRouting.LongContextBackend,
+					FastModelBackend:         newConfig.SmartRouting.FastModelBackend,
+					EstimationFailureBackend: newConfig.SmartRouting.EstimationFailureBackend,
+					EnableCostOptimization:   newConfig.SmartRouting.EnableCostOptimization,
 				})
 			}
 		})
@@ -360,6 +782,13 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if readinessMinHealthyBackends > 0 {
+		if err := mgr.AddReadyzCheck("backends",
+			proxy.BackendReadinessCheck(routeCache, readinessMinHealthyBackends)); err != nil {
+			setupLog.Error(err, "unable to set up backend readiness check")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager",
 		"proxy-addr", proxyAddr,
@@ -370,3 +799,74 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// applyProviderConfig pushes the enabled provider settings to the proxy and health checker
+func applyProviderConfig(providers map[string]config.ProviderConfig, proxyServer *proxy.Server, healthChecker *health.Checker) {
+	defaults := make(map[string]proxy.ProviderDefaults, len(providers))
+	baseURLs := make(map[string]string, len(providers))
+	for name, p := range providers {
+		if !p.Enabled {
+			continue
+		}
+		defaults[name] = proxy.ProviderDefaults{
+			BaseURL:    p.BaseURL,
+			Timeout:    time.Duration(p.Timeout) * time.Second,
+			MaxRetries: p.MaxRetries,
+		}
+		if p.BaseURL != "" {
+			baseURLs[name] = p.BaseURL
+		}
+	}
+
+	proxyServer.SetProviderDefaults(defaults)
+	healthChecker.SetProviderBaseURLs(baseURLs)
+}
+
+// applyNamespaceRateLimits pushes the namespace default rate limits to the route cache
+func applyNamespaceRateLimits(rateLimits config.RateLimitConfig, store *cache.Store) {
+	limits := make(map[string]*gatewayv1alpha1.RateLimitConfig, len(rateLimits.NamespaceDefaults))
+	if rateLimits.Enabled {
+		for namespace, limit := range rateLimits.NamespaceDefaults {
+			if limit.RequestsPerMinute <= 0 {
+				continue
+			}
+			limits[namespace] = &gatewayv1alpha1.RateLimitConfig{
+				RequestsPerMinute: int32(limit.RequestsPerMinute),
+				PerUser:           limit.PerUser,
+				UserHeader:        limit.UserHeader,
+			}
+		}
+	}
+	store.SetNamespaceRateLimits(limits)
+}
+
+// applyTracingConfig points the tracer at the collector in the configuration
+// file. Tracing must be enabled with --enable-tracing; the file only changes
+// where and how spans are exported.
+func applyTracingConfig(tracingConfig config.TracingConfig, tracer *tracing.Tracer) {
+	if tracer == nil || !tracingConfig.Enabled || tracingConfig.Endpoint == "" {
+		return
+	}
+	cfg := tracer.Config()
+	cfg.Endpoint = tracingConfig.Endpoint
+	cfg.Insecure = tracingConfig.Insecure
+	if err := tracer.Reload(context.Background(), cfg); err != nil {
+		setupLog.Error(err, "failed to reload tracer", "endpoint", cfg.Endpoint)
+		return
+	}
+	setupLog.V(1).Info("Tracing configuration applied", "endpoint", cfg.Endpoint, "insecure", cfg.Insecure)
+}
+
+// applyWeightOverrides pushes the per-route backend weight overrides to the route cache.
+// Keys that aren't in namespace/route form are skipped.
+func applyWeightOverrides(overrides map[string]map[string]int32, store *cache.Store) {
+	byRoute := make(map[types.NamespacedName]map[string]int32, len(overrides))
+	for route, weights := range overrides {
+		namespace, name, ok := strings.Cut(route, "/")
+		if !ok || namespace == "" || name == "" {
+			continue
+		}
+		byRoute[types.NamespacedName{Namespace: namespace, Name: name}] = weights
+	}
+	store.SetWeightOverrides(byRoute)
+}
@@ -17,6 +17,10 @@ limitations under the License.
 package cache
 
 import (
+	"maps"
+	"net"
+	"slices"
+	"strings"
 	"sync"
 
 	"k8s.io/apimachinery/pkg/types"
@@ -29,6 +33,9 @@ const (
 	HealthStatusHealthy   = "Healthy"
 	HealthStatusUnhealthy = "Unhealthy"
 	HealthStatusUnknown   = "Unknown"
+
+	// HealthStatusMaintenance marks a backend that is out of rotation
+	HealthStatusMaintenance = "Maintenance"
 )
 
 // Store provides thread-safe access to InferenceRoutes and InferenceBackends.
@@ -38,13 +45,38 @@ type Store struct {
 	mu       sync.RWMutex
 	routes   map[types.NamespacedName]*gatewayv1alpha1.InferenceRoute
 	backends map[types.NamespacedName]*gatewayv1alpha1.InferenceBackend
+
+	// namespaceRateLimits are default rate limits for routes without their own
+	namespaceRateLimits map[string]*gatewayv1alpha1.RateLimitConfig
+
+	// weightOverrides replace route backend weights, keyed by route then backend
+	weightOverrides map[types.NamespacedName]map[string]int32
+
+	// cordoned backends receive no new requests but are still health checked
+	cordoned map[types.NamespacedName]struct{}
+
+	// endpoints are the pod addresses (host:port) of backends that route
+	// directly to their Service's endpoints
+	endpoints map[types.NamespacedName][]string
+
+	// hostnames indexes routes by the hostnames they serve. Routes claiming
+	// the same hostname are kept sorted by namespace and name.
+	hostnames map[string][]types.NamespacedName
+
+	// subscribers receive route and backend change events
+	subscribers []chan StoreEvent
 }
 
 // NewStore creates a new empty cache store
 func NewStore() *Store {
 	return &Store{
-		routes:   make(map[types.NamespacedName]*gatewayv1alpha1.InferenceRoute),
-		backends: make(map[types.NamespacedName]*gatewayv1alpha1.InferenceBackend),
+		routes:              make(map[types.NamespacedName]*gatewayv1alpha1.InferenceRoute),
+		backends:            make(map[types.NamespacedName]*gatewayv1alpha1.InferenceBackend),
+		namespaceRateLimits: make(map[string]*gatewayv1alpha1.RateLimitConfig),
+		weightOverrides:     make(map[types.NamespacedName]map[string]int32),
+		cordoned:            make(map[types.NamespacedName]struct{}),
+		endpoints:           make(map[types.NamespacedName][]string),
+		hostnames:           make(map[string][]types.NamespacedName),
 	}
 }
 
@@ -54,8 +86,32 @@ func NewStore() *Store {
 func (s *Store) SetRoute(key types.NamespacedName, route *gatewayv1alpha1.InferenceRoute) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	previous, exists := s.routes[key]
 	// Deep copy to prevent mutation of cached objects
 	s.routes[key] = route.DeepCopy()
+	s.indexHostnamesLocked(key, previous, route)
+	s.publishLocked(setEvent(EventKindRoute, key, exists))
+}
+
+// SetRoutes adds or updates several routes under a single write lock. Nil
+// routes are ignored.
+func (s *Store) SetRoutes(routes map[types.NamespacedName]*gatewayv1alpha1.InferenceRoute) {
+	// Deep copy before locking to keep the critical section short
+	copies := make(map[types.NamespacedName]*gatewayv1alpha1.InferenceRoute, len(routes))
+	for key, route := range routes {
+		if route != nil {
+			copies[key] = route.DeepCopy()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, route := range copies {
+		previous, exists := s.routes[key]
+		s.routes[key] = route
+		s.indexHostnamesLocked(key, previous, route)
+		s.publishLocked(setEvent(EventKindRoute, key, exists))
+	}
 }
 
 // GetRoute retrieves a route from the cache
@@ -74,7 +130,74 @@ func (s *Store) GetRoute(key types.NamespacedName) (*gatewayv1alpha1.InferenceRo
 func (s *Store) DeleteRoute(key types.NamespacedName) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	previous, ok := s.routes[key]
+	if !ok {
+		return
+	}
 	delete(s.routes, key)
+	s.indexHostnamesLocked(key, previous, nil)
+	s.publishLocked(StoreEvent{Type: EventDeleted, Kind: EventKindRoute, Key: key})
+}
+
+// GetRouteByHostname retrieves the route serving a hostname, such as a
+// request's Host header. The port is ignored, and pending or failed routes
+// are skipped. When several routes claim the hostname, the oldest is
+// returned, with ties broken by namespace and name.
+func (s *Store) GetRouteByHostname(host string) (*gatewayv1alpha1.InferenceRoute, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var found *gatewayv1alpha1.InferenceRoute
+	for _, key := range s.hostnames[normalizeHostname(host)] {
+		route := s.routes[key]
+		if route.Status.Phase == "Failed" || route.Status.Phase == "Pending" {
+			continue
+		}
+		// Keys are sorted by namespace and name, so only an older route replaces the match
+		if found == nil || route.CreationTimestamp.Before(&found.CreationTimestamp) {
+			found = route
+		}
+	}
+	if found == nil {
+		return nil, false
+	}
+	return found.DeepCopy(), true
+}
+
+// indexHostnamesLocked replaces the hostnames indexed for a route. Either
+// route may be nil. The caller must hold s.mu.
+func (s *Store) indexHostnamesLocked(key types.NamespacedName, previous, route *gatewayv1alpha1.InferenceRoute) {
+	if previous != nil {
+		for _, hostname := range previous.Spec.Hostnames {
+			hostname = normalizeHostname(hostname)
+			keys := slices.DeleteFunc(s.hostnames[hostname], func(k types.NamespacedName) bool { return k == key })
+			if len(keys) == 0 {
+				delete(s.hostnames, hostname)
+			} else {
+				s.hostnames[hostname] = keys
+			}
+		}
+	}
+	if route != nil {
+		for _, hostname := range route.Spec.Hostnames {
+			hostname = normalizeHostname(hostname)
+			if hostname == "" || slices.Contains(s.hostnames[hostname], key) {
+				continue
+			}
+			keys := append(s.hostnames[hostname], key)
+			slices.SortFunc(keys, func(a, b types.NamespacedName) int {
+				return strings.Compare(a.String(), b.String())
+			})
+			s.hostnames[hostname] = keys
+		}
+	}
+}
+
+// normalizeHostname lowercases a hostname and strips any port and trailing dot
+func normalizeHostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
 }
 
 // ListRoutes returns all routes in the cache
@@ -107,8 +230,30 @@ func (s *Store) ListRoutesInNamespace(namespace string) []*gatewayv1alpha1.Infer
 func (s *Store) SetBackend(key types.NamespacedName, backend *gatewayv1alpha1.InferenceBackend) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	_, exists := s.backends[key]
 	// Deep copy to prevent mutation of cached objects
 	s.backends[key] = backend.DeepCopy()
+	s.publishLocked(setEvent(EventKindBackend, key, exists))
+}
+
+// SetBackends adds or updates several backends under a single write lock.
+// Nil backends are ignored.
+func (s *Store) SetBackends(backends map[types.NamespacedName]*gatewayv1alpha1.InferenceBackend) {
+	// Deep copy before locking to keep the critical section short
+	copies := make(map[types.NamespacedName]*gatewayv1alpha1.InferenceBackend, len(backends))
+	for key, backend := range backends {
+		if backend != nil {
+			copies[key] = backend.DeepCopy()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, backend := range copies {
+		_, exists := s.backends[key]
+		s.backends[key] = backend
+		s.publishLocked(setEvent(EventKindBackend, key, exists))
+	}
 }
 
 // GetBackend retrieves a backend from the cache
@@ -127,7 +272,54 @@ func (s *Store) GetBackend(key types.NamespacedName) (*gatewayv1alpha1.Inference
 func (s *Store) DeleteBackend(key types.NamespacedName) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	delete(s.cordoned, key)
+	delete(s.endpoints, key)
+	if _, ok := s.backends[key]; !ok {
+		return
+	}
 	delete(s.backends, key)
+	s.publishLocked(StoreEvent{Type: EventDeleted, Kind: EventKindBackend, Key: key})
+}
+
+// CordonBackend stops new requests from being routed to a backend
+func (s *Store) CordonBackend(key types.NamespacedName) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cordoned[key] = struct{}{}
+}
+
+// UncordonBackend lets a cordoned backend receive requests again
+func (s *Store) UncordonBackend(key types.NamespacedName) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cordoned, key)
+}
+
+// IsCordoned reports whether a backend is cordoned
+func (s *Store) IsCordoned(key types.NamespacedName) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.cordoned[key]
+	return ok
+}
+
+// SetEndpoints replaces the pod addresses (host:port) a backend routes to
+// directly. No addresses removes them, so the backend's Service is used.
+func (s *Store) SetEndpoints(key types.NamespacedName, addresses []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(addresses) == 0 {
+		delete(s.endpoints, key)
+		return
+	}
+	s.endpoints[key] = slices.Clone(addresses)
+}
+
+// GetEndpoints returns the pod addresses a backend routes to directly
+func (s *Store) GetEndpoints(key types.NamespacedName) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.endpoints[key])
 }
 
 // ListBackends returns all backends in the cache
@@ -154,6 +346,76 @@ func (s *Store) ListBackendsInNamespace(namespace string) []*gatewayv1alpha1.Inf
 	return backends
 }
 
+// ListBackendsByProvider returns all external backends that use a provider.
+// External backends without a provider use openai.
+func (s *Store) ListBackendsByProvider(provider string) []*gatewayv1alpha1.InferenceBackend {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var backends []*gatewayv1alpha1.InferenceBackend
+	for _, b := range s.backends {
+		if b.Spec.External == nil {
+			continue
+		}
+		backendProvider := b.Spec.External.Provider
+		if backendProvider == "" {
+			backendProvider = "openai"
+		}
+		if backendProvider == provider {
+			backends = append(backends, b.DeepCopy())
+		}
+	}
+	return backends
+}
+
+// --- Namespace defaults ---
+
+// SetNamespaceRateLimits replaces the default rate limits applied to routes
+// that don't configure their own, keyed by namespace
+func (s *Store) SetNamespaceRateLimits(limits map[string]*gatewayv1alpha1.RateLimitConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.namespaceRateLimits = make(map[string]*gatewayv1alpha1.RateLimitConfig, len(limits))
+	for namespace, limit := range limits {
+		if limit != nil {
+			s.namespaceRateLimits[namespace] = limit.DeepCopy()
+		}
+	}
+}
+
+// GetNamespaceRateLimit retrieves the default rate limit for a namespace
+func (s *Store) GetNamespaceRateLimit(namespace string) (*gatewayv1alpha1.RateLimitConfig, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	limit, ok := s.namespaceRateLimits[namespace]
+	if !ok {
+		return nil, false
+	}
+	return limit.DeepCopy(), true
+}
+
+// SetWeightOverrides replaces the backend weight overrides for all routes
+func (s *Store) SetWeightOverrides(overrides map[types.NamespacedName]map[string]int32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.weightOverrides = make(map[types.NamespacedName]map[string]int32, len(overrides))
+	for route, weights := range overrides {
+		if len(weights) > 0 {
+			s.weightOverrides[route] = maps.Clone(weights)
+		}
+	}
+}
+
+// GetWeightOverrides retrieves the backend weight overrides for a route
+func (s *Store) GetWeightOverrides(route types.NamespacedName) (map[string]int32, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	weights, ok := s.weightOverrides[route]
+	if !ok {
+		return nil, false
+	}
+	return maps.Clone(weights), true
+}
+
 // --- Convenience methods for proxy ---
 
 // GetHealthyBackend retrieves a backend only if it's healthy
@@ -171,6 +433,32 @@ func (s *Store) GetHealthyBackend(namespace, name string) (*gatewayv1alpha1.Infe
 	return backend, true
 }
 
+// ListHealthyBackendsInNamespace returns all healthy backends in a specific namespace
+func (s *Store) ListHealthyBackendsInNamespace(namespace string) []*gatewayv1alpha1.InferenceBackend {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var backends []*gatewayv1alpha1.InferenceBackend
+	for key, b := range s.backends {
+		if key.Namespace == namespace && b.Status.Health == HealthStatusHealthy {
+			backends = append(backends, b.DeepCopy())
+		}
+	}
+	return backends
+}
+
+// CountHealthyBackends returns the number of healthy backends across all namespaces
+func (s *Store) CountHealthyBackends() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	count := 0
+	for _, b := range s.backends {
+		if b.Status.Health == HealthStatusHealthy {
+			count++
+		}
+	}
+	return count
+}
+
 // GetBackendByName is a convenience method to get a backend by namespace and name
 func (s *Store) GetBackendByName(namespace, name string) (*gatewayv1alpha1.InferenceBackend, bool) {
 	return s.GetBackend(types.NamespacedName{
@@ -187,6 +475,86 @@ func (s *Store) GetRouteByName(namespace, name string) (*gatewayv1alpha1.Inferen
 	})
 }
 
+// --- Change notifications ---
+
+// EventType is the kind of change a StoreEvent describes
+type EventType string
+
+const (
+	// EventAdded means the object was stored for the first time
+	EventAdded EventType = "Added"
+	// EventUpdated means a stored object was replaced
+	EventUpdated EventType = "Updated"
+	// EventDeleted means the object was removed from the store
+	EventDeleted EventType = "Deleted"
+)
+
+// EventKind is the kind of object a StoreEvent refers to
+type EventKind string
+
+const (
+	// EventKindRoute refers to an InferenceRoute
+	EventKindRoute EventKind = "Route"
+	// EventKindBackend refers to an InferenceBackend
+	EventKindBackend EventKind = "Backend"
+)
+
+// subscriberBuffer is the number of events buffered for each subscriber
+const subscriberBuffer = 64
+
+// StoreEvent describes a route or backend being added, updated or deleted
+type StoreEvent struct {
+	Type EventType
+	Kind EventKind
+	Key  types.NamespacedName
+}
+
+// setEvent returns the event for storing an object that may already exist
+func setEvent(kind EventKind, key types.NamespacedName, exists bool) StoreEvent {
+	if exists {
+		return StoreEvent{Type: EventUpdated, Kind: kind, Key: key}
+	}
+	return StoreEvent{Type: EventAdded, Kind: kind, Key: key}
+}
+
+// Subscribe returns a channel that receives an event for every route and
+// backend change. Events are delivered without blocking the store, so a
+// subscriber that falls more than a buffer behind misses events and should
+// re-list the store if it needs a complete view.
+func (s *Store) Subscribe() <-chan StoreEvent {
+	ch := make(chan StoreEvent, subscriberBuffer)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, ch)
+	return ch
+}
+
+// Unsubscribe stops sending events to a channel returned by Subscribe and
+// closes it
+func (s *Store) Unsubscribe(events <-chan StoreEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, ch := range s.subscribers {
+		if ch == events {
+			s.subscribers = append(s.subscribers[:i], s.subscribers[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+// publishLocked sends an event to every subscriber with room for it. The
+// caller must hold s.mu.
+func (s *Store) publishLocked(event StoreEvent) {
+	for _, ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Drop the event rather than block on a slow subscriber
+		}
+	}
+}
+
 // --- Stats ---
 
 // Stats returns cache statistics
@@ -17,8 +17,12 @@ limitations under the License.
 package cache
 
 import (
+	"fmt"
+	"reflect"
+	"slices"
 	"sync"
 	"testing"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -308,6 +312,35 @@ func TestStore_ListBackendsInNamespace(t *testing.T) {
 	}
 }
 
+func TestStore_ListBackendsByProvider(t *testing.T) {
+	store := NewStore()
+
+	providers := map[string]*gatewayv1alpha1.ExternalBackend{
+		"gpt-4":  {URL: "https://api.openai.com", Provider: "openai"},
+		"gpt-35": {URL: "https://api.openai.com"}, // defaults to openai
+		"claude": {URL: "https://api.anthropic.com", Provider: "anthropic"},
+		"kserve": nil,
+	}
+	for name, external := range providers {
+		key := types.NamespacedName{Namespace: "default", Name: name}
+		store.SetBackend(key, &gatewayv1alpha1.InferenceBackend{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       gatewayv1alpha1.InferenceBackendSpec{External: external},
+		})
+	}
+
+	if openai := store.ListBackendsByProvider("openai"); len(openai) != 2 {
+		t.Errorf("expected 2 openai backends, got %d", len(openai))
+	}
+	anthropic := store.ListBackendsByProvider("anthropic")
+	if len(anthropic) != 1 || anthropic[0].Name != "claude" {
+		t.Errorf("expected only claude for anthropic, got %v", anthropic)
+	}
+	if cohere := store.ListBackendsByProvider("cohere"); len(cohere) != 0 {
+		t.Errorf("expected no cohere backends, got %d", len(cohere))
+	}
+}
+
 func TestStore_GetHealthyBackend(t *testing.T) {
 	store := NewStore()
 	key := types.NamespacedName{Namespace: "default", Name: "test-backend"}
@@ -350,6 +383,94 @@ func TestStore_GetHealthyBackend(t *testing.T) {
 	}
 }
 
+func TestStore_ListHealthyBackendsInNamespace(t *testing.T) {
+	store := NewStore()
+
+	backends := []struct {
+		namespace string
+		name      string
+		health    string
+	}{
+		{"ns1", "healthy-a", HealthStatusHealthy},
+		{"ns1", "healthy-b", HealthStatusHealthy},
+		{"ns1", "unhealthy", HealthStatusUnhealthy},
+		{"ns1", "unknown", HealthStatusUnknown},
+		{"ns1", "no-status", ""},
+		{"ns2", "healthy-other-ns", HealthStatusHealthy},
+	}
+	for _, b := range backends {
+		key := types.NamespacedName{Namespace: b.namespace, Name: b.name}
+		store.SetBackend(key, &gatewayv1alpha1.InferenceBackend{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      b.name,
+				Namespace: b.namespace,
+			},
+			Status: gatewayv1alpha1.InferenceBackendStatus{
+				Health: b.health,
+			},
+		})
+	}
+
+	healthy := store.ListHealthyBackendsInNamespace("ns1")
+	if len(healthy) != 2 {
+		t.Fatalf("expected 2 healthy backends in ns1, got %d", len(healthy))
+	}
+	for _, b := range healthy {
+		if b.Status.Health != HealthStatusHealthy {
+			t.Errorf("expected only healthy backends, got %s with health '%s'", b.Name, b.Status.Health)
+		}
+		if b.Namespace != "ns1" {
+			t.Errorf("expected backends from ns1, got %s/%s", b.Namespace, b.Name)
+		}
+	}
+
+	// Returned backends must be copies
+	healthy[0].Status.Health = HealthStatusUnhealthy
+	if got := store.ListHealthyBackendsInNamespace("ns1"); len(got) != 2 {
+		t.Errorf("expected cache to be unaffected by mutation, got %d healthy backends", len(got))
+	}
+
+	if got := store.ListHealthyBackendsInNamespace("missing"); len(got) != 0 {
+		t.Errorf("expected 0 healthy backends in empty namespace, got %d", len(got))
+	}
+
+	if got := store.CountHealthyBackends(); got != 3 {
+		t.Errorf("expected 3 healthy backends across namespaces, got %d", got)
+	}
+}
+
+func TestStore_NamespaceRateLimits(t *testing.T) {
+	store := NewStore()
+
+	if _, ok := store.GetNamespaceRateLimit("team-a"); ok {
+		t.Error("expected no default for unconfigured namespace")
+	}
+
+	store.SetNamespaceRateLimits(map[string]*gatewayv1alpha1.RateLimitConfig{
+		"team-a": {RequestsPerMinute: 100, PerUser: true},
+	})
+
+	limit, ok := store.GetNamespaceRateLimit("team-a")
+	if !ok {
+		t.Fatal("expected default for team-a")
+	}
+	if limit.RequestsPerMinute != 100 || !limit.PerUser {
+		t.Errorf("unexpected limit: %+v", limit)
+	}
+
+	// Returned limits must be copies
+	limit.RequestsPerMinute = 1
+	if limit, _ := store.GetNamespaceRateLimit("team-a"); limit.RequestsPerMinute != 100 {
+		t.Errorf("expected cached limit to be unaffected, got %d", limit.RequestsPerMinute)
+	}
+
+	// Setting limits replaces previous defaults
+	store.SetNamespaceRateLimits(nil)
+	if _, ok := store.GetNamespaceRateLimit("team-a"); ok {
+		t.Error("expected default for team-a to be removed")
+	}
+}
+
 func TestStore_GetBackendByName(t *testing.T) {
 	store := NewStore()
 	key := types.NamespacedName{Namespace: "default", Name: "test-backend"}
@@ -392,6 +513,79 @@ func TestStore_GetRouteByName(t *testing.T) {
 	}
 }
 
+func TestStore_GetRouteByHostname(t *testing.T) {
+	store := NewStore()
+	keyA := types.NamespacedName{Namespace: "tenant-a", Name: "chat"}
+	store.SetRoute(keyA, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "tenant-a"},
+		Spec:       gatewayv1alpha1.InferenceRouteSpec{Hostnames: []string{"Tenant-A.example.com"}},
+	})
+
+	route, ok := store.GetRouteByHostname("tenant-a.example.com:443")
+	if !ok || route.Namespace != "tenant-a" {
+		t.Fatalf("expected the tenant-a route, got %v", route)
+	}
+	if _, ok := store.GetRouteByHostname("tenant-b.example.com"); ok {
+		t.Error("expected no route for an unknown hostname")
+	}
+
+	// Changing the hostnames replaces the old ones in the index
+	store.SetRoute(keyA, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "tenant-a"},
+		Spec:       gatewayv1alpha1.InferenceRouteSpec{Hostnames: []string{"a.example.com"}},
+	})
+	if _, ok := store.GetRouteByHostname("tenant-a.example.com"); ok {
+		t.Error("expected the old hostname to be removed")
+	}
+
+	// A second route claiming the hostname takes over when the first is deleted
+	keyB := types.NamespacedName{Namespace: "tenant-b", Name: "chat"}
+	store.SetRoute(keyB, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "tenant-b"},
+		Spec:       gatewayv1alpha1.InferenceRouteSpec{Hostnames: []string{"a.example.com"}},
+	})
+	if route, _ := store.GetRouteByHostname("a.example.com"); route.Namespace != "tenant-a" {
+		t.Errorf("expected the first route by namespace to win, got %s", route.Namespace)
+	}
+	store.DeleteRoute(keyA)
+	if route, ok := store.GetRouteByHostname("a.example.com"); !ok || route.Namespace != "tenant-b" {
+		t.Errorf("expected the tenant-b route after deleting tenant-a, got %v", route)
+	}
+}
+
+func TestStore_GetRouteByHostname_Conflict(t *testing.T) {
+	store := NewStore()
+	created := time.Now()
+	setRoute := func(namespace, phase string, age time.Duration) {
+		store.SetRoute(types.NamespacedName{Namespace: namespace, Name: "chat"}, &gatewayv1alpha1.InferenceRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "chat",
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(created.Add(-age)),
+			},
+			Spec:   gatewayv1alpha1.InferenceRouteSpec{Hostnames: []string{"shared.example.com"}},
+			Status: gatewayv1alpha1.InferenceRouteStatus{Phase: phase},
+		})
+	}
+
+	// The oldest route keeps the hostname, even if another sorts first by name
+	setRoute("tenant-b", "Active", time.Hour)
+	setRoute("tenant-a", "Active", time.Minute)
+	if route, ok := store.GetRouteByHostname("shared.example.com"); !ok || route.Namespace != "tenant-b" {
+		t.Errorf("expected the older tenant-b route, got %v", route)
+	}
+
+	// A route that isn't serving loses the hostname to the next one
+	setRoute("tenant-b", "Pending", time.Hour)
+	if route, ok := store.GetRouteByHostname("shared.example.com"); !ok || route.Namespace != "tenant-a" {
+		t.Errorf("expected the active tenant-a route, got %v", route)
+	}
+	setRoute("tenant-a", "Failed", time.Minute)
+	if route, ok := store.GetRouteByHostname("shared.example.com"); ok {
+		t.Errorf("expected no route when none is serving, got %s", route.Namespace)
+	}
+}
+
 func TestStore_ConcurrentAccess(t *testing.T) {
 	store := NewStore()
 	var wg sync.WaitGroup
@@ -431,6 +625,203 @@ func TestStore_ConcurrentAccess(t *testing.T) {
 	}
 }
 
+func TestStore_SetRoutesAndBackends_MatchIndividualSets(t *testing.T) {
+	routes := make(map[types.NamespacedName]*gatewayv1alpha1.InferenceRoute)
+	backends := make(map[types.NamespacedName]*gatewayv1alpha1.InferenceBackend)
+	for i := 0; i < 10; i++ {
+		key := types.NamespacedName{Namespace: fmt.Sprintf("ns-%d", i%3), Name: fmt.Sprintf("object-%d", i)}
+		routes[key] = &gatewayv1alpha1.InferenceRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec: gatewayv1alpha1.InferenceRouteSpec{
+				DefaultBackend: &gatewayv1alpha1.BackendRef{Name: key.Name},
+			},
+		}
+		backends[key] = &gatewayv1alpha1.InferenceBackend{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Status:     gatewayv1alpha1.InferenceBackendStatus{Health: HealthStatusHealthy},
+		}
+	}
+
+	individual := NewStore()
+	for key, route := range routes {
+		individual.SetRoute(key, route)
+	}
+	for key, backend := range backends {
+		individual.SetBackend(key, backend)
+	}
+
+	batch := NewStore()
+	batch.SetRoutes(routes)
+	batch.SetBackends(backends)
+
+	if !reflect.DeepEqual(individual.routes, batch.routes) {
+		t.Error("expected batch routes to match individually set routes")
+	}
+	if !reflect.DeepEqual(individual.backends, batch.backends) {
+		t.Error("expected batch backends to match individually set backends")
+	}
+
+	// The batch stores copies, not the caller's objects
+	for key := range routes {
+		routes[key].Spec.DefaultBackend.Name = "mutated"
+		backends[key].Status.Health = HealthStatusUnhealthy
+	}
+	for key := range routes {
+		route, _ := batch.GetRoute(key)
+		if route.Spec.DefaultBackend.Name != key.Name {
+			t.Errorf("expected cached route %s to be unaffected by mutation", key)
+		}
+		backend, _ := batch.GetBackend(key)
+		if backend.Status.Health != HealthStatusHealthy {
+			t.Errorf("expected cached backend %s to be unaffected by mutation", key)
+		}
+	}
+}
+
+func TestStore_SetRoutes_IgnoresNil(t *testing.T) {
+	store := NewStore()
+	store.SetRoutes(map[types.NamespacedName]*gatewayv1alpha1.InferenceRoute{
+		{Namespace: "default", Name: "nil-route"}: nil,
+	})
+	store.SetBackends(map[types.NamespacedName]*gatewayv1alpha1.InferenceBackend{
+		{Namespace: "default", Name: "nil-backend"}: nil,
+	})
+
+	stats := store.GetStats()
+	if stats.RouteCount != 0 || stats.BackendCount != 0 {
+		t.Errorf("expected nil entries to be ignored, got %d routes and %d backends", stats.RouteCount, stats.BackendCount)
+	}
+}
+
+func TestStore_SetBatch_ConcurrentAccess(t *testing.T) {
+	store := NewStore()
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(id int) {
+			defer wg.Done()
+			routes := make(map[types.NamespacedName]*gatewayv1alpha1.InferenceRoute)
+			backends := make(map[types.NamespacedName]*gatewayv1alpha1.InferenceBackend)
+			for j := 0; j < 10; j++ {
+				key := types.NamespacedName{Namespace: "default", Name: fmt.Sprintf("object-%d", (id+j)%15)}
+				routes[key] = &gatewayv1alpha1.InferenceRoute{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+				backends[key] = &gatewayv1alpha1.InferenceBackend{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+			}
+			store.SetRoutes(routes)
+			store.SetBackends(backends)
+		}(i)
+		go func() {
+			defer wg.Done()
+			store.ListRoutes()
+			store.ListBackendsInNamespace("default")
+		}()
+	}
+
+	wg.Wait()
+
+	stats := store.GetStats()
+	if stats.RouteCount != 15 || stats.BackendCount != 15 {
+		t.Errorf("expected 15 routes and backends, got %d and %d", stats.RouteCount, stats.BackendCount)
+	}
+}
+
+func TestStore_WeightOverrides(t *testing.T) {
+	store := NewStore()
+	key := types.NamespacedName{Namespace: "default", Name: "chat"}
+
+	if _, ok := store.GetWeightOverrides(key); ok {
+		t.Fatal("expected no overrides before any are set")
+	}
+
+	overrides := map[types.NamespacedName]map[string]int32{
+		key:                                   {"backend-a": 0, "backend-b": 100},
+		{Namespace: "default", Name: "empty"}: {},
+	}
+	store.SetWeightOverrides(overrides)
+
+	// Mutating the input or the result must not affect the store
+	overrides[key]["backend-b"] = 1
+	weights, ok := store.GetWeightOverrides(key)
+	if !ok {
+		t.Fatal("expected overrides for route")
+	}
+	weights["backend-a"] = 50
+
+	weights, _ = store.GetWeightOverrides(key)
+	if weights["backend-a"] != 0 || weights["backend-b"] != 100 {
+		t.Errorf("expected stored overrides to be unaffected by mutation, got %v", weights)
+	}
+	if _, ok := store.GetWeightOverrides(types.NamespacedName{Namespace: "default", Name: "empty"}); ok {
+		t.Error("expected empty overrides to be ignored")
+	}
+
+	// Setting again replaces all overrides
+	store.SetWeightOverrides(nil)
+	if _, ok := store.GetWeightOverrides(key); ok {
+		t.Error("expected overrides to be cleared")
+	}
+}
+
+func TestStore_CordonBackend(t *testing.T) {
+	store := NewStore()
+	key := types.NamespacedName{Namespace: "default", Name: "backend-a"}
+
+	if store.IsCordoned(key) {
+		t.Fatal("expected backend not to be cordoned initially")
+	}
+
+	store.CordonBackend(key)
+	if !store.IsCordoned(key) {
+		t.Error("expected backend to be cordoned")
+	}
+	if store.IsCordoned(types.NamespacedName{Namespace: "other", Name: "backend-a"}) {
+		t.Error("expected cordon to be scoped to the namespace")
+	}
+
+	// Updating the backend object keeps the cordon
+	store.SetBackend(key, &gatewayv1alpha1.InferenceBackend{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}})
+	if !store.IsCordoned(key) {
+		t.Error("expected cordon to survive a backend update")
+	}
+
+	store.UncordonBackend(key)
+	if store.IsCordoned(key) {
+		t.Error("expected backend to be uncordoned")
+	}
+
+	// Deleting a backend clears its cordon
+	store.CordonBackend(key)
+	store.DeleteBackend(key)
+	if store.IsCordoned(key) {
+		t.Error("expected cordon to be cleared when the backend is deleted")
+	}
+}
+
+func TestStore_Endpoints(t *testing.T) {
+	store := NewStore()
+	key := types.NamespacedName{Namespace: "default", Name: "backend-a"}
+
+	addresses := []string{"10.0.0.1:8080", "10.0.0.2:8080"}
+	store.SetEndpoints(key, addresses)
+	addresses[0] = "mutated"
+	if got := store.GetEndpoints(key); !slices.Equal(got, []string{"10.0.0.1:8080", "10.0.0.2:8080"}) {
+		t.Errorf("expected the stored endpoints, got %v", got)
+	}
+
+	store.SetEndpoints(key, nil)
+	if got := store.GetEndpoints(key); len(got) != 0 {
+		t.Errorf("expected no endpoints after clearing them, got %v", got)
+	}
+
+	// Deleting a backend clears its endpoints
+	store.SetEndpoints(key, []string{"10.0.0.1:8080"})
+	store.DeleteBackend(key)
+	if got := store.GetEndpoints(key); len(got) != 0 {
+		t.Errorf("expected endpoints to be cleared when the backend is deleted, got %v", got)
+	}
+}
+
 func TestStore_GetStats(t *testing.T) {
 	store := NewStore()
 
@@ -457,3 +848,87 @@ func TestStore_GetStats(t *testing.T) {
 		t.Errorf("expected 5 backends, got %d", stats.BackendCount)
 	}
 }
+
+func TestStore_Subscribe(t *testing.T) {
+	store := NewStore()
+	events := store.Subscribe()
+	defer store.Unsubscribe(events)
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: "route"}
+	backendKey := types.NamespacedName{Namespace: "default", Name: "backend"}
+	route := &gatewayv1alpha1.InferenceRoute{ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: "default"}}
+	backend := &gatewayv1alpha1.InferenceBackend{ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "default"}}
+
+	store.SetRoute(routeKey, route)
+	store.SetRoute(routeKey, route)
+	store.DeleteRoute(routeKey)
+	store.SetBackends(map[types.NamespacedName]*gatewayv1alpha1.InferenceBackend{backendKey: backend})
+	store.SetBackend(backendKey, backend)
+	store.DeleteBackend(backendKey)
+	// Deleting something that isn't cached is not a change
+	store.DeleteBackend(backendKey)
+
+	want := []StoreEvent{
+		{Type: EventAdded, Kind: EventKindRoute, Key: routeKey},
+		{Type: EventUpdated, Kind: EventKindRoute, Key: routeKey},
+		{Type: EventDeleted, Kind: EventKindRoute, Key: routeKey},
+		{Type: EventAdded, Kind: EventKindBackend, Key: backendKey},
+		{Type: EventUpdated, Kind: EventKindBackend, Key: backendKey},
+		{Type: EventDeleted, Kind: EventKindBackend, Key: backendKey},
+	}
+	for i, expected := range want {
+		select {
+		case got := <-events:
+			if got != expected {
+				t.Errorf("event %d: expected %+v, got %+v", i, expected, got)
+			}
+		default:
+			t.Fatalf("event %d: expected %+v, got nothing", i, expected)
+		}
+	}
+	select {
+	case got := <-events:
+		t.Errorf("expected no more events, got %+v", got)
+	default:
+	}
+}
+
+func TestStore_Subscribe_SlowSubscriberDoesNotBlock(t *testing.T) {
+	store := NewStore()
+	slow := store.Subscribe()
+	fast := store.Subscribe()
+
+	// Nobody reads the slow subscriber, so its buffer fills up
+	received := make(chan int)
+	go func() {
+		count := 0
+		for range fast {
+			count++
+		}
+		received <- count
+	}()
+
+	for i := 0; i < subscriberBuffer*2; i++ {
+		key := types.NamespacedName{Namespace: "default", Name: fmt.Sprintf("route-%d", i)}
+		store.SetRoute(key, &gatewayv1alpha1.InferenceRoute{})
+	}
+
+	if got := len(slow); got != subscriberBuffer {
+		t.Errorf("expected the slow subscriber to hold %d events, got %d", subscriberBuffer, got)
+	}
+	store.Unsubscribe(fast)
+	if count := <-received; count == 0 {
+		t.Error("expected the reading subscriber to receive events")
+	}
+	if stats := store.GetStats(); stats.RouteCount != subscriberBuffer*2 {
+		t.Errorf("expected every route to be stored, got %d", stats.RouteCount)
+	}
+
+	// Unsubscribed channels are closed
+	store.Unsubscribe(slow)
+	for range slow {
+	}
+	if _, ok := <-slow; ok {
+		t.Error("expected the slow subscriber's channel to be closed")
+	}
+}
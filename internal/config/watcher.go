@@ -21,14 +21,50 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
 	"gopkg.in/yaml.v3"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
+var (
+	// Prometheus metrics for configuration reloads
+	configReloads = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kortex_config_reloads_total",
+			Help: "Total successful configuration reloads",
+		},
+	)
+
+	configReloadErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kortex_config_reload_errors_total",
+			Help: "Total configuration reloads that failed to read or parse",
+		},
+	)
+
+	configLastReloadTimestamp = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kortex_config_last_reload_timestamp",
+			Help: "Unix timestamp of the last successful configuration reload",
+		},
+	)
+)
+
+func init() {
+	// Register with controller-runtime's registry, which the manager serves
+	metrics.Registry.MustRegister(
+		configReloads,
+		configReloadErrors,
+		configLastReloadTimestamp,
+	)
+}
+
 // ConfigChangeHandler is called when configuration changes are detected
 type ConfigChangeHandler func(newConfig *KortexConfig)
 
@@ -51,6 +87,11 @@ type KortexConfig struct {
 
 	// Observability contains tracing and metrics settings
 	Observability ObservabilityConfig `yaml:"observability"`
+
+	// WeightOverrides replace backend weights for a route without editing the
+	// InferenceRoute, keyed by "namespace/route" and then backend name.
+	// A weight of 0 drains the backend.
+	WeightOverrides map[string]map[string]int32 `yaml:"weightOverrides"`
 }
 
 // GatewayConfig contains core gateway settings
@@ -85,6 +126,10 @@ type SmartRoutingConfig struct {
 	// FastModelBackend is the backend for short requests
 	FastModelBackend string `yaml:"fastModelBackend"`
 
+	// EstimationFailureBackend is the backend for requests whose tokens can't
+	// be estimated. Empty uses the route's default backend.
+	EstimationFailureBackend string `yaml:"estimationFailureBackend"`
+
 	// EnableCostOptimization enables cost-based routing
 	EnableCostOptimization bool `yaml:"enableCostOptimization"`
 }
@@ -117,6 +162,22 @@ type RateLimitConfig struct {
 
 	// UserHeaderName is the header to identify users
 	UserHeaderName string `yaml:"userHeaderName"`
+
+	// NamespaceDefaults are rate limits applied to routes in a namespace
+	// that don't configure their own, keyed by namespace
+	NamespaceDefaults map[string]NamespaceRateLimitConfig `yaml:"namespaceDefaults"`
+}
+
+// NamespaceRateLimitConfig is the default rate limit for routes in a namespace
+type NamespaceRateLimitConfig struct {
+	// RequestsPerMinute is the maximum requests per minute per route
+	RequestsPerMinute int `yaml:"requestsPerMinute"`
+
+	// PerUser applies the limit per user instead of per route
+	PerUser bool `yaml:"perUser"`
+
+	// UserHeader is the header to identify users
+	UserHeader string `yaml:"userHeader"`
 }
 
 // ObservabilityConfig contains tracing and metrics settings
@@ -150,6 +211,19 @@ type MetricsConfig struct {
 
 	// BindAddress for the metrics server
 	BindAddress string `yaml:"bindAddress"`
+
+	// OTLP configures pushing metrics to the tracing OTLP collector
+	OTLP OTLPMetricsConfig `yaml:"otlp"`
+}
+
+// OTLPMetricsConfig contains OTLP metrics export settings.
+// The collector endpoint and TLS settings are shared with tracing.
+type OTLPMetricsConfig struct {
+	// Enabled enables OTLP metrics export
+	Enabled bool `yaml:"enabled"`
+
+	// ExportInterval is the interval between exports in seconds
+	ExportInterval int `yaml:"exportInterval"`
 }
 
 // DefaultConfig returns the default Kortex configuration
@@ -183,6 +257,10 @@ func DefaultConfig() *KortexConfig {
 			Metrics: MetricsConfig{
 				Enabled:     true,
 				BindAddress: ":8443",
+				OTLP: OTLPMetricsConfig{
+					Enabled:        false,
+					ExportInterval: 60,
+				},
 			},
 		},
 	}
@@ -332,10 +410,14 @@ func (w *Watcher) loadConfig() error {
 // reloadConfig reloads the configuration and notifies handlers
 func (w *Watcher) reloadConfig() {
 	if err := w.loadConfig(); err != nil {
+		configReloadErrors.Inc()
 		w.log.Error(err, "Failed to reload configuration")
 		return
 	}
 
+	configReloads.Inc()
+	configLastReloadTimestamp.SetToCurrentTime()
+
 	// Notify all handlers
 	config := w.GetConfig()
 	for _, handler := range w.handlers {
@@ -365,6 +447,23 @@ func ValidateConfig(config *KortexConfig) []string {
 		}
 	}
 
+	for namespace, limit := range config.RateLimits.NamespaceDefaults {
+		if limit.RequestsPerMinute <= 0 {
+			errors = append(errors, "rateLimits.namespaceDefaults."+namespace+".requestsPerMinute must be positive")
+		}
+	}
+
+	for route, weights := range config.WeightOverrides {
+		if namespace, name, ok := strings.Cut(route, "/"); !ok || namespace == "" || name == "" {
+			errors = append(errors, "weightOverrides key "+route+" must be in the form namespace/route")
+		}
+		for backend, weight := range weights {
+			if weight < 0 {
+				errors = append(errors, "weightOverrides."+route+"."+backend+" must not be negative")
+			}
+		}
+	}
+
 	if config.Observability.Tracing.Enabled && config.Observability.Tracing.Endpoint == "" {
 		errors = append(errors, "observability.tracing.endpoint is required when tracing is enabled")
 	}
@@ -373,5 +472,13 @@ func ValidateConfig(config *KortexConfig) []string {
 		errors = append(errors, "observability.tracing.sampleRate must be between 0.0 and 1.0")
 	}
 
+	if config.Observability.Metrics.OTLP.Enabled && config.Observability.Tracing.Endpoint == "" {
+		errors = append(errors, "observability.tracing.endpoint is required when OTLP metrics are enabled")
+	}
+
+	if config.Observability.Metrics.OTLP.ExportInterval < 0 {
+		errors = append(errors, "observability.metrics.otlp.exportInterval must not be negative")
+	}
+
 	return errors
-}
\ No newline at end of file
+}
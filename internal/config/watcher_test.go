@@ -0,0 +1,161 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+}
+
+func TestWatcher_ReloadConfig_Success(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "version: v1\n")
+
+	w, err := NewWatcher(path, zap.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = w.Stop() }()
+
+	reloads := testutil.ToFloat64(configReloads)
+	reloadErrors := testutil.ToFloat64(configReloadErrors)
+
+	writeConfig(t, path, "version: v2\n")
+	w.reloadConfig()
+
+	if got := testutil.ToFloat64(configReloads); got != reloads+1 {
+		t.Errorf("expected reloads to increment to %v, got %v", reloads+1, got)
+	}
+	if got := testutil.ToFloat64(configReloadErrors); got != reloadErrors {
+		t.Errorf("expected reload errors to stay at %v, got %v", reloadErrors, got)
+	}
+	if testutil.ToFloat64(configLastReloadTimestamp) == 0 {
+		t.Error("expected last reload timestamp to be set")
+	}
+	if w.GetConfig().Version != "v2" {
+		t.Errorf("expected version 'v2', got '%s'", w.GetConfig().Version)
+	}
+}
+
+func TestWatcher_ReloadConfig_InvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "version: v1\n")
+
+	w, err := NewWatcher(path, zap.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = w.Stop() }()
+
+	reloads := testutil.ToFloat64(configReloads)
+	reloadErrors := testutil.ToFloat64(configReloadErrors)
+
+	writeConfig(t, path, "gateway: [not, a, mapping\n")
+	w.reloadConfig()
+
+	if got := testutil.ToFloat64(configReloadErrors); got != reloadErrors+1 {
+		t.Errorf("expected reload errors to increment to %v, got %v", reloadErrors+1, got)
+	}
+	if got := testutil.ToFloat64(configReloads); got != reloads {
+		t.Errorf("expected reloads to stay at %v, got %v", reloads, got)
+	}
+
+	// The previous configuration is kept
+	if w.GetConfig().Version != "v1" {
+		t.Errorf("expected version 'v1' to be kept, got '%s'", w.GetConfig().Version)
+	}
+}
+
+func TestWatcher_ReloadConfig_WeightOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "version: v1\n")
+
+	w, err := NewWatcher(path, zap.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = w.Stop() }()
+
+	writeConfig(t, path, `version: v2
+weightOverrides:
+  default/chat:
+    backend-a: 0
+    backend-b: 100
+`)
+	w.reloadConfig()
+
+	weights := w.GetConfig().WeightOverrides["default/chat"]
+	if len(weights) != 2 || weights["backend-a"] != 0 || weights["backend-b"] != 100 {
+		t.Errorf("expected overrides {backend-a: 0, backend-b: 100}, got %v", weights)
+	}
+}
+
+func TestValidateConfig_WeightOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]map[string]int32
+		wantErrs  int
+	}{
+		{"valid", map[string]map[string]int32{"default/chat": {"a": 0, "b": 50}}, 0},
+		{"missing namespace", map[string]map[string]int32{"chat": {"a": 10}}, 1},
+		{"empty route name", map[string]map[string]int32{"default/": {"a": 10}}, 1},
+		{"negative weight", map[string]map[string]int32{"default/chat": {"a": -1}}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.WeightOverrides = tt.overrides
+			if errs := ValidateConfig(cfg); len(errs) != tt.wantErrs {
+				t.Errorf("expected %d errors, got %v", tt.wantErrs, errs)
+			}
+		})
+	}
+}
+
+func TestReloadMetricsRegistered(t *testing.T) {
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	registered := make(map[string]bool)
+	for _, family := range families {
+		registered[family.GetName()] = true
+	}
+
+	for _, name := range []string{
+		"kortex_config_reloads_total",
+		"kortex_config_reload_errors_total",
+		"kortex_config_last_reload_timestamp",
+	} {
+		if !registered[name] {
+			t.Errorf("expected %s to be served from the manager's registry", name)
+		}
+	}
+}
@@ -19,19 +19,32 @@ package controller
 import (
 	"context"
 	"fmt"
+	"math"
+	"net"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
 	"github.com/judeoyovbaire/kortex/internal/cache"
 	"github.com/judeoyovbaire/kortex/internal/health"
+	"github.com/judeoyovbaire/kortex/internal/proxy"
 )
 
 // Health status constants
@@ -39,6 +52,10 @@ const (
 	HealthStatusHealthy   = "Healthy"
 	HealthStatusUnhealthy = "Unhealthy"
 	HealthStatusUnknown   = "Unknown"
+
+	// HealthStatusMaintenance marks a backend that an operator has taken out
+	// of rotation
+	HealthStatusMaintenance = "Maintenance"
 )
 
 // Condition types for InferenceBackend
@@ -47,16 +64,40 @@ const (
 	ConditionTypeBackendReady   = "Ready"
 )
 
+// AnnotationCordon drains a backend when set to "true": it receives no new
+// requests but is still health checked
+const AnnotationCordon = "kortex.io/cordon"
+
+// directEndpointsServiceIndex indexes DirectEndpoints backends by the
+// namespace/name of the Service whose EndpointSlices they follow
+const directEndpointsServiceIndex = "spec.kubernetes.directEndpointsService"
+
+// DefaultLatencySmoothingFactor is the weight given to the newest latency
+// sample in the backend's average latency
+const DefaultLatencySmoothingFactor = 0.3
+
+// assumedHealthyRequeueInterval is how often backends that are assumed healthy
+// are reconciled, since there is no probe to repeat
+const assumedHealthyRequeueInterval = 10 * time.Minute
+
 // InferenceBackendReconciler reconciles an InferenceBackend object
 type InferenceBackendReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
 	HealthChecker *health.Checker
 	Cache         *cache.Store
+	Metrics       *proxy.MetricsRecorder
+
+	// LatencySmoothingFactor is the EMA weight (0-1] of the newest health-check
+	// latency in Status.AverageLatencyMs. Zero uses DefaultLatencySmoothingFactor.
+	LatencySmoothingFactor float64
 
 	// Track consecutive failures per backend for threshold logic
 	failureCounts map[string]int32
 	failureMu     sync.RWMutex
+
+	// Track the latency moving average per backend
+	latencyAverages map[string]float64
 }
 
 // +kubebuilder:rbac:groups=gateway.inference-gateway.io,resources=inferencebackends,verbs=get;list;watch;create;update;patch;delete
@@ -64,6 +105,7 @@ type InferenceBackendReconciler struct {
 // +kubebuilder:rbac:groups=gateway.inference-gateway.io,resources=inferencebackends/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
 
 // Reconcile performs the reconciliation loop for InferenceBackend resources
 func (r *InferenceBackendReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -73,25 +115,47 @@ func (r *InferenceBackendReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	if r.failureCounts == nil {
 		r.failureCounts = make(map[string]int32)
 	}
+	if r.latencyAverages == nil {
+		r.latencyAverages = make(map[string]float64)
+	}
 
 	// Fetch the InferenceBackend resource
 	backend := &gatewayv1alpha1.InferenceBackend{}
 	if err := r.Get(ctx, req.NamespacedName, backend); err != nil {
 		if client.IgnoreNotFound(err) == nil {
-			// Resource was deleted, clean up failure tracking and cache
+			// Resource was deleted, clean up failure tracking and metrics
 			r.cleanupBackend(req.String())
+			if r.Cache != nil {
+				r.Cache.UncordonBackend(req.NamespacedName)
+				r.Cache.SetEndpoints(req.NamespacedName, nil)
+			}
+			if r.Metrics != nil {
+				r.Metrics.DeleteBackendMetrics(req.Name, req.Namespace)
+			}
 			return ctrl.Result{}, nil
 		}
 		log.Error(err, "Failed to fetch InferenceBackend")
 		return ctrl.Result{}, err
 	}
 
+	// Apply the cordon annotation before probing so draining takes effect immediately
+	r.syncCordon(ctx, req.NamespacedName, backend)
+
 	// Validate backend configuration
 	if err := r.validateBackendConfig(backend); err != nil {
 		log.Error(err, "Invalid backend configuration")
 		return r.updateStatusWithError(ctx, backend, err)
 	}
 
+	// Refresh the pods that DirectEndpoints backends route to
+	r.syncEndpoints(ctx, req.NamespacedName, backend)
+
+	// Backends in maintenance skip health checks and stay out of rotation
+	now := time.Now()
+	if backend.Spec.Maintenance.IsActive(now) {
+		return r.reconcileMaintenance(ctx, req, backend, now)
+	}
+
 	// Perform health check
 	result := r.HealthChecker.Check(ctx, backend)
 
@@ -104,6 +168,18 @@ func (r *InferenceBackendReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		r.failureCounts[key] = 0
 	}
 	currentFailures := r.failureCounts[key]
+
+	// Only successful probes contribute to the latency average, so timeouts
+	// don't masquerade as slow responses
+	averageLatency, ok := r.latencyAverages[key]
+	if !ok {
+		// Resume from the persisted average after a restart
+		averageLatency = float64(backend.Status.AverageLatencyMs)
+	}
+	if result.Healthy && !backend.Spec.AssumeHealthy {
+		averageLatency = updateLatencyEMA(averageLatency, result.Latency, r.latencySmoothingFactor())
+	}
+	r.latencyAverages[key] = averageLatency
 	r.failureMu.Unlock()
 
 	// Determine health status based on failure threshold
@@ -123,11 +199,11 @@ func (r *InferenceBackendReconciler) Reconcile(ctx context.Context, req ctrl.Req
 
 	// Update status fields
 	backend.Status.Health = healthStatus
-	backend.Status.AverageLatencyMs = result.Latency.Milliseconds()
+	backend.Status.AverageLatencyMs = int64(math.Round(averageLatency))
 
 	if result.Healthy {
-		now := metav1.Now()
-		backend.Status.LastHealthCheck = &now
+		checkedAt := metav1.NewTime(now)
+		backend.Status.LastHealthCheck = &checkedAt
 	}
 
 	// Set conditions
@@ -148,15 +224,223 @@ func (r *InferenceBackendReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	log.V(1).Info("Reconciled InferenceBackend",
 		"health", healthStatus,
 		"latency_ms", result.Latency.Milliseconds(),
+		"average_latency_ms", backend.Status.AverageLatencyMs,
 		"failures", currentFailures)
 
-	// Calculate requeue interval from health check config
+	return ctrl.Result{RequeueAfter: requeueInterval(backend, now)}, nil
+}
+
+// syncCordon cordons or uncordons the backend in the cache to match its
+// kortex.io/cordon annotation
+func (r *InferenceBackendReconciler) syncCordon(ctx context.Context, key types.NamespacedName, backend *gatewayv1alpha1.InferenceBackend) {
+	if r.Cache == nil {
+		return
+	}
+
+	cordon := backend.Annotations[AnnotationCordon] == "true"
+	if cordon == r.Cache.IsCordoned(key) {
+		return
+	}
+
+	if cordon {
+		r.Cache.CordonBackend(key)
+		logf.FromContext(ctx).Info("Cordoned InferenceBackend")
+	} else {
+		r.Cache.UncordonBackend(key)
+		logf.FromContext(ctx).Info("Uncordoned InferenceBackend")
+	}
+}
+
+// syncEndpoints caches the ready pod addresses of the Service of a backend
+// with DirectEndpoints, and clears them for other backends. The proxy routes
+// through the Service's ClusterIP while there are none.
+func (r *InferenceBackendReconciler) syncEndpoints(ctx context.Context, key types.NamespacedName, backend *gatewayv1alpha1.InferenceBackend) {
+	if r.Cache == nil {
+		return
+	}
+
+	k8s := backend.Spec.Kubernetes
+	if backend.Spec.Type != gatewayv1alpha1.BackendTypeKubernetes || k8s == nil || !k8s.DirectEndpoints {
+		r.Cache.SetEndpoints(key, nil)
+		return
+	}
+
+	addresses, err := r.readyEndpoints(ctx, backend)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to read Service endpoints, routing through the ClusterIP",
+			"service", k8s.ServiceName)
+	}
+	r.Cache.SetEndpoints(key, addresses)
+}
+
+// readyEndpoints returns the addresses (host:port) of the ready pods behind a
+// Kubernetes backend's Service port
+func (r *InferenceBackendReconciler) readyEndpoints(ctx context.Context, backend *gatewayv1alpha1.InferenceBackend) ([]string, error) {
+	k8s := backend.Spec.Kubernetes
+	namespace := serviceNamespace(backend)
+	port := k8s.Port
+	if port == 0 {
+		port = 8080
+	}
+
+	// EndpointSlices list target ports, which are matched to the Service
+	// port by name
+	service := &corev1.Service{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: k8s.ServiceName}, service); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	portName, ok := servicePortName(service, port)
+	if !ok {
+		return nil, fmt.Errorf("service %s/%s has no port %d", namespace, k8s.ServiceName, port)
+	}
+
+	endpointSlices := &discoveryv1.EndpointSliceList{}
+	if err := r.List(ctx, endpointSlices,
+		client.InNamespace(namespace),
+		client.MatchingLabels{discoveryv1.LabelServiceName: k8s.ServiceName},
+	); err != nil {
+		return nil, err
+	}
+	return endpointAddresses(endpointSlices.Items, portName), nil
+}
+
+// serviceNamespace returns the namespace of a Kubernetes backend's Service
+func serviceNamespace(backend *gatewayv1alpha1.InferenceBackend) string {
+	if backend.Spec.Kubernetes.Namespace != "" {
+		return backend.Spec.Kubernetes.Namespace
+	}
+	return backend.Namespace
+}
+
+// servicePortName returns the name of the Service port with the given number
+func servicePortName(service *corev1.Service, port int32) (string, bool) {
+	for _, p := range service.Spec.Ports {
+		if p.Port == port {
+			return p.Name, true
+		}
+	}
+	return "", false
+}
+
+// endpointAddresses returns the sorted addresses of the ready endpoints in the
+// slices, on the named port
+func endpointAddresses(endpointSlices []discoveryv1.EndpointSlice, portName string) []string {
+	var addresses []string
+	for _, slice := range endpointSlices {
+		var port *int32
+		for _, p := range slice.Ports {
+			if p.Port != nil && ptr.Deref(p.Name, "") == portName {
+				port = p.Port
+				break
+			}
+		}
+		if port == nil {
+			continue
+		}
+
+		for _, endpoint := range slice.Endpoints {
+			// A nil Ready condition means the endpoint is ready
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			// All addresses of an endpoint belong to the same pod
+			if len(endpoint.Addresses) == 0 {
+				continue
+			}
+			addresses = append(addresses, net.JoinHostPort(endpoint.Addresses[0], strconv.Itoa(int(*port))))
+		}
+	}
+	slices.Sort(addresses)
+	return slices.Compact(addresses)
+}
+
+// directEndpointsService returns the directEndpointsServiceIndex value of a
+// backend, which is empty unless it uses DirectEndpoints
+func directEndpointsService(obj client.Object) []string {
+	backend, ok := obj.(*gatewayv1alpha1.InferenceBackend)
+	if !ok || backend.Spec.Kubernetes == nil || !backend.Spec.Kubernetes.DirectEndpoints {
+		return nil
+	}
+	return []string{serviceNamespace(backend) + "/" + backend.Spec.Kubernetes.ServiceName}
+}
+
+// findBackendsForEndpointSlice returns reconcile requests for the backends
+// with DirectEndpoints whose Service owns the EndpointSlice
+func (r *InferenceBackendReconciler) findBackendsForEndpointSlice(ctx context.Context, obj client.Object) []reconcile.Request {
+	serviceName := obj.GetLabels()[discoveryv1.LabelServiceName]
+	if serviceName == "" {
+		return nil
+	}
+
+	// Backends may reference a Service in another namespace
+	backends := &gatewayv1alpha1.InferenceBackendList{}
+	if err := r.List(ctx, backends,
+		client.MatchingFields{directEndpointsServiceIndex: obj.GetNamespace() + "/" + serviceName}); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list InferenceBackends for EndpointSlice watch")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range backends.Items {
+		backend := &backends.Items[i]
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: backend.Namespace, Name: backend.Name},
+		})
+	}
+	return requests
+}
+
+// reconcileMaintenance forces the backend into the Maintenance state until the
+// maintenance window ends
+func (r *InferenceBackendReconciler) reconcileMaintenance(ctx context.Context, req ctrl.Request, backend *gatewayv1alpha1.InferenceBackend, now time.Time) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	// Start from a clean slate once maintenance is over
+	r.failureMu.Lock()
+	r.failureCounts[req.String()] = 0
+	r.failureMu.Unlock()
+
+	backend.Status.Health = HealthStatusMaintenance
+	r.setHealthCondition(backend, HealthStatusMaintenance, nil)
+	r.setReadyCondition(backend, HealthStatusMaintenance)
+
+	if err := r.Status().Update(ctx, backend); err != nil {
+		log.Error(err, "Failed to update InferenceBackend status")
+		return ctrl.Result{}, err
+	}
+
+	if r.Cache != nil {
+		r.Cache.SetBackend(req.NamespacedName, backend)
+	}
+
+	log.V(1).Info("InferenceBackend is in maintenance")
+
+	return ctrl.Result{RequeueAfter: requeueInterval(backend, now)}, nil
+}
+
+// requeueInterval returns the health check interval, shortened so that the
+// backend is reconciled as soon as a maintenance window starts or ends
+func requeueInterval(backend *gatewayv1alpha1.InferenceBackend, now time.Time) time.Duration {
 	interval := 30 * time.Second // default
-	if backend.Spec.HealthCheck != nil && backend.Spec.HealthCheck.IntervalSeconds > 0 {
+	switch {
+	case backend.Spec.AssumeHealthy:
+		interval = assumedHealthyRequeueInterval
+	case backend.Spec.HealthCheck != nil && backend.Spec.HealthCheck.IntervalSeconds > 0:
 		interval = time.Duration(backend.Spec.HealthCheck.IntervalSeconds) * time.Second
 	}
 
-	return ctrl.Result{RequeueAfter: interval}, nil
+	if m := backend.Spec.Maintenance; m != nil && !m.Enabled {
+		for _, boundary := range []*metav1.Time{m.Start, m.End} {
+			if boundary == nil || !boundary.After(now) {
+				continue
+			}
+			if until := boundary.Sub(now); until < interval {
+				interval = until
+			}
+		}
+	}
+
+	return interval
 }
 
 // validateBackendConfig ensures the backend has the required configuration for its type
@@ -166,9 +450,7 @@ func (r *InferenceBackendReconciler) validateBackendConfig(backend *gatewayv1alp
 		if backend.Spec.External == nil {
 			return fmt.Errorf("external config is required for backend type 'external'")
 		}
-		if backend.Spec.External.URL == "" {
-			return fmt.Errorf("external.url is required for backend type 'external'")
-		}
+		// An empty URL is allowed: the gateway falls back to the provider's configured base URL
 
 	case gatewayv1alpha1.BackendTypeKubernetes:
 		if backend.Spec.Kubernetes == nil {
@@ -238,6 +520,11 @@ func (r *InferenceBackendReconciler) setHealthCondition(backend *gatewayv1alpha1
 			condition.Message = "Backend failed health check threshold"
 		}
 
+	case HealthStatusMaintenance:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Maintenance"
+		condition.Message = "Backend is in maintenance and excluded from routing"
+
 	default: // Unknown
 		condition.Status = metav1.ConditionUnknown
 		condition.Reason = "HealthCheckPending"
@@ -272,10 +559,29 @@ func (r *InferenceBackendReconciler) setReadyCondition(backend *gatewayv1alpha1.
 	meta.SetStatusCondition(&backend.Status.Conditions, condition)
 }
 
+// latencySmoothingFactor returns the configured EMA factor or the default
+func (r *InferenceBackendReconciler) latencySmoothingFactor() float64 {
+	if r.LatencySmoothingFactor <= 0 || r.LatencySmoothingFactor > 1 {
+		return DefaultLatencySmoothingFactor
+	}
+	return r.LatencySmoothingFactor
+}
+
+// updateLatencyEMA folds a latency sample into an exponential moving average
+// in milliseconds. The first sample seeds the average.
+func updateLatencyEMA(averageMs float64, sample time.Duration, alpha float64) float64 {
+	sampleMs := float64(sample) / float64(time.Millisecond)
+	if averageMs <= 0 {
+		return sampleMs
+	}
+	return alpha*sampleMs + (1-alpha)*averageMs
+}
+
 // cleanupBackend removes tracking data for a deleted backend
 func (r *InferenceBackendReconciler) cleanupBackend(key string) {
 	r.failureMu.Lock()
 	delete(r.failureCounts, key)
+	delete(r.latencyAverages, key)
 	r.failureMu.Unlock()
 
 	// Note: Cache cleanup is handled separately when the backend is actually deleted
@@ -283,8 +589,22 @@ func (r *InferenceBackendReconciler) cleanupBackend(key string) {
 
 // SetupWithManager sets up the controller with the Manager
 func (r *InferenceBackendReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &gatewayv1alpha1.InferenceBackend{},
+		directEndpointsServiceIndex, directEndpointsService); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&gatewayv1alpha1.InferenceBackend{}).
+		// Keep the pods of DirectEndpoints backends up to date. Only
+		// EndpointSlices owned by a Service can belong to one.
+		Watches(
+			&discoveryv1.EndpointSlice{},
+			handler.EnqueueRequestsFromMapFunc(r.findBackendsForEndpointSlice),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetLabels()[discoveryv1.LabelServiceName] != ""
+			})),
+		).
 		Named("inferencebackend").
 		Complete(r)
 }
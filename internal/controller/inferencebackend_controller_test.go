@@ -0,0 +1,262 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+var _ = Describe("InferenceBackend Controller", func() {
+	Context("When averaging health-check latency", func() {
+		It("should seed the average with the first sample", func() {
+			Expect(updateLatencyEMA(0, 120*time.Millisecond, 0.3)).To(BeNumerically("~", 120, 0.001))
+		})
+
+		It("should converge toward a steady latency", func() {
+			average := updateLatencyEMA(0, 500*time.Millisecond, 0.3)
+			for i := 0; i < 30; i++ {
+				average = updateLatencyEMA(average, 100*time.Millisecond, 0.3)
+			}
+			Expect(average).To(BeNumerically("~", 100, 1))
+		})
+
+		It("should dampen a single spike", func() {
+			average := updateLatencyEMA(0, 100*time.Millisecond, 0.3)
+			for i := 0; i < 5; i++ {
+				average = updateLatencyEMA(average, 100*time.Millisecond, 0.3)
+			}
+
+			spiked := updateLatencyEMA(average, 2*time.Second, 0.3)
+			Expect(spiked).To(BeNumerically("~", 670, 1))
+			Expect(spiked).To(BeNumerically("<", 2000))
+
+			recovered := updateLatencyEMA(spiked, 100*time.Millisecond, 0.3)
+			Expect(recovered).To(BeNumerically("<", spiked))
+		})
+
+		It("should fall back to the default smoothing factor", func() {
+			r := &InferenceBackendReconciler{}
+			Expect(r.latencySmoothingFactor()).To(Equal(DefaultLatencySmoothingFactor))
+
+			r.LatencySmoothingFactor = 1.5
+			Expect(r.latencySmoothingFactor()).To(Equal(DefaultLatencySmoothingFactor))
+
+			r.LatencySmoothingFactor = 0.5
+			Expect(r.latencySmoothingFactor()).To(Equal(0.5))
+		})
+	})
+
+	Context("When a backend has a maintenance window", func() {
+		now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		at := func(offset time.Duration) *metav1.Time {
+			t := metav1.NewTime(now.Add(offset))
+			return &t
+		}
+
+		It("should only be active inside the window", func() {
+			var unset *gatewayv1alpha1.MaintenanceConfig
+			Expect(unset.IsActive(now)).To(BeFalse())
+			Expect((&gatewayv1alpha1.MaintenanceConfig{}).IsActive(now)).To(BeFalse())
+			Expect((&gatewayv1alpha1.MaintenanceConfig{Enabled: true}).IsActive(now)).To(BeTrue())
+
+			window := &gatewayv1alpha1.MaintenanceConfig{Start: at(-time.Hour), End: at(time.Hour)}
+			Expect(window.IsActive(now)).To(BeTrue())
+			Expect(window.IsActive(now.Add(-2 * time.Hour))).To(BeFalse())
+			Expect(window.IsActive(now.Add(time.Hour))).To(BeFalse())
+
+			Expect((&gatewayv1alpha1.MaintenanceConfig{Start: at(-time.Minute)}).IsActive(now)).To(BeTrue())
+			Expect((&gatewayv1alpha1.MaintenanceConfig{End: at(-time.Minute)}).IsActive(now)).To(BeFalse())
+		})
+
+		It("should requeue when the window starts or ends", func() {
+			backend := &gatewayv1alpha1.InferenceBackend{}
+			Expect(requeueInterval(backend, now)).To(Equal(30 * time.Second))
+
+			backend.Spec.Maintenance = &gatewayv1alpha1.MaintenanceConfig{Start: at(10 * time.Second)}
+			Expect(requeueInterval(backend, now)).To(Equal(10 * time.Second))
+
+			backend.Spec.Maintenance = &gatewayv1alpha1.MaintenanceConfig{Start: at(-time.Hour), End: at(5 * time.Second)}
+			Expect(requeueInterval(backend, now)).To(Equal(5 * time.Second))
+
+			backend.Spec.Maintenance = &gatewayv1alpha1.MaintenanceConfig{Enabled: true}
+			Expect(requeueInterval(backend, now)).To(Equal(30 * time.Second))
+		})
+	})
+
+	Context("When a backend is assumed healthy", func() {
+		It("should requeue rarely", func() {
+			backend := &gatewayv1alpha1.InferenceBackend{}
+			backend.Spec.AssumeHealthy = true
+			backend.Spec.HealthCheck = &gatewayv1alpha1.HealthCheck{IntervalSeconds: 10}
+			Expect(requeueInterval(backend, time.Now())).To(Equal(assumedHealthyRequeueInterval))
+		})
+	})
+
+	Context("When a backend is annotated for cordoning", func() {
+		It("should cordon and uncordon the backend in the cache", func() {
+			store := cache.NewStore()
+			reconciler := &InferenceBackendReconciler{Cache: store}
+			key := types.NamespacedName{Namespace: "default", Name: "drained"}
+			backend := &gatewayv1alpha1.InferenceBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        key.Name,
+					Namespace:   key.Namespace,
+					Annotations: map[string]string{AnnotationCordon: "true"},
+				},
+			}
+
+			reconciler.syncCordon(context.Background(), key, backend)
+			Expect(store.IsCordoned(key)).To(BeTrue())
+
+			backend.Annotations[AnnotationCordon] = "false"
+			reconciler.syncCordon(context.Background(), key, backend)
+			Expect(store.IsCordoned(key)).To(BeFalse())
+
+			backend.Annotations = nil
+			reconciler.syncCordon(context.Background(), key, backend)
+			Expect(store.IsCordoned(key)).To(BeFalse())
+		})
+	})
+
+	Context("When a backend routes to its Service's endpoints", func() {
+		const namespace = "default"
+		key := types.NamespacedName{Namespace: namespace, Name: "direct"}
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "direct-svc", Namespace: namespace},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Name: "http", Port: 8080, TargetPort: intstr.FromInt32(8000)}},
+			},
+		}
+		endpointSlice := &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "direct-svc-abc12",
+				Namespace: namespace,
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "direct-svc"},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Ports:       []discoveryv1.EndpointPort{{Name: ptr.To("http"), Port: ptr.To[int32](8000)}},
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.0.2"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}},
+				{Addresses: []string{"10.0.0.1"}},
+				{Addresses: []string{"10.0.0.3"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false)}},
+			},
+		}
+		newBackend := func(direct bool) *gatewayv1alpha1.InferenceBackend {
+			return &gatewayv1alpha1.InferenceBackend{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: gatewayv1alpha1.InferenceBackendSpec{
+					Type: gatewayv1alpha1.BackendTypeKubernetes,
+					Kubernetes: &gatewayv1alpha1.KubernetesBackend{
+						ServiceName:     "direct-svc",
+						Port:            8080,
+						DirectEndpoints: direct,
+					},
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			Expect(k8sClient.Create(ctx, service.DeepCopy())).To(Succeed())
+			Expect(k8sClient.Create(ctx, endpointSlice.DeepCopy())).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, endpointSlice.DeepCopy())).To(Succeed())
+			Expect(k8sClient.Delete(ctx, service.DeepCopy())).To(Succeed())
+		})
+
+		It("should cache the ready pods on the Service's target port", func() {
+			store := cache.NewStore()
+			reconciler := &InferenceBackendReconciler{Client: k8sClient, Cache: store}
+
+			reconciler.syncEndpoints(ctx, key, newBackend(true))
+			Expect(store.GetEndpoints(key)).To(Equal([]string{"10.0.0.1:8000", "10.0.0.2:8000"}))
+
+			reconciler.syncEndpoints(ctx, key, newBackend(false))
+			Expect(store.GetEndpoints(key)).To(BeEmpty())
+		})
+
+		It("should skip slices without the Service port", func() {
+			other := endpointSlice.DeepCopy()
+			other.Ports = []discoveryv1.EndpointPort{{Name: ptr.To("metrics"), Port: ptr.To[int32](9090)}}
+			Expect(endpointAddresses([]discoveryv1.EndpointSlice{*other}, "http")).To(BeEmpty())
+			Expect(endpointAddresses([]discoveryv1.EndpointSlice{*endpointSlice, *other}, "http")).To(HaveLen(2))
+		})
+
+		It("should index only DirectEndpoints backends by their Service", func() {
+			Expect(directEndpointsService(newBackend(true))).To(Equal([]string{namespace + "/direct-svc"}))
+			Expect(directEndpointsService(newBackend(false))).To(BeEmpty())
+
+			remote := newBackend(true)
+			remote.Spec.Kubernetes.Namespace = "models"
+			Expect(directEndpointsService(remote)).To(Equal([]string{"models/direct-svc"}))
+		})
+	})
+
+	Context("When operators request a recheck", func() {
+		const namespace = "default"
+		names := []string{"recheck-a", "recheck-b"}
+
+		BeforeEach(func() {
+			for _, name := range names {
+				backend := &gatewayv1alpha1.InferenceBackend{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+					Spec: gatewayv1alpha1.InferenceBackendSpec{
+						Type:     gatewayv1alpha1.BackendTypeExternal,
+						External: &gatewayv1alpha1.ExternalBackend{URL: "https://api.example.com"},
+					},
+				}
+				Expect(k8sClient.Create(ctx, backend)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			for _, name := range names {
+				backend := &gatewayv1alpha1.InferenceBackend{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, backend)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, backend)).To(Succeed())
+			}
+		})
+
+		It("should annotate each backend in the namespace", func() {
+			rechecker := NewBackendRechecker(k8sClient)
+
+			count, err := rechecker.Recheck(ctx, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(BeNumerically(">=", len(names)))
+
+			for _, name := range names {
+				backend := &gatewayv1alpha1.InferenceBackend{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, backend)).To(Succeed())
+				Expect(backend.Annotations).To(HaveKey(AnnotationRecheckRequested))
+			}
+		})
+	})
+})
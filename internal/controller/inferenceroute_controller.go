@@ -19,12 +19,15 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -33,6 +36,7 @@ import (
 
 	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
 	"github.com/judeoyovbaire/kortex/internal/cache"
+	"github.com/judeoyovbaire/kortex/internal/proxy"
 )
 
 // Route phase constants
@@ -50,11 +54,22 @@ const (
 	ConditionTypeRouteValid    = "RouteValid"
 )
 
+// AnnotationRateLimitRPM overrides the route's requests-per-minute limit at
+// the proxy for quick tuning, without editing the spec
+const AnnotationRateLimitRPM = "kortex.io/rate-limit-rpm"
+
 // InferenceRouteReconciler reconciles a InferenceRoute object
 type InferenceRouteReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	Cache  *cache.Store
+	Scheme      *runtime.Scheme
+	Cache       *cache.Store
+	Metrics     *proxy.MetricsRecorder
+	RateLimiter *proxy.RateLimiter
+
+	// CanaryAnalysis enables error rate rollbacks. Error rates only cover
+	// the requests served by this process's proxy, so it must only be set
+	// when the manager runs as a single replica.
+	CanaryAnalysis bool
 }
 
 // +kubebuilder:rbac:groups=gateway.inference-gateway.io,resources=inferenceroutes,verbs=get;list;watch;create;update;patch;delete
@@ -72,10 +87,13 @@ func (r *InferenceRouteReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	route := &gatewayv1alpha1.InferenceRoute{}
 	if err := r.Get(ctx, req.NamespacedName, route); err != nil {
 		if client.IgnoreNotFound(err) == nil {
-			// Resource was deleted, clean up cache
+			// Resource was deleted, clean up cache and metrics
 			if r.Cache != nil {
 				r.Cache.DeleteRoute(req.NamespacedName)
 			}
+			if r.Metrics != nil {
+				r.Metrics.DeleteRouteMetrics(req.Name)
+			}
 			return ctrl.Result{}, nil
 		}
 		log.Error(err, "Failed to fetch InferenceRoute")
@@ -90,6 +108,7 @@ func (r *InferenceRouteReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	healthyBackends := int32(0)
 	var missingBackends []string
 	var unhealthyBackends []string
+	backendHealth := make(map[string]string, totalBackends)
 
 	for _, name := range backendNames {
 		backend := &gatewayv1alpha1.InferenceBackend{}
@@ -107,6 +126,8 @@ func (r *InferenceRouteReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			continue
 		}
 
+		backendHealth[name] = backend.Status.Health
+
 		if backend.Status.Health == HealthStatusHealthy {
 			healthyBackends++
 		} else {
@@ -114,8 +135,13 @@ func (r *InferenceRouteReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}
 	}
 
-	// Determine the route phase based on backend availability
+	// Determine the route phase based on backend availability. A rule with
+	// nowhere to send its requests makes the route unusable.
 	phase := r.determinePhase(totalBackends, int(healthyBackends), len(missingBackends))
+	emptyRules := rulesWithoutBackends(route)
+	if len(emptyRules) > 0 {
+		phase = PhaseFailed
+	}
 
 	// Update status fields
 	now := metav1.Now()
@@ -123,9 +149,14 @@ func (r *InferenceRouteReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	route.Status.ActiveBackends = healthyBackends
 	route.Status.LastUpdated = &now
 
+	route.Status.DisabledExperiments = disabledExperiments(route)
+
+	// Advance canary rollouts
+	canaryRequeue := r.reconcileCanaries(route, backendHealth, now.Time)
+
 	// Set conditions
 	r.setBackendsCondition(route, missingBackends, unhealthyBackends)
-	r.setRouteValidCondition(route, missingBackends)
+	r.setRouteValidCondition(route, missingBackends, emptyRules)
 	r.setReadyCondition(route, phase)
 
 	// Persist status update
@@ -134,9 +165,26 @@ func (r *InferenceRouteReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, err
 	}
 
-	// Update cache for proxy to use
+	// The rate limit annotation takes effect at the proxy immediately
+	rateLimit, err := effectiveRateLimit(route)
+	if err != nil {
+		log.Error(err, "Ignoring rate limit annotation")
+	}
+	if r.RateLimiter != nil && rateLimit != nil {
+		r.RateLimiter.UpdateRouteLimit(route.Name, rateLimit)
+	}
+
+	// Update cache for proxy to use, with canary weights and the rate limit
+	// annotation applied
 	if r.Cache != nil {
-		r.Cache.SetRoute(req.NamespacedName, route)
+		cached := applyCanaryWeights(route)
+		if rateLimit != route.Spec.RateLimit {
+			if cached == route {
+				cached = route.DeepCopy()
+			}
+			cached.Spec.RateLimit = rateLimit
+		}
+		r.Cache.SetRoute(req.NamespacedName, cached)
 	}
 
 	log.V(1).Info("Reconciled InferenceRoute",
@@ -148,7 +196,245 @@ func (r *InferenceRouteReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	// Requeue periodically to catch backend status changes
 	// This is a backup; the watch on InferenceBackend should trigger more immediate updates
-	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	requeueAfter := 30 * time.Second
+	if canaryRequeue > 0 && canaryRequeue < requeueAfter {
+		requeueAfter = canaryRequeue
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// effectiveRateLimit returns the route's rate limit with its
+// kortex.io/rate-limit-rpm annotation applied. The spec's limit is returned
+// along with an error if the annotation isn't a positive integer.
+func effectiveRateLimit(route *gatewayv1alpha1.InferenceRoute) (*gatewayv1alpha1.RateLimitConfig, error) {
+	value, ok := route.Annotations[AnnotationRateLimitRPM]
+	if !ok {
+		return route.Spec.RateLimit, nil
+	}
+
+	rpm, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+	if err != nil || rpm <= 0 {
+		return route.Spec.RateLimit, fmt.Errorf("invalid %s annotation %q: must be a positive integer", AnnotationRateLimitRPM, value)
+	}
+
+	limit := &gatewayv1alpha1.RateLimitConfig{}
+	if route.Spec.RateLimit != nil {
+		limit = route.Spec.RateLimit.DeepCopy()
+	}
+	limit.RequestsPerMinute = int32(rpm)
+	return limit, nil
+}
+
+// rulesWithoutBackends returns the indexes of the route's rules that have no
+// backends. They're only a problem when there's no default backend to use.
+func rulesWithoutBackends(route *gatewayv1alpha1.InferenceRoute) []int {
+	if route.Spec.DefaultBackend != nil {
+		return nil
+	}
+	var empty []int
+	for i := range route.Spec.Rules {
+		if len(route.Spec.Rules[i].Backends) == 0 {
+			empty = append(empty, i)
+		}
+	}
+	return empty
+}
+
+// disabledExperiments returns the names of the route's experiments that are
+// turned off, or nil if all are running
+func disabledExperiments(route *gatewayv1alpha1.InferenceRoute) []string {
+	var disabled []string
+	for i := range route.Spec.Experiments {
+		if !route.Spec.Experiments[i].IsEnabled() {
+			disabled = append(disabled, route.Spec.Experiments[i].Name)
+		}
+	}
+	return disabled
+}
+
+// reconcileCanaries advances the canary rollouts of the route, rolling back
+// those whose canary error rate breaches their analysis, and records their
+// progress in status. It returns the time until the next step is due,
+// or zero if no rollout is progressing.
+func (r *InferenceRouteReconciler) reconcileCanaries(route *gatewayv1alpha1.InferenceRoute, backendHealth map[string]string, now time.Time) time.Duration {
+	previous := make(map[int32]*gatewayv1alpha1.CanaryStatus, len(route.Status.Canaries))
+	for i := range route.Status.Canaries {
+		previous[route.Status.Canaries[i].RuleIndex] = &route.Status.Canaries[i]
+	}
+
+	var canaries []gatewayv1alpha1.CanaryStatus
+	var requeue time.Duration
+	for i, rule := range route.Spec.Rules {
+		if rule.Canary == nil {
+			continue
+		}
+
+		prev := previous[int32(i)]
+		status := advanceCanary(rule.Canary, prev, backendHealth[rule.Canary.CanaryBackend], now)
+		status.RuleIndex = int32(i)
+
+		// Only a rollout that was already sending traffic to the canary
+		// before this step can be judged by its error rate
+		if prev != nil && prev.Phase == gatewayv1alpha1.CanaryPhaseProgressing &&
+			status.Phase != gatewayv1alpha1.CanaryPhaseRolledBack && status.CanaryBackend == prev.CanaryBackend {
+			if message := r.canaryErrorRateExceeded(route.Namespace, rule.Canary); message != "" {
+				rollBackCanary(&status, gatewayv1alpha1.CanaryReasonErrorRateExceeded, message)
+			}
+		}
+		canaries = append(canaries, status)
+
+		if status.Phase == gatewayv1alpha1.CanaryPhaseProgressing {
+			wait := canaryStepInterval(rule.Canary) - now.Sub(status.LastStepTime.Time)
+			if wait <= 0 {
+				wait = time.Second
+			}
+			if requeue == 0 || wait < requeue {
+				requeue = wait
+			}
+		}
+	}
+
+	route.Status.Canaries = canaries
+	return requeue
+}
+
+// advanceCanary computes the next state of a canary rollout. A rollout starts
+// at the first step and advances one step per interval while the canary
+// backend is healthy. It halts while the canary health is unknown and rolls
+// back to the stable backend once the canary is reported unhealthy. Changing
+// the canary backend restarts the rollout. Rollbacks on the canary's error
+// rate are handled by reconcileCanaries.
+func advanceCanary(canary *gatewayv1alpha1.CanaryConfig, prev *gatewayv1alpha1.CanaryStatus, canaryHealth string, now time.Time) gatewayv1alpha1.CanaryStatus {
+	if prev == nil || prev.CanaryBackend != canary.CanaryBackend || prev.LastStepTime == nil {
+		status := gatewayv1alpha1.CanaryStatus{
+			CanaryBackend: canary.CanaryBackend,
+			Step:          0,
+			Weight:        canary.Steps[0],
+			Phase:         gatewayv1alpha1.CanaryPhaseProgressing,
+			LastStepTime:  &metav1.Time{Time: now},
+		}
+		if len(canary.Steps) == 1 {
+			status.Phase = gatewayv1alpha1.CanaryPhaseCompleted
+		}
+		if canaryHealth == HealthStatusUnhealthy {
+			rollBackCanary(&status, gatewayv1alpha1.CanaryReasonUnhealthy, "Canary backend is unhealthy")
+		}
+		return status
+	}
+
+	status := *prev.DeepCopy()
+
+	// Terminal phases are sticky until the canary backend changes
+	if status.Phase == gatewayv1alpha1.CanaryPhaseCompleted || status.Phase == gatewayv1alpha1.CanaryPhaseRolledBack {
+		return status
+	}
+
+	if canaryHealth == HealthStatusUnhealthy {
+		rollBackCanary(&status, gatewayv1alpha1.CanaryReasonUnhealthy, "Canary backend is unhealthy")
+		return status
+	}
+
+	// Hold the current step until the canary is known to be healthy
+	if canaryHealth != HealthStatusHealthy {
+		return status
+	}
+
+	if now.Sub(status.LastStepTime.Time) < canaryStepInterval(canary) {
+		return status
+	}
+
+	next := status.Step + 1
+	if int(next) >= len(canary.Steps) {
+		next = int32(len(canary.Steps) - 1)
+	}
+	status.Step = next
+	status.Weight = canary.Steps[next]
+	status.LastStepTime = &metav1.Time{Time: now}
+	if int(next) == len(canary.Steps)-1 {
+		status.Phase = gatewayv1alpha1.CanaryPhaseCompleted
+	}
+	return status
+}
+
+// rollBackCanary sends all of the rule's traffic back to the stable backend,
+// recording why
+func rollBackCanary(status *gatewayv1alpha1.CanaryStatus, reason, message string) {
+	status.Weight = 0
+	status.Phase = gatewayv1alpha1.CanaryPhaseRolledBack
+	status.Reason = reason
+	status.Message = message
+}
+
+// canaryErrorRateExceeded checks the canary backend's error rate against the
+// canary's analysis. It returns a message describing the breach, or an empty
+// string if the error rate is acceptable, there aren't enough requests to
+// judge it, or there is no analysis or metrics recorder. The canary backend
+// lives in the route's namespace.
+func (r *InferenceRouteReconciler) canaryErrorRateExceeded(namespace string, canary *gatewayv1alpha1.CanaryConfig) string {
+	analysis := canary.Analysis
+	if analysis == nil || !r.CanaryAnalysis || r.Metrics == nil {
+		return ""
+	}
+	maxErrorRate, err := strconv.ParseFloat(analysis.MaxErrorRate, 64)
+	if err != nil {
+		return ""
+	}
+
+	window := time.Duration(analysis.WindowSeconds) * time.Second
+	if window <= 0 {
+		window = time.Minute
+	}
+	minRequests := int64(analysis.MinRequests)
+	if minRequests <= 0 {
+		minRequests = 20
+	}
+
+	errorRate, requests := r.Metrics.BackendErrorRate(namespace, canary.CanaryBackend, window)
+	if requests < minRequests || errorRate <= maxErrorRate {
+		return ""
+	}
+	return fmt.Sprintf("Canary error rate %.3f over the last %s exceeded %s (%d requests)",
+		errorRate, window, analysis.MaxErrorRate, requests)
+}
+
+// canaryStepInterval returns the configured step interval, defaulting to 5 minutes
+func canaryStepInterval(canary *gatewayv1alpha1.CanaryConfig) time.Duration {
+	if canary.StepIntervalSeconds <= 0 {
+		return 5 * time.Minute
+	}
+	return time.Duration(canary.StepIntervalSeconds) * time.Second
+}
+
+// applyCanaryWeights returns a copy of the route whose canary rules carry the
+// effective stable/canary weights recorded in status. Routes without canaries
+// are returned unchanged.
+func applyCanaryWeights(route *gatewayv1alpha1.InferenceRoute) *gatewayv1alpha1.InferenceRoute {
+	if len(route.Status.Canaries) == 0 {
+		return route
+	}
+
+	effective := route.DeepCopy()
+	for _, status := range effective.Status.Canaries {
+		if int(status.RuleIndex) >= len(effective.Spec.Rules) {
+			continue
+		}
+		rule := &effective.Spec.Rules[status.RuleIndex]
+		if rule.Canary == nil {
+			continue
+		}
+
+		// A zero weight means "default weight" to the proxy, so backends that
+		// should receive no traffic are left out entirely
+		var backends []gatewayv1alpha1.BackendRef
+		if status.Weight < 100 {
+			backends = append(backends, gatewayv1alpha1.BackendRef{Name: rule.Canary.StableBackend, Weight: ptr.To(100 - status.Weight)})
+		}
+		if status.Weight > 0 {
+			backends = append(backends, gatewayv1alpha1.BackendRef{Name: rule.Canary.CanaryBackend, Weight: ptr.To(status.Weight)})
+		}
+		rule.Backends = backends
+	}
+	return effective
 }
 
 // collectBackendNames extracts all unique backend names referenced in the route spec
@@ -160,6 +446,10 @@ func (r *InferenceRouteReconciler) collectBackendNames(route *gatewayv1alpha1.In
 		for _, backend := range rule.Backends {
 			nameSet[backend.Name] = struct{}{}
 		}
+		if rule.Canary != nil {
+			nameSet[rule.Canary.StableBackend] = struct{}{}
+			nameSet[rule.Canary.CanaryBackend] = struct{}{}
+		}
 	}
 
 	// From default backend
@@ -174,6 +464,13 @@ func (r *InferenceRouteReconciler) collectBackendNames(route *gatewayv1alpha1.In
 		}
 	}
 
+	// From model groups
+	for _, group := range route.Spec.ModelGroups {
+		for _, name := range group.Backends {
+			nameSet[name] = struct{}{}
+		}
+	}
+
 	// From A/B experiments
 	for _, exp := range route.Spec.Experiments {
 		nameSet[exp.Control] = struct{}{}
@@ -240,21 +537,26 @@ func (r *InferenceRouteReconciler) setBackendsCondition(route *gatewayv1alpha1.I
 }
 
 // setRouteValidCondition sets the RouteValid condition based on configuration validity
-func (r *InferenceRouteReconciler) setRouteValidCondition(route *gatewayv1alpha1.InferenceRoute, missing []string) {
+func (r *InferenceRouteReconciler) setRouteValidCondition(route *gatewayv1alpha1.InferenceRoute, missing []string, emptyRules []int) {
 	condition := metav1.Condition{
 		Type:               ConditionTypeRouteValid,
 		ObservedGeneration: route.Generation,
 		LastTransitionTime: metav1.Now(),
 	}
 
-	if len(missing) == 0 {
-		condition.Status = metav1.ConditionTrue
-		condition.Reason = "RouteValid"
-		condition.Message = "Route configuration is valid"
-	} else {
+	switch {
+	case len(emptyRules) > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "RuleWithoutBackends"
+		condition.Message = fmt.Sprintf("Rules %v have no backends and the route has no default backend", emptyRules)
+	case len(missing) > 0:
 		condition.Status = metav1.ConditionFalse
 		condition.Reason = "InvalidBackendReferences"
 		condition.Message = fmt.Sprintf("Referenced backends do not exist: %v", missing)
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "RouteValid"
+		condition.Message = "Route configuration is valid"
 	}
 
 	meta.SetStatusCondition(&route.Status.Conditions, condition)
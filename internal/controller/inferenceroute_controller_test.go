@@ -18,16 +18,20 @@ package controller
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/proxy"
 )
 
 var _ = Describe("InferenceRoute Controller", func() {
@@ -77,4 +81,239 @@ var _ = Describe("InferenceRoute Controller", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
+
+	Context("When an experiment is disabled", func() {
+		It("should list it in the route status", func() {
+			route := &gatewayv1alpha1.InferenceRoute{
+				Spec: gatewayv1alpha1.InferenceRouteSpec{
+					Experiments: []gatewayv1alpha1.ABExperiment{
+						{Name: "running", Control: "a", Treatment: "b"},
+						{Name: "killed", Control: "a", Treatment: "c", Enabled: ptr.To(false)},
+						{Name: "explicit", Control: "a", Treatment: "d", Enabled: ptr.To(true)},
+					},
+				},
+			}
+			Expect(disabledExperiments(route)).To(Equal([]string{"killed"}))
+		})
+	})
+
+	Context("When a route has a rate limit annotation", func() {
+		spec := &gatewayv1alpha1.RateLimitConfig{RequestsPerMinute: 60, PerUser: true}
+
+		It("should override the spec's requests per minute", func() {
+			route := &gatewayv1alpha1.InferenceRoute{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{AnnotationRateLimitRPM: "10"}},
+				Spec:       gatewayv1alpha1.InferenceRouteSpec{RateLimit: spec},
+			}
+			limit, err := effectiveRateLimit(route)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(limit.RequestsPerMinute).To(Equal(int32(10)))
+			Expect(limit.PerUser).To(BeTrue())
+			Expect(spec.RequestsPerMinute).To(Equal(int32(60)))
+		})
+
+		It("should restore the spec's limit when removed", func() {
+			route := &gatewayv1alpha1.InferenceRoute{
+				Spec: gatewayv1alpha1.InferenceRouteSpec{RateLimit: spec},
+			}
+			limit, err := effectiveRateLimit(route)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(limit).To(Equal(spec))
+		})
+
+		It("should ignore an invalid value", func() {
+			route := &gatewayv1alpha1.InferenceRoute{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{AnnotationRateLimitRPM: "fast"}},
+				Spec:       gatewayv1alpha1.InferenceRouteSpec{RateLimit: spec},
+			}
+			limit, err := effectiveRateLimit(route)
+			Expect(err).To(HaveOccurred())
+			Expect(limit).To(Equal(spec))
+		})
+	})
+
+	Context("When a rule has no backends", func() {
+		route := &gatewayv1alpha1.InferenceRoute{
+			Spec: gatewayv1alpha1.InferenceRouteSpec{
+				Rules: []gatewayv1alpha1.RouteRule{
+					{Backends: []gatewayv1alpha1.BackendRef{{Name: "a"}}},
+					{},
+				},
+			},
+		}
+
+		It("should mark the route invalid without a default backend", func() {
+			empty := rulesWithoutBackends(route)
+			Expect(empty).To(Equal([]int{1}))
+
+			reconciler := &InferenceRouteReconciler{}
+			reconciler.setRouteValidCondition(route, nil, empty)
+			condition := meta.FindStatusCondition(route.Status.Conditions, ConditionTypeRouteValid)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("RuleWithoutBackends"))
+		})
+
+		It("should accept the rule when the route has a default backend", func() {
+			withDefault := route.DeepCopy()
+			withDefault.Spec.DefaultBackend = &gatewayv1alpha1.BackendRef{Name: "a"}
+			Expect(rulesWithoutBackends(withDefault)).To(BeEmpty())
+		})
+	})
+
+	Context("When stepping a canary rollout", func() {
+		canary := &gatewayv1alpha1.CanaryConfig{
+			StableBackend:       "stable",
+			CanaryBackend:       "canary",
+			Steps:               []int32{10, 50, 100},
+			StepIntervalSeconds: 60,
+		}
+		start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		It("should start at the first step", func() {
+			status := advanceCanary(canary, nil, HealthStatusHealthy, start)
+			Expect(status.Step).To(Equal(int32(0)))
+			Expect(status.Weight).To(Equal(int32(10)))
+			Expect(status.Phase).To(Equal(gatewayv1alpha1.CanaryPhaseProgressing))
+		})
+
+		It("should advance the weight at each interval", func() {
+			status := advanceCanary(canary, nil, HealthStatusHealthy, start)
+
+			By("holding the step before the interval elapses")
+			status = advanceCanary(canary, &status, HealthStatusHealthy, start.Add(30*time.Second))
+			Expect(status.Weight).To(Equal(int32(10)))
+
+			By("advancing after one interval")
+			status = advanceCanary(canary, &status, HealthStatusHealthy, start.Add(60*time.Second))
+			Expect(status.Step).To(Equal(int32(1)))
+			Expect(status.Weight).To(Equal(int32(50)))
+			Expect(status.Phase).To(Equal(gatewayv1alpha1.CanaryPhaseProgressing))
+
+			By("completing at the last step")
+			status = advanceCanary(canary, &status, HealthStatusHealthy, start.Add(120*time.Second))
+			Expect(status.Weight).To(Equal(int32(100)))
+			Expect(status.Phase).To(Equal(gatewayv1alpha1.CanaryPhaseCompleted))
+		})
+
+		It("should halt while the canary health is unknown", func() {
+			status := advanceCanary(canary, nil, HealthStatusHealthy, start)
+			status = advanceCanary(canary, &status, HealthStatusUnknown, start.Add(5*time.Minute))
+			Expect(status.Step).To(Equal(int32(0)))
+			Expect(status.Weight).To(Equal(int32(10)))
+			Expect(status.Phase).To(Equal(gatewayv1alpha1.CanaryPhaseProgressing))
+		})
+
+		It("should roll back when the canary becomes unhealthy", func() {
+			status := advanceCanary(canary, nil, HealthStatusHealthy, start)
+			status = advanceCanary(canary, &status, HealthStatusHealthy, start.Add(60*time.Second))
+			Expect(status.Weight).To(Equal(int32(50)))
+
+			status = advanceCanary(canary, &status, HealthStatusUnhealthy, start.Add(90*time.Second))
+			Expect(status.Weight).To(Equal(int32(0)))
+			Expect(status.Phase).To(Equal(gatewayv1alpha1.CanaryPhaseRolledBack))
+			Expect(status.Reason).To(Equal(gatewayv1alpha1.CanaryReasonUnhealthy))
+
+			By("staying rolled back after the canary recovers")
+			status = advanceCanary(canary, &status, HealthStatusHealthy, start.Add(10*time.Minute))
+			Expect(status.Phase).To(Equal(gatewayv1alpha1.CanaryPhaseRolledBack))
+		})
+
+		It("should roll back when the canary error rate exceeds the analysis threshold", func() {
+			analyzed := canary.DeepCopy()
+			analyzed.Analysis = &gatewayv1alpha1.CanaryAnalysis{
+				MaxErrorRate:  "0.1",
+				WindowSeconds: 60,
+				MinRequests:   10,
+			}
+			route := &gatewayv1alpha1.InferenceRoute{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+				Spec: gatewayv1alpha1.InferenceRouteSpec{
+					Rules: []gatewayv1alpha1.RouteRule{{Canary: analyzed}},
+				},
+			}
+			metrics := proxy.NewMetricsRecorder()
+			reconciler := &InferenceRouteReconciler{Metrics: metrics, CanaryAnalysis: true}
+			health := map[string]string{"canary": HealthStatusHealthy}
+			now := time.Now()
+
+			reconciler.reconcileCanaries(route, health, now)
+			Expect(route.Status.Canaries[0].Phase).To(Equal(gatewayv1alpha1.CanaryPhaseProgressing))
+
+			By("ignoring errors from a same-named backend in another namespace")
+			for i := 0; i < 50; i++ {
+				metrics.RecordBackendStatus("other", "canary", 500)
+			}
+			reconciler.reconcileCanaries(route, health, now.Add(5*time.Second))
+			Expect(route.Status.Canaries[0].Phase).To(Equal(gatewayv1alpha1.CanaryPhaseProgressing))
+
+			By("ignoring errors until the window has enough requests")
+			for i := 0; i < 5; i++ {
+				metrics.RecordBackendStatus("default", "canary", 500)
+			}
+			reconciler.reconcileCanaries(route, health, now.Add(10*time.Second))
+			Expect(route.Status.Canaries[0].Phase).To(Equal(gatewayv1alpha1.CanaryPhaseProgressing))
+			Expect(route.Status.Canaries[0].Weight).To(Equal(int32(10)))
+
+			By("keeping the rollout going while the error rate is acceptable")
+			for i := 0; i < 95; i++ {
+				metrics.RecordBackendStatus("default", "canary", 200)
+			}
+			reconciler.reconcileCanaries(route, health, now.Add(60*time.Second))
+			Expect(route.Status.Canaries[0].Phase).To(Equal(gatewayv1alpha1.CanaryPhaseProgressing))
+			Expect(route.Status.Canaries[0].Weight).To(Equal(int32(50)))
+
+			By("rolling back once the error rate exceeds the threshold")
+			for i := 0; i < 20; i++ {
+				metrics.RecordBackendStatus("default", "canary", 503)
+			}
+			reconciler.reconcileCanaries(route, health, now.Add(70*time.Second))
+			status := route.Status.Canaries[0]
+			Expect(status.Phase).To(Equal(gatewayv1alpha1.CanaryPhaseRolledBack))
+			Expect(status.Weight).To(Equal(int32(0)))
+			Expect(status.Reason).To(Equal(gatewayv1alpha1.CanaryReasonErrorRateExceeded))
+			Expect(status.Message).To(ContainSubstring("exceeded 0.1"))
+			Expect(applyCanaryWeights(route).Spec.Rules[0].Backends).To(Equal([]gatewayv1alpha1.BackendRef{
+				{Name: "stable", Weight: ptr.To[int32](100)},
+			}))
+		})
+
+		It("should not analyze the error rate unless canary analysis is enabled", func() {
+			analyzed := canary.DeepCopy()
+			analyzed.Analysis = &gatewayv1alpha1.CanaryAnalysis{MaxErrorRate: "0.1", MinRequests: 1}
+			metrics := proxy.NewMetricsRecorder()
+			metrics.RecordBackendStatus("default", "canary", 500)
+			reconciler := &InferenceRouteReconciler{Metrics: metrics}
+
+			Expect(reconciler.canaryErrorRateExceeded("default", analyzed)).To(BeEmpty())
+			reconciler.CanaryAnalysis = true
+			Expect(reconciler.canaryErrorRateExceeded("default", analyzed)).NotTo(BeEmpty())
+		})
+
+		It("should apply the effective weights to the cached route", func() {
+			route := &gatewayv1alpha1.InferenceRoute{
+				Spec: gatewayv1alpha1.InferenceRouteSpec{
+					Rules: []gatewayv1alpha1.RouteRule{{
+						Backends: []gatewayv1alpha1.BackendRef{{Name: "stable"}},
+						Canary:   canary,
+					}},
+				},
+				Status: gatewayv1alpha1.InferenceRouteStatus{
+					Canaries: []gatewayv1alpha1.CanaryStatus{{RuleIndex: 0, CanaryBackend: "canary", Weight: 10}},
+				},
+			}
+
+			effective := applyCanaryWeights(route)
+			Expect(effective.Spec.Rules[0].Backends).To(Equal([]gatewayv1alpha1.BackendRef{
+				{Name: "stable", Weight: ptr.To[int32](90)},
+				{Name: "canary", Weight: ptr.To[int32](10)},
+			}))
+			Expect(route.Spec.Rules[0].Backends).To(HaveLen(1))
+
+			route.Status.Canaries[0].Weight = 0
+			Expect(applyCanaryWeights(route).Spec.Rules[0].Backends).To(Equal([]gatewayv1alpha1.BackendRef{
+				{Name: "stable", Weight: ptr.To[int32](100)},
+			}))
+		})
+	})
 })
@@ -0,0 +1,67 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+)
+
+// AnnotationRecheckRequested is set to the time an immediate health check of
+// the backend was requested. Changing it triggers a reconcile on the leader.
+const AnnotationRecheckRequested = "kortex.io/recheck-requested-at"
+
+// BackendRechecker triggers immediate reconciles, and so health probes, of
+// InferenceBackends without waiting for their requeue interval. It annotates
+// the backends rather than enqueueing them directly, so that it works on
+// every replica and not only the one running the controller.
+type BackendRechecker struct {
+	client client.Client
+}
+
+// NewBackendRechecker creates a rechecker that lists and annotates backends with c
+func NewBackendRechecker(c client.Client) *BackendRechecker {
+	return &BackendRechecker{client: c}
+}
+
+// Recheck requests a reconcile of every InferenceBackend in the namespace,
+// or in all namespaces if it is empty, and returns how many were requested
+func (r *BackendRechecker) Recheck(ctx context.Context, namespace string) (int, error) {
+	backends := &gatewayv1alpha1.InferenceBackendList{}
+	if err := r.client.List(ctx, backends, client.InNamespace(namespace)); err != nil {
+		return 0, fmt.Errorf("listing backends: %w", err)
+	}
+
+	requested := time.Now().UTC().Format(time.RFC3339Nano)
+	for i := range backends.Items {
+		backend := &backends.Items[i]
+		patch := client.MergeFrom(backend.DeepCopy())
+		if backend.Annotations == nil {
+			backend.Annotations = make(map[string]string)
+		}
+		backend.Annotations[AnnotationRecheckRequested] = requested
+		if err := r.client.Patch(ctx, backend, patch); err != nil {
+			return i, fmt.Errorf("requesting recheck of backend %s: %w", backend.Name, err)
+		}
+	}
+	return len(backends.Items), nil
+}
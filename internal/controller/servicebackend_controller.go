@@ -0,0 +1,222 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+)
+
+// Service annotations used for backend discovery
+const (
+	// AnnotationBackend marks a Service for discovery when set to "true"
+	AnnotationBackend = "kortex.io/backend"
+	// AnnotationModel sets the model served by the Service
+	AnnotationModel = "kortex.io/model"
+	// AnnotationPort selects the Service port (defaults to the first port)
+	AnnotationPort = "kortex.io/port"
+	// AnnotationInputTokenCost sets the cost per 1000 input tokens
+	AnnotationInputTokenCost = "kortex.io/input-token-cost"
+	// AnnotationOutputTokenCost sets the cost per 1000 output tokens
+	AnnotationOutputTokenCost = "kortex.io/output-token-cost"
+	// AnnotationRequestCost sets the fixed cost per request
+	AnnotationRequestCost = "kortex.io/request-cost"
+	// AnnotationCostCurrency sets the cost currency
+	AnnotationCostCurrency = "kortex.io/cost-currency"
+)
+
+// Labels applied to discovered InferenceBackends
+const (
+	LabelManagedBy            = "app.kubernetes.io/managed-by"
+	ManagedByServiceDiscovery = "kortex-service-discovery"
+)
+
+// ServiceBackendReconciler creates and updates InferenceBackends for Services
+// annotated with kortex.io/backend: "true", and removes them when the Service
+// is deleted or the annotation is removed
+type ServiceBackendReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gateway.inference-gateway.io,resources=inferencebackends,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile performs the reconciliation loop for annotated Services
+func (r *ServiceBackendReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	service := &corev1.Service{}
+	if err := r.Get(ctx, req.NamespacedName, service); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			log.Error(err, "Failed to fetch Service")
+			return ctrl.Result{}, err
+		}
+		// Service was deleted, remove the managed backend
+		return ctrl.Result{}, r.deleteManagedBackend(ctx, req)
+	}
+
+	if service.Annotations[AnnotationBackend] != "true" || !service.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.deleteManagedBackend(ctx, req)
+	}
+
+	desired, err := r.backendForService(service)
+	if err != nil {
+		log.Error(err, "Invalid backend annotations on Service")
+		return ctrl.Result{}, nil
+	}
+
+	existing := &gatewayv1alpha1.InferenceBackend{}
+	if err := r.Get(ctx, req.NamespacedName, existing); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.Create(ctx, desired); err != nil {
+			log.Error(err, "Failed to create InferenceBackend for Service")
+			return ctrl.Result{}, err
+		}
+		log.Info("Created InferenceBackend for Service", "backend", desired.Name)
+		return ctrl.Result{}, nil
+	}
+
+	// Never take over a backend that was authored by hand
+	if !isManagedBackend(existing) {
+		log.Info("InferenceBackend already exists and is not managed by service discovery, skipping",
+			"backend", existing.Name)
+		return ctrl.Result{}, nil
+	}
+
+	// Only the fields derived from the Service are managed; other spec fields
+	// (e.g. health checks) may be tuned on the backend directly
+	if existing.Spec.Type == desired.Spec.Type &&
+		equality.Semantic.DeepEqual(existing.Spec.Kubernetes, desired.Spec.Kubernetes) &&
+		equality.Semantic.DeepEqual(existing.Spec.Cost, desired.Spec.Cost) {
+		return ctrl.Result{}, nil
+	}
+
+	existing.Spec.Type = desired.Spec.Type
+	existing.Spec.Kubernetes = desired.Spec.Kubernetes
+	existing.Spec.Cost = desired.Spec.Cost
+	if err := r.Update(ctx, existing); err != nil {
+		log.Error(err, "Failed to update InferenceBackend for Service")
+		return ctrl.Result{}, err
+	}
+	log.Info("Updated InferenceBackend for Service", "backend", existing.Name)
+
+	return ctrl.Result{}, nil
+}
+
+// backendForService builds the desired InferenceBackend for a Service
+func (r *ServiceBackendReconciler) backendForService(service *corev1.Service) (*gatewayv1alpha1.InferenceBackend, error) {
+	port, err := servicePort(service)
+	if err != nil {
+		return nil, err
+	}
+
+	backend := &gatewayv1alpha1.InferenceBackend{}
+	backend.Name = service.Name
+	backend.Namespace = service.Namespace
+	backend.Labels = map[string]string{
+		LabelManagedBy: ManagedByServiceDiscovery,
+	}
+	backend.Spec = gatewayv1alpha1.InferenceBackendSpec{
+		Type: gatewayv1alpha1.BackendTypeKubernetes,
+		Kubernetes: &gatewayv1alpha1.KubernetesBackend{
+			ServiceName: service.Name,
+			Namespace:   service.Namespace,
+			Port:        port,
+			Model:       service.Annotations[AnnotationModel],
+		},
+	}
+
+	cost := &gatewayv1alpha1.CostConfig{
+		InputTokenCost:  service.Annotations[AnnotationInputTokenCost],
+		OutputTokenCost: service.Annotations[AnnotationOutputTokenCost],
+		RequestCost:     service.Annotations[AnnotationRequestCost],
+		Currency:        service.Annotations[AnnotationCostCurrency],
+	}
+	if cost.InputTokenCost != "" || cost.OutputTokenCost != "" || cost.RequestCost != "" {
+		if cost.Currency == "" {
+			cost.Currency = "USD"
+		}
+		backend.Spec.Cost = cost
+	}
+
+	// Owning the backend lets the garbage collector remove it with the Service
+	if err := controllerutil.SetControllerReference(service, backend, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return backend, nil
+}
+
+// servicePort resolves the port to use from the annotation or the first Service port
+func servicePort(service *corev1.Service) (int32, error) {
+	if value, ok := service.Annotations[AnnotationPort]; ok {
+		port, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || port <= 0 || port > 65535 {
+			return 0, fmt.Errorf("invalid %s annotation %q", AnnotationPort, value)
+		}
+		return int32(port), nil
+	}
+	if len(service.Spec.Ports) > 0 {
+		return service.Spec.Ports[0].Port, nil
+	}
+	return 0, fmt.Errorf("service has no ports and no %s annotation", AnnotationPort)
+}
+
+// deleteManagedBackend removes the backend for a Service if service discovery created it
+func (r *ServiceBackendReconciler) deleteManagedBackend(ctx context.Context, req ctrl.Request) error {
+	backend := &gatewayv1alpha1.InferenceBackend{}
+	if err := r.Get(ctx, req.NamespacedName, backend); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !isManagedBackend(backend) {
+		return nil
+	}
+
+	if err := r.Delete(ctx, backend); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	logf.FromContext(ctx).Info("Deleted InferenceBackend for Service", "backend", backend.Name)
+	return nil
+}
+
+// isManagedBackend reports whether the backend was created by service discovery
+func isManagedBackend(backend *gatewayv1alpha1.InferenceBackend) bool {
+	return backend.Labels[LabelManagedBy] == ManagedByServiceDiscovery
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *ServiceBackendReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Service{}).
+		Owns(&gatewayv1alpha1.InferenceBackend{}).
+		Named("servicebackend").
+		Complete(r)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+)
+
+var _ = Describe("ServiceBackend Controller", func() {
+	Context("When reconciling an annotated Service", func() {
+		const serviceName = "llama-service"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      serviceName,
+			Namespace: "default",
+		}
+
+		newReconciler := func() *ServiceBackendReconciler {
+			return &ServiceBackendReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+		}
+
+		BeforeEach(func() {
+			By("creating an annotated Service")
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      serviceName,
+					Namespace: "default",
+					Annotations: map[string]string{
+						AnnotationBackend:         "true",
+						AnnotationModel:           "llama-3-8b",
+						AnnotationInputTokenCost:  "0.001",
+						AnnotationOutputTokenCost: "0.002",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Name: "http", Port: 8000}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, service)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			service := &corev1.Service{}
+			if err := k8sClient.Get(ctx, typeNamespacedName, service); err == nil {
+				Expect(k8sClient.Delete(ctx, service)).To(Succeed())
+			}
+			backend := &gatewayv1alpha1.InferenceBackend{}
+			if err := k8sClient.Get(ctx, typeNamespacedName, backend); err == nil {
+				Expect(k8sClient.Delete(ctx, backend)).To(Succeed())
+			}
+		})
+
+		It("should create a managed InferenceBackend and remove it on deletion", func() {
+			By("reconciling the Service")
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			backend := &gatewayv1alpha1.InferenceBackend{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, backend)).To(Succeed())
+			Expect(backend.Labels[LabelManagedBy]).To(Equal(ManagedByServiceDiscovery))
+			Expect(backend.Spec.Type).To(Equal(gatewayv1alpha1.BackendTypeKubernetes))
+			Expect(backend.Spec.Kubernetes).NotTo(BeNil())
+			Expect(backend.Spec.Kubernetes.ServiceName).To(Equal(serviceName))
+			Expect(backend.Spec.Kubernetes.Port).To(Equal(int32(8000)))
+			Expect(backend.Spec.Kubernetes.Model).To(Equal("llama-3-8b"))
+			Expect(backend.Spec.Cost).NotTo(BeNil())
+			Expect(backend.Spec.Cost.InputTokenCost).To(Equal("0.001"))
+			Expect(backend.Spec.Cost.OutputTokenCost).To(Equal("0.002"))
+
+			By("deleting the Service")
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, service)).To(Succeed())
+
+			_, err = newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, typeNamespacedName, &gatewayv1alpha1.InferenceBackend{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should remove the managed InferenceBackend when the annotation is removed", func() {
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, &gatewayv1alpha1.InferenceBackend{})).To(Succeed())
+
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			delete(service.Annotations, AnnotationBackend)
+			Expect(k8sClient.Update(ctx, service)).To(Succeed())
+
+			_, err = newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, typeNamespacedName, &gatewayv1alpha1.InferenceBackend{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
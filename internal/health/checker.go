@@ -19,12 +19,19 @@ package health
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"sync"
 	"time"
 
 	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
 )
 
+// DefaultBatchConcurrency bounds the probes CheckBatch runs at once when the
+// checker has no concurrency limit
+const DefaultBatchConcurrency = 10
+
 // Result represents the outcome of a health check
 type Result struct {
 	Healthy   bool
@@ -36,6 +43,12 @@ type Result struct {
 // Checker performs health checks on inference backends
 type Checker struct {
 	httpClient *http.Client
+
+	providerMu       sync.RWMutex
+	providerBaseURLs map[string]string
+
+	// probes bounds the number of concurrent health probes, nil means unbounded
+	probes chan struct{}
 }
 
 // NewChecker creates a new health checker with default settings
@@ -56,8 +69,72 @@ func NewCheckerWithTimeout(timeout time.Duration) *Checker {
 	}
 }
 
+// SetProviderBaseURLs sets the base URLs used for external backends that
+// don't configure a URL, keyed by provider name
+func (c *Checker) SetProviderBaseURLs(baseURLs map[string]string) {
+	c.providerMu.Lock()
+	defer c.providerMu.Unlock()
+	c.providerBaseURLs = baseURLs
+}
+
+// SetMaxConcurrency limits the number of health probes that run at once.
+// Checks beyond the limit wait for a free slot. Zero or less removes the limit.
+// It must be called before the checker is used.
+func (c *Checker) SetMaxConcurrency(n int) {
+	if n <= 0 {
+		c.probes = nil
+		return
+	}
+	c.probes = make(chan struct{}, n)
+}
+
+// acquireProbe waits for a free probe slot. The returned function releases it.
+func (c *Checker) acquireProbe(ctx context.Context) (func(), error) {
+	if c.probes == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.probes <- struct{}{}:
+		return func() { <-c.probes }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// externalURL returns the backend URL, falling back to the provider base URL
+func (c *Checker) externalURL(external *gatewayv1alpha1.ExternalBackend) string {
+	if external.URL != "" {
+		return external.URL
+	}
+	provider := external.Provider
+	if provider == "" {
+		provider = "openai" // default
+	}
+
+	c.providerMu.RLock()
+	defer c.providerMu.RUnlock()
+	return c.providerBaseURLs[provider]
+}
+
 // Check performs a health check on the given backend
 func (c *Checker) Check(ctx context.Context, backend *gatewayv1alpha1.InferenceBackend) Result {
+	// Backends assumed healthy are never probed
+	if backend.Spec.AssumeHealthy {
+		return Result{Healthy: true, Timestamp: time.Now()}
+	}
+
+	// Wait for a probe slot before starting the timeout so that queueing
+	// does not count against the backend
+	release, err := c.acquireProbe(ctx)
+	if err != nil {
+		return Result{
+			Healthy:   false,
+			Error:     fmt.Errorf("waiting for health check slot: %w", err),
+			Timestamp: time.Now(),
+		}
+	}
+	defer release()
+
 	// Determine timeout from backend config
 	timeout := 5 * time.Second
 	if backend.Spec.HealthCheck != nil && backend.Spec.HealthCheck.TimeoutSeconds > 0 {
@@ -68,6 +145,10 @@ func (c *Checker) Check(ctx context.Context, backend *gatewayv1alpha1.InferenceB
 	checkCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	if backend.Spec.HealthCheck != nil && backend.Spec.HealthCheck.Type == gatewayv1alpha1.HealthCheckTypeTCP {
+		return c.checkTCP(checkCtx, backend)
+	}
+
 	switch backend.Spec.Type {
 	case gatewayv1alpha1.BackendTypeExternal:
 		return c.checkExternal(checkCtx, backend)
@@ -84,6 +165,40 @@ func (c *Checker) Check(ctx context.Context, backend *gatewayv1alpha1.InferenceB
 	}
 }
 
+// CheckBatch probes the backends concurrently and returns the results keyed
+// by backend name. At most the SetMaxConcurrency limit, or
+// DefaultBatchConcurrency when there is none, run at once.
+func (c *Checker) CheckBatch(ctx context.Context, backends []*gatewayv1alpha1.InferenceBackend) map[string]Result {
+	limit := DefaultBatchConcurrency
+	if c.probes != nil {
+		limit = cap(c.probes)
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		slots   = make(chan struct{}, limit)
+		results = make(map[string]Result, len(backends))
+	)
+	for _, backend := range backends {
+		if backend == nil {
+			continue
+		}
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			result := c.Check(ctx, backend)
+			mu.Lock()
+			results[backend.Name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
 // checkExternal verifies external API endpoints (OpenAI, Anthropic, etc.)
 // External APIs typically don't have traditional health endpoints, so we verify URL reachability
 func (c *Checker) checkExternal(ctx context.Context, backend *gatewayv1alpha1.InferenceBackend) Result {
@@ -95,7 +210,7 @@ func (c *Checker) checkExternal(ctx context.Context, backend *gatewayv1alpha1.In
 		}
 	}
 
-	url := backend.Spec.External.URL
+	url := c.externalURL(backend.Spec.External)
 	if url == "" {
 		return Result{
 			Healthy:   false,
@@ -116,6 +231,8 @@ func (c *Checker) checkExternal(ctx context.Context, backend *gatewayv1alpha1.In
 			Latency:   time.Since(start),
 		}
 	}
+	req.Host = backend.Spec.External.HostOverride
+	setHealthCheckHeaders(req, backend)
 
 	resp, err := c.httpClient.Do(req)
 	latency := time.Since(start)
@@ -157,7 +274,7 @@ func (c *Checker) checkKubernetes(ctx context.Context, backend *gatewayv1alpha1.
 		}
 	}
 
-	return c.doHealthCheck(ctx, url)
+	return c.doHealthCheck(ctx, url, backend)
 }
 
 // checkKServe performs health check against a KServe InferenceService
@@ -171,11 +288,97 @@ func (c *Checker) checkKServe(ctx context.Context, backend *gatewayv1alpha1.Infe
 		}
 	}
 
-	return c.doHealthCheck(ctx, url)
+	return c.doHealthCheck(ctx, url, backend)
+}
+
+// checkTCP marks the backend healthy if a TCP connection to its serving port
+// succeeds, for backends that don't speak HTTP
+func (c *Checker) checkTCP(ctx context.Context, backend *gatewayv1alpha1.InferenceBackend) Result {
+	address, err := c.tcpAddress(backend)
+	if err != nil {
+		return Result{
+			Healthy:   false,
+			Error:     err,
+			Timestamp: time.Now(),
+		}
+	}
+
+	start := time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	latency := time.Since(start)
+
+	if err != nil {
+		return Result{
+			Healthy:   false,
+			Error:     fmt.Errorf("tcp health check failed: %w", err),
+			Timestamp: time.Now(),
+			Latency:   latency,
+		}
+	}
+	_ = conn.Close()
+
+	return Result{
+		Healthy:   true,
+		Latency:   latency,
+		Timestamp: time.Now(),
+	}
+}
+
+// tcpAddress returns the host and port of the backend's serving port
+func (c *Checker) tcpAddress(backend *gatewayv1alpha1.InferenceBackend) (string, error) {
+	switch backend.Spec.Type {
+	case gatewayv1alpha1.BackendTypeExternal:
+		rawURL, err := c.BuildHealthCheckURL(backend)
+		if err != nil {
+			return "", err
+		}
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return "", fmt.Errorf("invalid external backend URL: %w", err)
+		}
+		port := u.Port()
+		if port == "" {
+			port = "443"
+			if u.Scheme == "http" {
+				port = "80"
+			}
+		}
+		return net.JoinHostPort(u.Hostname(), port), nil
+
+	case gatewayv1alpha1.BackendTypeKubernetes:
+		if backend.Spec.Kubernetes == nil {
+			return "", fmt.Errorf("kubernetes backend config is not configured")
+		}
+		k8s := backend.Spec.Kubernetes
+		namespace := k8s.Namespace
+		if namespace == "" {
+			namespace = backend.Namespace
+		}
+		port := k8s.Port
+		if port == 0 {
+			port = 8080
+		}
+		return fmt.Sprintf("%s.%s.svc.cluster.local:%d", k8s.ServiceName, namespace, port), nil
+
+	case gatewayv1alpha1.BackendTypeKServe:
+		if backend.Spec.KServe == nil {
+			return "", fmt.Errorf("kserve backend config is not configured")
+		}
+		kserve := backend.Spec.KServe
+		namespace := kserve.Namespace
+		if namespace == "" {
+			namespace = backend.Namespace
+		}
+		return fmt.Sprintf("%s-predictor.%s.svc.cluster.local:80", kserve.ServiceName, namespace), nil
+
+	default:
+		return "", fmt.Errorf("unknown backend type: %s", backend.Spec.Type)
+	}
 }
 
 // doHealthCheck performs the actual HTTP health check
-func (c *Checker) doHealthCheck(ctx context.Context, url string) Result {
+func (c *Checker) doHealthCheck(ctx context.Context, url string, backend *gatewayv1alpha1.InferenceBackend) Result {
 	start := time.Now()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -187,6 +390,7 @@ func (c *Checker) doHealthCheck(ctx context.Context, url string) Result {
 			Latency:   time.Since(start),
 		}
 	}
+	setHealthCheckHeaders(req, backend)
 
 	resp, err := c.httpClient.Do(req)
 	latency := time.Since(start)
@@ -216,15 +420,29 @@ func (c *Checker) doHealthCheck(ctx context.Context, url string) Result {
 	}
 }
 
+// setHealthCheckHeaders adds the backend's configured health check headers
+func setHealthCheckHeaders(req *http.Request, backend *gatewayv1alpha1.InferenceBackend) {
+	if backend == nil || backend.Spec.HealthCheck == nil {
+		return
+	}
+	for name, value := range backend.Spec.HealthCheck.Headers {
+		req.Header.Set(name, value)
+	}
+}
+
 // BuildHealthCheckURL constructs the health check URL for a backend
 // This is exported for use by other packages that need the URL
 func (c *Checker) BuildHealthCheckURL(backend *gatewayv1alpha1.InferenceBackend) (string, error) {
 	switch backend.Spec.Type {
 	case gatewayv1alpha1.BackendTypeExternal:
-		if backend.Spec.External == nil || backend.Spec.External.URL == "" {
+		if backend.Spec.External == nil {
+			return "", fmt.Errorf("external backend URL is not configured")
+		}
+		url := c.externalURL(backend.Spec.External)
+		if url == "" {
 			return "", fmt.Errorf("external backend URL is not configured")
 		}
-		return backend.Spec.External.URL, nil
+		return url, nil
 
 	case gatewayv1alpha1.BackendTypeKubernetes:
 		if backend.Spec.Kubernetes == nil {
@@ -18,8 +18,13 @@ package health
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -82,6 +87,39 @@ func TestChecker_Check_ExternalBackend(t *testing.T) {
 	}
 }
 
+func TestChecker_Check_AssumeHealthy(t *testing.T) {
+	var probes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	checker := NewChecker()
+	backend := &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "default",
+		},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type: gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{
+				URL: server.URL,
+			},
+			AssumeHealthy: true,
+		},
+	}
+
+	result := checker.Check(context.Background(), backend)
+
+	if !result.Healthy {
+		t.Errorf("expected backend assumed healthy to be healthy, got error: %v", result.Error)
+	}
+	if got := probes.Load(); got != 0 {
+		t.Errorf("expected no health probe, got %d", got)
+	}
+}
+
 func TestChecker_Check_ExternalBackend_Unhealthy(t *testing.T) {
 	// Create a test server that returns 500
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -113,6 +151,39 @@ func TestChecker_Check_ExternalBackend_Unhealthy(t *testing.T) {
 	}
 }
 
+func TestChecker_Check_ExternalBackend_HostOverride(t *testing.T) {
+	var receivedHost string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHost = r.Host
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	checker := NewChecker()
+	backend := &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "default",
+		},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type: gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{
+				URL:          server.URL,
+				HostOverride: "llm.internal.example.com",
+			},
+		},
+	}
+
+	result := checker.Check(context.Background(), backend)
+
+	if !result.Healthy {
+		t.Errorf("expected healthy, got error: %v", result.Error)
+	}
+	if receivedHost != "llm.internal.example.com" {
+		t.Errorf("expected the overridden Host header, got %q", receivedHost)
+	}
+}
+
 func TestChecker_Check_ExternalBackend_AuthError(t *testing.T) {
 	// Create a test server that returns 401 (should still be considered healthy)
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -265,6 +336,39 @@ func TestChecker_BuildHealthCheckURL_External(t *testing.T) {
 	}
 }
 
+func TestChecker_BuildHealthCheckURL_ExternalProviderBaseURL(t *testing.T) {
+	checker := NewChecker()
+	checker.SetProviderBaseURLs(map[string]string{
+		"openai": "https://openai.internal.example.com/v1",
+	})
+	backend := &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "default",
+		},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type: gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{
+				Provider: "openai",
+			},
+		},
+	}
+
+	url, err := checker.BuildHealthCheckURL(backend)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if url != "https://openai.internal.example.com/v1" {
+		t.Errorf("expected 'https://openai.internal.example.com/v1', got '%s'", url)
+	}
+
+	// Providers without a configured base URL still require a URL
+	backend.Spec.External.Provider = "anthropic"
+	if _, err := checker.BuildHealthCheckURL(backend); err == nil {
+		t.Error("expected error for provider without base URL")
+	}
+}
+
 func TestChecker_BuildHealthCheckURL_Kubernetes(t *testing.T) {
 	checker := NewChecker()
 	backend := &gatewayv1alpha1.InferenceBackend{
@@ -478,7 +582,7 @@ func TestChecker_doHealthCheck_Success(t *testing.T) {
 	defer server.Close()
 
 	checker := NewChecker()
-	result := checker.doHealthCheck(context.Background(), server.URL)
+	result := checker.doHealthCheck(context.Background(), server.URL, nil)
 
 	if !result.Healthy {
 		t.Errorf("expected healthy, got unhealthy: %v", result.Error)
@@ -492,7 +596,7 @@ func TestChecker_doHealthCheck_ServerError(t *testing.T) {
 	defer server.Close()
 
 	checker := NewChecker()
-	result := checker.doHealthCheck(context.Background(), server.URL)
+	result := checker.doHealthCheck(context.Background(), server.URL, nil)
 
 	if result.Healthy {
 		t.Error("expected unhealthy for 500 response")
@@ -506,10 +610,304 @@ func TestChecker_doHealthCheck_RedirectOK(t *testing.T) {
 	defer server.Close()
 
 	checker := NewChecker()
-	result := checker.doHealthCheck(context.Background(), server.URL)
+	result := checker.doHealthCheck(context.Background(), server.URL, nil)
 
 	// 3xx responses should be considered healthy
 	if !result.Healthy {
 		t.Error("expected healthy for 301 response")
 	}
 }
+
+func TestChecker_doHealthCheck_Headers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer probe-token" || r.Header.Get("X-Probe") != "kortex" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	backend := &gatewayv1alpha1.InferenceBackend{
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			HealthCheck: &gatewayv1alpha1.HealthCheck{
+				Headers: map[string]string{"Authorization": "Bearer probe-token", "X-Probe": "kortex"},
+			},
+		},
+	}
+
+	checker := NewChecker()
+	if result := checker.doHealthCheck(context.Background(), server.URL, backend); !result.Healthy {
+		t.Errorf("expected the configured headers on the probe, got %v", result.Error)
+	}
+	if result := checker.doHealthCheck(context.Background(), server.URL, nil); result.Healthy {
+		t.Error("expected the probe without headers to be rejected")
+	}
+}
+
+func TestChecker_Check_ExternalBackend_Headers(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	backend := &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-backend", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type:        gatewayv1alpha1.BackendTypeExternal,
+			External:    &gatewayv1alpha1.ExternalBackend{URL: server.URL},
+			HealthCheck: &gatewayv1alpha1.HealthCheck{Headers: map[string]string{"X-Api-Version": "2024-06-01"}},
+		},
+	}
+
+	NewChecker().Check(context.Background(), backend)
+
+	if got := received.Get("X-Api-Version"); got != "2024-06-01" {
+		t.Errorf("expected the configured header on the external probe, got %q", got)
+	}
+}
+
+func TestChecker_SetMaxConcurrency(t *testing.T) {
+	const limit = 3
+
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			observed := maxInFlight.Load()
+			if current <= observed || maxInFlight.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	checker := NewChecker()
+	checker.SetMaxConcurrency(limit)
+
+	backend := &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-backend", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type:     gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{URL: server.URL, Provider: "openai"},
+		},
+	}
+
+	// Schedule many checks at once
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if result := checker.Check(context.Background(), backend); !result.Healthy {
+				t.Errorf("expected healthy result, got error: %v", result.Error)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := maxInFlight.Load(); got > limit {
+		t.Errorf("expected at most %d concurrent probes, observed %d", limit, got)
+	}
+	if got := maxInFlight.Load(); got < 2 {
+		t.Errorf("expected probes to run concurrently, observed %d", got)
+	}
+}
+
+func TestChecker_SetMaxConcurrency_ContextCanceled(t *testing.T) {
+	checker := NewChecker()
+	checker.SetMaxConcurrency(1)
+
+	// Occupy the only slot
+	release, err := checker.acquireProbe(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	result := checker.Check(ctx, &gatewayv1alpha1.InferenceBackend{
+		Spec: gatewayv1alpha1.InferenceBackendSpec{Type: gatewayv1alpha1.BackendTypeExternal},
+	})
+	if result.Healthy || result.Error == nil {
+		t.Error("expected check to fail while waiting for a probe slot")
+	}
+}
+
+// newTCPBackend returns an external backend probed over TCP at the address
+func newTCPBackend(address string) *gatewayv1alpha1.InferenceBackend {
+	return &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "raw-model-server",
+			Namespace: "default",
+		},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type: gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{
+				URL: "http://" + address,
+			},
+			HealthCheck: &gatewayv1alpha1.HealthCheck{
+				Type: gatewayv1alpha1.HealthCheckTypeTCP,
+			},
+		},
+	}
+}
+
+func TestChecker_Check_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	// The server accepts connections but never speaks HTTP
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	result := NewChecker().Check(context.Background(), newTCPBackend(listener.Addr().String()))
+
+	if !result.Healthy {
+		t.Errorf("expected healthy when the port accepts connections, got: %v", result.Error)
+	}
+}
+
+func TestChecker_Check_TCP_ClosedPort(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	result := NewChecker().Check(context.Background(), newTCPBackend(address))
+
+	if result.Healthy {
+		t.Error("expected unhealthy when the port is closed")
+	}
+	if result.Error == nil {
+		t.Error("expected an error for a closed port")
+	}
+}
+
+func TestChecker_tcpAddress(t *testing.T) {
+	checker := NewChecker()
+	tests := []struct {
+		name    string
+		backend *gatewayv1alpha1.InferenceBackend
+		want    string
+	}{
+		{
+			name: "kubernetes",
+			backend: &gatewayv1alpha1.InferenceBackend{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "models"},
+				Spec: gatewayv1alpha1.InferenceBackendSpec{
+					Type:       gatewayv1alpha1.BackendTypeKubernetes,
+					Kubernetes: &gatewayv1alpha1.KubernetesBackend{ServiceName: "triton", Port: 8001},
+				},
+			},
+			want: "triton.models.svc.cluster.local:8001",
+		},
+		{
+			name: "external without port",
+			backend: &gatewayv1alpha1.InferenceBackend{
+				Spec: gatewayv1alpha1.InferenceBackendSpec{
+					Type:     gatewayv1alpha1.BackendTypeExternal,
+					External: &gatewayv1alpha1.ExternalBackend{URL: "https://api.example.com/v1"},
+				},
+			},
+			want: "api.example.com:443",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checker.tcpAddress(tt.backend)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestChecker_CheckBatch(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			observed := maxInFlight.Load()
+			if current <= observed || maxInFlight.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		if strings.HasSuffix(r.URL.Path, "/down") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	backends := make([]*gatewayv1alpha1.InferenceBackend, 0, 25)
+	for i := 0; i < 25; i++ {
+		url := server.URL + "/up"
+		if i == 0 {
+			url = server.URL + "/down"
+		}
+		backends = append(backends, &gatewayv1alpha1.InferenceBackend{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("backend-%d", i), Namespace: "default"},
+			Spec: gatewayv1alpha1.InferenceBackendSpec{
+				Type:     gatewayv1alpha1.BackendTypeExternal,
+				External: &gatewayv1alpha1.ExternalBackend{URL: url},
+			},
+		})
+	}
+
+	results := NewChecker().CheckBatch(context.Background(), backends)
+
+	if len(results) != len(backends) {
+		t.Fatalf("expected %d results, got %d", len(backends), len(results))
+	}
+	if results["backend-0"].Healthy {
+		t.Error("expected backend-0 to be unhealthy")
+	}
+	for name, result := range results {
+		if name != "backend-0" && !result.Healthy {
+			t.Errorf("expected %s to be healthy, got error: %v", name, result.Error)
+		}
+	}
+	if got := maxInFlight.Load(); got > DefaultBatchConcurrency {
+		t.Errorf("expected at most %d concurrent probes, observed %d", DefaultBatchConcurrency, got)
+	}
+	if got := maxInFlight.Load(); got < 2 {
+		t.Errorf("expected probes to run concurrently, observed %d", got)
+	}
+
+	// The checker's own limit bounds the batch too
+	maxInFlight.Store(0)
+	limited := NewChecker()
+	limited.SetMaxConcurrency(2)
+	if results := limited.CheckBatch(context.Background(), backends); len(results) != len(backends) {
+		t.Errorf("expected %d results with a limit, got %d", len(backends), len(results))
+	}
+	if got := maxInFlight.Load(); got > 2 {
+		t.Errorf("expected at most 2 concurrent probes, observed %d", got)
+	}
+}
@@ -79,11 +79,11 @@ func (a *Anthropic) GetBaseURL() string {
 
 // anthropicRequest represents an Anthropic messages request
 type anthropicRequest struct {
-	Model     string              `json:"model"`
-	MaxTokens int                 `json:"max_tokens"`
-	Messages  []anthropicMessage  `json:"messages"`
-	System    string              `json:"system,omitempty"`
-	Stream    bool                `json:"stream,omitempty"`
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	Messages  []anthropicMessage `json:"messages"`
+	System    string             `json:"system,omitempty"`
+	Stream    bool               `json:"stream,omitempty"`
 }
 
 type anthropicMessage struct {
@@ -93,11 +93,11 @@ type anthropicMessage struct {
 
 // anthropicResponse represents an Anthropic messages response
 type anthropicResponse struct {
-	ID           string `json:"id"`
-	Type         string `json:"type"`
-	Role         string `json:"role"`
-	Model        string `json:"model"`
-	Content      []struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Role    string `json:"role"`
+	Model   string `json:"model"`
+	Content []struct {
 		Type string `json:"type"`
 		Text string `json:"text"`
 	} `json:"content"`
@@ -227,4 +227,4 @@ func (a *Anthropic) EstimateTokens(text string) int {
 }
 
 // Ensure Anthropic implements Provider
-var _ Provider = (*Anthropic)(nil)
\ No newline at end of file
+var _ Provider = (*Anthropic)(nil)
@@ -247,4 +247,4 @@ func (c *Cohere) EstimateTokens(text string) int {
 }
 
 // Ensure Cohere implements Provider
-var _ Provider = (*Cohere)(nil)
\ No newline at end of file
+var _ Provider = (*Cohere)(nil)
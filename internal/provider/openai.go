@@ -77,12 +77,12 @@ func (o *OpenAI) GetBaseURL() string {
 
 // openAIRequest represents an OpenAI chat completion request
 type openAIRequest struct {
-	Model       string             `json:"model"`
-	Messages    []openAIMessage    `json:"messages"`
-	MaxTokens   int                `json:"max_tokens,omitempty"`
-	Temperature float64            `json:"temperature,omitempty"`
-	TopP        float64            `json:"top_p,omitempty"`
-	Stream      bool               `json:"stream,omitempty"`
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+	Temperature float64         `json:"temperature,omitempty"`
+	TopP        float64         `json:"top_p,omitempty"`
+	Stream      bool            `json:"stream,omitempty"`
 }
 
 type openAIMessage struct {
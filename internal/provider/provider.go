@@ -49,11 +49,11 @@ type ChatRequest struct {
 
 // ChatResponse represents a chat completion response
 type ChatResponse struct {
-	ID      string        `json:"id"`
-	Model   string        `json:"model"`
-	Message ChatMessage   `json:"message"`
-	Usage   TokenUsage    `json:"usage"`
-	Raw     []byte        `json:"-"` // Raw response body for passthrough
+	ID      string      `json:"id"`
+	Model   string      `json:"model"`
+	Message ChatMessage `json:"message"`
+	Usage   TokenUsage  `json:"usage"`
+	Raw     []byte      `json:"-"` // Raw response body for passthrough
 }
 
 // Provider defines the interface that all inference providers must implement
@@ -134,4 +134,4 @@ func (r *Registry) List() []string {
 		names = append(names, name)
 	}
 	return names
-}
\ No newline at end of file
+}
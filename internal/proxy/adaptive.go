@@ -0,0 +1,198 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var adaptiveConcurrencyLimit = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "kortex_backend_concurrency_limit",
+		Help: "Current adaptive concurrency limit per backend",
+	},
+	[]string{"backend"},
+)
+
+// AdaptiveLimiterConfig configures adaptive concurrency limiting
+type AdaptiveLimiterConfig struct {
+	// InitialLimit is the limit a backend starts with
+	InitialLimit int
+
+	// MinLimit and MaxLimit bound the limit
+	MinLimit int
+	MaxLimit int
+
+	// LatencyTolerance is how many times slower than the baseline latency a
+	// request can be before the backend is considered degraded (e.g. 2.0)
+	LatencyTolerance float64
+
+	// BackoffRatio multiplies the limit when the backend degrades (e.g. 0.9)
+	BackoffRatio float64
+
+	// BaselineWindow is how far back the baseline latency looks. The baseline
+	// is the fastest healthy request in the window, so it recovers once a
+	// fast outlier falls out of it.
+	BaselineWindow time.Duration
+}
+
+// DefaultAdaptiveLimiterConfig returns sensible defaults for adaptive concurrency
+func DefaultAdaptiveLimiterConfig() AdaptiveLimiterConfig {
+	return AdaptiveLimiterConfig{
+		InitialLimit:     20,
+		MinLimit:         1,
+		MaxLimit:         1000,
+		LatencyTolerance: 2.0,
+		BackoffRatio:     0.9,
+		BaselineWindow:   time.Minute,
+	}
+}
+
+// AdaptiveLimiter adjusts each backend's concurrency limit with AIMD: the
+// limit grows by one for every limit's worth of fast requests and shrinks
+// multiplicatively when requests are slow or fail. The limit is enforced by
+// the request queue in place of a fixed MaxConcurrency.
+type AdaptiveLimiter struct {
+	config AdaptiveLimiterConfig
+
+	mu       sync.Mutex
+	backends map[string]*adaptiveState
+	now      func() time.Time
+}
+
+// baselineBuckets is how many slices the baseline window is split into
+const baselineBuckets = 10
+
+// adaptiveState is the limit and recent minimum latencies of one backend
+type adaptiveState struct {
+	limit    float64
+	minimums [baselineBuckets]latencyBucket
+}
+
+// latencyBucket is the fastest request seen in one slice of the window
+type latencyBucket struct {
+	start   time.Time
+	minimum time.Duration
+}
+
+// NewAdaptiveLimiter creates an adaptive concurrency limiter
+func NewAdaptiveLimiter(config AdaptiveLimiterConfig) *AdaptiveLimiter {
+	return &AdaptiveLimiter{
+		config:   config,
+		backends: make(map[string]*adaptiveState),
+		now:      time.Now,
+	}
+}
+
+// Limit returns the backend's current concurrency limit
+func (l *AdaptiveLimiter) Limit(backend string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.stateLocked(backend).limit)
+}
+
+// Observe updates the backend's limit from a completed request. Failed
+// requests, such as timeouts or overload responses, always reduce the limit
+// and never count towards the baseline.
+func (l *AdaptiveLimiter) Observe(backend string, latency time.Duration, failed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := l.stateLocked(backend)
+	now := l.now()
+	window := l.baselineWindow()
+
+	degraded := failed
+	if !failed {
+		baseline := state.baseline(now, window)
+		degraded = baseline > 0 && float64(latency) > float64(baseline)*l.config.LatencyTolerance
+		// Slow requests stay out of the baseline, so that it only rises once
+		// the faster requests have aged out of the window
+		if !degraded {
+			state.record(now, latency, window)
+		}
+	}
+
+	if degraded {
+		state.limit = math.Max(state.limit*l.config.BackoffRatio, float64(l.minLimit()))
+	} else {
+		state.limit = math.Min(state.limit+1/state.limit, float64(l.maxLimit()))
+	}
+	adaptiveConcurrencyLimit.WithLabelValues(backend).Set(math.Floor(state.limit))
+}
+
+// stateLocked returns the backend's state, creating it at the initial limit.
+// The caller must hold l.mu.
+func (l *AdaptiveLimiter) stateLocked(backend string) *adaptiveState {
+	state, ok := l.backends[backend]
+	if !ok {
+		initial := min(max(l.config.InitialLimit, l.minLimit()), l.maxLimit())
+		state = &adaptiveState{limit: float64(initial)}
+		l.backends[backend] = state
+		adaptiveConcurrencyLimit.WithLabelValues(backend).Set(float64(initial))
+	}
+	return state
+}
+
+// baselineWindow is the configured window, defaulting when unset
+func (l *AdaptiveLimiter) baselineWindow() time.Duration {
+	if l.config.BaselineWindow <= 0 {
+		return DefaultAdaptiveLimiterConfig().BaselineWindow
+	}
+	return l.config.BaselineWindow
+}
+
+// baseline returns the fastest latency recorded within the window, or zero
+// if none was
+func (s *adaptiveState) baseline(now time.Time, window time.Duration) time.Duration {
+	var baseline time.Duration
+	for _, bucket := range s.minimums {
+		if bucket.minimum == 0 || now.Sub(bucket.start) >= window {
+			continue
+		}
+		if baseline == 0 || bucket.minimum < baseline {
+			baseline = bucket.minimum
+		}
+	}
+	return baseline
+}
+
+// record adds a latency to the bucket for the current slice of the window
+func (s *adaptiveState) record(now time.Time, latency time.Duration, window time.Duration) {
+	width := max(window/baselineBuckets, time.Millisecond)
+	start := now.Truncate(width)
+	bucket := &s.minimums[(start.UnixNano()/int64(width))%baselineBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = latencyBucket{start: start, minimum: latency}
+		return
+	}
+	bucket.minimum = min(bucket.minimum, latency)
+}
+
+// minLimit is the lowest limit, always allowing at least one request
+func (l *AdaptiveLimiter) minLimit() int {
+	return max(l.config.MinLimit, 1)
+}
+
+// maxLimit is the highest limit
+func (l *AdaptiveLimiter) maxLimit() int {
+	return max(l.config.MaxLimit, l.minLimit())
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+func TestAdaptiveLimiter_IncreasesWhileStable(t *testing.T) {
+	limiter := NewAdaptiveLimiter(DefaultAdaptiveLimiterConfig())
+	initial := limiter.Limit("stable")
+
+	for i := 0; i < 200; i++ {
+		limiter.Observe("stable", 100*time.Millisecond, false)
+	}
+
+	if got := limiter.Limit("stable"); got <= initial {
+		t.Errorf("expected limit to grow above %d with stable latency, got %d", initial, got)
+	}
+}
+
+func TestAdaptiveLimiter_DecreasesOnRisingLatency(t *testing.T) {
+	limiter := NewAdaptiveLimiter(DefaultAdaptiveLimiterConfig())
+
+	for i := 0; i < 200; i++ {
+		limiter.Observe("degrading", 100*time.Millisecond, false)
+	}
+	peak := limiter.Limit("degrading")
+
+	// Latency climbs well past twice the baseline
+	for latency := 150 * time.Millisecond; latency <= time.Second; latency += 50 * time.Millisecond {
+		limiter.Observe("degrading", latency, false)
+	}
+
+	got := limiter.Limit("degrading")
+	if got >= peak {
+		t.Errorf("expected limit to fall below %d as latency rose, got %d", peak, got)
+	}
+	if gauge := testutil.ToFloat64(adaptiveConcurrencyLimit.WithLabelValues("degrading")); gauge != float64(got) {
+		t.Errorf("expected gauge to report limit %d, got %v", got, gauge)
+	}
+}
+
+func TestAdaptiveLimiter_Bounds(t *testing.T) {
+	config := DefaultAdaptiveLimiterConfig()
+	config.InitialLimit = 5
+	config.MinLimit = 2
+	config.MaxLimit = 6
+	limiter := NewAdaptiveLimiter(config)
+
+	// Failures shrink the limit, but never below the minimum
+	for i := 0; i < 50; i++ {
+		limiter.Observe("bounded", time.Second, true)
+	}
+	if got := limiter.Limit("bounded"); got != 2 {
+		t.Errorf("expected limit to stop at the minimum of 2, got %d", got)
+	}
+
+	// Fast requests grow the limit, but never above the maximum
+	for i := 0; i < 500; i++ {
+		limiter.Observe("bounded", 10*time.Millisecond, false)
+	}
+	if got := limiter.Limit("bounded"); got != 6 {
+		t.Errorf("expected limit to stop at the maximum of 6, got %d", got)
+	}
+}
+
+func TestAdaptiveLimiter_BaselineRecovers(t *testing.T) {
+	limiter := NewAdaptiveLimiter(DefaultAdaptiveLimiterConfig())
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	// One unusually fast request sets a low baseline
+	limiter.Observe("recovering", 5*time.Millisecond, false)
+	for i := 0; i < 50; i++ {
+		limiter.Observe("recovering", 100*time.Millisecond, false)
+	}
+	lowered := limiter.Limit("recovering")
+
+	// Once it ages out of the window, normal requests set the baseline again
+	now = now.Add(2 * time.Minute)
+	for i := 0; i < 200; i++ {
+		limiter.Observe("recovering", 100*time.Millisecond, false)
+	}
+	if got := limiter.Limit("recovering"); got <= lowered {
+		t.Errorf("expected limit to grow above %d after the baseline recovered, got %d", lowered, got)
+	}
+}
+
+func TestBackendHandler_ObserveConcurrency(t *testing.T) {
+	handler := NewBackendHandler(cache.NewStore(), nil, zap.New(), nil, nil, nil)
+	limiter := NewAdaptiveLimiter(DefaultAdaptiveLimiterConfig())
+	handler.SetAdaptiveLimiter(limiter)
+	initial := limiter.Limit("observed")
+
+	// Cheap client errors neither set the baseline nor change the limit
+	handler.observeConcurrency("observed", http.StatusBadRequest, time.Millisecond, nil)
+	if got := limiter.Limit("observed"); got != initial {
+		t.Errorf("expected a 400 to leave the limit at %d, got %d", initial, got)
+	}
+	for i := 0; i < 50; i++ {
+		handler.observeConcurrency("observed", http.StatusOK, 100*time.Millisecond, nil)
+	}
+	if got := limiter.Limit("observed"); got <= initial {
+		t.Errorf("expected the limit to grow after a 400, got %d", got)
+	}
+
+	// Rate limiting and server errors mean the backend is overloaded
+	grown := limiter.Limit("observed")
+	handler.observeConcurrency("observed", http.StatusTooManyRequests, time.Millisecond, nil)
+	handler.observeConcurrency("observed", http.StatusInternalServerError, time.Millisecond, nil)
+	if got := limiter.Limit("observed"); got >= grown {
+		t.Errorf("expected overload responses to lower the limit below %d, got %d", grown, got)
+	}
+}
+
+func TestBackendHandler_AdaptiveLatencyIsTimeToFirstByte(t *testing.T) {
+	store := cache.NewStore()
+	handler := NewBackendHandler(store, nil, zap.New(), nil, nil, nil)
+	limiter := NewAdaptiveLimiter(DefaultAdaptiveLimiterConfig())
+	handler.SetAdaptiveLimiter(limiter)
+
+	// A stream whose first chunk is fast but which runs for a while
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: first\n\n"))
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer upstream.Close()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "stream"}, newExternalTestBackend("stream", upstream.URL))
+
+	route := &gatewayv1alpha1.InferenceRoute{ObjectMeta: metav1.ObjectMeta{Name: "test-route", Namespace: "default"}}
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	handler.ExecuteWithFallback(req.Context(), httptest.NewRecorder(), req, route, nil, gatewayv1alpha1.BackendRef{Name: "stream"})
+
+	limiter.mu.Lock()
+	baseline := limiter.backends["stream"].baseline(limiter.now(), time.Minute)
+	limiter.mu.Unlock()
+	if baseline <= 0 || baseline >= 100*time.Millisecond {
+		t.Errorf("expected the baseline to be the time to the first chunk, got %v", baseline)
+	}
+}
+
+func TestBackendHandler_AdaptiveLimitEnforced(t *testing.T) {
+	handler := NewBackendHandler(cache.NewStore(), nil, zap.New(), nil, nil, nil)
+	handler.SetRequestQueue(NewRequestQueue(QueueConfig{MaxSize: 0}))
+
+	config := DefaultAdaptiveLimiterConfig()
+	config.InitialLimit = 1
+	handler.SetAdaptiveLimiter(NewAdaptiveLimiter(config))
+
+	// No MaxConcurrency is set, so only the adaptive limit applies
+	backend := &gatewayv1alpha1.InferenceBackend{ObjectMeta: metav1.ObjectMeta{Name: "adaptive"}}
+
+	release, err := handler.acquireSlot(context.Background(), backend)
+	if err != nil {
+		t.Fatalf("expected the first request to get a slot: %v", err)
+	}
+	defer release()
+
+	if _, err := handler.acquireSlot(context.Background(), backend); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("expected the adaptive limit of 1 to reject a second request, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PriorityHeader is the request header that selects a QoS class
+const PriorityHeader = "X-Priority"
+
+// Priority is the QoS class of a request
+type Priority string
+
+const (
+	// PriorityHigh is for critical traffic and is admitted up to full capacity
+	PriorityHigh Priority = "high"
+	// PriorityNormal is the default class
+	PriorityNormal Priority = "normal"
+	// PriorityLow is for best-effort traffic and is shed first
+	PriorityLow Priority = "low"
+)
+
+var (
+	admissionRejections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kortex_admission_rejected_total",
+			Help: "Total requests rejected by admission control",
+		},
+		[]string{"priority"},
+	)
+
+	concurrencyLimitRejections = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kortex_concurrency_limit_rejected_total",
+			Help: "Total requests rejected because the proxy was at its concurrent request limit",
+		},
+	)
+)
+
+// ParsePriority maps a header value to a QoS class. Unknown or empty values
+// are treated as normal priority.
+func ParsePriority(value string) Priority {
+	switch Priority(strings.ToLower(strings.TrimSpace(value))) {
+	case PriorityHigh, "critical":
+		return PriorityHigh
+	case PriorityLow, "best-effort":
+		return PriorityLow
+	default:
+		return PriorityNormal
+	}
+}
+
+// RequestPriority returns the QoS class of a request
+func RequestPriority(req *http.Request) Priority {
+	return ParsePriority(req.Header.Get(PriorityHeader))
+}
+
+// AdmissionConfig configures the admission controller
+type AdmissionConfig struct {
+	// MaxInFlight is the gateway capacity in concurrent requests
+	MaxInFlight int
+
+	// NormalPriorityFraction is the share of capacity normal priority requests may use
+	NormalPriorityFraction float64
+
+	// LowPriorityFraction is the share of capacity low priority requests may use
+	LowPriorityFraction float64
+}
+
+// DefaultAdmissionConfig returns an admission config that reserves the top 10%
+// of capacity for high priority and the top 30% for normal and above
+func DefaultAdmissionConfig(maxInFlight int) AdmissionConfig {
+	return AdmissionConfig{
+		MaxInFlight:            maxInFlight,
+		NormalPriorityFraction: 0.9,
+		LowPriorityFraction:    0.7,
+	}
+}
+
+// AdmissionController bounds in-flight requests and sheds lower priority
+// requests first as the gateway approaches capacity
+type AdmissionController struct {
+	mu       sync.Mutex
+	inFlight int
+	limits   map[Priority]int
+}
+
+// NewAdmissionController creates an admission controller
+func NewAdmissionController(config AdmissionConfig) *AdmissionController {
+	limit := func(fraction float64) int {
+		if fraction <= 0 || fraction > 1 {
+			fraction = 1
+		}
+		return int(float64(config.MaxInFlight) * fraction)
+	}
+
+	return &AdmissionController{
+		limits: map[Priority]int{
+			PriorityHigh:   config.MaxInFlight,
+			PriorityNormal: limit(config.NormalPriorityFraction),
+			PriorityLow:    limit(config.LowPriorityFraction),
+		},
+	}
+}
+
+// Admit tries to admit a request of the given priority. If admitted, the
+// returned release function must be called once the request completes.
+func (a *AdmissionController) Admit(priority Priority) (func(), bool) {
+	limit, ok := a.limits[priority]
+	if !ok {
+		limit = a.limits[PriorityNormal]
+	}
+
+	a.mu.Lock()
+	if a.inFlight >= limit {
+		a.mu.Unlock()
+		admissionRejections.WithLabelValues(string(priority)).Inc()
+		return nil, false
+	}
+	a.inFlight++
+	a.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			a.mu.Lock()
+			a.inFlight--
+			a.mu.Unlock()
+		})
+	}, true
+}
+
+// InFlight returns the number of admitted requests that have not completed
+func (a *AdmissionController) InFlight() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.inFlight
+}
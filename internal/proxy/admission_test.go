@@ -0,0 +1,102 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"testing"
+)
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Priority
+	}{
+		{"high", PriorityHigh},
+		{"Critical", PriorityHigh},
+		{"low", PriorityLow},
+		{"best-effort", PriorityLow},
+		{"normal", PriorityNormal},
+		{"", PriorityNormal},
+		{"urgent", PriorityNormal},
+	}
+
+	for _, tt := range tests {
+		if got := ParsePriority(tt.value); got != tt.expected {
+			t.Errorf("ParsePriority(%q) = %q, expected %q", tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestAdmissionController_ShedsLowPriorityFirst(t *testing.T) {
+	ac := NewAdmissionController(AdmissionConfig{
+		MaxInFlight:            10,
+		NormalPriorityFraction: 0.9,
+		LowPriorityFraction:    0.5,
+	})
+
+	// Fill capacity up to the low priority limit
+	var releases []func()
+	for i := 0; i < 5; i++ {
+		release, ok := ac.Admit(PriorityLow)
+		if !ok {
+			t.Fatalf("expected low priority request %d to be admitted", i)
+		}
+		releases = append(releases, release)
+	}
+
+	if _, ok := ac.Admit(PriorityLow); ok {
+		t.Error("expected low priority request to be shed above its limit")
+	}
+
+	// Normal priority is admitted up to its own limit
+	for i := 0; i < 4; i++ {
+		release, ok := ac.Admit(PriorityNormal)
+		if !ok {
+			t.Fatalf("expected normal priority request %d to be admitted", i)
+		}
+		releases = append(releases, release)
+	}
+	if _, ok := ac.Admit(PriorityNormal); ok {
+		t.Error("expected normal priority request to be shed above its limit")
+	}
+
+	// High priority uses the reserved headroom
+	release, ok := ac.Admit(PriorityHigh)
+	if !ok {
+		t.Fatal("expected high priority request to be admitted")
+	}
+	releases = append(releases, release)
+	if _, ok := ac.Admit(PriorityHigh); ok {
+		t.Error("expected high priority request to be rejected at full capacity")
+	}
+
+	if ac.InFlight() != 10 {
+		t.Errorf("expected 10 in-flight requests, got %d", ac.InFlight())
+	}
+
+	// Releasing is idempotent and frees capacity
+	for _, release := range releases {
+		release()
+		release()
+	}
+	if ac.InFlight() != 0 {
+		t.Errorf("expected 0 in-flight requests, got %d", ac.InFlight())
+	}
+	if _, ok := ac.Admit(PriorityLow); !ok {
+		t.Error("expected low priority request to be admitted after capacity is freed")
+	}
+}
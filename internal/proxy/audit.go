@@ -0,0 +1,220 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+)
+
+// redactedValue replaces the values of redacted fields in audit records
+const redactedValue = "[REDACTED]"
+
+// AuditRecord is a sampled request and response pair
+type AuditRecord struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Namespace    string    `json:"namespace"`
+	Route        string    `json:"route"`
+	Backend      string    `json:"backend"`
+	StatusCode   int       `json:"statusCode"`
+	RequestBody  string    `json:"requestBody"`
+	ResponseBody string    `json:"responseBody"`
+}
+
+// AuditSink receives sampled audit records. Implementations must be safe for
+// concurrent use.
+type AuditSink interface {
+	WriteAudit(record AuditRecord)
+}
+
+// logAuditSink writes audit records to the log
+type logAuditSink struct {
+	log logr.Logger
+}
+
+// NewLogAuditSink creates a sink that writes audit records to the log
+func NewLogAuditSink(log logr.Logger) AuditSink {
+	return &logAuditSink{log: log.WithName("audit")}
+}
+
+func (s *logAuditSink) WriteAudit(record AuditRecord) {
+	s.log.Info("Audit record",
+		"timestamp", record.Timestamp,
+		"namespace", record.Namespace,
+		"route", record.Route,
+		"backend", record.Backend,
+		"status", record.StatusCode,
+		"requestBody", record.RequestBody,
+		"responseBody", record.ResponseBody,
+	)
+}
+
+// AuditLogger captures the bodies of a sample of requests on routes with
+// audit sampling and writes them to a sink with configured fields redacted
+type AuditLogger struct {
+	sink   AuditSink
+	random func() float64
+}
+
+// NewAuditLogger creates an audit logger that writes to the sink
+func NewAuditLogger(sink AuditSink) *AuditLogger {
+	return &AuditLogger{
+		sink:   sink,
+		random: rand.Float64,
+	}
+}
+
+// sample decides whether a request on the route is captured
+func (a *AuditLogger) sample(route *gatewayv1alpha1.InferenceRoute) bool {
+	if a == nil || a.sink == nil || route.Spec.AuditSampling == nil {
+		return false
+	}
+	return a.random()*100 < float64(route.Spec.AuditSampling.Percent)
+}
+
+// write redacts the bodies and sends the record to the sink
+func (a *AuditLogger) write(route *gatewayv1alpha1.InferenceRoute, backend string, statusCode int, request, response []byte) {
+	var fields []string
+	if route.Spec.AuditSampling != nil {
+		fields = route.Spec.AuditSampling.RedactFields
+	}
+	a.sink.WriteAudit(AuditRecord{
+		Timestamp:    time.Now(),
+		Namespace:    route.Namespace,
+		Route:        route.Name,
+		Backend:      backend,
+		StatusCode:   statusCode,
+		RequestBody:  string(redactBody(request, fields)),
+		ResponseBody: string(redactBody(response, fields)),
+	})
+}
+
+// captureRequestBody reads the request body for an audit record and replaces
+// it so it can still be sent to the backend
+func captureRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = req.Body.Close()
+	setRequestBody(req, body)
+	return body, nil
+}
+
+// auditBody captures a response body and reports it once the proxy closes it
+type auditBody struct {
+	*ResponseBodyCapturer
+	once    sync.Once
+	onClose func(body []byte)
+}
+
+// newAuditBody wraps a response body for an audit record
+func newAuditBody(body io.ReadCloser, onClose func(body []byte)) *auditBody {
+	return &auditBody{
+		ResponseBodyCapturer: NewResponseBodyCapturer(body),
+		onClose:              onClose,
+	}
+}
+
+func (b *auditBody) Close() error {
+	err := b.ResponseBodyCapturer.Close()
+	b.once.Do(func() {
+		b.onClose(b.Bytes())
+	})
+	return err
+}
+
+// redactBody replaces the values of the named fields, at any depth, in a JSON
+// body or in the data lines of an SSE stream. Other bodies are left unchanged.
+func redactBody(body []byte, fields []string) []byte {
+	if len(fields) == 0 || len(body) == 0 {
+		return body
+	}
+
+	if redacted, ok := redactJSON(body, fields); ok {
+		return redacted
+	}
+
+	lines := bytes.Split(body, []byte("\n"))
+	for i, line := range lines {
+		payload, ok := bytes.CutPrefix(line, []byte("data:"))
+		if !ok {
+			continue
+		}
+		if redacted, ok := redactJSON(bytes.TrimSpace(payload), fields); ok {
+			lines[i] = append([]byte("data: "), redacted...)
+		}
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
+
+// redactJSON redacts a JSON document, reporting false if it isn't valid JSON
+func redactJSON(data []byte, fields []string) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return nil, false
+	}
+	redacted, err := json.Marshal(redactValue(value, fields))
+	if err != nil {
+		return nil, false
+	}
+	return redacted, true
+}
+
+// redactValue replaces the values of the named fields in a decoded document
+func redactValue(value any, fields []string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if redactedField(key, fields) {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(child, fields)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = redactValue(child, fields)
+		}
+	}
+	return value
+}
+
+// redactedField reports whether a field is redacted, ignoring case
+func redactedField(key string, fields []string) bool {
+	for _, field := range fields {
+		if strings.EqualFold(key, field) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// capturingAuditSink records audit records for assertions
+type capturingAuditSink struct {
+	mu      sync.Mutex
+	records []AuditRecord
+}
+
+func (s *capturingAuditSink) WriteAudit(record AuditRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+}
+
+func (s *capturingAuditSink) Records() []AuditRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]AuditRecord(nil), s.records...)
+}
+
+func TestAuditLogger_SampleRate(t *testing.T) {
+	logger := NewAuditLogger(&capturingAuditSink{})
+	var i int
+	logger.random = func() float64 {
+		i++
+		return float64(i%100) / 100
+	}
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			AuditSampling: &gatewayv1alpha1.AuditSampling{Percent: 25},
+		},
+	}
+	sampled := 0
+	for n := 0; n < 1000; n++ {
+		if logger.sample(route) {
+			sampled++
+		}
+	}
+	if sampled != 250 {
+		t.Errorf("expected 25%% of requests to be sampled, got %d of 1000", sampled)
+	}
+
+	// Routes without audit sampling and handlers without a logger never sample
+	if logger.sample(&gatewayv1alpha1.InferenceRoute{}) {
+		t.Error("expected no sampling without auditSampling")
+	}
+	var disabled *AuditLogger
+	if disabled.sample(route) {
+		t.Error("expected no sampling without an audit logger")
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	fields := []string{"api_key", "Authorization"}
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "top-level field",
+			body:     `{"api_key":"sk-secret","model":"gpt-4o"}`,
+			expected: `{"api_key":"[REDACTED]","model":"gpt-4o"}`,
+		},
+		{
+			name:     "nested fields ignore case",
+			body:     `{"metadata":{"authorization":"Bearer abc"},"items":[{"API_KEY":"sk-1","n":1.50}]}`,
+			expected: `{"items":[{"API_KEY":"[REDACTED]","n":1.50}],"metadata":{"authorization":"[REDACTED]"}}`,
+		},
+		{
+			name:     "stream data lines",
+			body:     "data: {\"api_key\":\"sk-secret\"}\n\ndata: [DONE]\n\n",
+			expected: "data: {\"api_key\":\"[REDACTED]\"}\n\ndata: [DONE]\n\n",
+		},
+		{
+			name:     "not JSON",
+			body:     "api_key=sk-secret",
+			expected: "api_key=sk-secret",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(redactBody([]byte(tt.body), fields)); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	if got := string(redactBody([]byte(`{"api_key":"sk-secret"}`), nil)); got != `{"api_key":"sk-secret"}` {
+		t.Errorf("expected the body unchanged without redacted fields, got %q", got)
+	}
+}
+
+func TestBackendHandler_AuditSampling(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","api_key":"sk-echoed"}`))
+	}))
+	defer upstream.Close()
+
+	store := cache.NewStore()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "audited"}, newExternalTestBackend("audited", upstream.URL))
+	sink := &capturingAuditSink{}
+	handler := NewBackendHandler(store, nil, zap.New(), nil, nil, nil)
+	handler.SetAuditLogger(NewAuditLogger(sink))
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			AuditSampling: &gatewayv1alpha1.AuditSampling{Percent: 100, RedactFields: []string{"api_key"}},
+		},
+	}
+	execute := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions",
+			strings.NewReader(`{"api_key":"sk-secret","messages":[{"role":"user","content":"hi"}]}`))
+		rec := httptest.NewRecorder()
+		handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "audited"})
+		return rec
+	}
+
+	rec := execute()
+
+	// The client still gets the unredacted response
+	if !strings.Contains(rec.Body.String(), "sk-echoed") {
+		t.Errorf("expected the response to reach the client unchanged, got %q", rec.Body.String())
+	}
+
+	records := sink.Records()
+	if len(records) != 1 {
+		t.Fatalf("expected 1 audit record, got %d", len(records))
+	}
+	record := records[0]
+	if record.Route != "chat" || record.Backend != "audited" || record.StatusCode != http.StatusOK {
+		t.Errorf("unexpected record metadata: %+v", record)
+	}
+	if strings.Contains(record.RequestBody, "sk-secret") || !strings.Contains(record.RequestBody, `"content":"hi"`) {
+		t.Errorf("expected the request body with api_key redacted, got %q", record.RequestBody)
+	}
+	if strings.Contains(record.ResponseBody, "sk-echoed") || !strings.Contains(record.ResponseBody, "chatcmpl-1") {
+		t.Errorf("expected the response body with api_key redacted, got %q", record.ResponseBody)
+	}
+
+	// A zero percent sample captures nothing
+	route.Spec.AuditSampling.Percent = 0
+	execute()
+	if got := len(sink.Records()); got != 1 {
+		t.Errorf("expected no record at 0%%, got %d records", got)
+	}
+}
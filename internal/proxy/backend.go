@@ -19,11 +19,19 @@ package proxy
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -37,6 +45,53 @@ import (
 	"github.com/judeoyovbaire/kortex/internal/tracing"
 )
 
+// defaultAttemptTimeout is the per-backend attempt timeout when neither the
+// route nor the provider defaults configure one
+const defaultAttemptTimeout = 30 * time.Second
+
+const (
+	// maxDNSRetries is how many times a backend whose hostname can't be
+	// resolved is retried, such as a Service whose pods don't exist yet.
+	// These retries apply even when the provider configures none.
+	maxDNSRetries = 2
+
+	// dnsRetryBackoff is the delay before retrying a failed DNS lookup
+	dnsRetryBackoff = 250 * time.Millisecond
+)
+
+// maxFallbackResponseSize bounds the body kept from a response whose status
+// the route falls back on, in case it must still be returned to the client
+const maxFallbackResponseSize = 1024 * 1024
+
+// fallbackStatusError is returned when a backend answers with a status code
+// the route falls back on. It holds the response, which is returned to the
+// client if no other backend serves the request.
+type fallbackStatusError struct {
+	response *cachedResponse
+}
+
+func (e *fallbackStatusError) Error() string {
+	return fmt.Sprintf("backend returned status %d", e.response.status)
+}
+
+// errBackendUnreachable indicates the backend could not be reached and nothing
+// was written to the client, so the attempt can safely be retried
+var errBackendUnreachable = errors.New("backend unreachable")
+
+// ProviderDefaults holds per-provider settings applied to external backends
+// that use the provider
+type ProviderDefaults struct {
+	// BaseURL is used when the backend does not configure a URL
+	BaseURL string
+
+	// Timeout is the per-attempt timeout when the route does not set one
+	Timeout time.Duration
+
+	// MaxRetries is the number of times an unreachable backend is retried
+	// before moving on to the next backend in the fallback chain
+	MaxRetries int
+}
+
 // BackendHandler executes requests against backends with fallback support
 type BackendHandler struct {
 	cache          *cache.Store
@@ -47,6 +102,15 @@ type BackendHandler struct {
 	tracer         *tracing.Tracer
 	circuitBreaker *CircuitBreakerManager
 	retrier        *Retrier
+	queue          *RequestQueue
+	adaptive       *AdaptiveLimiter
+	audit          *AuditLogger
+	providerLimits *ProviderRateLimiter
+	transport      http.RoundTripper
+	endpoints      endpointBalancer
+
+	providerMu       sync.RWMutex
+	providerDefaults map[string]ProviderDefaults
 }
 
 // NewBackendHandler creates a new backend handler
@@ -73,6 +137,65 @@ func (h *BackendHandler) SetRetrier(r *Retrier) {
 	h.retrier = r
 }
 
+// SetRequestQueue enforces each backend's MaxConcurrency, queuing requests
+// beyond it. A nil queue disables the limit.
+func (h *BackendHandler) SetRequestQueue(q *RequestQueue) {
+	h.queue = q
+}
+
+// SetAdaptiveLimiter adjusts each backend's concurrency limit from observed
+// latency. The limit is enforced by the request queue and capped by
+// MaxConcurrency when that is set. A nil limiter disables it.
+func (h *BackendHandler) SetAdaptiveLimiter(l *AdaptiveLimiter) {
+	h.adaptive = l
+}
+
+// SetAuditLogger captures request and response bodies on routes with audit sampling
+func (h *BackendHandler) SetAuditLogger(a *AuditLogger) {
+	h.audit = a
+}
+
+// SetProviderRateLimiter holds requests back from backends whose provider
+// reports that its rate limit is almost exhausted
+func (h *BackendHandler) SetProviderRateLimiter(l *ProviderRateLimiter) {
+	h.providerLimits = l
+}
+
+// SetMaxRedirects follows up to n backend redirects server-side and returns
+// the final response to the client. Only redirects to the backend's own host
+// or to the allowed hosts and their subdomains are followed. Zero or less
+// passes redirects through.
+func (h *BackendHandler) SetMaxRedirects(n int, allowedHosts ...string) {
+	if n <= 0 {
+		h.transport = nil
+		return
+	}
+	h.transport = newRedirectTransport(http.DefaultTransport, n, allowedHosts...)
+}
+
+// SetProviderDefaults replaces the per-provider defaults, keyed by provider name
+func (h *BackendHandler) SetProviderDefaults(defaults map[string]ProviderDefaults) {
+	h.providerMu.Lock()
+	defer h.providerMu.Unlock()
+	h.providerDefaults = defaults
+}
+
+// getProviderDefaults returns the defaults for the backend's provider, if any
+func (h *BackendHandler) getProviderDefaults(backend *gatewayv1alpha1.InferenceBackend) (ProviderDefaults, bool) {
+	if backend.Spec.External == nil {
+		return ProviderDefaults{}, false
+	}
+	provider := backend.Spec.External.Provider
+	if provider == "" {
+		provider = "openai" // default
+	}
+
+	h.providerMu.RLock()
+	defer h.providerMu.RUnlock()
+	defaults, ok := h.providerDefaults[provider]
+	return defaults, ok
+}
+
 // GetCircuitBreakerStats returns stats for all circuit breakers
 func (h *BackendHandler) GetCircuitBreakerStats() map[string]CircuitBreakerStats {
 	if h.circuitBreaker == nil {
@@ -82,31 +205,109 @@ func (h *BackendHandler) GetCircuitBreakerStats() map[string]CircuitBreakerStats
 }
 
 // ExecuteWithFallback attempts to execute the request against the primary backend,
-// falling back to other backends in the chain if the primary fails
+// falling back to other backends in the chain if the primary fails. Rule is the
+// route rule the request matched, or nil if it uses the default backend.
 func (h *BackendHandler) ExecuteWithFallback(
 	ctx context.Context,
 	w http.ResponseWriter,
 	req *http.Request,
 	route *gatewayv1alpha1.InferenceRoute,
+	rule *gatewayv1alpha1.RouteRule,
 	primaryBackend gatewayv1alpha1.BackendRef,
 ) {
 	// Build fallback chain: primary backend first, then fallback backends
-	chain := h.buildFallbackChain(route, primaryBackend)
-
-	// Determine timeout per backend attempt
-	timeout := 30 * time.Second
-	if route.Spec.Fallback != nil && route.Spec.Fallback.TimeoutSeconds > 0 {
-		timeout = time.Duration(route.Spec.Fallback.TimeoutSeconds) * time.Second
+	var maxAttempts int
+	if route.Spec.Fallback != nil {
+		maxAttempts = int(route.Spec.Fallback.MaxAttempts)
 	}
+	h.executeChain(ctx, w, req, route, rule, h.buildFallbackChain(route, primaryBackend), maxAttempts)
+}
+
+// ExecuteModelGroup attempts to execute the request against the model group's
+// backends in priority order, skipping unhealthy ones and failing over on errors
+func (h *BackendHandler) ExecuteModelGroup(
+	ctx context.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	route *gatewayv1alpha1.InferenceRoute,
+	group *gatewayv1alpha1.ModelGroup,
+) {
+	h.executeChain(ctx, w, req, route, nil, group.Backends, 0)
+}
 
-	var lastErr error
+// ExecutePinned executes the request against the named backend only, with no
+// fallback, so failures of the pinned backend reach the client
+func (h *BackendHandler) ExecutePinned(
+	ctx context.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	route *gatewayv1alpha1.InferenceRoute,
+	backend string,
+) {
+	h.executeChain(ctx, w, req, route, nil, []string{backend}, 1)
+}
+
+// executeChain tries each backend in the chain in order until one serves the
+// request, then writes a failure response if none did. At most maxAttempts
+// backends are tried, or all of them if maxAttempts is zero.
+func (h *BackendHandler) executeChain(
+	ctx context.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	route *gatewayv1alpha1.InferenceRoute,
+	rule *gatewayv1alpha1.RouteRule,
+	chain []string,
+	maxAttempts int,
+) {
+	var lastErr, lastAttemptErr error
 	var previousBackend string
-	for i, backendName := range chain {
+	var lastAttemptElapsed time.Duration
+	var attempted, circuitOpen, unavailable, saturated, modelRejected int
+
+	if h.metrics != nil {
+		h.metrics.SetSLOTarget(route.Name, routeSLOTarget(route))
+	}
+
+	// Buffer the body once so that every backend in the chain, and every
+	// retry against it, is sent the full request
+	var body []byte
+	if req != nil && req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			h.log.V(1).Info("Failed to read request body", "error", err)
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		_ = req.Body.Close()
+		setRequestBody(req, body)
+	}
+
+	// The model the client asked for, only read if a backend restricts models
+	clientModel := sync.OnceValues(func() (string, error) { return requestedModel(req) })
+
+	// Responses with these statuses try the next backend
+	var fallbackStatuses []int32
+	if route.Spec.Fallback != nil {
+		fallbackStatuses = route.Spec.Fallback.OnStatusCodes
+	}
+	var fallbackResponse *cachedResponse
+	var fallbackBackend string
+
+	for i := 0; i < len(chain); i++ {
+		backendName := chain[i]
+
 		// Check circuit breaker first
 		if h.circuitBreaker != nil {
 			if err := h.circuitBreaker.Allow(backendName); err != nil {
 				h.log.V(1).Info("Circuit breaker blocking backend", "backend", backendName, "error", err)
 				lastErr = err
+				circuitOpen++
+
+				// The rule's breaker fallback stands in for an open primary
+				if i == 0 {
+					chain = withBreakerFallback(chain, rule)
+				}
 				continue
 			}
 		}
@@ -116,12 +317,63 @@ func (h *BackendHandler) ExecuteWithFallback(
 		if !ok {
 			h.log.V(1).Info("Backend not found in cache", "backend", backendName)
 			lastErr = fmt.Errorf("backend %s not found", backendName)
+			unavailable++
+			continue
+		}
+
+		// Backends in maintenance are never used, not even as a last resort
+		if backend.Status.Health == cache.HealthStatusMaintenance {
+			h.log.V(1).Info("Skipping backend in maintenance", "backend", backendName)
+			lastErr = fmt.Errorf("backend %s is in maintenance", backendName)
+			unavailable++
 			continue
 		}
 
+		// Cordoned backends are being drained and take no new requests
+		if h.cache.IsCordoned(types.NamespacedName{Namespace: route.Namespace, Name: backendName}) {
+			h.log.V(1).Info("Skipping cordoned backend", "backend", backendName)
+			lastErr = fmt.Errorf("backend %s is cordoned", backendName)
+			unavailable++
+			continue
+		}
+
+		// Backends serving several models only take the ones they allow
+		if len(backend.Spec.AllowedModels) > 0 {
+			model, err := clientModel()
+			if err != nil {
+				h.log.V(1).Info("Failed to read request body", "error", err)
+				http.Error(w, "Failed to read request body", http.StatusBadRequest)
+				return
+			}
+			if !modelAllowed(backend, model) {
+				h.log.V(1).Info("Skipping backend that doesn't serve the model", "backend", backendName, "model", model)
+				lastErr = fmt.Errorf("backend %s does not serve model %q", backendName, model)
+				modelRejected++
+				continue
+			}
+		}
+
 		// Skip unhealthy backends unless it's the last resort
 		if backend.Status.Health != "Healthy" && i < len(chain)-1 {
 			h.log.V(1).Info("Skipping unhealthy backend", "backend", backendName, "health", backend.Status.Health)
+			unavailable++
+			continue
+		}
+
+		// Stay under the rate limit the provider reported
+		if !h.providerLimits.Allow(backendName) {
+			h.log.V(1).Info("Provider rate limit nearly exhausted", "backend", backendName)
+			lastErr = ErrProviderRateLimited
+			saturated++
+			continue
+		}
+
+		// Wait for a free slot on capacity-limited backends
+		release, err := h.acquireSlot(ctx, backend)
+		if err != nil {
+			h.log.V(1).Info("Backend at capacity", "backend", backendName, "error", err)
+			lastErr = err
+			saturated++
 			continue
 		}
 
@@ -135,23 +387,45 @@ func (h *BackendHandler) ExecuteWithFallback(
 			h.metrics.IncActiveRequests(backendName)
 		}
 
-		// Create timeout context for this attempt
-		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		// Execute the request, retrying unreachable backends per provider
+		// defaults. The last backend's response is returned whatever its status.
+		tryStatuses := fallbackStatuses
+		if i == len(chain)-1 || (maxAttempts > 0 && attempted+1 >= maxAttempts) {
+			tryStatuses = nil
+		}
 		start := time.Now()
-
-		// Execute the request
-		statusCode, err := h.executeRequest(attemptCtx, w, req, route, backend)
+		statusCode, err := func() (int, error) {
+			// ReverseProxy panics with http.ErrAbortHandler when the client
+			// goes away mid-stream, which must not leak the slot
+			defer func() {
+				release()
+				if h.metrics != nil {
+					h.metrics.DecActiveRequests(backendName)
+				}
+			}()
+			return h.executeWithRetries(ctx, w, req, body, route, backend, tryStatuses)
+		}()
 		duration := time.Since(start)
-		cancel()
-
-		// Decrement active requests
-		if h.metrics != nil {
-			h.metrics.DecActiveRequests(backendName)
+		attempted++
+
+		// A status the route falls back on came from a working backend
+		backendErr := err
+		var statusErr *fallbackStatusError
+		if errors.As(err, &statusErr) {
+			fallbackResponse = statusErr.response
+			fallbackBackend = backendName
+			backendErr = nil
+		} else if statusCode != 0 && !errors.Is(err, errBackendUnreachable) {
+			// The backend wrote its own response, which replaces the held one.
+			// Backends that failed before answering keep it held.
+			fallbackResponse = nil
 		}
 
 		// Record circuit breaker result
 		if h.circuitBreaker != nil {
-			if err != nil || statusCode >= 500 {
+			if isConnectionError(err) {
+				h.circuitBreaker.RecordConnectionFailure(backendName)
+			} else if backendErr != nil || statusCode >= 500 {
 				h.circuitBreaker.RecordFailure(backendName)
 			} else {
 				h.circuitBreaker.RecordSuccess(backendName)
@@ -162,45 +436,283 @@ func (h *BackendHandler) ExecuteWithFallback(
 			// Success - record metrics
 			if h.metrics != nil {
 				h.metrics.RecordRequest(route.Name, backendName, statusCode, duration)
+				h.metrics.RecordBackendStatus(route.Namespace, backendName, statusCode)
 			}
 			return
 		}
 
 		// Record error
 		if h.metrics != nil {
-			h.metrics.RecordError(route.Name, backendName, "request_failed")
+			errorType := "request_failed"
+			if statusErr != nil {
+				errorType = "fallback_status"
+			}
+			h.metrics.RecordError(route.Name, backendName, errorType)
 			h.metrics.RecordRequest(route.Name, backendName, statusCode, duration)
+			h.metrics.RecordBackendStatus(route.Namespace, backendName, statusCode)
 		}
 
 		h.log.Info("Backend request failed, trying next",
 			"backend", backendName,
 			"error", err.Error(),
+			"timeout", isTimeout(err),
+			"elapsed", duration,
 			"attempt", i+1,
 			"total", len(chain),
 		)
 		lastErr = err
+		lastAttemptErr = err
+		lastAttemptElapsed = duration
 		previousBackend = backendName
 
-		// Apply exponential backoff before retrying (100ms * 2^attempt, max 2s)
+		if maxAttempts > 0 && attempted >= maxAttempts {
+			h.log.V(1).Info("Reached the maximum number of fallback attempts",
+				"route", route.Name,
+				"maxAttempts", maxAttempts,
+			)
+			break
+		}
+
+		// Apply exponential backoff before trying the next backend
 		if i < len(chain)-1 {
-			backoff := time.Duration(100<<uint(i)) * time.Millisecond
-			if backoff > 2*time.Second {
-				backoff = 2 * time.Second
-			}
 			select {
 			case <-ctx.Done():
 				// Context cancelled, don't continue retrying
 				http.Error(w, "Request cancelled", http.StatusServiceUnavailable)
 				return
-			case <-time.After(backoff):
+			case <-time.After(attemptBackoff(i)):
 				// Continue to next backend
 			}
 		}
 	}
 
-	// All backends failed
-	h.log.Error(lastErr, "All backends in fallback chain failed")
-	http.Error(w, "All backends failed: "+lastErr.Error(), http.StatusServiceUnavailable)
+	// No other backend served a request that fell back on its status, so the
+	// client gets the response that triggered the fallback
+	if fallbackResponse != nil {
+		h.log.V(1).Info("No backend served the request after a fallback status, returning it",
+			"route", route.Name,
+			"backend", fallbackBackend,
+			"status", fallbackResponse.status,
+		)
+		fallbackResponse.write(w)
+		return
+	}
+
+	// All backends failed: log the full detail, but only return the classification
+	reason := classifyFailure(attempted, circuitOpen, unavailable, saturated, lastAttemptErr)
+	if attempted == 0 && modelRejected > 0 && circuitOpen == 0 && unavailable == 0 && saturated == 0 {
+		reason = FailureModelNotAllowed
+	}
+	h.log.Error(lastErr, "All backends in fallback chain failed",
+		"route", route.Name,
+		"reason", reason,
+		"chain", chain,
+		"last_backend", previousBackend,
+		"last_elapsed", lastAttemptElapsed,
+	)
+
+	// Name the backend that timed out, as successful responses do
+	if reason == FailureTimeout {
+		w.Header().Set("X-Served-By", previousBackend)
+	}
+	writeFailure(w, reason)
+}
+
+// classifyFailure determines why no backend in the chain served the request
+func classifyFailure(attempted, circuitOpen, unavailable, saturated int, lastAttemptErr error) FailureReason {
+	if attempted == 0 {
+		if circuitOpen > 0 && unavailable == 0 && saturated == 0 {
+			return FailureCircuitOpen
+		}
+		if saturated > 0 && unavailable == 0 && circuitOpen == 0 {
+			return FailureSaturated
+		}
+		return FailureAllUnhealthy
+	}
+	if isTimeout(lastAttemptErr) {
+		return FailureTimeout
+	}
+	return FailureAllErrored
+}
+
+// isTimeout reports whether the error was caused by a timeout
+func isTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, errStreamIdle) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isConnectionError reports whether the backend couldn't be connected to at
+// all, such as a refused connection or failed DNS lookup, as opposed to a
+// timeout or an error response
+func isConnectionError(err error) bool {
+	if err == nil || isTimeout(err) {
+		return false
+	}
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) ||
+		(errors.As(err, &opErr) && opErr.Op == "dial") ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
+
+// isDNSError reports whether the error was caused by a failed DNS lookup
+func isDNSError(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// acquireSlot waits for one of the backend's MaxConcurrency slots when a
+// request queue is configured
+func (h *BackendHandler) acquireSlot(ctx context.Context, backend *gatewayv1alpha1.InferenceBackend) (func(), error) {
+	if h.queue == nil {
+		return func() {}, nil
+	}
+
+	return h.queue.Acquire(ctx, backend.Name, h.concurrencyLimit(backend))
+}
+
+// concurrencyLimit returns the backend's effective concurrency limit: its
+// MaxConcurrency, lowered by the adaptive limiter when one is configured.
+// Zero or less means unlimited.
+func (h *BackendHandler) concurrencyLimit(backend *gatewayv1alpha1.InferenceBackend) int {
+	limit := int(backend.Spec.MaxConcurrency)
+	if h.adaptive != nil {
+		if adaptive := h.adaptive.Limit(backend.Name); limit <= 0 || adaptive < limit {
+			limit = adaptive
+		}
+	}
+	return limit
+}
+
+// saturated reports whether a new request for the backend would have to wait
+// for a slot. Backends are never saturated without a request queue.
+func (h *BackendHandler) saturated(backend *gatewayv1alpha1.InferenceBackend) bool {
+	if h.queue == nil {
+		return false
+	}
+	limit := h.concurrencyLimit(backend)
+	if limit <= 0 {
+		return false
+	}
+	return h.queue.Active(backend.Name) >= limit || h.queue.Depth(backend.Name) > 0
+}
+
+// observeConcurrency feeds a completed attempt to the adaptive limiter.
+// Errors, server errors and 429s count as degradation. Other client errors
+// say nothing about the backend's load and are ignored.
+func (h *BackendHandler) observeConcurrency(backend string, statusCode int, duration time.Duration, err error) {
+	if h.adaptive == nil {
+		return
+	}
+	if err == nil && statusCode >= 400 && statusCode < 500 && statusCode != http.StatusTooManyRequests {
+		return
+	}
+	failed := err != nil || statusCode >= 500 || statusCode == http.StatusTooManyRequests
+	h.adaptive.Observe(backend, duration, failed)
+}
+
+// executeWithRetries executes the request against a single backend, sending
+// it the buffered body on each attempt. When the backend's provider configures
+// retries, attempts that fail before anything was written to the client are
+// retried with backoff. Attempts whose hostname couldn't be resolved are
+// always retried up to maxDNSRetries times with a short backoff.
+func (h *BackendHandler) executeWithRetries(
+	ctx context.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	body []byte,
+	route *gatewayv1alpha1.InferenceRoute,
+	backend *gatewayv1alpha1.InferenceBackend,
+	fallbackStatuses []int32,
+) (int, error) {
+	defaults, _ := h.getProviderDefaults(backend)
+	timeout := attemptTimeout(route, defaults)
+
+	// Provider retries draw from the same retry budget as the retrier
+	var budget *RetryBudget
+	if h.retrier != nil {
+		budget = h.retrier.Budget()
+	}
+	if budget != nil {
+		budget.RecordRequest(backend.Name)
+	}
+
+	dnsRetries := 0
+	for attempt := 0; ; attempt++ {
+		if body != nil {
+			setRequestBody(req, body)
+		}
+
+		// Create timeout context for this attempt
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		statusCode, err := h.executeRequest(attemptCtx, w, req, route, backend, fallbackStatuses)
+		cancel()
+
+		if err == nil || !errors.Is(err, errBackendUnreachable) {
+			return statusCode, err
+		}
+		dnsFailure := isDNSError(err)
+		if attempt >= defaults.MaxRetries && (!dnsFailure || dnsRetries >= maxDNSRetries) {
+			return statusCode, err
+		}
+		if budget != nil && !budget.TryRetry(backend.Name) {
+			h.log.V(1).Info("Retry budget exhausted, not retrying", "backend", backend.Name)
+			return statusCode, err
+		}
+
+		backoff := attemptBackoff(attempt)
+		if dnsFailure {
+			dnsRetries++
+			backoff = dnsRetryBackoff
+			h.log.Info("Backend hostname could not be resolved, retrying",
+				"backend", backend.Name,
+				"attempt", dnsRetries,
+				"maxRetries", maxDNSRetries,
+				"error", err.Error(),
+			)
+		} else {
+			h.log.V(1).Info("Backend unreachable, retrying",
+				"backend", backend.Name,
+				"attempt", attempt+1,
+				"maxRetries", defaults.MaxRetries,
+				"error", err.Error(),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return statusCode, err
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// attemptTimeout returns the per-attempt timeout: the route's fallback timeout
+// takes precedence over the provider default
+func attemptTimeout(route *gatewayv1alpha1.InferenceRoute, defaults ProviderDefaults) time.Duration {
+	if route.Spec.Fallback != nil && route.Spec.Fallback.TimeoutSeconds > 0 {
+		return time.Duration(route.Spec.Fallback.TimeoutSeconds) * time.Second
+	}
+	if defaults.Timeout > 0 {
+		return defaults.Timeout
+	}
+	return defaultAttemptTimeout
+}
+
+// attemptBackoff returns the exponential backoff before the next attempt (100ms * 2^attempt, max 2s)
+func attemptBackoff(attempt int) time.Duration {
+	backoff := time.Duration(100<<uint(attempt)) * time.Millisecond
+	if backoff > 2*time.Second {
+		backoff = 2 * time.Second
+	}
+	return backoff
 }
 
 // buildFallbackChain constructs the ordered list of backends to try
@@ -220,13 +732,33 @@ func (h *BackendHandler) buildFallbackChain(route *gatewayv1alpha1.InferenceRout
 	return chain
 }
 
-// executeRequest performs the actual request to a backend
+// withBreakerFallback moves the rule's breaker fallback, if it has one, to
+// directly after the primary backend in the chain
+func withBreakerFallback(chain []string, rule *gatewayv1alpha1.RouteRule) []string {
+	if rule == nil || rule.BreakerFallback == nil || rule.BreakerFallback.Name == chain[0] {
+		return chain
+	}
+
+	fallback := rule.BreakerFallback.Name
+	reordered := []string{chain[0], fallback}
+	for _, name := range chain[1:] {
+		if name != fallback {
+			reordered = append(reordered, name)
+		}
+	}
+	return reordered
+}
+
+// executeRequest performs the actual request to a backend. Responses with one
+// of the fallback statuses aren't written to the client; they are returned in
+// a *fallbackStatusError instead.
 func (h *BackendHandler) executeRequest(
 	ctx context.Context,
 	w http.ResponseWriter,
 	req *http.Request,
 	route *gatewayv1alpha1.InferenceRoute,
 	backend *gatewayv1alpha1.InferenceBackend,
+	fallbackStatuses []int32,
 ) (int, error) {
 	// Build target URL
 	targetURL, err := h.buildTargetURL(backend)
@@ -240,6 +772,20 @@ func (h *BackendHandler) executeRequest(
 		provider = backend.Spec.External.Provider
 	}
 
+	// Fill in the model for clients that omit it from the body
+	if err := injectModel(req, backend); err != nil {
+		return 0, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	// Capture the request body if this request is sampled for auditing
+	audited := h.audit.sample(route)
+	var auditRequest []byte
+	if audited {
+		if auditRequest, err = captureRequestBody(req); err != nil {
+			return 0, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
 	// Start backend span if tracing is enabled
 	var span trace.Span
 	if h.tracer != nil {
@@ -249,15 +795,26 @@ func (h *BackendHandler) executeRequest(
 		}()
 	}
 
-	// Track status code
+	// Track status code and any transport error
 	statusCode := http.StatusOK
+	var proxyErr error
 
 	// Create reverse proxy with response modification for cost tracking
 	proxy := &httputil.ReverseProxy{
+		Transport: h.transport,
 		Director: func(r *http.Request) {
 			r.URL.Scheme = targetURL.Scheme
 			r.URL.Host = targetURL.Host
 			r.Host = targetURL.Host
+			if backend.Spec.External != nil && backend.Spec.External.HostOverride != "" {
+				r.Host = backend.Spec.External.HostOverride
+			}
+
+			// Translate the API version for backends with a different path layout
+			if backend.Spec.External != nil && len(backend.Spec.External.PathVersionMap) > 0 {
+				r.URL.Path = translatePathVersion(r.URL.Path, backend.Spec.External.PathVersionMap)
+				r.URL.RawPath = ""
+			}
 
 			// Preserve the original path
 			if targetURL.Path != "" && targetURL.Path != "/" {
@@ -267,6 +824,7 @@ func (h *BackendHandler) executeRequest(
 			// Inject API key for external backends
 			if backend.Spec.Type == gatewayv1alpha1.BackendTypeExternal {
 				h.injectAPIKey(ctx, r, backend)
+				injectDefaultHeaders(r, backend)
 			}
 
 			h.log.V(2).Info("Proxying request",
@@ -277,23 +835,95 @@ func (h *BackendHandler) executeRequest(
 		ModifyResponse: func(resp *http.Response) error {
 			statusCode = resp.StatusCode
 
+			// Abort streams that stall. A backend that is silent before its
+			// first byte fails here, while nothing has reached the client.
+			if route.Spec.StreamIdleTimeoutSeconds > 0 && isEventStream(resp) {
+				idleTimeout := time.Duration(route.Spec.StreamIdleTimeoutSeconds) * time.Second
+				// A stream that stalls after data was sent can't fail over,
+				// so the client's stream is cut short
+				idleBody := newIdleTimeoutBody(resp.Body, idleTimeout, func() {
+					h.log.Info("Streaming backend went idle, aborting the stream",
+						"backend", backend.Name,
+						"idleTimeout", idleTimeout,
+					)
+					if h.metrics != nil {
+						h.metrics.RecordError(route.Name, backend.Name, "stream_idle_timeout")
+					}
+				})
+				resp.Body = idleBody
+				if err := idleBody.awaitFirstByte(); err != nil {
+					return err
+				}
+			}
+
 			// Add headers to indicate which backend served the request
 			resp.Header.Set("X-Served-By", backend.Name)
 			resp.Header.Set("X-Backend-Type", string(backend.Spec.Type))
 
+			// Track the rate limit budget the provider reports
+			h.providerLimits.Observe(backend.Name, resp.Header)
+
+			// Hold back responses the route falls back on, so the next
+			// backend can still answer the client
+			if slices.Contains(fallbackStatuses, int32(resp.StatusCode)) {
+				body, err := io.ReadAll(io.LimitReader(resp.Body, maxFallbackResponseSize))
+				if err != nil {
+					return err
+				}
+				header := resp.Header.Clone()
+				header.Del("Content-Length")
+				return &fallbackStatusError{response: &cachedResponse{
+					status: resp.StatusCode,
+					header: header,
+					body:   body,
+				}}
+			}
+
 			// Track costs if enabled
 			if route.Spec.CostTracking && backend.Spec.Cost != nil && h.costTracker != nil {
 				h.trackCosts(resp, route.Name, backend, provider)
 			}
 
+			// Capture the response body once it has been sent to the client
+			if audited {
+				status := resp.StatusCode
+				resp.Body = newAuditBody(resp.Body, func(body []byte) {
+					h.audit.write(route, backend.Name, status, auditRequest, body)
+				})
+			}
+
+			// Keep idle streaming connections open between events
+			if route.Spec.StreamHeartbeatSeconds > 0 && isEventStream(resp) {
+				resp.Body = newHeartbeatBody(resp.Body, time.Duration(route.Spec.StreamHeartbeatSeconds)*time.Second)
+			}
+
 			return nil
 		},
 		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
-			h.log.Error(err, "Proxy error",
-				"backend", backend.Name,
-				"target", targetURL.String(),
-			)
+			var statusErr *fallbackStatusError
+			if errors.As(err, &statusErr) {
+				h.log.V(1).Info("Backend returned a fallback status",
+					"backend", backend.Name,
+					"status", statusErr.response.status,
+				)
+				proxyErr = err
+				return
+			}
+			if isDNSError(err) {
+				// Usually a Service or pod that hasn't been created yet
+				h.log.Info("Backend hostname could not be resolved",
+					"backend", backend.Name,
+					"host", targetURL.Hostname(),
+					"error", err.Error(),
+				)
+			} else {
+				h.log.Error(err, "Proxy error",
+					"backend", backend.Name,
+					"target", targetURL.String(),
+				)
+			}
 			statusCode = http.StatusBadGateway
+			proxyErr = err
 		},
 	}
 
@@ -304,8 +934,14 @@ func (h *BackendHandler) executeRequest(
 	}
 
 	// Execute proxy request
+	start := time.Now()
 	proxy.ServeHTTP(recorder, req.WithContext(ctx))
 
+	// Record time to first byte written to the client
+	if !recorder.firstByte.IsZero() && h.metrics != nil {
+		h.metrics.RecordTTFB(route.Name, backend.Name, recorder.firstByte.Sub(start))
+	}
+
 	// Update status code from recorder
 	if recorder.statusCode != http.StatusOK {
 		statusCode = recorder.statusCode
@@ -321,6 +957,26 @@ func (h *BackendHandler) executeRequest(
 		}
 	}
 
+	// The adaptive limiter sees the time to the first byte, so that long
+	// streams don't look like a slow backend
+	latency := time.Since(start)
+	if !recorder.firstByte.IsZero() {
+		latency = recorder.firstByte.Sub(start)
+	}
+
+	// A held back response is handled by the fallback chain
+	var statusErr *fallbackStatusError
+	if errors.As(proxyErr, &statusErr) {
+		h.observeConcurrency(backend.Name, statusErr.response.status, latency, nil)
+		return statusCode, proxyErr
+	}
+	h.observeConcurrency(backend.Name, statusCode, latency, proxyErr)
+
+	// Nothing was written to the client if the backend could not be reached
+	if proxyErr != nil && !recorder.written {
+		return statusCode, fmt.Errorf("%w: %w", errBackendUnreachable, proxyErr)
+	}
+
 	// Check if the request failed with a server error
 	if statusCode >= 500 {
 		return statusCode, fmt.Errorf("backend returned status %d", statusCode)
@@ -336,12 +992,49 @@ func (h *BackendHandler) trackCosts(
 	backend *gatewayv1alpha1.InferenceBackend,
 	provider string,
 ) {
-	// We need to read the body to parse token usage, but we also need to forward it
-	// For streaming responses, this won't work well - we'd need a different approach
 	if resp.Body == nil {
 		return
 	}
 
+	// Costs are aggregated per provider for external backends
+	costProvider := provider
+	if backend.Spec.External != nil && costProvider == "" {
+		costProvider = "openai"
+	}
+
+	// A cost reported by the backend takes precedence over the computed estimate
+	reportedCost, reported := ParseReportedCost(resp, backend.Spec.Cost)
+	conversationID := h.costTracker.ConversationID(resp.Request)
+	user := h.costTracker.UserID(resp.Request)
+	tag := h.costTracker.CostTag(resp.Request)
+	track := func(usage TokenUsage) {
+		cost := reportedCost
+		switch {
+		case reported:
+			h.costTracker.TrackReportedCost(routeName, backend.Name, costProvider, user, tag, usage, reportedCost, backend.Spec.Cost)
+		case usage.InputTokens > 0 || usage.OutputTokens > 0:
+			h.costTracker.TrackRequest(routeName, backend.Name, costProvider, user, tag, usage, backend.Spec.Cost)
+			cost = h.costTracker.calculateCost(usage, backend.Spec.Cost)
+		default:
+			return
+		}
+		h.costTracker.TrackConversation(conversationID, usage, cost, backend.Spec.Cost)
+	}
+
+	// Streams are parsed as they are forwarded and tracked when they end, so
+	// the client still receives each event as it is produced
+	if isEventStream(resp) {
+		parser := newStreamUsageParser(provider)
+		if parser == nil {
+			if reported {
+				track(TokenUsage{})
+			}
+			return
+		}
+		resp.Body = newUsageTrackingBody(resp.Body, parser, track)
+		return
+	}
+
 	// Read the body
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -356,25 +1049,33 @@ func (h *BackendHandler) trackCosts(
 	usage := ParseTokenUsage(provider, resp, bodyBytes)
 
 	// Track costs
-	if usage.InputTokens > 0 || usage.OutputTokens > 0 {
-		h.costTracker.TrackRequest(routeName, backend.Name, usage, backend.Spec.Cost)
-	}
+	track(usage)
 }
 
 // buildTargetURL constructs the backend URL based on its type
 func (h *BackendHandler) buildTargetURL(backend *gatewayv1alpha1.InferenceBackend) (*url.URL, error) {
 	switch backend.Spec.Type {
 	case gatewayv1alpha1.BackendTypeExternal:
-		if backend.Spec.External == nil || backend.Spec.External.URL == "" {
+		if backend.Spec.External == nil {
 			return nil, fmt.Errorf("external backend URL is not configured")
 		}
-		return url.Parse(backend.Spec.External.URL)
+		if backend.Spec.External.URL != "" {
+			return url.Parse(backend.Spec.External.URL)
+		}
+		// Fall back to the provider's configured base URL
+		if defaults, ok := h.getProviderDefaults(backend); ok && defaults.BaseURL != "" {
+			return url.Parse(defaults.BaseURL)
+		}
+		return nil, fmt.Errorf("external backend URL is not configured")
 
 	case gatewayv1alpha1.BackendTypeKubernetes:
 		if backend.Spec.Kubernetes == nil {
 			return nil, fmt.Errorf("kubernetes backend config is not configured")
 		}
 		k8s := backend.Spec.Kubernetes
+		if address, ok := h.directEndpoint(backend); ok {
+			return &url.URL{Scheme: "http", Host: address}, nil
+		}
 		namespace := k8s.Namespace
 		if namespace == "" {
 			namespace = backend.Namespace
@@ -460,11 +1161,127 @@ func (h *BackendHandler) injectAPIKey(ctx context.Context, req *http.Request, ba
 	h.log.V(2).Info("Injected API key", "backend", backend.Name, "provider", provider)
 }
 
+// injectDefaultHeaders sets the backend's default headers on the outgoing request.
+// These apply to every request sent to the backend regardless of the route, and
+// are applied after the API key so they can override provider defaults such as
+// the anthropic-version header.
+func injectDefaultHeaders(req *http.Request, backend *gatewayv1alpha1.InferenceBackend) {
+	if backend.Spec.External == nil {
+		return
+	}
+	for name, value := range backend.Spec.External.DefaultHeaders {
+		req.Header.Set(name, value)
+	}
+}
+
+// translatePathVersion rewrites the longest key of mapping that prefixes path
+// on a segment boundary. Paths without a matching key are returned unchanged.
+func translatePathVersion(path string, mapping map[string]string) string {
+	var match string
+	for from := range mapping {
+		if len(from) <= len(match) {
+			continue
+		}
+		prefix := strings.TrimSuffix(from, "/")
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			match = from
+		}
+	}
+	if match == "" {
+		return path
+	}
+
+	rest := strings.TrimPrefix(path, strings.TrimSuffix(match, "/"))
+	return strings.TrimSuffix(mapping[match], "/") + rest
+}
+
+// injectModel sets the model on JSON request bodies that don't specify one:
+// the model named by the X-Model header, or else the backend's configured
+// model. A model in the body is never overwritten. Bodies that aren't JSON
+// objects are left untouched.
+func injectModel(req *http.Request, backend *gatewayv1alpha1.InferenceBackend) error {
+	model := req.Header.Get("X-Model")
+	if model == "" && backend.Spec.External != nil {
+		model = backend.Spec.External.Model
+	}
+	if model == "" || req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	_ = req.Body.Close()
+	setRequestBody(req, body)
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return nil
+	}
+	if model, ok := fields["model"]; ok && string(model) != "null" && string(model) != `""` {
+		return nil
+	}
+
+	encoded, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+	fields["model"] = encoded
+
+	body, err = json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	setRequestBody(req, body)
+	return nil
+}
+
+// requestedModel returns the model the client asked for: the JSON body's
+// model field, or else the X-Model header. It is empty if neither is set.
+func requestedModel(req *http.Request) (string, error) {
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return "", err
+		}
+		_ = req.Body.Close()
+		setRequestBody(req, body)
+
+		var fields struct {
+			Model string `json:"model"`
+		}
+		if json.Unmarshal(body, &fields) == nil && fields.Model != "" {
+			return fields.Model, nil
+		}
+	}
+	return req.Header.Get("X-Model"), nil
+}
+
+// modelAllowed reports whether the backend serves the requested model.
+// Backends without AllowedModels serve any model, as do all backends when the
+// client doesn't name one.
+func modelAllowed(backend *gatewayv1alpha1.InferenceBackend, model string) bool {
+	if model == "" || len(backend.Spec.AllowedModels) == 0 {
+		return true
+	}
+	return slices.Contains(backend.Spec.AllowedModels, model)
+}
+
+// setRequestBody replaces the request body and its length
+func setRequestBody(req *http.Request, body []byte) {
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+}
+
 // responseRecorder wraps http.ResponseWriter to capture the status code
+// and the time the first body byte was written
 type responseRecorder struct {
 	http.ResponseWriter
 	statusCode int
 	written    bool
+	firstByte  time.Time
 }
 
 func (r *responseRecorder) WriteHeader(code int) {
@@ -479,5 +1296,14 @@ func (r *responseRecorder) Write(b []byte) (int, error) {
 	if !r.written {
 		r.written = true
 	}
+	if r.firstByte.IsZero() && len(b) > 0 {
+		r.firstByte = time.Now()
+	}
 	return r.ResponseWriter.Write(b)
 }
+
+// Unwrap returns the underlying ResponseWriter so that flushes issued by the
+// reverse proxy reach the client for streaming responses
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -17,9 +17,19 @@ limitations under the License.
 package proxy
 
 import (
+	"context"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -131,6 +141,299 @@ func TestBackendHandler_buildTargetURL_External(t *testing.T) {
 	}
 }
 
+func TestBackendHandler_buildTargetURL_ExternalProviderBaseURL(t *testing.T) {
+	store := cache.NewStore()
+	log := zap.New()
+	handler := NewBackendHandler(store, nil, log, nil, nil, nil)
+	handler.SetProviderDefaults(map[string]ProviderDefaults{
+		"openai": {BaseURL: "https://openai.internal.example.com/v1"},
+	})
+
+	backend := &gatewayv1alpha1.InferenceBackend{
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type: gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{
+				Provider: "openai",
+			},
+		},
+	}
+
+	url, err := handler.buildTargetURL(backend)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if url.String() != "https://openai.internal.example.com/v1" {
+		t.Errorf("expected 'https://openai.internal.example.com/v1', got '%s'", url.String())
+	}
+
+	// An explicit backend URL takes precedence over the provider base URL
+	backend.Spec.External.URL = "https://api.openai.com/v1"
+	url, err = handler.buildTargetURL(backend)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if url.String() != "https://api.openai.com/v1" {
+		t.Errorf("expected 'https://api.openai.com/v1', got '%s'", url.String())
+	}
+
+	// Providers without defaults still require a URL
+	backend.Spec.External.URL = ""
+	backend.Spec.External.Provider = "cohere"
+	if _, err := handler.buildTargetURL(backend); err == nil {
+		t.Error("expected error for provider without base URL")
+	}
+}
+
+func TestAttemptTimeout(t *testing.T) {
+	routeWithTimeout := &gatewayv1alpha1.InferenceRoute{
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Fallback: &gatewayv1alpha1.FallbackChain{TimeoutSeconds: 5},
+		},
+	}
+	route := &gatewayv1alpha1.InferenceRoute{}
+
+	tests := []struct {
+		name     string
+		route    *gatewayv1alpha1.InferenceRoute
+		defaults ProviderDefaults
+		expected time.Duration
+	}{
+		{"default", route, ProviderDefaults{}, defaultAttemptTimeout},
+		{"provider timeout", route, ProviderDefaults{Timeout: 60 * time.Second}, 60 * time.Second},
+		{"route overrides provider", routeWithTimeout, ProviderDefaults{Timeout: 60 * time.Second}, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := attemptTimeout(tt.route, tt.defaults); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWithBreakerFallback(t *testing.T) {
+	rule := &gatewayv1alpha1.RouteRule{BreakerFallback: &gatewayv1alpha1.BackendRef{Name: "standby"}}
+
+	tests := []struct {
+		name  string
+		chain []string
+		rule  *gatewayv1alpha1.RouteRule
+		want  []string
+	}{
+		{name: "no rule", chain: []string{"primary", "fallback"}, want: []string{"primary", "fallback"}},
+		{name: "no breaker fallback", chain: []string{"primary"}, rule: &gatewayv1alpha1.RouteRule{}, want: []string{"primary"}},
+		{name: "inserted after primary", chain: []string{"primary", "fallback"}, rule: rule, want: []string{"primary", "standby", "fallback"}},
+		{name: "moved ahead of chain", chain: []string{"primary", "fallback", "standby"}, rule: rule, want: []string{"primary", "standby", "fallback"}},
+		{name: "primary is the breaker fallback", chain: []string{"standby", "fallback"}, rule: rule, want: []string{"standby", "fallback"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := withBreakerFallback(tt.chain, tt.rule)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected chain %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBackendHandler_ExecuteWithFallback_BreakerFallback(t *testing.T) {
+	store := cache.NewStore()
+	for _, name := range []string{"primary", "fallback", "standby"} {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer upstream.Close()
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, newExternalTestBackend(name, upstream.URL))
+	}
+
+	config := DefaultCircuitBreakerConfig()
+	config.FailureThreshold = 1
+	cb := NewCircuitBreakerManager(config, zap.New())
+	handler := NewBackendHandler(store, nil, zap.New(), nil, nil, nil)
+	handler.SetCircuitBreaker(cb)
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Fallback: &gatewayv1alpha1.FallbackChain{Backends: []string{"fallback"}},
+		},
+	}
+	rule := &gatewayv1alpha1.RouteRule{
+		Backends:        []gatewayv1alpha1.BackendRef{{Name: "primary"}},
+		BreakerFallback: &gatewayv1alpha1.BackendRef{Name: "standby"},
+	}
+	execute := func(rule *gatewayv1alpha1.RouteRule) string {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		rec := httptest.NewRecorder()
+		handler.ExecuteWithFallback(context.Background(), rec, req, route, rule, gatewayv1alpha1.BackendRef{Name: "primary"})
+		return rec.Header().Get("X-Served-By")
+	}
+
+	// A closed circuit leaves the primary in use
+	if got := execute(rule); got != "primary" {
+		t.Fatalf("expected the primary to serve while its circuit is closed, got '%s'", got)
+	}
+
+	// An open circuit sends traffic to the breaker fallback, not the chain
+	cb.RecordFailure("primary")
+	if got := execute(rule); got != "standby" {
+		t.Errorf("expected the breaker fallback to serve while the primary's circuit is open, got '%s'", got)
+	}
+
+	// Without a breaker fallback the route's chain is used as before
+	if got := execute(nil); got != "fallback" {
+		t.Errorf("expected the route's fallback without a breaker fallback, got '%s'", got)
+	}
+}
+
+func TestBackendHandler_ExecuteWithFallback_ProviderRetries(t *testing.T) {
+	var attempts atomic.Int32
+	var body string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			// Drop the first connection without a response
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				_ = conn.Close()
+			}
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	store := cache.NewStore()
+	log := zap.New()
+	handler := NewBackendHandler(store, nil, log, nil, nil, nil)
+	handler.SetProviderDefaults(map[string]ProviderDefaults{
+		"openai": {BaseURL: upstream.URL, MaxRetries: 1},
+	})
+
+	backend := &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "openai",
+			Namespace: "default",
+		},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type: gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{
+				Provider: "openai",
+			},
+		},
+		Status: gatewayv1alpha1.InferenceBackendStatus{
+			Health: "Healthy",
+		},
+	}
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "openai"}, backend)
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-route",
+			Namespace: "default",
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"gpt-4"}`))
+	rec := httptest.NewRecorder()
+
+	handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "openai"})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("expected 2 attempts, got %d", got)
+	}
+	if body != `{"model":"gpt-4"}` {
+		t.Errorf("expected request body to be replayed on retry, got '%s'", body)
+	}
+}
+
+// dnsFailingTransport fails the first lookups with a DNS error, then passes
+// requests through
+type dnsFailingTransport struct {
+	failures atomic.Int32
+	attempts atomic.Int32
+}
+
+func (t *dnsFailingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.attempts.Add(1) <= t.failures.Load() {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{
+			Err:        "no such host",
+			Name:       req.URL.Hostname(),
+			IsNotFound: true,
+		}}
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestBackendHandler_ExecuteWithFallback_RetriesDNSFailure(t *testing.T) {
+	var body string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	store := cache.NewStore()
+	handler := NewBackendHandler(store, nil, zap.New(), nil, nil, nil)
+	transport := &dnsFailingTransport{}
+	transport.failures.Store(1)
+	handler.transport = transport
+
+	// No provider retries are configured, but DNS failures are still retried
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "pending"}, newExternalTestBackend("pending", upstream.URL))
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-route", Namespace: "default"},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"gpt-4"}`))
+	rec := httptest.NewRecorder()
+	handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "pending"})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 once the hostname resolved, got %d", rec.Code)
+	}
+	if got := transport.attempts.Load(); got != 2 {
+		t.Errorf("expected 2 attempts, got %d", got)
+	}
+	if body != `{"model":"gpt-4"}` {
+		t.Errorf("expected request body to be replayed on retry, got '%s'", body)
+	}
+}
+
+func TestBackendHandler_ExecuteWithFallback_DNSRetriesBounded(t *testing.T) {
+	store := cache.NewStore()
+	handler := NewBackendHandler(store, nil, zap.New(), nil, nil, nil)
+	transport := &dnsFailingTransport{}
+	transport.failures.Store(100)
+	handler.transport = transport
+
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "missing"}, newExternalTestBackend("missing", "http://missing.default.svc"))
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-route", Namespace: "default"},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{}`))
+	rec := httptest.NewRecorder()
+	handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "missing"})
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 once retries ran out, got %d", rec.Code)
+	}
+	if got := transport.attempts.Load(); got != maxDNSRetries+1 {
+		t.Errorf("expected %d attempts, got %d", maxDNSRetries+1, got)
+	}
+}
+
 func TestBackendHandler_buildTargetURL_Kubernetes(t *testing.T) {
 	store := cache.NewStore()
 	log := zap.New()
@@ -381,7 +684,7 @@ func TestBackendHandler_ExecuteWithFallback_BackendNotFound(t *testing.T) {
 		headers: make(http.Header),
 	}
 
-	handler.ExecuteWithFallback(nil, mockWriter, nil, route, primary)
+	handler.ExecuteWithFallback(nil, mockWriter, nil, route, nil, primary)
 
 	// Should return 503 when all backends fail
 	if mockWriter.statusCode != 503 {
@@ -389,6 +692,194 @@ func TestBackendHandler_ExecuteWithFallback_BackendNotFound(t *testing.T) {
 	}
 }
 
+func TestBackendHandler_ExecuteWithFallback_MaxAttempts(t *testing.T) {
+	store := cache.NewStore()
+	var mu sync.Mutex
+	tried := make(map[string]bool)
+	names := []string{"primary", "fallback-1", "fallback-2", "fallback-3"}
+	for _, name := range names {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			tried[name] = true
+			mu.Unlock()
+			// Drop the connection so the backend counts as unreachable
+			panic(http.ErrAbortHandler)
+		}))
+		defer upstream.Close()
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, newExternalTestBackend(name, upstream.URL))
+	}
+	handler := NewBackendHandler(store, nil, zap.New(), nil, nil, nil)
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Fallback: &gatewayv1alpha1.FallbackChain{
+				Backends:    names[1:],
+				MaxAttempts: 2,
+			},
+		},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "primary"})
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", rec.Code)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(tried) != 2 || !tried["primary"] || !tried["fallback-1"] {
+		t.Errorf("expected only the primary and first fallback to be tried, got %v", tried)
+	}
+}
+
+// newStatusFallbackHandler creates a handler whose backends answer with the
+// given statuses, recording the body each backend was called with
+func newStatusFallbackHandler(t *testing.T, statuses map[string]int) (*BackendHandler, *sync.Map) {
+	t.Helper()
+	store := cache.NewStore()
+	var called sync.Map
+	for name, status := range statuses {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			called.Store(name, string(body))
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(name))
+		}))
+		t.Cleanup(upstream.Close)
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, newExternalTestBackend(name, upstream.URL))
+	}
+	return NewBackendHandler(store, nil, zap.New(), nil, nil, nil), &called
+}
+
+func TestBackendHandler_ExecuteWithFallback_OnStatusCodes(t *testing.T) {
+	handler, called := newStatusFallbackHandler(t, map[string]int{
+		"no-model":  http.StatusNotFound,
+		"has-model": http.StatusOK,
+	})
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Fallback: &gatewayv1alpha1.FallbackChain{
+				Backends:      []string{"has-model"},
+				OnStatusCodes: []int32{http.StatusNotFound},
+			},
+		},
+	}
+	execute := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		rec := httptest.NewRecorder()
+		handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "no-model"})
+		return rec
+	}
+
+	rec := execute()
+	if rec.Code != http.StatusOK || rec.Body.String() != "has-model" {
+		t.Errorf("expected the 404 to fall back to has-model, got %d %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("X-Served-By"); got != "has-model" {
+		t.Errorf("expected has-model to serve the request, got '%s'", got)
+	}
+
+	// Without the status configured, the 404 is returned to the client
+	route.Spec.Fallback.OnStatusCodes = nil
+	called.Delete("has-model")
+	rec = execute()
+	if rec.Code != http.StatusNotFound || rec.Body.String() != "no-model" {
+		t.Errorf("expected the 404 to be returned, got %d %q", rec.Code, rec.Body.String())
+	}
+	if _, ok := called.Load("has-model"); ok {
+		t.Error("expected no fallback for an unconfigured 4xx status")
+	}
+}
+
+func TestBackendHandler_ExecuteWithFallback_OnStatusCodesReplaysBody(t *testing.T) {
+	handler, called := newStatusFallbackHandler(t, map[string]int{
+		"no-model":  http.StatusNotFound,
+		"has-model": http.StatusOK,
+	})
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Fallback: &gatewayv1alpha1.FallbackChain{
+				Backends:      []string{"has-model"},
+				OnStatusCodes: []int32{http.StatusNotFound},
+			},
+		},
+	}
+	body := `{"messages":[{"role":"user","content":"hi"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "no-model"})
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "has-model" {
+		t.Fatalf("expected the 404 to fall back to has-model, got %d %q", rec.Code, rec.Body.String())
+	}
+	for _, name := range []string{"no-model", "has-model"} {
+		if got, _ := called.Load(name); got != body {
+			t.Errorf("expected %s to receive the request body, got %q", name, got)
+		}
+	}
+}
+
+func TestBackendHandler_ExecuteWithFallback_OnStatusCodesExhausted(t *testing.T) {
+	handler, _ := newStatusFallbackHandler(t, map[string]int{
+		"first":  http.StatusNotFound,
+		"second": http.StatusBadRequest,
+	})
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Fallback: &gatewayv1alpha1.FallbackChain{
+				Backends:      []string{"second", "missing"},
+				OnStatusCodes: []int32{http.StatusNotFound, http.StatusBadRequest},
+			},
+		},
+	}
+
+	// The last backend tried answers 400, and the backend after it isn't
+	// available, so the held back 400 is returned
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "first"})
+
+	if rec.Code != http.StatusBadRequest || rec.Body.String() != "second" {
+		t.Errorf("expected the last fallback status response, got %d %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("X-Served-By"); got != "second" {
+		t.Errorf("expected the response to name the backend that sent it, got '%s'", got)
+	}
+}
+
+func TestBackendHandler_ExecuteWithFallback_OnStatusCodesKeptThroughUnreachable(t *testing.T) {
+	handler, _ := newStatusFallbackHandler(t, map[string]int{"first": http.StatusNotFound})
+
+	// The next backend in the chain refuses connections
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	handler.cache.SetBackend(types.NamespacedName{Namespace: "default", Name: "down"}, newExternalTestBackend("down", down.URL))
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Fallback: &gatewayv1alpha1.FallbackChain{
+				Backends:      []string{"down"},
+				OnStatusCodes: []int32{http.StatusNotFound},
+			},
+		},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "first"})
+
+	if rec.Code != http.StatusNotFound || rec.Body.String() != "first" {
+		t.Errorf("expected the held 404 after the unreachable backend, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
 func TestBackendHandler_ExecuteWithFallback_SkipsUnhealthyBackends(t *testing.T) {
 	store := cache.NewStore()
 	log := zap.New()
@@ -423,7 +914,7 @@ func TestBackendHandler_ExecuteWithFallback_SkipsUnhealthyBackends(t *testing.T)
 		headers: make(http.Header),
 	}
 
-	handler.ExecuteWithFallback(nil, mockWriter, nil, route, primary)
+	handler.ExecuteWithFallback(nil, mockWriter, nil, route, nil, primary)
 
 	// Should try to use the fallback (which doesn't exist either)
 	// The point is that it skipped the unhealthy backend
@@ -432,6 +923,372 @@ func TestBackendHandler_ExecuteWithFallback_SkipsUnhealthyBackends(t *testing.T)
 	}
 }
 
+func TestBackendHandler_ExecuteWithFallback_SkipsMaintenanceAsLastResort(t *testing.T) {
+	store := cache.NewStore()
+	log := zap.New()
+	handler := NewBackendHandler(store, nil, log, nil, nil, nil)
+
+	called := false
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "maintained"}, &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "maintained", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type:     gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{URL: upstream.URL, Provider: "custom"},
+		},
+		Status: gatewayv1alpha1.InferenceBackendStatus{Health: cache.HealthStatusMaintenance},
+	})
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-route", Namespace: "default"},
+	}
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ExecuteWithFallback(req.Context(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "maintained"})
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", rec.Code)
+	}
+	if called {
+		t.Error("expected backend in maintenance not to receive the request")
+	}
+}
+
+func TestBackendHandler_ExecuteWithFallback_InjectsDefaultHeaders(t *testing.T) {
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	store := cache.NewStore()
+	log := zap.New()
+	handler := NewBackendHandler(store, nil, log, nil, nil, nil)
+
+	backend := &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "external",
+			Namespace: "default",
+		},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type: gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{
+				URL:      upstream.URL,
+				Provider: "custom",
+				DefaultHeaders: map[string]string{
+					"X-Api-Version":   "2024-10-01",
+					"X-Deployment-Id": "prod-east",
+				},
+			},
+		},
+		Status: gatewayv1alpha1.InferenceBackendStatus{
+			Health: "Healthy",
+		},
+	}
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "external"}, backend)
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-route",
+			Namespace: "default",
+		},
+	}
+	primary := gatewayv1alpha1.BackendRef{Name: "external"}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("X-Api-Version", "client-supplied")
+	rec := httptest.NewRecorder()
+
+	handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, primary)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if got := received.Get("X-Api-Version"); got != "2024-10-01" {
+		t.Errorf("expected X-Api-Version '2024-10-01', got '%s'", got)
+	}
+	if got := received.Get("X-Deployment-Id"); got != "prod-east" {
+		t.Errorf("expected X-Deployment-Id 'prod-east', got '%s'", got)
+	}
+}
+
+func TestBackendHandler_ExecuteWithFallback_InjectsDefaultModel(t *testing.T) {
+	var received string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	store := cache.NewStore()
+	log := zap.New()
+	handler := NewBackendHandler(store, nil, log, nil, nil, nil)
+
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "external"}, &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type: gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{
+				URL:      upstream.URL,
+				Provider: "custom",
+				Model:    "gpt-4o-mini",
+			},
+		},
+		Status: gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+	})
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-route", Namespace: "default"},
+	}
+	primary := gatewayv1alpha1.BackendRef{Name: "external"}
+
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"model absent", `{"messages":[]}`, `{"messages":[],"model":"gpt-4o-mini"}`},
+		{"model empty", `{"model":"","messages":[]}`, `{"messages":[],"model":"gpt-4o-mini"}`},
+		{"model present", `{"model": "gpt-4o", "messages": []}`, `{"model": "gpt-4o", "messages": []}`},
+		{"not json", `prompt=hello`, `prompt=hello`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = ""
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, primary)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", rec.Code)
+			}
+			if received != tt.expected {
+				t.Errorf("expected body %s, got %s", tt.expected, received)
+			}
+		})
+	}
+}
+
+func TestBackendHandler_ExecuteWithFallback_ClientModel(t *testing.T) {
+	received := make(map[string]string)
+	store := cache.NewStore()
+	for _, name := range []string{"vllm", "general"} {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			received[name] = string(body)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer upstream.Close()
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, newExternalTestBackend(name, upstream.URL))
+	}
+	// The vLLM backend has several models loaded
+	vllm, _ := store.GetBackendByName("default", "vllm")
+	vllm.Spec.External.Model = "llama-3-8b"
+	vllm.Spec.AllowedModels = []string{"llama-3-8b", "mistral-7b"}
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "vllm"}, vllm)
+
+	handler := NewBackendHandler(store, nil, zap.New(), nil, nil, nil)
+	direct := &gatewayv1alpha1.InferenceRoute{ObjectMeta: metav1.ObjectMeta{Name: "direct", Namespace: "default"}}
+	withFallback := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "fallback", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Fallback: &gatewayv1alpha1.FallbackChain{Backends: []string{"general"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		route    *gatewayv1alpha1.InferenceRoute
+		header   string
+		body     string
+		status   int
+		servedBy string
+		expected string
+	}{
+		{
+			name:     "model from header",
+			route:    direct,
+			header:   "mistral-7b",
+			body:     `{"messages":[]}`,
+			status:   http.StatusOK,
+			servedBy: "vllm",
+			expected: `{"messages":[],"model":"mistral-7b"}`,
+		},
+		{
+			name:     "model from body",
+			route:    direct,
+			header:   "llama-3-8b",
+			body:     `{"model":"mistral-7b","messages":[]}`,
+			status:   http.StatusOK,
+			servedBy: "vllm",
+			expected: `{"model":"mistral-7b","messages":[]}`,
+		},
+		{
+			name:     "backend default",
+			route:    direct,
+			body:     `{"messages":[]}`,
+			status:   http.StatusOK,
+			servedBy: "vllm",
+			expected: `{"messages":[],"model":"llama-3-8b"}`,
+		},
+		{
+			name:   "model not allowed",
+			route:  direct,
+			body:   `{"model":"gpt-4o","messages":[]}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:     "model served by fallback",
+			route:    withFallback,
+			header:   "gpt-4o",
+			body:     `{"messages":[]}`,
+			status:   http.StatusOK,
+			servedBy: "general",
+			expected: `{"messages":[],"model":"gpt-4o"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clear(received)
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Set("X-Model", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ExecuteWithFallback(context.Background(), rec, req, tt.route, nil, gatewayv1alpha1.BackendRef{Name: "vllm"})
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			if tt.servedBy == "" {
+				if len(received) != 0 {
+					t.Errorf("expected no backend to be called, got %v", received)
+				}
+				return
+			}
+			if got := received[tt.servedBy]; got != tt.expected {
+				t.Errorf("expected %s to receive %s, got %q", tt.servedBy, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTranslatePathVersion(t *testing.T) {
+	mapping := map[string]string{
+		"/v1":                  "/openai/v1",
+		"/v1/chat/completions": "/v2/chat",
+		"/legacy/":             "/v0/",
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/v1/chat/completions", "/v2/chat"},
+		{"/v1/embeddings", "/openai/v1/embeddings"},
+		{"/v1", "/openai/v1"},
+		{"/v1beta/models", "/v1beta/models"},
+		{"/legacy/complete", "/v0/complete"},
+		{"/health", "/health"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := translatePathVersion(tt.path, mapping); got != tt.expected {
+				t.Errorf("expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBackendHandler_ExecuteWithFallback_TranslatesPathVersion(t *testing.T) {
+	var receivedPath string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedPath = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	store := cache.NewStore()
+	log := zap.New()
+	handler := NewBackendHandler(store, nil, log, nil, nil, nil)
+
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "azure"}, &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "azure", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type: gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{
+				URL:      upstream.URL + "/api",
+				Provider: "custom",
+				PathVersionMap: map[string]string{
+					"/v1/chat/completions": "/openai/deployments/gpt-4o/chat/completions",
+				},
+			},
+		},
+		Status: gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+	})
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-route", Namespace: "default"},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "azure"})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if expected := "/api/openai/deployments/gpt-4o/chat/completions"; receivedPath != expected {
+		t.Errorf("expected path '%s', got '%s'", expected, receivedPath)
+	}
+}
+
+func TestBackendHandler_HostOverride(t *testing.T) {
+	var receivedHost string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHost = r.Host
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	store := cache.NewStore()
+	handler := NewBackendHandler(store, nil, zap.New(), nil, nil, nil)
+
+	backend := newExternalTestBackend("shared", upstream.URL)
+	backend.Spec.External.HostOverride = "llm.internal.example.com"
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "shared"}, backend)
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-route", Namespace: "default"},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "shared"})
+
+	// Reaching the test server at all means the connection went to the URL host
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the request to reach the URL host, got status %d", rec.Code)
+	}
+	if receivedHost != "llm.internal.example.com" {
+		t.Errorf("expected the overridden Host header, got %q", receivedHost)
+	}
+}
+
 // mockResponseWriter implements http.ResponseWriter for testing
 type mockResponseWriter struct {
 	headers    http.Header
@@ -451,3 +1308,70 @@ func (m *mockResponseWriter) Write(data []byte) (int, error) {
 func (m *mockResponseWriter) WriteHeader(statusCode int) {
 	m.statusCode = statusCode
 }
+
+func TestBackendHandler_ExecuteWithFallback_RecordsTTFB(t *testing.T) {
+	const firstByteDelay = 50 * time.Millisecond
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(firstByteDelay)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("data: {}\n\n"))
+	}))
+	defer upstream.Close()
+
+	store := cache.NewStore()
+	log := zap.New()
+	handler := NewBackendHandler(store, nil, log, NewMetricsRecorder(), nil, nil)
+
+	backend := &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ttfb-backend",
+			Namespace: "default",
+		},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type: gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{
+				URL:      upstream.URL,
+				Provider: "custom",
+			},
+		},
+		Status: gatewayv1alpha1.InferenceBackendStatus{
+			Health: "Healthy",
+		},
+	}
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "ttfb-backend"}, backend)
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ttfb-route",
+			Namespace: "default",
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "ttfb-backend"})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	metric := &dto.Metric{}
+	if err := BackendTTFB.WithLabelValues("ttfb-route", "ttfb-backend").(prometheus.Metric).Write(metric); err != nil {
+		t.Fatalf("failed to read TTFB histogram: %v", err)
+	}
+	histogram := metric.GetHistogram()
+	if histogram.GetSampleCount() != 1 {
+		t.Fatalf("expected 1 TTFB observation, got %d", histogram.GetSampleCount())
+	}
+	if histogram.GetSampleSum() < firstByteDelay.Seconds() {
+		t.Errorf("expected TTFB >= %v, got %vs", firstByteDelay, histogram.GetSampleSum())
+	}
+	for _, bucket := range histogram.GetBucket() {
+		if bucket.GetUpperBound() < firstByteDelay.Seconds() && bucket.GetCumulativeCount() != 0 {
+			t.Errorf("expected bucket %v to be empty, got %d", bucket.GetUpperBound(), bucket.GetCumulativeCount())
+		}
+	}
+}
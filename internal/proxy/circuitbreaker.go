@@ -120,17 +120,25 @@ type CircuitBreakerConfig struct {
 
 	// MinRequestsForRate is minimum requests before rate-based threshold applies
 	MinRequestsForRate int
+
+	// ConnectionErrorThreshold is the number of consecutive connection errors,
+	// such as refused connections, before opening the circuit. A backend that
+	// can't be reached at all trips sooner than one returning occasional
+	// errors. Connection errors also count toward FailureThreshold. Zero
+	// disables the separate threshold.
+	ConnectionErrorThreshold int
 }
 
 // DefaultCircuitBreakerConfig returns sensible defaults
 func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
 	return CircuitBreakerConfig{
-		FailureThreshold:     5,
-		SuccessThreshold:     3,
-		Timeout:              30 * time.Second,
-		HalfOpenMaxRequests:  3,
-		FailureRateThreshold: 0.5, // 50% failure rate
-		MinRequestsForRate:   10,
+		FailureThreshold:         5,
+		SuccessThreshold:         3,
+		Timeout:                  30 * time.Second,
+		HalfOpenMaxRequests:      3,
+		FailureRateThreshold:     0.5, // 50% failure rate
+		MinRequestsForRate:       10,
+		ConnectionErrorThreshold: 2,
 	}
 }
 
@@ -140,16 +148,17 @@ type CircuitBreaker struct {
 	config CircuitBreakerConfig
 	log    logr.Logger
 
-	mu                  sync.RWMutex
-	state               CircuitState
-	failures            int
-	successes           int
-	consecutiveFailures int
-	consecutiveSuccesses int
-	totalRequests       int
-	lastFailure         time.Time
-	openedAt            time.Time
-	halfOpenRequests    int
+	mu                            sync.RWMutex
+	state                         CircuitState
+	failures                      int
+	successes                     int
+	consecutiveFailures           int
+	consecutiveSuccesses          int
+	consecutiveConnectionFailures int
+	totalRequests                 int
+	lastFailure                   time.Time
+	openedAt                      time.Time
+	halfOpenRequests              int
 }
 
 // NewCircuitBreaker creates a new circuit breaker for a backend
@@ -210,6 +219,7 @@ func (cb *CircuitBreaker) RecordSuccess() {
 	cb.totalRequests++
 	cb.consecutiveSuccesses++
 	cb.consecutiveFailures = 0
+	cb.consecutiveConnectionFailures = 0
 
 	circuitBreakerSuccesses.WithLabelValues(cb.name).Inc()
 
@@ -225,8 +235,19 @@ func (cb *CircuitBreaker) RecordSuccess() {
 	}
 }
 
-// RecordFailure records a failed request
+// RecordFailure records a failed request, such as an error status
 func (cb *CircuitBreaker) RecordFailure() {
+	cb.recordFailure(false)
+}
+
+// RecordConnectionFailure records a request that failed because the backend
+// couldn't be connected to, which counts toward ConnectionErrorThreshold
+func (cb *CircuitBreaker) RecordConnectionFailure() {
+	cb.recordFailure(true)
+}
+
+// recordFailure records a failed request and trips the circuit if needed
+func (cb *CircuitBreaker) recordFailure(connection bool) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
@@ -236,6 +257,13 @@ func (cb *CircuitBreaker) RecordFailure() {
 	cb.consecutiveSuccesses = 0
 	cb.lastFailure = time.Now()
 
+	// Any other failure shows the backend can still be reached
+	if connection {
+		cb.consecutiveConnectionFailures++
+	} else {
+		cb.consecutiveConnectionFailures = 0
+	}
+
 	circuitBreakerFailures.WithLabelValues(cb.name).Inc()
 
 	switch cb.state {
@@ -259,6 +287,11 @@ func (cb *CircuitBreaker) shouldTrip() bool {
 		return true
 	}
 
+	// Backends that can't be connected to trip on a separate, lower threshold
+	if cb.config.ConnectionErrorThreshold > 0 && cb.consecutiveConnectionFailures >= cb.config.ConnectionErrorThreshold {
+		return true
+	}
+
 	// Rate-based threshold (if configured and enough requests)
 	if cb.config.FailureRateThreshold > 0 && cb.totalRequests >= cb.config.MinRequestsForRate {
 		failureRate := float64(cb.failures) / float64(cb.totalRequests)
@@ -285,6 +318,7 @@ func (cb *CircuitBreaker) transitionTo(newState CircuitState) {
 		cb.log.Info("Circuit breaker opened",
 			"previousState", oldState.String(),
 			"consecutiveFailures", cb.consecutiveFailures,
+			"consecutiveConnectionFailures", cb.consecutiveConnectionFailures,
 			"timeout", cb.config.Timeout,
 		)
 
@@ -302,6 +336,7 @@ func (cb *CircuitBreaker) transitionTo(newState CircuitState) {
 		cb.totalRequests = 0
 		cb.consecutiveFailures = 0
 		cb.consecutiveSuccesses = 0
+		cb.consecutiveConnectionFailures = 0
 		cb.log.Info("Circuit breaker closed",
 			"previousState", oldState.String(),
 		)
@@ -315,16 +350,25 @@ func (cb *CircuitBreaker) State() CircuitState {
 	return cb.state
 }
 
+// IsOpen reports whether the circuit is open and still rejecting requests.
+// Unlike Allow, it does not transition the state or count as a request.
+func (cb *CircuitBreaker) IsOpen() bool {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	return cb.state == StateOpen && time.Since(cb.openedAt) < cb.config.Timeout
+}
+
 // Stats returns current circuit breaker statistics
 type CircuitBreakerStats struct {
-	State                CircuitState
-	Failures             int
-	Successes            int
-	ConsecutiveFailures  int
-	ConsecutiveSuccesses int
-	TotalRequests        int
-	LastFailure          time.Time
-	OpenedAt             time.Time
+	State                         CircuitState
+	Failures                      int
+	Successes                     int
+	ConsecutiveFailures           int
+	ConsecutiveSuccesses          int
+	ConsecutiveConnectionFailures int
+	TotalRequests                 int
+	LastFailure                   time.Time
+	OpenedAt                      time.Time
 }
 
 // Stats returns the current statistics
@@ -333,14 +377,15 @@ func (cb *CircuitBreaker) Stats() CircuitBreakerStats {
 	defer cb.mu.RUnlock()
 
 	return CircuitBreakerStats{
-		State:                cb.state,
-		Failures:             cb.failures,
-		Successes:            cb.successes,
-		ConsecutiveFailures:  cb.consecutiveFailures,
-		ConsecutiveSuccesses: cb.consecutiveSuccesses,
-		TotalRequests:        cb.totalRequests,
-		LastFailure:          cb.lastFailure,
-		OpenedAt:             cb.openedAt,
+		State:                         cb.state,
+		Failures:                      cb.failures,
+		Successes:                     cb.successes,
+		ConsecutiveFailures:           cb.consecutiveFailures,
+		ConsecutiveSuccesses:          cb.consecutiveSuccesses,
+		ConsecutiveConnectionFailures: cb.consecutiveConnectionFailures,
+		TotalRequests:                 cb.totalRequests,
+		LastFailure:                   cb.lastFailure,
+		OpenedAt:                      cb.openedAt,
 	}
 }
 
@@ -400,6 +445,16 @@ func (m *CircuitBreakerManager) Allow(backendName string) error {
 	return m.GetBreaker(backendName).Allow()
 }
 
+// IsOpen reports whether the backend's circuit is open. Backends without a
+// circuit breaker are reported as closed.
+func (m *CircuitBreakerManager) IsOpen(backendName string) bool {
+	m.mu.RLock()
+	cb, exists := m.breakers[backendName]
+	m.mu.RUnlock()
+
+	return exists && cb.IsOpen()
+}
+
 // RecordSuccess records a successful request to a backend
 func (m *CircuitBreakerManager) RecordSuccess(backendName string) {
 	m.GetBreaker(backendName).RecordSuccess()
@@ -410,6 +465,11 @@ func (m *CircuitBreakerManager) RecordFailure(backendName string) {
 	m.GetBreaker(backendName).RecordFailure()
 }
 
+// RecordConnectionFailure records a request that couldn't connect to a backend
+func (m *CircuitBreakerManager) RecordConnectionFailure(backendName string) {
+	m.GetBreaker(backendName).RecordConnectionFailure()
+}
+
 // AllStats returns stats for all circuit breakers
 func (m *CircuitBreakerManager) AllStats() map[string]CircuitBreakerStats {
 	m.mu.RLock()
@@ -17,6 +17,13 @@ limitations under the License.
 package proxy
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
 	"testing"
 	"time"
 
@@ -333,4 +340,120 @@ func TestCircuitState_String(t *testing.T) {
 			t.Errorf("CircuitState(%d).String() = %q, want %q", tt.state, got, tt.expected)
 		}
 	}
-}
\ No newline at end of file
+}
+func TestCircuitBreakerManager_IsOpen(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	config := DefaultCircuitBreakerConfig()
+	config.FailureThreshold = 2
+	config.Timeout = 50 * time.Millisecond
+	manager := NewCircuitBreakerManager(config, log)
+
+	// Unknown backends are closed and no breaker is created
+	if manager.IsOpen("unknown") {
+		t.Error("expected unknown backend to be closed")
+	}
+	if len(manager.AllStats()) != 0 {
+		t.Error("expected IsOpen not to create a circuit breaker")
+	}
+
+	manager.RecordFailure("test-backend")
+	manager.RecordFailure("test-backend")
+
+	if !manager.IsOpen("test-backend") {
+		t.Error("expected circuit to be open")
+	}
+
+	// Once the timeout elapses the backend may be probed again
+	time.Sleep(60 * time.Millisecond)
+	if manager.IsOpen("test-backend") {
+		t.Error("expected circuit not to be reported open after timeout")
+	}
+	if manager.GetBreaker("test-backend").State() != StateOpen {
+		t.Error("expected IsOpen not to transition the circuit state")
+	}
+}
+
+func TestCircuitBreaker_ConnectionErrorsTripSooner(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	config := CircuitBreakerConfig{
+		FailureThreshold:         5,
+		SuccessThreshold:         1,
+		Timeout:                  time.Minute,
+		HalfOpenMaxRequests:      1,
+		ConnectionErrorThreshold: 2,
+	}
+
+	statusErrors := NewCircuitBreaker("status-errors", config, log)
+	statusErrors.RecordFailure()
+	statusErrors.RecordFailure()
+	if statusErrors.State() != StateClosed {
+		t.Errorf("expected 2 status failures to keep the circuit closed, got %v", statusErrors.State())
+	}
+
+	connectionErrors := NewCircuitBreaker("connection-errors", config, log)
+	connectionErrors.RecordConnectionFailure()
+	connectionErrors.RecordConnectionFailure()
+	if connectionErrors.State() != StateOpen {
+		t.Errorf("expected 2 connection failures to open the circuit, got %v", connectionErrors.State())
+	}
+}
+
+func TestCircuitBreaker_ConnectionErrorsMustBeConsecutive(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	config := CircuitBreakerConfig{
+		FailureThreshold:         5,
+		SuccessThreshold:         1,
+		Timeout:                  time.Minute,
+		HalfOpenMaxRequests:      1,
+		ConnectionErrorThreshold: 2,
+	}
+	cb := NewCircuitBreaker("mixed-errors", config, log)
+
+	// A status failure shows the backend is reachable again
+	cb.RecordConnectionFailure()
+	cb.RecordFailure()
+	cb.RecordConnectionFailure()
+	if cb.State() != StateClosed {
+		t.Fatalf("expected interleaved failures to keep the circuit closed, got %v", cb.State())
+	}
+	if got := cb.Stats().ConsecutiveConnectionFailures; got != 1 {
+		t.Errorf("expected 1 consecutive connection failure, got %d", got)
+	}
+
+	// Connection failures still count toward the overall threshold
+	cb.RecordFailure()
+	cb.RecordFailure()
+	if cb.State() != StateOpen {
+		t.Errorf("expected 5 failures of either kind to open the circuit, got %v", cb.State())
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	_, refused := http.Get(server.URL)
+	if refused == nil {
+		t.Fatal("expected a request to a closed server to fail")
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"connection refused", refused, true},
+		{"dns failure", &net.DNSError{Err: "no such host", Name: "missing.invalid"}, true},
+		{"connection reset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"timeout", context.DeadlineExceeded, false},
+		{"other", errors.New("bad response"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isConnectionError(tt.err); got != tt.want {
+				t.Errorf("isConnectionError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
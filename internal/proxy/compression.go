@@ -0,0 +1,169 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CompressionConfig configures gzip compression of responses sent to clients
+type CompressionConfig struct {
+	// Level is the gzip compression level. Zero or an invalid level uses
+	// gzip.DefaultCompression.
+	Level int
+
+	// MinSize is the smallest response, by Content-Length, that is compressed.
+	// Responses without a Content-Length are always compressed.
+	MinSize int64
+}
+
+// DefaultCompressionConfig returns sensible defaults for response compression
+func DefaultCompressionConfig() CompressionConfig {
+	return CompressionConfig{
+		Level:   gzip.DefaultCompression,
+		MinSize: 1024,
+	}
+}
+
+// compressionHandler applies a CompressionConfig to responses
+type compressionHandler struct {
+	config CompressionConfig
+}
+
+// newCompressionHandler creates a handler for the given configuration
+func newCompressionHandler(cfg CompressionConfig) *compressionHandler {
+	if cfg.Level == 0 || cfg.Level < gzip.HuffmanOnly || cfg.Level > gzip.BestCompression {
+		cfg.Level = gzip.DefaultCompression
+	}
+	return &compressionHandler{config: cfg}
+}
+
+// acceptsGzip reports whether the client accepts gzip-encoded responses
+func acceptsGzip(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept-Encoding") {
+		for _, entry := range strings.Split(value, ",") {
+			coding, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+			if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+				continue
+			}
+			// "gzip;q=0" explicitly refuses gzip
+			if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// wrap returns a writer that gzips the response if the client accepts it.
+// The caller must call the returned close function once the response is complete.
+func (c *compressionHandler) wrap(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, func()) {
+	if r.Method == http.MethodHead || !acceptsGzip(r) {
+		return w, func() {}
+	}
+
+	cw := &compressResponseWriter{ResponseWriter: w, config: c.config}
+	return cw, cw.close
+}
+
+// compressResponseWriter decides whether to compress when the response headers
+// are written, so that it can see what the backend returned
+type compressResponseWriter struct {
+	http.ResponseWriter
+	config      CompressionConfig
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+// shouldCompress reports whether the response described by the headers
+// benefits from compression
+func (w *compressResponseWriter) shouldCompress(code int) bool {
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		return false
+	}
+
+	header := w.Header()
+
+	// The backend already compressed the response
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	// Streaming responses must reach the client as each event is produced
+	if mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil && mediaType == "text/event-stream" {
+		return false
+	}
+
+	if length := header.Get("Content-Length"); length != "" {
+		if size, err := strconv.ParseInt(length, 10, 64); err == nil && size < w.config.MinSize {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *compressResponseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.Header().Add("Vary", "Accept-Encoding")
+		if w.shouldCompress(code) {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Header().Del("Content-Length")
+			// Level was validated by newCompressionHandler
+			w.gz, _ = gzip.NewWriterLevel(w.ResponseWriter, w.config.Level)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *compressResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz != nil {
+		return w.gz.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends buffered compressed data to the client
+func (w *compressResponseWriter) Flush() {
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying writer so http.ResponseController can reach
+// deadline support
+func (w *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close writes the gzip footer if the response was compressed
+func (w *compressResponseWriter) close() {
+	if w.gz != nil {
+		_ = w.gz.Close()
+	}
+}
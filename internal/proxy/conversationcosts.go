@@ -0,0 +1,169 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"container/list"
+	"net/http"
+	"time"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+)
+
+// DefaultConversationIDHeader carries the ID that groups the requests of a
+// multi-turn conversation, such as a tool-call flow
+const DefaultConversationIDHeader = "X-Conversation-ID"
+
+// ConversationCostConfig configures rolling up the cost of requests that
+// share a conversation ID into a single entry
+type ConversationCostConfig struct {
+	// Header carries the conversation ID
+	Header string
+
+	// TTL is how long a conversation is kept after its last request
+	TTL time.Duration
+
+	// MaxConversations bounds the number of conversations tracked. The least
+	// recently updated conversation is dropped when the limit is reached.
+	MaxConversations int
+}
+
+// DefaultConversationCostConfig returns sensible defaults for conversation costs
+func DefaultConversationCostConfig() ConversationCostConfig {
+	return ConversationCostConfig{
+		Header:           DefaultConversationIDHeader,
+		TTL:              time.Hour,
+		MaxConversations: 10000,
+	}
+}
+
+// EnableConversationCosts rolls up the cost of requests sharing a
+// conversation ID, so a multi-request tool-call session has one cost entry
+// alongside the per-route and per-backend totals
+func (c *CostTracker) EnableConversationCosts(cfg ConversationCostConfig) {
+	defaults := DefaultConversationCostConfig()
+	if cfg.Header == "" {
+		cfg.Header = defaults.Header
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaults.TTL
+	}
+	if cfg.MaxConversations <= 0 {
+		cfg.MaxConversations = defaults.MaxConversations
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conversations = &cfg
+	c.conversationCosts = make(map[string]*list.Element)
+	c.conversationOrder = list.New()
+}
+
+// conversationCost is an entry of CostTracker.conversationOrder, which is
+// kept from the least to the most recently updated conversation
+type conversationCost struct {
+	id    string
+	stats CostStats
+}
+
+// ConversationID returns the request's conversation ID, or an empty string
+// if it has none or conversation costs are disabled
+func (c *CostTracker) ConversationID(req *http.Request) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.conversations == nil || req == nil {
+		return ""
+	}
+	return req.Header.Get(c.conversations.Header)
+}
+
+// TrackConversation adds the cost of one request to its conversation. It does
+// nothing without a conversation ID or when conversation costs are disabled.
+func (c *CostTracker) TrackConversation(
+	conversationID string,
+	usage TokenUsage,
+	cost float64,
+	costConfig *gatewayv1alpha1.CostConfig,
+) {
+	if conversationID == "" || costConfig == nil {
+		return
+	}
+	currency := costConfig.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conversations == nil {
+		return
+	}
+
+	now := time.Now()
+	if element, ok := c.conversationCosts[conversationID]; ok &&
+		now.Sub(element.Value.(*conversationCost).stats.LastUpdated) >= c.conversations.TTL {
+		c.removeConversationLocked(element)
+	}
+	element, ok := c.conversationCosts[conversationID]
+	if ok {
+		c.conversationOrder.MoveToBack(element)
+	} else {
+		c.evictConversationsLocked(now)
+		element = c.conversationOrder.PushBack(&conversationCost{
+			id:    conversationID,
+			stats: CostStats{Currency: currency},
+		})
+		c.conversationCosts[conversationID] = element
+	}
+	element.Value.(*conversationCost).stats.add(usage, cost, now)
+}
+
+// GetConversationCosts returns the cost statistics of a conversation
+func (c *CostTracker) GetConversationCosts(conversationID string) *CostStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	element, exists := c.conversationCosts[conversationID]
+	if !exists {
+		return nil
+	}
+	stats := element.Value.(*conversationCost).stats
+	if time.Since(stats.LastUpdated) >= c.conversations.TTL {
+		return nil
+	}
+	return &stats
+}
+
+// evictConversationsLocked makes room for a new conversation by removing
+// expired ones, then the least recently updated. Both are at the front of
+// the order, so eviction doesn't scan every conversation. The caller must
+// hold c.mu.
+func (c *CostTracker) evictConversationsLocked(now time.Time) {
+	for element := c.conversationOrder.Front(); element != nil; element = c.conversationOrder.Front() {
+		expired := now.Sub(element.Value.(*conversationCost).stats.LastUpdated) >= c.conversations.TTL
+		if !expired && len(c.conversationCosts) < c.conversations.MaxConversations {
+			return
+		}
+		c.removeConversationLocked(element)
+	}
+}
+
+// removeConversationLocked forgets a conversation. The caller must hold c.mu.
+func (c *CostTracker) removeConversationLocked(element *list.Element) {
+	c.conversationOrder.Remove(element)
+	delete(c.conversationCosts, element.Value.(*conversationCost).id)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// trackConversationTurn tracks the cost of one response to a request with
+// the conversation ID
+func trackConversationTurn(handler *BackendHandler, backend *gatewayv1alpha1.InferenceBackend, conversationID, body string) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	if conversationID != "" {
+		req.Header.Set(DefaultConversationIDHeader, conversationID)
+	}
+	resp := &http.Response{
+		Header:  http.Header{"Content-Type": []string{"application/json"}},
+		Body:    io.NopCloser(strings.NewReader(body)),
+		Request: req,
+	}
+	handler.trackCosts(resp, "chat", backend, "openai")
+}
+
+func TestCostTracker_ConversationAggregatesToolCallTurns(t *testing.T) {
+	backend := &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpt"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			External: &gatewayv1alpha1.ExternalBackend{Provider: "openai"},
+			Cost:     &gatewayv1alpha1.CostConfig{InputTokenCost: "0.01", OutputTokenCost: "0.02"},
+		},
+	}
+	costTracker := NewCostTracker(nil)
+	costTracker.EnableConversationCosts(DefaultConversationCostConfig())
+	handler := NewBackendHandler(cache.NewStore(), nil, zap.New(), nil, costTracker, nil)
+
+	// A tool call, the tool result turn, and the final answer
+	toolCall := `{"choices":[{"message":{"tool_calls":[{"id":"call_1"}]}}],"usage":{"prompt_tokens":1000,"completion_tokens":100}}`
+	toolResult := `{"choices":[{"message":{"tool_calls":[{"id":"call_2"}]}}],"usage":{"prompt_tokens":2000,"completion_tokens":100}}`
+	answer := `{"choices":[{"message":{"content":"done"}}],"usage":{"prompt_tokens":3000,"completion_tokens":800}}`
+	for _, body := range []string{toolCall, toolResult, answer} {
+		trackConversationTurn(handler, backend, "conv-1", body)
+	}
+	trackConversationTurn(handler, backend, "conv-2", answer)
+	trackConversationTurn(handler, backend, "", answer)
+
+	stats := costTracker.GetConversationCosts("conv-1")
+	if stats == nil {
+		t.Fatal("expected conversation cost stats")
+	}
+	if stats.TotalRequests != 3 {
+		t.Errorf("expected 3 requests in the conversation, got %d", stats.TotalRequests)
+	}
+	if stats.TotalInputTokens != 6000 || stats.TotalOutputTokens != 1000 {
+		t.Errorf("expected 6000/1000 tokens, got %d/%d", stats.TotalInputTokens, stats.TotalOutputTokens)
+	}
+	if want := 0.06 + 0.02; math.Abs(stats.TotalCost-want) > 1e-9 {
+		t.Errorf("expected conversation cost %v, got %v", want, stats.TotalCost)
+	}
+
+	if other := costTracker.GetConversationCosts("conv-2"); other == nil || other.TotalRequests != 1 {
+		t.Errorf("expected conv-2 to be tracked separately, got %+v", other)
+	}
+	if route := costTracker.GetRouteCosts("chat"); route.TotalRequests != 5 {
+		t.Errorf("expected route totals to include every request, got %d", route.TotalRequests)
+	}
+}
+
+func TestCostTracker_ConversationCostsDisabled(t *testing.T) {
+	costTracker := NewCostTracker(nil)
+	config := &gatewayv1alpha1.CostConfig{RequestCost: "0.01"}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set(DefaultConversationIDHeader, "conv-1")
+	if id := costTracker.ConversationID(req); id != "" {
+		t.Errorf("expected no conversation ID while disabled, got %q", id)
+	}
+
+	costTracker.TrackConversation("conv-1", TokenUsage{InputTokens: 10}, 0.01, config)
+	if stats := costTracker.GetConversationCosts("conv-1"); stats != nil {
+		t.Errorf("expected no conversation costs while disabled, got %+v", stats)
+	}
+}
+
+func TestCostTracker_ConversationEviction(t *testing.T) {
+	costTracker := NewCostTracker(nil)
+	costTracker.EnableConversationCosts(ConversationCostConfig{TTL: time.Hour, MaxConversations: 2})
+	config := &gatewayv1alpha1.CostConfig{RequestCost: "0.01"}
+
+	costTracker.TrackConversation("first", TokenUsage{}, 0.01, config)
+	time.Sleep(time.Millisecond)
+	costTracker.TrackConversation("second", TokenUsage{}, 0.01, config)
+	costTracker.TrackConversation("third", TokenUsage{}, 0.01, config)
+
+	if stats := costTracker.GetConversationCosts("first"); stats != nil {
+		t.Error("expected the least recently updated conversation to be evicted")
+	}
+	for _, id := range []string{"second", "third"} {
+		if costTracker.GetConversationCosts(id) == nil {
+			t.Errorf("expected conversation %s to be kept", id)
+		}
+	}
+}
+
+func TestCostTracker_ConversationEvictionFollowsUpdates(t *testing.T) {
+	costTracker := NewCostTracker(nil)
+	costTracker.EnableConversationCosts(ConversationCostConfig{TTL: time.Hour, MaxConversations: 2})
+	config := &gatewayv1alpha1.CostConfig{RequestCost: "0.01"}
+
+	costTracker.TrackConversation("first", TokenUsage{}, 0.01, config)
+	costTracker.TrackConversation("second", TokenUsage{}, 0.01, config)
+	// Updating the first conversation makes the second the least recent
+	costTracker.TrackConversation("first", TokenUsage{}, 0.01, config)
+	costTracker.TrackConversation("third", TokenUsage{}, 0.01, config)
+
+	if stats := costTracker.GetConversationCosts("second"); stats != nil {
+		t.Error("expected the least recently updated conversation to be evicted")
+	}
+	if stats := costTracker.GetConversationCosts("first"); stats == nil || stats.TotalRequests != 2 {
+		t.Errorf("expected the updated conversation to be kept with 2 requests, got %+v", stats)
+	}
+}
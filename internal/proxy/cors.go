@@ -0,0 +1,193 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig configures Cross-Origin Resource Sharing for browser clients
+type CORSConfig struct {
+	// AllowedOrigins lists origins allowed to call the gateway. "*" allows any origin.
+	AllowedOrigins []string
+
+	// AllowedMethods lists methods allowed in preflight requests
+	AllowedMethods []string
+
+	// AllowedHeaders lists request headers allowed in preflight requests.
+	// When empty, the headers requested by the browser are allowed.
+	AllowedHeaders []string
+
+	// ExposedHeaders lists response headers the browser may read
+	ExposedHeaders []string
+
+	// AllowCredentials allows cookies and authorization headers to be sent
+	AllowCredentials bool
+
+	// MaxAge is how long browsers may cache a preflight response (0 = unset)
+	MaxAge time.Duration
+}
+
+// DefaultCORSConfig returns a configuration allowing the given origins to make
+// the requests used by OpenAI-compatible clients
+func DefaultCORSConfig(origins ...string) CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: origins,
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodOptions},
+		ExposedHeaders: []string{"X-Served-By", "X-RateLimit-Limit", "X-RateLimit-Remaining", "Retry-After"},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+// corsHandler applies a CORSConfig to requests
+type corsHandler struct {
+	config CORSConfig
+}
+
+// newCORSHandler creates a handler for the given configuration
+func newCORSHandler(cfg CORSConfig) *corsHandler {
+	return &corsHandler{config: cfg}
+}
+
+// isPreflight reports whether the request is a CORS preflight request
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions &&
+		r.Header.Get("Origin") != "" &&
+		r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// originAllowed reports whether the origin may access the gateway
+func (c *corsHandler) originAllowed(origin string) bool {
+	for _, allowed := range c.config.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowOrigin returns the Access-Control-Allow-Origin value for an allowed origin.
+// The wildcard can't be combined with credentials, so the origin is echoed instead.
+func (c *corsHandler) allowOrigin(origin string) string {
+	if slices.Contains(c.config.AllowedOrigins, "*") && !c.config.AllowCredentials {
+		return "*"
+	}
+	return origin
+}
+
+// handlePreflight answers a preflight request without routing it to a backend
+func (c *corsHandler) handlePreflight(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	method := r.Header.Get("Access-Control-Request-Method")
+
+	w.Header().Add("Vary", "Origin")
+	w.Header().Add("Vary", "Access-Control-Request-Method")
+	w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+	if !c.originAllowed(origin) || !c.methodAllowed(method) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", c.allowOrigin(origin))
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(c.config.AllowedMethods, ", "))
+
+	if len(c.config.AllowedHeaders) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(c.config.AllowedHeaders, ", "))
+	} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+		w.Header().Set("Access-Control-Allow-Headers", requested)
+	}
+
+	if c.config.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+	if c.config.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.config.MaxAge.Seconds())))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// methodAllowed reports whether the method may be used in a cross-origin request
+func (c *corsHandler) methodAllowed(method string) bool {
+	for _, allowed := range c.config.AllowedMethods {
+		if strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// wrap returns a writer that adds CORS headers to the actual response of a
+// cross-origin request. Requests from disallowed origins are left untouched.
+func (c *corsHandler) wrap(w http.ResponseWriter, r *http.Request) http.ResponseWriter {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return w
+	}
+
+	w.Header().Add("Vary", "Origin")
+	if !c.originAllowed(origin) {
+		return w
+	}
+
+	headers := http.Header{}
+	headers.Set("Access-Control-Allow-Origin", c.allowOrigin(origin))
+	if len(c.config.ExposedHeaders) > 0 {
+		headers.Set("Access-Control-Expose-Headers", strings.Join(c.config.ExposedHeaders, ", "))
+	}
+	if c.config.AllowCredentials {
+		headers.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	return &corsResponseWriter{ResponseWriter: w, headers: headers}
+}
+
+// corsResponseWriter sets CORS headers just before the response is written so
+// that they replace any CORS headers sent by the backend
+type corsResponseWriter struct {
+	http.ResponseWriter
+	headers     http.Header
+	wroteHeader bool
+}
+
+func (w *corsResponseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		for key, values := range w.headers {
+			w.ResponseWriter.Header()[key] = values
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *corsResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying writer so http.ResponseController can reach
+// flushing and deadline support
+func (w *corsResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORS_PreflightRejected(t *testing.T) {
+	cors := newCORSHandler(DefaultCORSConfig("https://app.example.com"))
+
+	tests := []struct {
+		name   string
+		origin string
+		method string
+	}{
+		{"disallowed origin", "https://evil.example.com", "POST"},
+		{"disallowed method", "https://app.example.com", "DELETE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, "/v1/chat/completions", nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", tt.method)
+			rec := httptest.NewRecorder()
+
+			cors.handlePreflight(rec, req)
+
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("expected status 403, got %d", rec.Code)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+				t.Errorf("expected no allowed origin, got '%s'", got)
+			}
+		})
+	}
+}
+
+func TestCORS_WildcardOrigin(t *testing.T) {
+	cfg := DefaultCORSConfig("*")
+	cors := newCORSHandler(cfg)
+	if got := cors.allowOrigin("https://app.example.com"); got != "*" {
+		t.Errorf("expected wildcard origin, got '%s'", got)
+	}
+
+	// Credentials require the origin to be echoed
+	cfg.AllowCredentials = true
+	cors = newCORSHandler(cfg)
+	if got := cors.allowOrigin("https://app.example.com"); got != "https://app.example.com" {
+		t.Errorf("expected echoed origin with credentials, got '%s'", got)
+	}
+}
+
+func TestCORS_WrapSkipsNonCORSRequests(t *testing.T) {
+	cors := newCORSHandler(DefaultCORSConfig("https://app.example.com"))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	if w := cors.wrap(rec, req); w != http.ResponseWriter(rec) {
+		t.Error("expected requests without an Origin to be left untouched")
+	}
+
+	req.Header.Set("Origin", "https://evil.example.com")
+	w := cors.wrap(rec, req)
+	w.WriteHeader(http.StatusOK)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("expected no CORS headers for disallowed origin, got '%s'", got)
+	}
+}
@@ -18,10 +18,13 @@ package proxy
 
 import (
 	"bytes"
+	"container/list"
 	"encoding/json"
 	"io"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -44,26 +47,48 @@ type TokenUsage struct {
 	OutputTokens int64
 }
 
-// CostTracker tracks costs per route and backend
+// CostTracker tracks costs per route, backend, provider and, when enabled,
+// cost tag
 type CostTracker struct {
-	mu           sync.RWMutex
-	routeCosts   map[string]*CostStats
-	backendCosts map[string]*CostStats
-	metrics      *MetricsRecorder
+	mu            sync.RWMutex
+	routeCosts    map[string]*CostStats
+	backendCosts  map[string]*CostStats
+	providerCosts map[string]*CostStats
+	metrics       *MetricsRecorder
+
+	// conversationCosts roll up requests sharing a conversation ID, when
+	// conversations is set
+	conversations     *ConversationCostConfig
+	conversationCosts map[string]*list.Element
+	conversationOrder *list.List
+
+	// tagCosts aggregate requests by the cost tag they carry, when costTags
+	// is set
+	costTags *CostTagConfig
+	tagCosts map[string]*CostStats
+
+	// sink receives a record of each tracked request, attributed to the
+	// user that identity finds
+	sink     CostSink
+	identity *IdentityExtractor
 }
 
 // NewCostTracker creates a new cost tracker
 func NewCostTracker(metrics *MetricsRecorder) *CostTracker {
 	return &CostTracker{
-		routeCosts:   make(map[string]*CostStats),
-		backendCosts: make(map[string]*CostStats),
-		metrics:      metrics,
+		routeCosts:    make(map[string]*CostStats),
+		backendCosts:  make(map[string]*CostStats),
+		providerCosts: make(map[string]*CostStats),
+		metrics:       metrics,
 	}
 }
 
-// TrackRequest records cost for a request
+// TrackRequest records cost for a request. Provider is the backend's
+// provider; costs are not aggregated by provider when it is empty. User is
+// the optional user the request's cost record is attributed to, and tag the
+// optional cost tag it is aggregated under.
 func (c *CostTracker) TrackRequest(
-	route, backend string,
+	route, backend, provider, user, tag string,
 	usage TokenUsage,
 	costConfig *gatewayv1alpha1.CostConfig,
 ) {
@@ -71,29 +96,89 @@ func (c *CostTracker) TrackRequest(
 		return
 	}
 
-	// Calculate cost
-	cost := c.calculateCost(usage, costConfig)
+	c.trackCost(route, backend, provider, user, tag, usage, c.calculateCost(usage, costConfig), costConfig)
+}
+
+// TrackReportedCost records a request whose cost was reported by the backend
+// rather than computed from the cost configuration
+func (c *CostTracker) TrackReportedCost(
+	route, backend, provider, user, tag string,
+	usage TokenUsage,
+	cost float64,
+	costConfig *gatewayv1alpha1.CostConfig,
+) {
+	if costConfig == nil {
+		return
+	}
+
+	c.trackCost(route, backend, provider, user, tag, usage, cost, costConfig)
+}
+
+// trackCost records the cost of a request in the stats and metrics, and
+// sends its record to the sink
+func (c *CostTracker) trackCost(
+	route, backend, provider, user, tag string,
+	usage TokenUsage,
+	cost float64,
+	costConfig *gatewayv1alpha1.CostConfig,
+) {
 	currency := costConfig.Currency
 	if currency == "" {
 		currency = "USD"
 	}
 
+	now := time.Now()
+	if sink := c.recordCost(route, backend, provider, tag, usage, cost, currency, now); sink != nil {
+		// The sink may do I/O, so it is called without holding the lock
+		sink.WriteCost(CostRecord{
+			Timestamp:    now,
+			Route:        route,
+			Backend:      backend,
+			Provider:     provider,
+			UserID:       user,
+			Tag:          tag,
+			InputTokens:  usage.InputTokens,
+			OutputTokens: usage.OutputTokens,
+			Cost:         cost,
+			Currency:     currency,
+		})
+	}
+}
+
+// recordCost updates the stats and metrics and returns the sink, if any
+func (c *CostTracker) recordCost(
+	route, backend, provider, tag string,
+	usage TokenUsage,
+	cost float64,
+	currency string,
+	now time.Time,
+) CostSink {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	now := time.Now()
-
 	// Update route costs
 	c.updateStats(c.routeCosts, route, usage, cost, currency, now)
 
 	// Update backend costs
 	c.updateStats(c.backendCosts, backend, usage, cost, currency, now)
 
+	// Update provider costs
+	if provider != "" {
+		c.updateStats(c.providerCosts, provider, usage, cost, currency, now)
+	}
+
+	// Update tag costs
+	tag = c.costTagLocked(tag)
+	if tag != "" {
+		c.updateStats(c.tagCosts, tag, usage, cost, currency, now)
+	}
+
 	// Record in metrics
 	if c.metrics != nil {
-		c.metrics.RecordCost(route, backend, cost)
+		c.metrics.RecordCost(route, backend, currency, tag, cost)
 		c.metrics.RecordTokens(route, backend, usage.InputTokens, usage.OutputTokens)
 	}
+	return c.sink
 }
 
 // updateStats updates cost statistics for a given key
@@ -110,7 +195,11 @@ func (c *CostTracker) updateStats(
 		s = &CostStats{Currency: currency}
 		stats[key] = s
 	}
+	s.add(usage, cost, timestamp)
+}
 
+// add counts one request towards the statistics
+func (s *CostStats) add(usage TokenUsage, cost float64, timestamp time.Time) {
 	s.TotalCost += cost
 	s.TotalRequests++
 	s.TotalInputTokens += usage.InputTokens
@@ -187,6 +276,26 @@ func (c *CostTracker) GetBackendCosts(backend string) *CostStats {
 	return nil
 }
 
+// GetProviderCosts returns cost statistics aggregated across all backends of
+// a provider
+func (c *CostTracker) GetProviderCosts(provider string) *CostStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if stats, exists := c.providerCosts[provider]; exists {
+		// Return a copy
+		return &CostStats{
+			TotalCost:         stats.TotalCost,
+			TotalRequests:     stats.TotalRequests,
+			TotalInputTokens:  stats.TotalInputTokens,
+			TotalOutputTokens: stats.TotalOutputTokens,
+			Currency:          stats.Currency,
+			LastUpdated:       stats.LastUpdated,
+		}
+	}
+	return nil
+}
+
 // GetAllStats returns all cost statistics
 func (c *CostTracker) GetAllStats() (routes, backends map[string]*CostStats) {
 	c.mu.RLock()
@@ -226,6 +335,31 @@ func (c *CostTracker) Reset() {
 
 	c.routeCosts = make(map[string]*CostStats)
 	c.backendCosts = make(map[string]*CostStats)
+	c.providerCosts = make(map[string]*CostStats)
+	if c.conversations != nil {
+		c.conversationCosts = make(map[string]*list.Element)
+		c.conversationOrder = list.New()
+	}
+	if c.costTags != nil {
+		c.tagCosts = make(map[string]*CostStats)
+	}
+}
+
+// ParseReportedCost returns the cost a backend reported in the configured
+// cost header, if the header is configured and holds a valid cost
+func ParseReportedCost(resp *http.Response, costConfig *gatewayv1alpha1.CostConfig) (float64, bool) {
+	if resp == nil || costConfig == nil || costConfig.CostHeader == "" {
+		return 0, false
+	}
+	value := strings.TrimSpace(resp.Header.Get(costConfig.CostHeader))
+	if value == "" {
+		return 0, false
+	}
+	cost, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(cost) || math.IsInf(cost, 0) || cost < 0 {
+		return 0, false
+	}
+	return cost, true
 }
 
 // ParseTokenUsage extracts token usage from an API response based on provider
@@ -242,14 +376,75 @@ func ParseTokenUsage(provider string, resp *http.Response, body []byte) TokenUsa
 	}
 }
 
+// tokenCount is a token count that tolerates the shapes providers actually
+// send: integers, floats and numeric strings. Anything else, including
+// negative or out-of-range values, decodes to zero instead of failing the
+// whole usage object.
+type tokenCount int64
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *tokenCount) UnmarshalJSON(data []byte) error {
+	*t = 0
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case float64:
+		*t = tokenCountFromFloat(v)
+	case string:
+		*t = parseTokenString(v)
+	}
+	return nil
+}
+
+// parseTokenString parses a numeric string such as "42" or "42.0"
+func parseTokenString(s string) tokenCount {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n < 0 {
+			return 0
+		}
+		return tokenCount(n)
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return tokenCountFromFloat(f)
+	}
+	return 0
+}
+
+// tokenCountFromFloat truncates a float token count, rejecting values that
+// cannot be a token count
+func tokenCountFromFloat(f float64) tokenCount {
+	if math.IsNaN(f) || f < 0 || f >= math.MaxInt64 {
+		return 0
+	}
+	return tokenCount(f)
+}
+
+// firstNonZero returns the first non-zero count
+func firstNonZero(counts ...tokenCount) int64 {
+	for _, c := range counts {
+		if c != 0 {
+			return int64(c)
+		}
+	}
+	return 0
+}
+
 // parseOpenAIUsage extracts token usage from OpenAI response
 func parseOpenAIUsage(body []byte) TokenUsage {
 	// OpenAI response format:
 	// {"usage": {"prompt_tokens": 10, "completion_tokens": 20, "total_tokens": 30}}
+	// The Responses API reports input_tokens/output_tokens instead.
 	var response struct {
 		Usage struct {
-			PromptTokens     int64 `json:"prompt_tokens"`
-			CompletionTokens int64 `json:"completion_tokens"`
+			PromptTokens     tokenCount `json:"prompt_tokens"`
+			CompletionTokens tokenCount `json:"completion_tokens"`
+			InputTokens      tokenCount `json:"input_tokens"`
+			OutputTokens     tokenCount `json:"output_tokens"`
 		} `json:"usage"`
 	}
 
@@ -258,34 +453,44 @@ func parseOpenAIUsage(body []byte) TokenUsage {
 	}
 
 	return TokenUsage{
-		InputTokens:  response.Usage.PromptTokens,
-		OutputTokens: response.Usage.CompletionTokens,
+		InputTokens:  firstNonZero(response.Usage.PromptTokens, response.Usage.InputTokens),
+		OutputTokens: firstNonZero(response.Usage.CompletionTokens, response.Usage.OutputTokens),
 	}
 }
 
 // parseAnthropicUsage extracts token usage from Anthropic response
 func parseAnthropicUsage(resp *http.Response, body []byte) TokenUsage {
 	// Try headers first (newer API versions)
-	inputStr := resp.Header.Get("X-Usage-Input-Tokens")
-	outputStr := resp.Header.Get("X-Usage-Output-Tokens")
-
-	if inputStr != "" && outputStr != "" {
-		input, _ := strconv.ParseInt(inputStr, 10, 64)
-		output, _ := strconv.ParseInt(outputStr, 10, 64)
-		return TokenUsage{
-			InputTokens:  input,
-			OutputTokens: output,
+	if resp != nil {
+		inputStr := resp.Header.Get("X-Usage-Input-Tokens")
+		outputStr := resp.Header.Get("X-Usage-Output-Tokens")
+
+		if inputStr != "" && outputStr != "" {
+			return TokenUsage{
+				InputTokens:  int64(parseTokenString(inputStr)),
+				OutputTokens: int64(parseTokenString(outputStr)),
+			}
 		}
 	}
 
+	// Streaming responses report usage across message_start and message_delta events
+	if isEventStream(resp) {
+		return ParseAnthropicStreamUsage(bytes.NewReader(body))
+	}
+
 	// Fall back to response body
 	// Anthropic response format:
 	// {"usage": {"input_tokens": 10, "output_tokens": 20}}
+	// Some proxies wrap the message: {"message": {"usage": {...}}}
+	type anthropicUsage struct {
+		InputTokens  tokenCount `json:"input_tokens"`
+		OutputTokens tokenCount `json:"output_tokens"`
+	}
 	var response struct {
-		Usage struct {
-			InputTokens  int64 `json:"input_tokens"`
-			OutputTokens int64 `json:"output_tokens"`
-		} `json:"usage"`
+		Usage   anthropicUsage `json:"usage"`
+		Message struct {
+			Usage anthropicUsage `json:"usage"`
+		} `json:"message"`
 	}
 
 	if err := json.Unmarshal(body, &response); err != nil {
@@ -293,8 +498,8 @@ func parseAnthropicUsage(resp *http.Response, body []byte) TokenUsage {
 	}
 
 	return TokenUsage{
-		InputTokens:  response.Usage.InputTokens,
-		OutputTokens: response.Usage.OutputTokens,
+		InputTokens:  firstNonZero(response.Usage.InputTokens, response.Message.Usage.InputTokens),
+		OutputTokens: firstNonZero(response.Usage.OutputTokens, response.Message.Usage.OutputTokens),
 	}
 }
 
@@ -302,13 +507,19 @@ func parseAnthropicUsage(resp *http.Response, body []byte) TokenUsage {
 func parseCohereUsage(body []byte) TokenUsage {
 	// Cohere response format:
 	// {"meta": {"billed_units": {"input_tokens": 10, "output_tokens": 20}}}
+	// The v2 API moves this under "usage", and "tokens" is reported when
+	// billed units are absent.
+	type cohereUnits struct {
+		InputTokens  tokenCount `json:"input_tokens"`
+		OutputTokens tokenCount `json:"output_tokens"`
+	}
+	type cohereUsage struct {
+		BilledUnits cohereUnits `json:"billed_units"`
+		Tokens      cohereUnits `json:"tokens"`
+	}
 	var response struct {
-		Meta struct {
-			BilledUnits struct {
-				InputTokens  int64 `json:"input_tokens"`
-				OutputTokens int64 `json:"output_tokens"`
-			} `json:"billed_units"`
-		} `json:"meta"`
+		Meta  cohereUsage `json:"meta"`
+		Usage cohereUsage `json:"usage"`
 	}
 
 	if err := json.Unmarshal(body, &response); err != nil {
@@ -316,8 +527,18 @@ func parseCohereUsage(body []byte) TokenUsage {
 	}
 
 	return TokenUsage{
-		InputTokens:  response.Meta.BilledUnits.InputTokens,
-		OutputTokens: response.Meta.BilledUnits.OutputTokens,
+		InputTokens: firstNonZero(
+			response.Meta.BilledUnits.InputTokens,
+			response.Usage.BilledUnits.InputTokens,
+			response.Meta.Tokens.InputTokens,
+			response.Usage.Tokens.InputTokens,
+		),
+		OutputTokens: firstNonZero(
+			response.Meta.BilledUnits.OutputTokens,
+			response.Usage.BilledUnits.OutputTokens,
+			response.Meta.Tokens.OutputTokens,
+			response.Usage.Tokens.OutputTokens,
+		),
 	}
 }
 
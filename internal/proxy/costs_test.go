@@ -17,17 +17,25 @@ limitations under the License.
 package proxy
 
 import (
+	"io"
+	"math"
 	"net/http"
+	"strings"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
 	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
 )
 
 func TestCostTracker_TrackRequest_NilConfig(t *testing.T) {
 	ct := NewCostTracker(nil)
 
 	// Should not panic
-	ct.TrackRequest("route1", "backend1", TokenUsage{InputTokens: 100, OutputTokens: 50}, nil)
+	ct.TrackRequest("route1", "backend1", "", "", "", TokenUsage{InputTokens: 100, OutputTokens: 50}, nil)
 
 	stats := ct.GetRouteCosts("route1")
 	if stats != nil {
@@ -48,7 +56,7 @@ func TestCostTracker_TrackRequest_BasicCost(t *testing.T) {
 		OutputTokens: 500,
 	}
 
-	ct.TrackRequest("route1", "backend1", usage, config)
+	ct.TrackRequest("route1", "backend1", "", "", "", usage, config)
 
 	routeStats := ct.GetRouteCosts("route1")
 	if routeStats == nil {
@@ -88,7 +96,7 @@ func TestCostTracker_TrackRequest_WithRequestCost(t *testing.T) {
 		OutputTokens: 500,
 	}
 
-	ct.TrackRequest("route1", "backend1", usage, config)
+	ct.TrackRequest("route1", "backend1", "", "", "", usage, config)
 
 	routeStats := ct.GetRouteCosts("route1")
 	// Expected cost: 0.025 (tokens) + 0.001 (request) = 0.026
@@ -110,7 +118,7 @@ func TestCostTracker_TrackRequest_Aggregation(t *testing.T) {
 
 	// Track multiple requests
 	for i := 0; i < 5; i++ {
-		ct.TrackRequest("route1", "backend1", TokenUsage{InputTokens: 100, OutputTokens: 100}, config)
+		ct.TrackRequest("route1", "backend1", "", "", "", TokenUsage{InputTokens: 100, OutputTokens: 100}, config)
 	}
 
 	routeStats := ct.GetRouteCosts("route1")
@@ -133,8 +141,8 @@ func TestCostTracker_TrackRequest_SeparateBackendStats(t *testing.T) {
 		Currency:        "USD",
 	}
 
-	ct.TrackRequest("route1", "backend1", TokenUsage{InputTokens: 100, OutputTokens: 100}, config)
-	ct.TrackRequest("route1", "backend2", TokenUsage{InputTokens: 200, OutputTokens: 200}, config)
+	ct.TrackRequest("route1", "backend1", "", "", "", TokenUsage{InputTokens: 100, OutputTokens: 100}, config)
+	ct.TrackRequest("route1", "backend2", "", "", "", TokenUsage{InputTokens: 200, OutputTokens: 200}, config)
 
 	backend1Stats := ct.GetBackendCosts("backend1")
 	backend2Stats := ct.GetBackendCosts("backend2")
@@ -147,6 +155,48 @@ func TestCostTracker_TrackRequest_SeparateBackendStats(t *testing.T) {
 	}
 }
 
+func TestCostTracker_TrackRequest_AggregatesByProvider(t *testing.T) {
+	ct := NewCostTracker(nil)
+	config := &gatewayv1alpha1.CostConfig{
+		InputTokenCost:  "0.01",
+		OutputTokenCost: "0.02",
+		Currency:        "USD",
+	}
+
+	ct.TrackRequest("route1", "gpt-4", "openai", "", "", TokenUsage{InputTokens: 1000, OutputTokens: 500}, config)
+	ct.TrackRequest("route2", "gpt-35", "openai", "", "", TokenUsage{InputTokens: 2000, OutputTokens: 1000}, config)
+	ct.TrackRequest("route1", "claude", "anthropic", "", "", TokenUsage{InputTokens: 100}, config)
+	ct.TrackRequest("route1", "local", "", "", "", TokenUsage{InputTokens: 100}, config)
+
+	openai := ct.GetProviderCosts("openai")
+	if openai == nil {
+		t.Fatal("expected openai provider stats")
+	}
+	if openai.TotalRequests != 2 {
+		t.Errorf("expected 2 openai requests, got %d", openai.TotalRequests)
+	}
+	if openai.TotalInputTokens != 3000 || openai.TotalOutputTokens != 1500 {
+		t.Errorf("expected 3000 input and 1500 output tokens, got %d and %d",
+			openai.TotalInputTokens, openai.TotalOutputTokens)
+	}
+	// (1000+2000)/1000 * 0.01 + (500+1000)/1000 * 0.02 = 0.06
+	if math.Abs(openai.TotalCost-0.06) > 1e-9 {
+		t.Errorf("expected total openai cost 0.06, got %f", openai.TotalCost)
+	}
+
+	if anthropic := ct.GetProviderCosts("anthropic"); anthropic == nil || anthropic.TotalRequests != 1 {
+		t.Errorf("expected one anthropic request, got %+v", anthropic)
+	}
+	if empty := ct.GetProviderCosts(""); empty != nil {
+		t.Errorf("expected no stats for backends without a provider, got %+v", empty)
+	}
+
+	ct.Reset()
+	if stats := ct.GetProviderCosts("openai"); stats != nil {
+		t.Error("expected nil provider stats after reset")
+	}
+}
+
 func TestCostTracker_GetAllStats(t *testing.T) {
 	ct := NewCostTracker(nil)
 	config := &gatewayv1alpha1.CostConfig{
@@ -154,8 +204,8 @@ func TestCostTracker_GetAllStats(t *testing.T) {
 		Currency:       "USD",
 	}
 
-	ct.TrackRequest("route1", "backend1", TokenUsage{InputTokens: 100}, config)
-	ct.TrackRequest("route2", "backend2", TokenUsage{InputTokens: 200}, config)
+	ct.TrackRequest("route1", "backend1", "", "", "", TokenUsage{InputTokens: 100}, config)
+	ct.TrackRequest("route2", "backend2", "", "", "", TokenUsage{InputTokens: 200}, config)
 
 	routes, backends := ct.GetAllStats()
 
@@ -174,7 +224,7 @@ func TestCostTracker_Reset(t *testing.T) {
 		Currency:       "USD",
 	}
 
-	ct.TrackRequest("route1", "backend1", TokenUsage{InputTokens: 100}, config)
+	ct.TrackRequest("route1", "backend1", "", "", "", TokenUsage{InputTokens: 100}, config)
 	ct.Reset()
 
 	stats := ct.GetRouteCosts("route1")
@@ -190,7 +240,7 @@ func TestCostTracker_DefaultCurrency(t *testing.T) {
 		// No currency specified
 	}
 
-	ct.TrackRequest("route1", "backend1", TokenUsage{InputTokens: 100}, config)
+	ct.TrackRequest("route1", "backend1", "", "", "", TokenUsage{InputTokens: 100}, config)
 
 	stats := ct.GetRouteCosts("route1")
 	if stats.Currency != "USD" {
@@ -198,6 +248,26 @@ func TestCostTracker_DefaultCurrency(t *testing.T) {
 	}
 }
 
+func TestCostTracker_TrackRequest_CurrencyLabel(t *testing.T) {
+	ct := NewCostTracker(NewMetricsRecorder())
+	usd := &gatewayv1alpha1.CostConfig{InputTokenCost: "1.00"}
+	eur := &gatewayv1alpha1.CostConfig{InputTokenCost: "2.00", Currency: "EUR"}
+
+	ct.TrackRequest("currency-route", "usd-backend", "", "", "", TokenUsage{InputTokens: 1000}, usd)
+	ct.TrackRequest("currency-route", "eur-backend", "", "", "", TokenUsage{InputTokens: 1000}, eur)
+	ct.TrackRequest("currency-route", "eur-backend", "", "", "", TokenUsage{InputTokens: 500}, eur)
+
+	if got := testutil.ToFloat64(CostTotal.WithLabelValues("currency-route", "usd-backend", "USD", "")); got != 1 {
+		t.Errorf("expected 1 USD, got %v", got)
+	}
+	if got := testutil.ToFloat64(CostTotal.WithLabelValues("currency-route", "eur-backend", "EUR", "")); got != 3 {
+		t.Errorf("expected 3 EUR, got %v", got)
+	}
+	if got := testutil.ToFloat64(CostTotal.WithLabelValues("currency-route", "eur-backend", "USD", "")); got != 0 {
+		t.Errorf("expected EUR costs not to be counted as USD, got %v", got)
+	}
+}
+
 func TestParseTokenUsage_OpenAI(t *testing.T) {
 	body := []byte(`{
 		"id": "chatcmpl-123",
@@ -354,3 +424,219 @@ func (m *mockReadCloser) Read(p []byte) (n int, err error) {
 func (m *mockReadCloser) Close() error {
 	return nil
 }
+
+func TestParseTokenUsage_ProviderVariants(t *testing.T) {
+	tests := []struct {
+		name           string
+		provider       string
+		body           string
+		expectedInput  int64
+		expectedOutput int64
+	}{
+		{
+			name:           "openai float counts",
+			provider:       "openai",
+			body:           `{"usage": {"prompt_tokens": 100.0, "completion_tokens": 50.7}}`,
+			expectedInput:  100,
+			expectedOutput: 50,
+		},
+		{
+			name:           "openai numeric strings",
+			provider:       "openai",
+			body:           `{"usage": {"prompt_tokens": "100", "completion_tokens": " 50 "}}`,
+			expectedInput:  100,
+			expectedOutput: 50,
+		},
+		{
+			name:           "openai responses api",
+			provider:       "openai",
+			body:           `{"usage": {"input_tokens": 12, "output_tokens": 34}}`,
+			expectedInput:  12,
+			expectedOutput: 34,
+		},
+		{
+			name:           "openai missing completion tokens",
+			provider:       "openai",
+			body:           `{"usage": {"prompt_tokens": 10}}`,
+			expectedInput:  10,
+			expectedOutput: 0,
+		},
+		{
+			name:           "openai null usage",
+			provider:       "openai",
+			body:           `{"usage": null}`,
+			expectedInput:  0,
+			expectedOutput: 0,
+		},
+		{
+			name:           "openai non-numeric values",
+			provider:       "openai",
+			body:           `{"usage": {"prompt_tokens": "many", "completion_tokens": true}}`,
+			expectedInput:  0,
+			expectedOutput: 0,
+		},
+		{
+			name:           "openai negative and overflowing values",
+			provider:       "openai",
+			body:           `{"usage": {"prompt_tokens": -5, "completion_tokens": 1e300}}`,
+			expectedInput:  0,
+			expectedOutput: 0,
+		},
+		{
+			name:           "anthropic string counts",
+			provider:       "anthropic",
+			body:           `{"usage": {"input_tokens": "200", "output_tokens": "100.0"}}`,
+			expectedInput:  200,
+			expectedOutput: 100,
+		},
+		{
+			name:           "anthropic nested under message",
+			provider:       "anthropic",
+			body:           `{"message": {"usage": {"input_tokens": 7, "output_tokens": 9}}}`,
+			expectedInput:  7,
+			expectedOutput: 9,
+		},
+		{
+			name:           "cohere v2 usage",
+			provider:       "cohere",
+			body:           `{"usage": {"billed_units": {"input_tokens": 80, "output_tokens": 40}}}`,
+			expectedInput:  80,
+			expectedOutput: 40,
+		},
+		{
+			name:           "cohere tokens without billed units",
+			provider:       "cohere",
+			body:           `{"meta": {"tokens": {"input_tokens": 81.0, "output_tokens": "41"}}}`,
+			expectedInput:  81,
+			expectedOutput: 41,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: make(http.Header)}
+			usage := ParseTokenUsage(tt.provider, resp, []byte(tt.body))
+
+			if usage.InputTokens != tt.expectedInput {
+				t.Errorf("expected %d input tokens, got %d", tt.expectedInput, usage.InputTokens)
+			}
+			if usage.OutputTokens != tt.expectedOutput {
+				t.Errorf("expected %d output tokens, got %d", tt.expectedOutput, usage.OutputTokens)
+			}
+		})
+	}
+}
+
+func TestParseTokenUsage_Anthropic_NilResponse(t *testing.T) {
+	usage := ParseTokenUsage("anthropic", nil, []byte(`{"usage": {"input_tokens": 5, "output_tokens": 6}}`))
+
+	if usage.InputTokens != 5 || usage.OutputTokens != 6 {
+		t.Errorf("expected 5/6 tokens, got %d/%d", usage.InputTokens, usage.OutputTokens)
+	}
+}
+
+func FuzzParseTokenUsage(f *testing.F) {
+	f.Add("openai", `{"usage": {"prompt_tokens": 10, "completion_tokens": 20}}`)
+	f.Add("openai", `{"usage": {"prompt_tokens": "10", "completion_tokens": 2.5}}`)
+	f.Add("anthropic", `{"usage": {"input_tokens": 10, "output_tokens": 20}}`)
+	f.Add("anthropic", `{"message": {"usage": {"input_tokens": null}}}`)
+	f.Add("cohere", `{"meta": {"billed_units": {"input_tokens": 1e20}}}`)
+	f.Add("cohere", `{"usage": [1, 2, 3]}`)
+	f.Add("", `not json`)
+
+	f.Fuzz(func(t *testing.T, provider, body string) {
+		for _, resp := range []*http.Response{nil, {Header: make(http.Header)}} {
+			usage := ParseTokenUsage(provider, resp, []byte(body))
+			if usage.InputTokens < 0 || usage.OutputTokens < 0 {
+				t.Fatalf("negative token usage %+v for %q", usage, body)
+			}
+		}
+	})
+}
+
+func TestParseReportedCost(t *testing.T) {
+	config := &gatewayv1alpha1.CostConfig{CostHeader: "X-Cost"}
+
+	tests := []struct {
+		name   string
+		header string
+		config *gatewayv1alpha1.CostConfig
+		want   float64
+		wantOK bool
+	}{
+		{name: "reported cost", header: "0.0042", config: config, want: 0.0042, wantOK: true},
+		{name: "surrounding whitespace", header: " 1.5 ", config: config, want: 1.5, wantOK: true},
+		{name: "missing header", header: "", config: config},
+		{name: "not a number", header: "free", config: config},
+		{name: "negative cost", header: "-1", config: config},
+		{name: "infinite cost", header: "Inf", config: config},
+		{name: "header not configured", header: "0.5", config: &gatewayv1alpha1.CostConfig{}},
+		{name: "no cost config", header: "0.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("X-Cost", tt.header)
+			}
+			got, ok := ParseReportedCost(resp, tt.config)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("expected (%v, %v), got (%v, %v)", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestBackendHandler_trackCosts_ReportedCost(t *testing.T) {
+	backend := &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpt"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			External: &gatewayv1alpha1.ExternalBackend{Provider: "openai"},
+			Cost: &gatewayv1alpha1.CostConfig{
+				InputTokenCost:  "0.01",
+				OutputTokenCost: "0.02",
+				CostHeader:      "X-Cost",
+			},
+		},
+	}
+	body := `{"usage": {"prompt_tokens": 1000, "completion_tokens": 1000}}`
+
+	tests := []struct {
+		name     string
+		header   string
+		wantCost float64
+	}{
+		// The backend's own figure wins over the 0.01 + 0.02 estimate
+		{name: "reported cost header", header: "0.5", wantCost: 0.5},
+		{name: "computed cost without header", header: "", wantCost: 0.03},
+		{name: "computed cost with invalid header", header: "n/a", wantCost: 0.03},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			costTracker := NewCostTracker(nil)
+			handler := NewBackendHandler(cache.NewStore(), nil, zap.New(), nil, costTracker, nil)
+
+			resp := &http.Response{
+				Header: http.Header{"Content-Type": []string{"application/json"}},
+				Body:   io.NopCloser(strings.NewReader(body)),
+			}
+			if tt.header != "" {
+				resp.Header.Set("X-Cost", tt.header)
+			}
+			handler.trackCosts(resp, "chat", backend, "openai")
+
+			stats := costTracker.GetBackendCosts("gpt")
+			if stats == nil {
+				t.Fatal("expected backend cost stats")
+			}
+			if math.Abs(stats.TotalCost-tt.wantCost) > 1e-9 {
+				t.Errorf("expected cost %v, got %v", tt.wantCost, stats.TotalCost)
+			}
+			if stats.TotalInputTokens != 1000 {
+				t.Errorf("expected token usage to be tracked either way, got %d input tokens", stats.TotalInputTokens)
+			}
+		})
+	}
+}
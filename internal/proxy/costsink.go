@@ -0,0 +1,100 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// CostRecord is the cost of a single request
+type CostRecord struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Route        string    `json:"route"`
+	Backend      string    `json:"backend"`
+	Provider     string    `json:"provider,omitempty"`
+	UserID       string    `json:"userId,omitempty"`
+	Tag          string    `json:"tag,omitempty"`
+	InputTokens  int64     `json:"inputTokens"`
+	OutputTokens int64     `json:"outputTokens"`
+	Cost         float64   `json:"cost"`
+	Currency     string    `json:"currency"`
+}
+
+// CostSink receives a record for every request whose cost is tracked, for a
+// durable cost log. Implementations must be safe for concurrent use.
+type CostSink interface {
+	WriteCost(record CostRecord)
+}
+
+// jsonLinesCostSink writes each cost record as a line of JSON
+type jsonLinesCostSink struct {
+	log logr.Logger
+
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONLinesCostSink creates a sink that writes cost records to w as JSON
+// lines, such as a file that finance tooling picks up
+func NewJSONLinesCostSink(w io.Writer, log logr.Logger) CostSink {
+	return &jsonLinesCostSink{
+		log:     log.WithName("cost-sink"),
+		encoder: json.NewEncoder(w),
+	}
+}
+
+func (s *jsonLinesCostSink) WriteCost(record CostRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.encoder.Encode(record); err != nil {
+		s.log.Error(err, "Failed to write cost record", "route", record.Route, "backend", record.Backend)
+	}
+}
+
+// SetCostSink sends a record of every tracked request to the sink. A nil sink
+// disables the records.
+func (c *CostTracker) SetCostSink(sink CostSink) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sink = sink
+}
+
+// SetIdentityExtractor sets the extractor used to attribute cost records to users
+func (c *CostTracker) SetIdentityExtractor(identity *IdentityExtractor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.identity = identity
+}
+
+// UserID returns the user a request's cost record is attributed to, or an
+// empty string if cost records are disabled or the user is unknown. The
+// extractor hashes identities taken from credential headers, so credentials
+// never reach the cost log.
+func (c *CostTracker) UserID(req *http.Request) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.sink == nil || c.identity == nil || req == nil {
+		return ""
+	}
+	return c.identity.Extract(req)
+}
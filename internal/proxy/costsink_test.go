@@ -0,0 +1,162 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// capturingCostSink keeps the records written to it
+type capturingCostSink struct {
+	mu      sync.Mutex
+	records []CostRecord
+}
+
+func (s *capturingCostSink) WriteCost(record CostRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+}
+
+func TestCostTracker_CostSink(t *testing.T) {
+	sink := &capturingCostSink{}
+	ct := NewCostTracker(nil)
+	ct.SetCostSink(sink)
+	config := &gatewayv1alpha1.CostConfig{InputTokenCost: "0.01", OutputTokenCost: "0.02", Currency: "EUR"}
+
+	ct.TrackRequest("chat", "gpt", "openai", "alice", "", TokenUsage{InputTokens: 1000, OutputTokens: 500}, config)
+	ct.TrackReportedCost("chat", "claude", "anthropic", "", "", TokenUsage{InputTokens: 10}, 0.5, config)
+	ct.TrackRequest("chat", "gpt", "openai", "bob", "", TokenUsage{InputTokens: 100}, nil)
+
+	if len(sink.records) != 2 {
+		t.Fatalf("expected a record per tracked request, got %d", len(sink.records))
+	}
+	first := sink.records[0]
+	if first.Route != "chat" || first.Backend != "gpt" || first.Provider != "openai" || first.UserID != "alice" {
+		t.Errorf("unexpected record attribution: %+v", first)
+	}
+	if first.InputTokens != 1000 || first.OutputTokens != 500 || first.Cost != 0.02 || first.Currency != "EUR" {
+		t.Errorf("unexpected record cost: %+v", first)
+	}
+	if first.Timestamp.IsZero() {
+		t.Error("expected the record to be timestamped")
+	}
+	if second := sink.records[1]; second.Cost != 0.5 || second.UserID != "" {
+		t.Errorf("expected the reported cost without a user, got %+v", second)
+	}
+}
+
+func TestJSONLinesCostSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONLinesCostSink(&buf, zap.New())
+
+	sink.WriteCost(CostRecord{Route: "chat", Backend: "gpt", UserID: "alice", Cost: 0.25})
+	sink.WriteCost(CostRecord{Route: "embed", Backend: "ada", Cost: 0.01})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per record, got %q", buf.String())
+	}
+	var record CostRecord
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("expected a JSON line: %v", err)
+	}
+	if record.Route != "chat" || record.UserID != "alice" || record.Cost != 0.25 {
+		t.Errorf("unexpected decoded record: %+v", record)
+	}
+}
+
+func TestServer_CostRecordAttributedToUser(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"usage":{"prompt_tokens":10,"completion_tokens":5}}`))
+	}))
+	defer upstream.Close()
+
+	store := cache.NewStore()
+	backend := newExternalTestBackend("gpt", upstream.URL)
+	backend.Spec.Cost = &gatewayv1alpha1.CostConfig{InputTokenCost: "0.001", OutputTokenCost: "0.002"}
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "gpt"}, backend)
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			DefaultBackend: &gatewayv1alpha1.BackendRef{Name: "gpt"},
+			CostTracking:   true,
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	sink := &capturingCostSink{}
+	costTracker := NewCostTracker(nil)
+	costTracker.SetCostSink(sink)
+	server := NewServer(DefaultConfig(), store, nil, zap.New(),
+		WithCostTracker(costTracker),
+		WithIdentityExtractor(NewIdentityExtractor(IdentitySource{Type: IdentitySourceHeader, Name: "X-User-ID"})),
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("X-User-ID", "alice")
+	server.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(sink.records) != 1 {
+		t.Fatalf("expected one cost record, got %d", len(sink.records))
+	}
+	if got := sink.records[0]; got.UserID != "alice" || got.InputTokens != 10 || got.OutputTokens != 5 {
+		t.Errorf("expected a record for alice's tokens, got %+v", got)
+	}
+}
+
+func TestCostTracker_UserIDHashesCredentials(t *testing.T) {
+	ct := NewCostTracker(nil)
+	ct.SetIdentityExtractor(NewIdentityExtractor(
+		IdentitySource{Type: IdentitySourceHeader, Name: DefaultUserIDHeader},
+		IdentitySource{Type: IdentitySourceHeader, Name: "Authorization"},
+	))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("Authorization", "Bearer sk-secret")
+	if got := ct.UserID(req); got != "" {
+		t.Errorf("expected no user without a cost sink, got %q", got)
+	}
+
+	ct.SetCostSink(&capturingCostSink{})
+	user := ct.UserID(req)
+	if user != hashIdentity("Bearer sk-secret") || strings.Contains(user, "sk-secret") {
+		t.Errorf("expected the shared hashed identity for the Authorization header, got %q", user)
+	}
+	if ct.UserID(req) != user {
+		t.Error("expected the hashed identity to be stable")
+	}
+
+	req.Header.Set(DefaultUserIDHeader, "alice")
+	if got := ct.UserID(req); got != "alice" {
+		t.Errorf("expected the user header to be used as is, got %q", got)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+	"strings"
+)
+
+// DefaultCostTagHeader carries the team or project a request's cost is
+// attributed to
+const DefaultCostTagHeader = "X-Cost-Tag"
+
+// overflowCostTag collects the cost of new tags once MaxTags is reached
+const overflowCostTag = "other"
+
+// CostTagConfig configures aggregating cost by a tag the client sends
+type CostTagConfig struct {
+	// Header carries the cost tag
+	Header string
+
+	// MaxTags bounds the number of distinct tags aggregated and used as
+	// metric labels. Requests with new tags beyond the limit are attributed
+	// to "other".
+	MaxTags int
+}
+
+// DefaultCostTagConfig returns sensible defaults for cost tags
+func DefaultCostTagConfig() CostTagConfig {
+	return CostTagConfig{
+		Header:  DefaultCostTagHeader,
+		MaxTags: 1000,
+	}
+}
+
+// EnableCostTags aggregates cost per tag, read from a request header, and
+// adds the tag as a label on cost metrics, so teams or projects sharing a
+// route can be charged separately
+func (c *CostTracker) EnableCostTags(cfg CostTagConfig) {
+	defaults := DefaultCostTagConfig()
+	if cfg.Header == "" {
+		cfg.Header = defaults.Header
+	}
+	if cfg.MaxTags <= 0 {
+		cfg.MaxTags = defaults.MaxTags
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.costTags = &cfg
+	c.tagCosts = make(map[string]*CostStats)
+}
+
+// CostTag returns the request's cost tag, or an empty string if it has none
+// or cost tags are disabled
+func (c *CostTracker) CostTag(req *http.Request) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.costTags == nil || req == nil {
+		return ""
+	}
+	return strings.TrimSpace(req.Header.Get(c.costTags.Header))
+}
+
+// GetTagCosts returns the cost statistics of a tag
+func (c *CostTracker) GetTagCosts(tag string) *CostStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stats, exists := c.tagCosts[tag]
+	if !exists {
+		return nil
+	}
+	copied := *stats
+	return &copied
+}
+
+// costTagLocked returns the tag a request's cost is aggregated under: the
+// tag itself, "other" once MaxTags distinct tags are tracked, or an empty
+// string when cost tags are disabled. The caller must hold c.mu.
+func (c *CostTracker) costTagLocked(tag string) string {
+	if c.costTags == nil || tag == "" {
+		return ""
+	}
+	if _, ok := c.tagCosts[tag]; ok || len(c.tagCosts) < c.costTags.MaxTags {
+		return tag
+	}
+	return overflowCostTag
+}
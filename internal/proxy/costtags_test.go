@@ -0,0 +1,128 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// trackTaggedRequest tracks the cost of one response to a request with the
+// cost tag
+func trackTaggedRequest(handler *BackendHandler, backend *gatewayv1alpha1.InferenceBackend, header, tag string) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	if tag != "" {
+		req.Header.Set(header, tag)
+	}
+	resp := &http.Response{
+		Header:  http.Header{"Content-Type": []string{"application/json"}},
+		Body:    io.NopCloser(strings.NewReader(`{"usage":{"prompt_tokens":1000,"completion_tokens":500}}`)),
+		Request: req,
+	}
+	handler.trackCosts(resp, "tagged-route", backend, "openai")
+}
+
+func TestCostTracker_TagCosts(t *testing.T) {
+	backend := &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "tagged-backend"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			External: &gatewayv1alpha1.ExternalBackend{Provider: "openai"},
+			Cost:     &gatewayv1alpha1.CostConfig{InputTokenCost: "0.01", OutputTokenCost: "0.02"},
+		},
+	}
+	costTracker := NewCostTracker(NewMetricsRecorder())
+	costTracker.EnableCostTags(CostTagConfig{Header: "X-Team"})
+	handler := NewBackendHandler(cache.NewStore(), nil, zap.New(), nil, costTracker, nil)
+
+	trackTaggedRequest(handler, backend, "X-Team", "search")
+	trackTaggedRequest(handler, backend, "X-Team", "search")
+	trackTaggedRequest(handler, backend, "X-Team", "billing")
+	trackTaggedRequest(handler, backend, "X-Team", "")
+	trackTaggedRequest(handler, backend, DefaultCostTagHeader, "ignored")
+
+	search := costTracker.GetTagCosts("search")
+	if search == nil || search.TotalRequests != 2 || search.TotalInputTokens != 2000 {
+		t.Fatalf("expected 2 requests and 2000 input tokens for search, got %+v", search)
+	}
+	if want := 2 * (0.01 + 0.01); math.Abs(search.TotalCost-want) > 1e-9 {
+		t.Errorf("expected search cost %v, got %v", want, search.TotalCost)
+	}
+	if billing := costTracker.GetTagCosts("billing"); billing == nil || billing.TotalRequests != 1 {
+		t.Errorf("expected billing to be tracked separately, got %+v", billing)
+	}
+	if ignored := costTracker.GetTagCosts("ignored"); ignored != nil {
+		t.Errorf("expected only the configured header to be used, got %+v", ignored)
+	}
+	if route := costTracker.GetRouteCosts("tagged-route"); route.TotalRequests != 5 {
+		t.Errorf("expected route totals to include every request, got %d", route.TotalRequests)
+	}
+
+	if got := testutil.ToFloat64(CostTotal.WithLabelValues("tagged-route", "tagged-backend", "USD", "search")); math.Abs(got-0.04) > 1e-9 {
+		t.Errorf("expected 0.04 on the search series, got %v", got)
+	}
+	if got := testutil.ToFloat64(CostTotal.WithLabelValues("tagged-route", "tagged-backend", "USD", "")); math.Abs(got-0.04) > 1e-9 {
+		t.Errorf("expected untagged requests on a series without a tag, got %v", got)
+	}
+}
+
+func TestCostTracker_TagCostsDisabled(t *testing.T) {
+	costTracker := NewCostTracker(nil)
+	config := &gatewayv1alpha1.CostConfig{RequestCost: "0.01"}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set(DefaultCostTagHeader, "search")
+	if tag := costTracker.CostTag(req); tag != "" {
+		t.Errorf("expected no cost tag while disabled, got %q", tag)
+	}
+
+	costTracker.TrackRequest("chat", "gpt", "", "", "search", TokenUsage{}, config)
+	if stats := costTracker.GetTagCosts("search"); stats != nil {
+		t.Errorf("expected no tag costs while disabled, got %+v", stats)
+	}
+}
+
+func TestCostTracker_TagCostsOverflow(t *testing.T) {
+	costTracker := NewCostTracker(nil)
+	costTracker.EnableCostTags(CostTagConfig{MaxTags: 2})
+	config := &gatewayv1alpha1.CostConfig{RequestCost: "0.01"}
+
+	for _, tag := range []string{"a", "b", "c", "d", "a"} {
+		costTracker.TrackRequest("chat", "gpt", "", "", tag, TokenUsage{}, config)
+	}
+
+	if a := costTracker.GetTagCosts("a"); a == nil || a.TotalRequests != 2 {
+		t.Errorf("expected known tags to keep aggregating, got %+v", a)
+	}
+	if c := costTracker.GetTagCosts("c"); c != nil {
+		t.Errorf("expected tags beyond the limit not to be tracked, got %+v", c)
+	}
+	if other := costTracker.GetTagCosts(overflowCostTag); other == nil || other.TotalRequests != 2 {
+		t.Errorf("expected tags beyond the limit to be attributed to %q, got %+v", overflowCostTag, other)
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+)
+
+// endpointBalancer spreads requests round-robin across the pods of backends
+// that route directly to their Service's endpoints
+type endpointBalancer struct {
+	cursors sync.Map // types.NamespacedName -> *atomic.Uint64
+}
+
+// next returns the next of the addresses for the backend
+func (b *endpointBalancer) next(key types.NamespacedName, addresses []string) string {
+	cursor, _ := b.cursors.LoadOrStore(key, new(atomic.Uint64))
+	n := cursor.(*atomic.Uint64).Add(1) - 1
+	return addresses[n%uint64(len(addresses))]
+}
+
+// directEndpoint returns the pod address (host:port) to send a request for a
+// Kubernetes backend with DirectEndpoints, or false when the backend should
+// be reached through its Service
+func (h *BackendHandler) directEndpoint(backend *gatewayv1alpha1.InferenceBackend) (string, bool) {
+	if backend.Spec.Kubernetes == nil || !backend.Spec.Kubernetes.DirectEndpoints || h.cache == nil {
+		return "", false
+	}
+	key := types.NamespacedName{Namespace: backend.Namespace, Name: backend.Name}
+	addresses := h.cache.GetEndpoints(key)
+	if len(addresses) == 0 {
+		return "", false
+	}
+	return h.endpoints.next(key, addresses), true
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// newDirectEndpointsBackend creates a healthy Kubernetes backend that routes
+// to its Service's endpoints
+func newDirectEndpointsBackend(name string) *gatewayv1alpha1.InferenceBackend {
+	return &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type: gatewayv1alpha1.BackendTypeKubernetes,
+			Kubernetes: &gatewayv1alpha1.KubernetesBackend{
+				ServiceName:     "vllm",
+				Port:            8080,
+				DirectEndpoints: true,
+			},
+		},
+		Status: gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+	}
+}
+
+func TestRouter_HandleRequest_DirectEndpoints(t *testing.T) {
+	store := cache.NewStore()
+	key := types.NamespacedName{Namespace: "default", Name: "vllm"}
+	store.SetBackend(key, newDirectEndpointsBackend("vllm"))
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			DefaultBackend: &gatewayv1alpha1.BackendRef{Name: "vllm"},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	// Each pod of the Service is a separate server
+	served := make(map[string]int)
+	var pods []string
+	for _, pod := range []string{"pod-a", "pod-b", "pod-c"} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			served[pod]++
+		}))
+		defer server.Close()
+		u, _ := url.Parse(server.URL)
+		pods = append(pods, u.Host)
+	}
+	store.SetEndpoints(key, pods)
+
+	router := NewRouter(store, nil, zap.New())
+	for i := 0; i < 6; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		rec := httptest.NewRecorder()
+		router.HandleRequest(req.Context(), rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+	}
+
+	for _, pod := range []string{"pod-a", "pod-b", "pod-c"} {
+		if served[pod] != 2 {
+			t.Errorf("expected requests to be spread evenly across the pods, got %v", served)
+			break
+		}
+	}
+}
+
+func TestBackendHandler_buildTargetURL_DirectEndpointsFallback(t *testing.T) {
+	store := cache.NewStore()
+	handler := NewBackendHandler(store, nil, zap.New(), nil, nil, nil)
+	backend := newDirectEndpointsBackend("vllm")
+
+	// Without known endpoints, requests go through the Service
+	target, err := handler.buildTargetURL(backend)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "http://vllm.default.svc.cluster.local:8080"; target.String() != want {
+		t.Errorf("expected '%s', got '%s'", want, target.String())
+	}
+
+	store.SetEndpoints(types.NamespacedName{Namespace: "default", Name: "vllm"}, []string{"10.0.0.1:8000"})
+	target, err = handler.buildTargetURL(backend)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "http://10.0.0.1:8000"; target.String() != want {
+		t.Errorf("expected '%s', got '%s'", want, target.String())
+	}
+
+	// Backends without DirectEndpoints ignore cached endpoints
+	backend.Spec.Kubernetes.DirectEndpoints = false
+	if target, _ = handler.buildTargetURL(backend); target.Host != "vllm.default.svc.cluster.local:8080" {
+		t.Errorf("expected the Service address, got '%s'", target.Host)
+	}
+}
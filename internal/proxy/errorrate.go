@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// MaxErrorRateWindow is the longest window a backend's error rate can be
+	// measured over
+	MaxErrorRateWindow = time.Hour
+
+	// errorRateBucketWidth is the resolution of the error rate window
+	errorRateBucketWidth = 10 * time.Second
+
+	// errorRateBuckets is the number of buckets kept per backend
+	errorRateBuckets = int(MaxErrorRateWindow / errorRateBucketWidth)
+)
+
+// errorRateTracker keeps the recent request and failure counts of each
+// backend, by namespace and name, so that their error rate can be measured
+// over any window up to MaxErrorRateWindow. The counts only cover the
+// requests served by this process.
+type errorRateTracker struct {
+	mu       sync.Mutex
+	backends map[types.NamespacedName]*[errorRateBuckets]sloBucket
+	now      func() time.Time
+}
+
+// newErrorRateTracker creates an empty tracker
+func newErrorRateTracker() *errorRateTracker {
+	return &errorRateTracker{
+		backends: make(map[types.NamespacedName]*[errorRateBuckets]sloBucket),
+		now:      time.Now,
+	}
+}
+
+// record adds a completed request to the backend's counts. Requests without
+// a response or with a 5xx status count as failed.
+func (t *errorRateTracker) record(backend types.NamespacedName, statusCode int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	buckets, ok := t.backends[backend]
+	if !ok {
+		buckets = new([errorRateBuckets]sloBucket)
+		t.backends[backend] = buckets
+	}
+	now := t.now()
+	start := now.Truncate(errorRateBucketWidth)
+	bucket := &buckets[(now.UnixNano()/int64(errorRateBucketWidth))%int64(errorRateBuckets)]
+	if !bucket.start.Equal(start) {
+		*bucket = sloBucket{start: start}
+	}
+	bucket.total++
+	if statusCode == 0 || statusCode >= http.StatusInternalServerError {
+		bucket.failed++
+	}
+}
+
+// errorRate returns the share of the backend's requests that failed within
+// the window, and the number of requests it's based on
+func (t *errorRateTracker) errorRate(backend types.NamespacedName, window time.Duration) (float64, int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	buckets, ok := t.backends[backend]
+	if !ok {
+		return 0, 0
+	}
+	if window > MaxErrorRateWindow {
+		window = MaxErrorRateWindow
+	}
+	cutoff := t.now().Add(-window)
+	var total, failed int64
+	for _, bucket := range buckets {
+		if bucket.start.After(cutoff) {
+			total += bucket.total
+			failed += bucket.failed
+		}
+	}
+	if total == 0 {
+		return 0, 0
+	}
+	return float64(failed) / float64(total), total
+}
+
+// delete forgets the backend
+func (t *errorRateTracker) delete(backend types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.backends, backend)
+}
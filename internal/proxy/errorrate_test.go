@@ -0,0 +1,93 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestMetricsRecorder_BackendErrorRate(t *testing.T) {
+	m := NewMetricsRecorder()
+	for i := 0; i < 3; i++ {
+		m.RecordBackendStatus("default", "canary", 200)
+	}
+	m.RecordBackendStatus("default", "canary", 502)
+	m.RecordBackendStatus("default", "stable", 200)
+
+	rate, requests := m.BackendErrorRate("default", "canary", time.Minute)
+	if rate != 0.25 || requests != 4 {
+		t.Errorf("expected an error rate of 0.25 over 4 requests, got %v over %d", rate, requests)
+	}
+	if rate, _ := m.BackendErrorRate("default", "stable", time.Minute); rate != 0 {
+		t.Errorf("expected no errors for the stable backend, got %v", rate)
+	}
+
+	m.DeleteBackendMetrics("canary", "default")
+	if _, requests := m.BackendErrorRate("default", "canary", time.Minute); requests != 0 {
+		t.Errorf("expected the deleted backend to have no requests, got %d", requests)
+	}
+}
+
+func TestMetricsRecorder_BackendErrorRate_Namespaced(t *testing.T) {
+	m := NewMetricsRecorder()
+	m.RecordBackendStatus("team-a", "canary", 500)
+	m.RecordBackendStatus("team-b", "canary", 200)
+
+	// Backends with the same name in different namespaces don't share a window
+	if rate, requests := m.BackendErrorRate("team-a", "canary", time.Minute); rate != 1 || requests != 1 {
+		t.Errorf("expected team-a's canary to fail 1 of 1 requests, got %v over %d", rate, requests)
+	}
+	if rate, requests := m.BackendErrorRate("team-b", "canary", time.Minute); rate != 0 || requests != 1 {
+		t.Errorf("expected team-b's canary to succeed 1 of 1 requests, got %v over %d", rate, requests)
+	}
+
+	m.DeleteBackendMetrics("canary", "team-a")
+	if _, requests := m.BackendErrorRate("team-b", "canary", time.Minute); requests != 1 {
+		t.Errorf("expected deleting team-a's canary to keep team-b's requests, got %d", requests)
+	}
+}
+
+func TestErrorRateTracker_Window(t *testing.T) {
+	tracker := newErrorRateTracker()
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+	canary := types.NamespacedName{Namespace: "default", Name: "canary"}
+
+	tracker.record(canary, 500)
+	tracker.record(canary, 0)
+	now = now.Add(2 * time.Minute)
+	tracker.record(canary, 200)
+
+	if rate, requests := tracker.errorRate(canary, 5*time.Minute); math.Abs(rate-2.0/3) > 1e-9 || requests != 3 {
+		t.Errorf("expected an error rate of 2/3 over 3 requests, got %v over %d", rate, requests)
+	}
+
+	// A shorter window only sees the recent success
+	if rate, requests := tracker.errorRate(canary, time.Minute); rate != 0 || requests != 1 {
+		t.Errorf("expected only the success in the window, got %v over %d", rate, requests)
+	}
+
+	// Requests older than the longest window are forgotten
+	now = now.Add(MaxErrorRateWindow)
+	if _, requests := tracker.errorRate(canary, 2*MaxErrorRateWindow); requests != 0 {
+		t.Errorf("expected no requests once the window has passed, got %d", requests)
+	}
+}
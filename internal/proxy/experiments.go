@@ -19,6 +19,7 @@ package proxy
 import (
 	"hash/fnv"
 	"net/http"
+	"strings"
 
 	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
 )
@@ -31,6 +32,9 @@ const (
 
 	// DefaultUserIDHeader is the default header for user identification
 	DefaultUserIDHeader = "X-User-ID"
+
+	// ForceVariantHeader lets allowed users pick their experiment variant
+	ForceVariantHeader = "X-Force-Variant"
 )
 
 // ExperimentResult contains the result of experiment assignment
@@ -38,17 +42,43 @@ type ExperimentResult struct {
 	Backend    string
 	Variant    string
 	Experiment string
+
+	// Forced is set when the variant came from the X-Force-Variant header
+	// rather than the user's hash bucket
+	Forced bool
 }
 
 // ExperimentManager handles A/B testing experiment assignment
 type ExperimentManager struct {
-	metrics *MetricsRecorder
+	metrics  *MetricsRecorder
+	identity *IdentityExtractor
+
+	// forceVariantUsers may force their variant with X-Force-Variant
+	forceVariantUsers map[string]bool
 }
 
 // NewExperimentManager creates a new experiment manager
 func NewExperimentManager(metrics *MetricsRecorder) *ExperimentManager {
 	return &ExperimentManager{
-		metrics: metrics,
+		metrics:  metrics,
+		identity: NewIdentityExtractor(DefaultIdentitySources()...),
+	}
+}
+
+// SetIdentityExtractor sets the extractor used to identify users for assignment
+func (e *ExperimentManager) SetIdentityExtractor(identity *IdentityExtractor) {
+	e.identity = identity
+}
+
+// SetForceVariantUsers sets the user identities allowed to force their
+// variant with the X-Force-Variant header, e.g. QA accounts. Forcing is
+// disabled when no users are allowed.
+func (e *ExperimentManager) SetForceVariantUsers(users []string) {
+	e.forceVariantUsers = make(map[string]bool, len(users))
+	for _, user := range users {
+		if user != "" {
+			e.forceVariantUsers[user] = true
+		}
 	}
 }
 
@@ -62,11 +92,35 @@ func (e *ExperimentManager) GetBackend(
 		return ExperimentResult{}
 	}
 
+	// A disabled experiment sends everyone to control, even forced users,
+	// and isn't recorded
+	if !experiment.IsEnabled() {
+		return ExperimentResult{
+			Backend:    experiment.Control,
+			Variant:    VariantControl,
+			Experiment: experiment.Name,
+		}
+	}
+
+	// Forced assignments aren't recorded, so they don't skew the results
+	if variant, ok := e.forcedVariant(req); ok {
+		backend := experiment.Control
+		if variant == VariantTreatment {
+			backend = experiment.Treatment
+		}
+		return ExperimentResult{
+			Backend:    backend,
+			Variant:    variant,
+			Experiment: experiment.Name,
+			Forced:     true,
+		}
+	}
+
 	// Get user identifier for consistent hashing
 	userID := e.getUserID(req)
 
 	// Calculate bucket using consistent hash
-	bucket := e.calculateBucket(userID, experiment.Name)
+	bucket := e.calculateBucket(userID, experiment.Name, experiment.Salt)
 
 	// Determine variant based on traffic percentage
 	trafficPercent := experiment.TrafficPercent
@@ -106,31 +160,35 @@ func (e *ExperimentManager) ShouldApplyExperiment(
 	return selectedBackend == experiment.Control || selectedBackend == experiment.Treatment
 }
 
-// getUserID extracts the user identifier from the request
-func (e *ExperimentManager) getUserID(req *http.Request) string {
-	// Try X-User-ID header first
-	userID := req.Header.Get(DefaultUserIDHeader)
-	if userID != "" {
-		return userID
+// forcedVariant returns the variant named by the X-Force-Variant header, if
+// the request's user is allowed to force one
+func (e *ExperimentManager) forcedVariant(req *http.Request) (string, bool) {
+	variant := strings.ToLower(strings.TrimSpace(req.Header.Get(ForceVariantHeader)))
+	if variant != VariantControl && variant != VariantTreatment {
+		return "", false
 	}
-
-	// Try Authorization header (for API keys)
-	auth := req.Header.Get("Authorization")
-	if auth != "" {
-		return auth
+	if !e.forceVariantUsers[e.getUserID(req)] {
+		return "", false
 	}
+	return variant, true
+}
 
-	// Fall back to client IP for anonymous users
-	// This provides some consistency for users without explicit ID
-	return req.RemoteAddr
+// getUserID extracts the user identifier from the request
+func (e *ExperimentManager) getUserID(req *http.Request) string {
+	return e.identity.Extract(req)
 }
 
-// calculateBucket computes a consistent hash bucket (0-99) for a user and experiment
-func (e *ExperimentManager) calculateBucket(userID, experimentName string) int {
+// calculateBucket computes a consistent hash bucket (0-99) for a user and
+// experiment. An empty salt keeps the assignments of unsalted experiments.
+func (e *ExperimentManager) calculateBucket(userID, experimentName, salt string) int {
 	h := fnv.New32a()
 	// Combine user ID and experiment name for the hash
 	// This ensures different experiments can have different assignments for the same user
-	_, _ = h.Write([]byte(userID + ":" + experimentName))
+	key := userID + ":" + experimentName
+	if salt != "" {
+		key += ":" + salt
+	}
+	_, _ = h.Write([]byte(key))
 	return int(h.Sum32() % 100)
 }
 
@@ -17,9 +17,13 @@ limitations under the License.
 package proxy
 
 import (
+	"fmt"
 	"net/http/httptest"
+	"slices"
 	"testing"
 
+	"k8s.io/utils/ptr"
+
 	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
 )
 
@@ -65,6 +69,39 @@ func TestExperimentManager_GetBackend_ConsistentAssignment(t *testing.T) {
 	}
 }
 
+func TestExperimentManager_GetBackend_Salt(t *testing.T) {
+	em := NewExperimentManager(nil)
+	experiment := &gatewayv1alpha1.ABExperiment{
+		Name:           "salted",
+		Control:        "control-backend",
+		Treatment:      "treatment-backend",
+		TrafficPercent: 50,
+	}
+
+	assign := func(salt string) []string {
+		experiment.Salt = salt
+		variants := make([]string, 100)
+		for i := range variants {
+			req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+			req.Header.Set("X-User-ID", fmt.Sprintf("user-%d", i))
+			variants[i] = em.GetBackend(experiment, req).Variant
+		}
+		return variants
+	}
+
+	unsalted := assign("")
+	first := assign("run-2")
+	if !slices.Equal(first, assign("run-2")) {
+		t.Error("expected assignments to be consistent for the same salt")
+	}
+	if slices.Equal(first, unsalted) {
+		t.Error("expected a salt to change assignments")
+	}
+	if slices.Equal(first, assign("run-3")) {
+		t.Error("expected a different salt to change assignments")
+	}
+}
+
 func TestExperimentManager_GetBackend_TrafficSplit(t *testing.T) {
 	em := NewExperimentManager(nil)
 	experiment := &gatewayv1alpha1.ABExperiment{
@@ -131,6 +168,37 @@ func TestExperimentManager_GetBackend_DefaultTrafficPercent(t *testing.T) {
 	}
 }
 
+func TestExperimentManager_GetBackend_Disabled(t *testing.T) {
+	em := NewExperimentManager(nil)
+	em.SetForceVariantUsers([]string{"qa-user"})
+	experiment := &gatewayv1alpha1.ABExperiment{
+		Name:           "killed",
+		Control:        "control-backend",
+		Treatment:      "treatment-backend",
+		TrafficPercent: 100,
+		Enabled:        ptr.To(false),
+	}
+
+	for _, user := range []string{"user-1", "user-2", "qa-user"} {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		req.Header.Set("X-User-ID", user)
+		req.Header.Set(ForceVariantHeader, VariantTreatment)
+
+		result := em.GetBackend(experiment, req)
+		if result.Backend != "control-backend" || result.Variant != VariantControl {
+			t.Errorf("expected %s to get control from a disabled experiment, got %s (%s)", user, result.Backend, result.Variant)
+		}
+	}
+
+	// Re-enabling the experiment restores the split
+	experiment.Enabled = ptr.To(true)
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	req.Header.Set("X-User-ID", "user-1")
+	if result := em.GetBackend(experiment, req); result.Backend != "treatment-backend" {
+		t.Errorf("expected an enabled experiment at 100%% to send treatment, got %s", result.Backend)
+	}
+}
+
 func TestExperimentManager_ShouldApplyExperiment(t *testing.T) {
 	em := NewExperimentManager(nil)
 	experiment := &gatewayv1alpha1.ABExperiment{
@@ -299,8 +367,8 @@ func TestExperimentManager_GetUserID_Fallbacks(t *testing.T) {
 		expectedMatch string
 	}{
 		{"uses X-User-ID", "user123", "", "1.2.3.4:1234", "user123"},
-		{"falls back to Authorization", "", "Bearer token123", "1.2.3.4:1234", "Bearer token123"},
-		{"falls back to RemoteAddr", "", "", "1.2.3.4:1234", "1.2.3.4:1234"},
+		{"ignores Authorization", "", "Bearer token123", "1.2.3.4:1234", "1.2.3.4"},
+		{"falls back to remote IP", "", "", "1.2.3.4:1234", "1.2.3.4"},
 	}
 
 	for _, tt := range tests {
@@ -321,3 +389,72 @@ func TestExperimentManager_GetUserID_Fallbacks(t *testing.T) {
 		})
 	}
 }
+
+func TestExperimentManager_GetBackend_ForcedVariant(t *testing.T) {
+	em := NewExperimentManager(nil)
+	em.SetForceVariantUsers([]string{"qa-user"})
+	experiment := &gatewayv1alpha1.ABExperiment{
+		Name:           "forced",
+		Control:        "control-backend",
+		Treatment:      "treatment-backend",
+		TrafficPercent: 0,
+	}
+
+	for _, variant := range []string{VariantTreatment, VariantControl} {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		req.Header.Set("X-User-ID", "qa-user")
+		req.Header.Set(ForceVariantHeader, variant)
+
+		result := em.GetBackend(experiment, req)
+		if result.Variant != variant || !result.Forced {
+			t.Errorf("expected forced variant %s, got %+v", variant, result)
+		}
+		if want := variant + "-backend"; result.Backend != want {
+			t.Errorf("expected backend %s, got %s", want, result.Backend)
+		}
+	}
+}
+
+func TestExperimentManager_GetBackend_ForcedVariantGated(t *testing.T) {
+	experiment := &gatewayv1alpha1.ABExperiment{
+		Name:           "gated",
+		Control:        "control-backend",
+		Treatment:      "treatment-backend",
+		TrafficPercent: 1,
+	}
+
+	tests := []struct {
+		name    string
+		allowed []string
+		user    string
+		variant string
+	}{
+		{"forcing disabled", nil, "qa-user", VariantTreatment},
+		{"user not allowed", []string{"qa-user"}, "someone-else", VariantTreatment},
+		{"unknown variant", []string{"qa-user"}, "qa-user", "experimental"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			em := NewExperimentManager(nil)
+			em.SetForceVariantUsers(tt.allowed)
+
+			req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+			req.Header.Set("X-User-ID", tt.user)
+			req.Header.Set(ForceVariantHeader, tt.variant)
+
+			result := em.GetBackend(experiment, req)
+			if result.Forced {
+				t.Errorf("expected the header to be ignored, got %+v", result)
+			}
+			// Without forcing, the user gets their hash bucket's variant
+			expected := VariantControl
+			if em.calculateBucket(tt.user, experiment.Name, experiment.Salt) < 1 {
+				expected = VariantTreatment
+			}
+			if result.Variant != expected {
+				t.Errorf("expected hashed variant %s, got %s", expected, result.Variant)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultHealthCacheTTL is how long a backend stays deprioritized after a failure
+const DefaultHealthCacheTTL = 10 * time.Second
+
+// HealthCache remembers backends that recently failed a request. Backend
+// health in status is only refreshed on reconcile, so the router consults
+// this cache to steer traffic away from a backend as soon as it fails.
+type HealthCache struct {
+	mu       sync.RWMutex
+	ttl      time.Duration
+	failures map[string]time.Time
+	now      func() time.Time
+}
+
+// NewHealthCache creates a cache that remembers failures for ttl
+func NewHealthCache(ttl time.Duration) *HealthCache {
+	if ttl <= 0 {
+		ttl = DefaultHealthCacheTTL
+	}
+	return &HealthCache{
+		ttl:      ttl,
+		failures: make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// MarkFailed records a request failure for the backend
+func (c *HealthCache) MarkFailed(backend string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures[backend] = c.now()
+}
+
+// RecentlyFailed reports whether the backend failed within the cache TTL
+func (c *HealthCache) RecentlyFailed(backend string) bool {
+	c.mu.RLock()
+	failedAt, ok := c.failures[backend]
+	c.mu.RUnlock()
+	if !ok {
+		return false
+	}
+
+	if c.now().Sub(failedAt) < c.ttl {
+		return true
+	}
+
+	// Expired, drop the entry
+	c.mu.Lock()
+	if failedAt.Equal(c.failures[backend]) {
+		delete(c.failures, backend)
+	}
+	c.mu.Unlock()
+	return false
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthCache_RecentlyFailed(t *testing.T) {
+	now := time.Now()
+	hc := NewHealthCache(5 * time.Second)
+	hc.now = func() time.Time { return now }
+
+	if hc.RecentlyFailed("backend-a") {
+		t.Error("expected unknown backend to not be recently failed")
+	}
+
+	hc.MarkFailed("backend-a")
+	if !hc.RecentlyFailed("backend-a") {
+		t.Error("expected backend-a to be recently failed")
+	}
+	if hc.RecentlyFailed("backend-b") {
+		t.Error("expected backend-b to be unaffected")
+	}
+
+	now = now.Add(5 * time.Second)
+	if hc.RecentlyFailed("backend-a") {
+		t.Error("expected failure to expire after the TTL")
+	}
+	if _, ok := hc.failures["backend-a"]; ok {
+		t.Error("expected expired entry to be removed")
+	}
+}
+
+func TestNewHealthCache_DefaultTTL(t *testing.T) {
+	hc := NewHealthCache(0)
+	if hc.ttl != DefaultHealthCacheTTL {
+		t.Errorf("expected default TTL %v, got %v", DefaultHealthCacheTTL, hc.ttl)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+// sseHeartbeat is sent while a streaming backend is silent. Clients ignore SSE
+// comment lines, so it keeps the connection busy without adding an event.
+var sseHeartbeat = []byte(": keep-alive\n\n")
+
+// heartbeatBody wraps a streaming response body and emits a heartbeat comment
+// whenever the backend has sent nothing for the interval. Heartbeats are only
+// sent between events, never in the middle of one.
+type heartbeatBody struct {
+	body     io.ReadCloser
+	interval time.Duration
+	chunks   chan heartbeatChunk
+	done     chan struct{}
+	once     sync.Once
+
+	pending []byte
+	err     error
+
+	// newlines counts the newlines at the end of what has been sent so far;
+	// an event is complete after a blank line
+	newlines int
+	started  bool
+}
+
+// heartbeatChunk is the result of one read from the backend
+type heartbeatChunk struct {
+	data []byte
+	err  error
+}
+
+// newHeartbeatBody wraps a streaming response body
+func newHeartbeatBody(body io.ReadCloser, interval time.Duration) *heartbeatBody {
+	b := &heartbeatBody{
+		body:     body,
+		interval: interval,
+		chunks:   make(chan heartbeatChunk),
+		done:     make(chan struct{}),
+	}
+	go b.readLoop()
+	return b
+}
+
+// readLoop reads from the backend so that Read can wait for data and the
+// heartbeat interval at the same time
+func (b *heartbeatBody) readLoop() {
+	for {
+		buf := make([]byte, 32*1024)
+		n, err := b.body.Read(buf)
+		select {
+		case b.chunks <- heartbeatChunk{data: buf[:n], err: err}:
+		case <-b.done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (b *heartbeatBody) Read(p []byte) (int, error) {
+	if len(b.pending) == 0 && b.err == nil {
+		b.wait()
+	}
+	if len(b.pending) > 0 {
+		n := copy(p, b.pending)
+		b.pending = b.pending[n:]
+		b.track(p[:n])
+		return n, nil
+	}
+	return 0, b.err
+}
+
+// wait blocks until the backend sends data or a heartbeat is due
+func (b *heartbeatBody) wait() {
+	timer := time.NewTimer(b.interval)
+	defer timer.Stop()
+	for {
+		select {
+		case chunk := <-b.chunks:
+			b.pending, b.err = chunk.data, chunk.err
+			return
+		case <-timer.C:
+			if b.betweenEvents() {
+				b.pending = sseHeartbeat
+				return
+			}
+			// A heartbeat now would corrupt the partial event
+			timer.Reset(b.interval)
+		}
+	}
+}
+
+// betweenEvents reports whether everything sent so far ends on an event boundary
+func (b *heartbeatBody) betweenEvents() bool {
+	return !b.started || b.newlines >= 2
+}
+
+// track records how the data sent to the client ends
+func (b *heartbeatBody) track(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	b.started = true
+	trimmed := bytes.TrimRight(data, "\r\n")
+	newlines := bytes.Count(data[len(trimmed):], []byte("\n"))
+	if len(trimmed) == 0 {
+		b.newlines += newlines
+	} else {
+		b.newlines = newlines
+	}
+}
+
+func (b *heartbeatBody) Close() error {
+	var err error
+	b.once.Do(func() {
+		close(b.done)
+		err = b.body.Close()
+	})
+	return err
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// readStream reads the body in the background and returns a function that
+// waits for the full stream
+func readStream(t *testing.T, body io.Reader) func() string {
+	t.Helper()
+	result := make(chan string, 1)
+	go func() {
+		data, _ := io.ReadAll(body)
+		result <- string(data)
+	}()
+	return func() string {
+		select {
+		case data := <-result:
+			return data
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out reading the stream")
+			return ""
+		}
+	}
+}
+
+func TestHeartbeatBody_InjectsDuringGap(t *testing.T) {
+	upstream, writer := io.Pipe()
+	body := newHeartbeatBody(upstream, 20*time.Millisecond)
+	defer func() { _ = body.Close() }()
+	wait := readStream(t, body)
+
+	_, _ = writer.Write([]byte("data: {\"n\":1}\n\n"))
+	time.Sleep(70 * time.Millisecond)
+	_, _ = writer.Write([]byte("data: {\"n\":2}\n\n"))
+	_ = writer.Close()
+
+	got := wait()
+	events := strings.Split(got, "\n\n")
+	if events[0] != `data: {"n":1}` {
+		t.Errorf("expected the first event first, got %q", got)
+	}
+	if n := strings.Count(got, string(sseHeartbeat)); n < 2 {
+		t.Errorf("expected heartbeats during the gap, got %d in %q", n, got)
+	}
+	if !strings.HasSuffix(got, ": keep-alive\n\ndata: {\"n\":2}\n\n") {
+		t.Errorf("expected the second event after the heartbeats, got %q", got)
+	}
+}
+
+func TestHeartbeatBody_StopsWhileDataFlows(t *testing.T) {
+	upstream, writer := io.Pipe()
+	body := newHeartbeatBody(upstream, 50*time.Millisecond)
+	defer func() { _ = body.Close() }()
+	wait := readStream(t, body)
+
+	for i := 0; i < 20; i++ {
+		_, _ = writer.Write([]byte("data: token\n\n"))
+		time.Sleep(5 * time.Millisecond)
+	}
+	_ = writer.Close()
+
+	if got := wait(); strings.Contains(got, string(sseHeartbeat)) {
+		t.Errorf("expected no heartbeats while data flows, got %q", got)
+	}
+}
+
+func TestHeartbeatBody_NeverSplitsAnEvent(t *testing.T) {
+	upstream, writer := io.Pipe()
+	body := newHeartbeatBody(upstream, 10*time.Millisecond)
+	defer func() { _ = body.Close() }()
+	wait := readStream(t, body)
+
+	_, _ = writer.Write([]byte("data: par"))
+	time.Sleep(50 * time.Millisecond)
+	_, _ = writer.Write([]byte("tial\n"))
+	time.Sleep(50 * time.Millisecond)
+	_, _ = writer.Write([]byte("\n"))
+	_ = writer.Close()
+
+	if got := wait(); got != "data: partial\n\n" {
+		t.Errorf("expected no heartbeat inside the event, got %q", got)
+	}
+}
+
+func TestBackendHandler_StreamHeartbeat(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: first\n\n"))
+		w.(http.Flusher).Flush()
+		time.Sleep(1200 * time.Millisecond)
+		_, _ = w.Write([]byte("data: second\n\n"))
+	}))
+	defer upstream.Close()
+
+	store := cache.NewStore()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "slow"}, newExternalTestBackend("slow", upstream.URL))
+	handler := NewBackendHandler(store, nil, zap.New(), nil, nil, nil)
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec:       gatewayv1alpha1.InferenceRouteSpec{StreamHeartbeatSeconds: 1},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "slow"})
+
+	if got, want := rec.Body.String(), "data: first\n\n: keep-alive\n\ndata: second\n\n"; got != want {
+		t.Errorf("expected a heartbeat between the events, got %q", got)
+	}
+}
@@ -0,0 +1,294 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client's key for deduplicating retries
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set on responses replayed from the cache
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// IdempotencyConfig configures response caching for requests with an
+// Idempotency-Key header
+type IdempotencyConfig struct {
+	// TTL is how long a response is replayed for its key
+	TTL time.Duration
+
+	// MaxEntries bounds the number of cached responses. The oldest entry is
+	// evicted when the cache is full.
+	MaxEntries int
+
+	// MaxResponseSize is the largest response body, in bytes, that is cached.
+	// Larger responses are passed through without being cached.
+	MaxResponseSize int64
+}
+
+// DefaultIdempotencyConfig returns sensible defaults for idempotency keys
+func DefaultIdempotencyConfig() IdempotencyConfig {
+	return IdempotencyConfig{
+		TTL:             10 * time.Minute,
+		MaxEntries:      1000,
+		MaxResponseSize: 1024 * 1024,
+	}
+}
+
+// idempotencyCache stores responses by idempotency key. A request whose key
+// is already in flight waits for the first request to finish and replays its
+// response, so the backend is only called once.
+type idempotencyCache struct {
+	config IdempotencyConfig
+
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+	now     func() time.Time
+}
+
+// idempotencyEntry is a cached response, or a request still in flight
+type idempotencyEntry struct {
+	done     chan struct{}
+	response *cachedResponse
+	storedAt time.Time
+}
+
+// cachedResponse is a complete response that can be replayed
+type cachedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// newIdempotencyCache creates a cache for the given configuration
+func newIdempotencyCache(cfg IdempotencyConfig) *idempotencyCache {
+	defaults := DefaultIdempotencyConfig()
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaults.TTL
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = defaults.MaxEntries
+	}
+	if cfg.MaxResponseSize <= 0 {
+		cfg.MaxResponseSize = defaults.MaxResponseSize
+	}
+	return &idempotencyCache{
+		config:  cfg,
+		entries: make(map[string]*idempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+// idempotencyKey scopes the client's key to the caller and the request, so
+// different users or endpoints never share a response
+func idempotencyKey(req *http.Request, key, identity string) string {
+	sum := sha256.New()
+	for _, part := range []string{
+		identity,
+		req.Header.Get("X-Namespace"),
+		req.Header.Get("X-Route"),
+		req.Method,
+		req.URL.Path,
+		key,
+	} {
+		sum.Write([]byte(part))
+		sum.Write([]byte{0})
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// serve replays the cached response for the key if there is one. Otherwise
+// it calls next with a writer that captures the response for later replays.
+func (c *idempotencyCache) serve(ctx context.Context, w http.ResponseWriter, key string, next func(http.ResponseWriter)) {
+	for {
+		c.mu.Lock()
+		entry, ok := c.entries[key]
+		if ok && entry.response != nil && c.now().Sub(entry.storedAt) >= c.config.TTL {
+			delete(c.entries, key)
+			ok = false
+		}
+		if !ok {
+			entry = &idempotencyEntry{done: make(chan struct{})}
+			c.entries[key] = entry
+			c.mu.Unlock()
+			c.record(w, key, entry, next)
+			return
+		}
+		c.mu.Unlock()
+
+		// Wait for the request in flight with the same key
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			http.Error(w, "Request cancelled", http.StatusServiceUnavailable)
+			return
+		}
+		if entry.response != nil {
+			entry.response.replay(w)
+			return
+		}
+		// The first response couldn't be cached, so this request goes to the
+		// backend itself
+	}
+}
+
+// record serves the request and stores its response if it can be replayed
+func (c *idempotencyCache) record(w http.ResponseWriter, key string, entry *idempotencyEntry, next func(http.ResponseWriter)) {
+	capture := &captureResponseWriter{
+		ResponseWriter: w,
+		limit:          c.config.MaxResponseSize,
+		baseline:       w.Header().Clone(),
+	}
+
+	// Waiters must be released even if the handler panics
+	defer func() {
+		c.mu.Lock()
+		if response := capture.response(); response != nil {
+			entry.response = response
+			entry.storedAt = c.now()
+			c.evictLocked()
+		} else if c.entries[key] == entry {
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+		close(entry.done)
+	}()
+
+	next(capture)
+}
+
+// evictLocked removes expired entries, then the oldest ones while the cache
+// is over its limit. The caller must hold c.mu.
+func (c *idempotencyCache) evictLocked() {
+	now := c.now()
+	for key, entry := range c.entries {
+		if entry.response != nil && now.Sub(entry.storedAt) >= c.config.TTL {
+			delete(c.entries, key)
+		}
+	}
+
+	for len(c.entries) > c.config.MaxEntries {
+		var oldestKey string
+		var oldest time.Time
+		for key, entry := range c.entries {
+			// Requests in flight are never evicted
+			if entry.response == nil {
+				continue
+			}
+			if oldestKey == "" || entry.storedAt.Before(oldest) {
+				oldestKey, oldest = key, entry.storedAt
+			}
+		}
+		if oldestKey == "" {
+			return
+		}
+		delete(c.entries, oldestKey)
+	}
+}
+
+// replay writes the cached response, marked as replayed
+func (r *cachedResponse) replay(w http.ResponseWriter) {
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	r.write(w)
+}
+
+// write writes the cached response
+func (r *cachedResponse) write(w http.ResponseWriter) {
+	header := w.Header()
+	for key, values := range r.header {
+		header[key] = slices.Clone(values)
+	}
+	w.WriteHeader(r.status)
+	_, _ = w.Write(r.body)
+}
+
+// captureResponseWriter copies the response as it is written to the client.
+// Only headers set while handling the request are captured; those already set
+// by the server, such as rate limit headers, belong to each request.
+type captureResponseWriter struct {
+	http.ResponseWriter
+	limit       int64
+	baseline    http.Header
+	status      int
+	header      http.Header
+	body        bytes.Buffer
+	overflowed  bool
+	wroteHeader bool
+}
+
+func (w *captureResponseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = code
+		w.header = make(http.Header)
+		for key, values := range w.Header() {
+			if !slices.Equal(values, w.baseline[key]) {
+				w.header[key] = slices.Clone(values)
+			}
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *captureResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.overflowed {
+		if int64(w.body.Len()+len(b)) > w.limit {
+			w.overflowed = true
+			w.body.Reset()
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush passes streamed data through to the client
+func (w *captureResponseWriter) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying writer so http.ResponseController can reach
+// deadline support
+func (w *captureResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// response returns the captured response if it can be replayed. Server errors
+// aren't cached so that a retry can succeed.
+func (w *captureResponseWriter) response() *cachedResponse {
+	if !w.wroteHeader || w.overflowed || w.status >= http.StatusInternalServerError {
+		return nil
+	}
+	return &cachedResponse{
+		status: w.status,
+		header: w.header,
+		body:   bytes.Clone(w.body.Bytes()),
+	}
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// newIdempotencyTestServer creates a server whose default route proxies to
+// upstream, with idempotency keys enabled
+func newIdempotencyTestServer(t *testing.T, upstream http.HandlerFunc) *Server {
+	t.Helper()
+	backend := httptest.NewServer(upstream)
+	t.Cleanup(backend.Close)
+
+	store := cache.NewStore()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "chat"}, newExternalTestBackend("chat", backend.URL))
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			DefaultBackend: &gatewayv1alpha1.BackendRef{Name: "chat"},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	cfg := DefaultIdempotencyConfig()
+	return NewServer(DefaultConfig(), store, nil, zap.New(), WithIdempotency(&cfg))
+}
+
+// sendWithKey sends a chat completion request with an idempotency key
+func sendWithKey(server *Server, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_IdempotencyKeyReplaysResponse(t *testing.T) {
+	var calls atomic.Int32
+	server := newIdempotencyTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"id":"chatcmpl-%d"}`, n)
+	})
+
+	first := sendWithKey(server, "retry-1")
+	second := sendWithKey(server, "retry-1")
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected the backend to be called once, got %d", got)
+	}
+	if first.Code != http.StatusOK || second.Code != first.Code {
+		t.Errorf("expected identical 200 responses, got %d and %d", first.Code, second.Code)
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("expected identical bodies, got %q and %q", first.Body.String(), second.Body.String())
+	}
+	if got := second.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("expected replayed headers, got content type %q", got)
+	}
+	if first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Error("expected the original response not to be marked as replayed")
+	}
+	if second.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Error("expected the duplicate response to be marked as replayed")
+	}
+
+	// Other keys and requests without a key still reach the backend
+	sendWithKey(server, "retry-2")
+	sendWithKey(server, "")
+	if got := calls.Load(); got != 3 {
+		t.Errorf("expected distinct requests to reach the backend, got %d calls", got)
+	}
+}
+
+func TestServer_IdempotencyKeyConcurrentDuplicates(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := newIdempotencyTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1"}`))
+	})
+
+	// Duplicates arriving while the first request is in flight wait for it
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 5)
+	for i := range responses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i] = sendWithKey(server, "slow")
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected concurrent duplicates to call the backend once, got %d", got)
+	}
+	for i, rec := range responses {
+		if rec.Code != http.StatusOK || rec.Body.String() != `{"id":"chatcmpl-1"}` {
+			t.Errorf("response %d: expected the shared response, got %d %q", i, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestServer_IdempotencyKeyServerErrorNotCached(t *testing.T) {
+	var calls atomic.Int32
+	server := newIdempotencyTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-2"}`))
+	})
+
+	sendWithKey(server, "flaky")
+	retry := sendWithKey(server, "flaky")
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected a retry after a server error to reach the backend, got %d calls", got)
+	}
+	if retry.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Error("expected the retry not to be a replay")
+	}
+}
+
+func TestIdempotencyCache_Expiry(t *testing.T) {
+	c := newIdempotencyCache(IdempotencyConfig{TTL: time.Minute, MaxEntries: 2})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	var calls int
+	serve := func(key string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c.serve(context.Background(), rec, key, func(w http.ResponseWriter) {
+			calls++
+			_, _ = w.Write([]byte("ok"))
+		})
+		return rec
+	}
+
+	serve("a")
+	serve("a")
+	if calls != 1 {
+		t.Fatalf("expected a replay within the TTL, got %d calls", calls)
+	}
+
+	now = now.Add(time.Minute)
+	serve("a")
+	if calls != 2 {
+		t.Errorf("expected an expired key to reach the handler again, got %d calls", calls)
+	}
+
+	// The oldest response is evicted once the cache is full
+	now = now.Add(time.Second)
+	serve("b")
+	now = now.Add(time.Second)
+	serve("c")
+	calls = 0
+	serve("a")
+	serve("c")
+	if calls != 1 {
+		t.Errorf("expected only the evicted key to reach the handler, got %d calls", calls)
+	}
+}
+
+func TestIdempotencyCache_LargeResponseNotCached(t *testing.T) {
+	c := newIdempotencyCache(IdempotencyConfig{MaxResponseSize: 4})
+
+	var calls int
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		c.serve(context.Background(), rec, "large", func(w http.ResponseWriter) {
+			calls++
+			_, _ = w.Write([]byte("too large"))
+		})
+		if rec.Body.String() != "too large" {
+			t.Errorf("expected the response to reach the client, got %q", rec.Body.String())
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("expected responses over the size limit not to be replayed, got %d calls", calls)
+	}
+}
+
+func TestIdempotencyKey_Scoped(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	base := idempotencyKey(req, "key", "alice")
+
+	if idempotencyKey(req, "key", "bob") == base {
+		t.Error("expected different users not to share a key")
+	}
+	embeddings := httptest.NewRequest(http.MethodPost, "/v1/embeddings", nil)
+	if idempotencyKey(embeddings, "key", "alice") == base {
+		t.Error("expected different paths not to share a key")
+	}
+	if idempotencyKey(req, "key", "alice") != base {
+		t.Error("expected the same request to produce the same key")
+	}
+}
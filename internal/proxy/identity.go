@@ -0,0 +1,245 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// IdentitySourceType identifies where a user identity is read from
+type IdentitySourceType string
+
+const (
+	// IdentitySourceHeader reads the identity from a request header
+	IdentitySourceHeader IdentitySourceType = "header"
+	// IdentitySourceJWTClaim reads the identity from a claim in a bearer JWT
+	IdentitySourceJWTClaim IdentitySourceType = "jwt"
+	// IdentitySourceClientCert reads the identity from the client certificate CN
+	IdentitySourceClientCert IdentitySourceType = "client-cert"
+	// IdentitySourceRemoteIP uses the client IP address as the identity
+	IdentitySourceRemoteIP IdentitySourceType = "remote-ip"
+)
+
+// IdentitySource is a single place to look for a user identity
+type IdentitySource struct {
+	// Type is the kind of source
+	Type IdentitySourceType
+
+	// Name is the header name (header) or claim name (jwt), unused otherwise
+	Name string
+}
+
+// DefaultIdentitySources returns the default identity precedence:
+// X-User-ID header, then client IP
+func DefaultIdentitySources() []IdentitySource {
+	return []IdentitySource{
+		{Type: IdentitySourceHeader, Name: DefaultUserIDHeader},
+		{Type: IdentitySourceRemoteIP},
+	}
+}
+
+// credentialHeaders carry secrets. Identities read from them are hashed so
+// that tokens and API keys don't end up in rate limiter keys or logs.
+var credentialHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"X-Api-Key":           true,
+}
+
+// IdentityExtractor resolves a stable user identity from a request using an
+// ordered list of sources. It is shared by experiments and rate limiting so
+// that both see the same user.
+type IdentityExtractor struct {
+	sources []IdentitySource
+}
+
+// NewIdentityExtractor creates an extractor that consults sources in order
+func NewIdentityExtractor(sources ...IdentitySource) *IdentityExtractor {
+	return &IdentityExtractor{
+		sources: sources,
+	}
+}
+
+// Sources returns the configured sources in precedence order
+func (e *IdentityExtractor) Sources() []IdentitySource {
+	sources := make([]IdentitySource, len(e.sources))
+	copy(sources, e.sources)
+	return sources
+}
+
+// Extract returns the identity from the first source that yields a value,
+// or an empty string if no source matched
+func (e *IdentityExtractor) Extract(req *http.Request) string {
+	for _, source := range e.sources {
+		if id := extractFromSource(source, req); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// ExtractWithHeader consults the given header before the configured sources.
+// This lets a route-specific user header take precedence while still falling
+// back to the shared identity chain.
+func (e *IdentityExtractor) ExtractWithHeader(req *http.Request, header string) string {
+	id, _ := e.UserWithHeader(req, header)
+	return id
+}
+
+// UserWithHeader returns the same identity as ExtractWithHeader, and whether
+// it identifies a user. Identities taken from the client IP don't, since many
+// users can share an address.
+func (e *IdentityExtractor) UserWithHeader(req *http.Request, header string) (string, bool) {
+	if header != "" {
+		if id := req.Header.Get(header); id != "" {
+			return id, true
+		}
+	}
+	for _, source := range e.sources {
+		if id := extractFromSource(source, req); id != "" {
+			return id, source.Type != IdentitySourceRemoteIP
+		}
+	}
+	return "", false
+}
+
+// extractFromSource reads the identity from a single source
+func extractFromSource(source IdentitySource, req *http.Request) string {
+	switch source.Type {
+	case IdentitySourceHeader:
+		if source.Name == "" {
+			return ""
+		}
+		id := req.Header.Get(source.Name)
+		if id != "" && credentialHeaders[http.CanonicalHeaderKey(source.Name)] {
+			return hashIdentity(id)
+		}
+		return id
+
+	case IdentitySourceJWTClaim:
+		return jwtClaim(req, source.Name)
+
+	case IdentitySourceClientCert:
+		if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+			return ""
+		}
+		return req.TLS.PeerCertificates[0].Subject.CommonName
+
+	case IdentitySourceRemoteIP:
+		return remoteIP(req)
+
+	default:
+		return ""
+	}
+}
+
+// hashIdentity replaces a credential with a short, stable digest of it
+func hashIdentity(credential string) string {
+	sum := sha256.Sum256([]byte(credential))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// jwtClaim extracts a claim from a bearer JWT in the Authorization header.
+// The signature is not verified, so any client can choose its claims: only
+// use a jwt source behind an edge that authenticates the token first.
+func jwtClaim(req *http.Request, claim string) string {
+	if claim == "" {
+		return ""
+	}
+
+	auth := req.Header.Get("Authorization")
+	token, ok := strings.CutPrefix(auth, "Bearer ")
+	if !ok {
+		return ""
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+
+	switch v := claims[claim].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// remoteIP returns the client IP without the ephemeral port so that the
+// identity stays stable across connections
+func remoteIP(req *http.Request) string {
+	if req.RemoteAddr == "" {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// ParseIdentitySources parses a comma-separated source list such as
+// "header:X-User-ID,jwt:sub,client-cert,remote-ip"
+func ParseIdentitySources(spec string) ([]IdentitySource, error) {
+	var sources []IdentitySource
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kind, name, _ := strings.Cut(entry, ":")
+		source := IdentitySource{Type: IdentitySourceType(kind), Name: name}
+
+		switch source.Type {
+		case IdentitySourceHeader, IdentitySourceJWTClaim:
+			if name == "" {
+				return nil, fmt.Errorf("identity source %q requires a name", kind)
+			}
+		case IdentitySourceClientCert, IdentitySourceRemoteIP:
+			if name != "" {
+				return nil, fmt.Errorf("identity source %q does not take a name", kind)
+			}
+		default:
+			return nil, fmt.Errorf("unknown identity source: %s", kind)
+		}
+
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// makeJWT builds an unsigned JWT with the given JSON payload
+func makeJWT(payload string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	body := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return header + "." + body + ".sig"
+}
+
+func TestIdentityExtractor_Sources(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   IdentitySource
+		setup    func(req *http.Request)
+		expected string
+	}{
+		{
+			name:   "header",
+			source: IdentitySource{Type: IdentitySourceHeader, Name: "X-Tenant"},
+			setup: func(req *http.Request) {
+				req.Header.Set("X-Tenant", "tenant-a")
+			},
+			expected: "tenant-a",
+		},
+		{
+			name:   "jwt string claim",
+			source: IdentitySource{Type: IdentitySourceJWTClaim, Name: "sub"},
+			setup: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+makeJWT(`{"sub":"user-42"}`))
+			},
+			expected: "user-42",
+		},
+		{
+			name:   "jwt numeric claim",
+			source: IdentitySource{Type: IdentitySourceJWTClaim, Name: "uid"},
+			setup: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+makeJWT(`{"uid":1234}`))
+			},
+			expected: "1234",
+		},
+		{
+			name:   "jwt malformed token",
+			source: IdentitySource{Type: IdentitySourceJWTClaim, Name: "sub"},
+			setup: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer not-a-jwt")
+			},
+			expected: "",
+		},
+		{
+			name:   "client cert CN",
+			source: IdentitySource{Type: IdentitySourceClientCert},
+			setup: func(req *http.Request) {
+				req.TLS = &tls.ConnectionState{
+					PeerCertificates: []*x509.Certificate{
+						{Subject: pkix.Name{CommonName: "billing-service"}},
+					},
+				}
+			},
+			expected: "billing-service",
+		},
+		{
+			name:     "client cert without TLS",
+			source:   IdentitySource{Type: IdentitySourceClientCert},
+			setup:    func(req *http.Request) {},
+			expected: "",
+		},
+		{
+			name:   "remote IP strips port",
+			source: IdentitySource{Type: IdentitySourceRemoteIP},
+			setup: func(req *http.Request) {
+				req.RemoteAddr = "10.0.0.7:51234"
+			},
+			expected: "10.0.0.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", nil)
+			tt.setup(req)
+
+			extractor := NewIdentityExtractor(tt.source)
+			if got := extractor.Extract(req); got != tt.expected {
+				t.Errorf("expected identity %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIdentityExtractor_FallbackOrder(t *testing.T) {
+	extractor := NewIdentityExtractor(
+		IdentitySource{Type: IdentitySourceHeader, Name: "X-User-ID"},
+		IdentitySource{Type: IdentitySourceJWTClaim, Name: "sub"},
+		IdentitySource{Type: IdentitySourceRemoteIP},
+	)
+
+	req := httptest.NewRequest("POST", "/", nil)
+	req.RemoteAddr = "1.2.3.4:1234"
+	req.Header.Set("Authorization", "Bearer "+makeJWT(`{"sub":"jwt-user"}`))
+	req.Header.Set("X-User-ID", "header-user")
+
+	if got := extractor.Extract(req); got != "header-user" {
+		t.Errorf("expected header to win, got %q", got)
+	}
+
+	req.Header.Del("X-User-ID")
+	if got := extractor.Extract(req); got != "jwt-user" {
+		t.Errorf("expected JWT claim fallback, got %q", got)
+	}
+
+	req.Header.Del("Authorization")
+	if got := extractor.Extract(req); got != "1.2.3.4" {
+		t.Errorf("expected remote IP fallback, got %q", got)
+	}
+}
+
+func TestIdentityExtractor_Credentials(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", nil)
+	req.RemoteAddr = "1.2.3.4:1234"
+	req.Header.Set("Authorization", "Bearer sk-secret")
+
+	// Credentials are not an identity by default
+	if got := NewIdentityExtractor(DefaultIdentitySources()...).Extract(req); got != "1.2.3.4" {
+		t.Errorf("expected the default sources to skip Authorization, got %q", got)
+	}
+
+	// When configured, they are hashed rather than used verbatim
+	extractor := NewIdentityExtractor(IdentitySource{Type: IdentitySourceHeader, Name: "authorization"})
+	got := extractor.Extract(req)
+	if got == "" || strings.Contains(got, "sk-secret") {
+		t.Errorf("expected a hashed identity, got %q", got)
+	}
+	if again := extractor.Extract(req); again != got {
+		t.Errorf("expected the hashed identity to be stable, got %q then %q", got, again)
+	}
+	req.Header.Set("Authorization", "Bearer sk-other")
+	if other := extractor.Extract(req); other == got {
+		t.Error("expected different credentials to hash to different identities")
+	}
+}
+
+func TestIdentityExtractor_ExtractWithHeader(t *testing.T) {
+	extractor := NewIdentityExtractor(DefaultIdentitySources()...)
+
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("X-User-ID", "shared-user")
+	req.Header.Set("X-Team", "team-a")
+
+	if got := extractor.ExtractWithHeader(req, "X-Team"); got != "team-a" {
+		t.Errorf("expected route header to take precedence, got %q", got)
+	}
+	if got := extractor.ExtractWithHeader(req, "X-Missing"); got != "shared-user" {
+		t.Errorf("expected fallback to shared sources, got %q", got)
+	}
+}
+
+func TestIdentityExtractor_UserWithHeader(t *testing.T) {
+	extractor := NewIdentityExtractor(DefaultIdentitySources()...)
+	req := httptest.NewRequest("POST", "/", nil)
+	req.RemoteAddr = "1.2.3.4:1234"
+
+	if id, ok := extractor.UserWithHeader(req, "X-Team"); id != "1.2.3.4" || ok {
+		t.Errorf("expected the client IP not to count as a user, got %q (%v)", id, ok)
+	}
+	req.Header.Set("X-User-ID", "user-a")
+	if id, ok := extractor.UserWithHeader(req, "X-Team"); id != "user-a" || !ok {
+		t.Errorf("expected the user header to identify a user, got %q (%v)", id, ok)
+	}
+	req.Header.Set("X-Team", "team-a")
+	if id, ok := extractor.UserWithHeader(req, "X-Team"); id != "team-a" || !ok {
+		t.Errorf("expected the route header to identify a user, got %q (%v)", id, ok)
+	}
+}
+
+func TestParseIdentitySources(t *testing.T) {
+	sources, err := ParseIdentitySources("header:X-User-ID, jwt:sub,client-cert,remote-ip")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []IdentitySource{
+		{Type: IdentitySourceHeader, Name: "X-User-ID"},
+		{Type: IdentitySourceJWTClaim, Name: "sub"},
+		{Type: IdentitySourceClientCert},
+		{Type: IdentitySourceRemoteIP},
+	}
+	if len(sources) != len(expected) {
+		t.Fatalf("expected %d sources, got %d", len(expected), len(sources))
+	}
+	for i := range expected {
+		if sources[i] != expected[i] {
+			t.Errorf("expected sources[%d]=%+v, got %+v", i, expected[i], sources[i])
+		}
+	}
+
+	for _, invalid := range []string{"header", "jwt:", "remote-ip:foo", "cookie:session"} {
+		if _, err := ParseIdentitySources(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestServer_RateLimitAndExperimentsShareIdentity(t *testing.T) {
+	store := cache.NewStore()
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "limited"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "limited", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			RateLimit: &gatewayv1alpha1.RateLimitConfig{RequestsPerMinute: 1, PerUser: true},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	identity := NewIdentityExtractor(IdentitySource{Type: IdentitySourceHeader, Name: "X-Tenant"})
+	em := NewExperimentManager(nil)
+	rl := NewRateLimiter()
+	defer rl.Stop()
+
+	server := NewServer(DefaultConfig(), store, nil, zap.New(),
+		WithExperiments(em),
+		WithRateLimiter(rl),
+		WithIdentityExtractor(identity),
+	)
+
+	newRequest := func(tenant string) *http.Request {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		req.Header.Set("X-Route", "limited")
+		req.Header.Set("X-Tenant", tenant)
+		req.Header.Set("X-User-ID", "ignored")
+		return req
+	}
+
+	if got := em.getUserID(newRequest("tenant-a")); got != "tenant-a" {
+		t.Errorf("expected experiments to use shared identity, got %q", got)
+	}
+
+	// The first request for tenant-a consumes its only token
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, newRequest("tenant-a"))
+	if rec.Code == http.StatusTooManyRequests {
+		t.Fatal("first request for tenant-a should not be rate limited")
+	}
+
+	// A second tenant-a request is limited, even though X-User-ID is identical for everyone
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, newRequest("tenant-a"))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 for tenant-a, got %d", rec.Code)
+	}
+
+	// tenant-b has its own bucket
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, newRequest("tenant-b"))
+	if rec.Code == http.StatusTooManyRequests {
+		t.Error("tenant-b should not share tenant-a's limit")
+	}
+}
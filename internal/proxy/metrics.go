@@ -21,7 +21,10 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/judeoyovbaire/kortex/internal/tracing"
 )
 
 var (
@@ -77,7 +80,17 @@ var (
 			Name: "inference_gateway_rate_limit_hits_total",
 			Help: "Total number of rate limit rejections",
 		},
-		[]string{"route", "user"},
+		[]string{"route"},
+	)
+
+	// RateLimitMissingUser counts requests to per-user rate limited routes
+	// that had no user identity and were only limited per client IP or route
+	RateLimitMissingUser = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kortex_ratelimit_missing_user_total",
+			Help: "Total number of requests to per-user rate limited routes without a user identity",
+		},
+		[]string{"route"},
 	)
 
 	// ExperimentAssignments counts experiment variant assignments
@@ -89,13 +102,33 @@ var (
 		[]string{"experiment", "variant"},
 	)
 
+	// ExperimentFallbacks counts treatment requests sent to control because
+	// the treatment backend was unhealthy
+	ExperimentFallbacks = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "inference_gateway_experiment_fallbacks_total",
+			Help: "Total number of treatment requests sent to control because the treatment backend was unhealthy",
+		},
+		[]string{"route", "experiment"},
+	)
+
+	// ExperimentOverrides counts requests whose weighted backend selection was
+	// changed by an experiment
+	ExperimentOverrides = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "inference_gateway_experiment_overrides_total",
+			Help: "Total number of backend selections changed by an experiment",
+		},
+		[]string{"route", "from_backend", "to_backend", "experiment"},
+	)
+
 	// CostTotal tracks total cost incurred
 	CostTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "inference_gateway_cost_total",
-			Help: "Total cost incurred in USD",
+			Help: "Total cost incurred, in the currency of the currency label, by cost tag when enabled",
 		},
-		[]string{"route", "backend"},
+		[]string{"route", "backend", "currency", "tag"},
 	)
 
 	// TokensProcessed tracks tokens processed
@@ -115,6 +148,16 @@ var (
 		},
 		[]string{"route", "from_backend", "to_backend"},
 	)
+
+	// BackendTTFB tracks the time until the first response byte is written to the client
+	BackendTTFB = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kortex_backend_ttfb_seconds",
+			Help:    "Time to first byte of backend responses in seconds",
+			Buckets: []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		},
+		[]string{"route", "backend"},
+	)
 )
 
 func init() {
@@ -126,19 +169,46 @@ func init() {
 		BackendHealth,
 		ActiveRequests,
 		RateLimitHits,
+		RateLimitMissingUser,
 		ExperimentAssignments,
+		ExperimentFallbacks,
+		ExperimentOverrides,
 		CostTotal,
 		TokensProcessed,
 		FallbacksTriggered,
+		BackendTTFB,
+		admissionRejections,
+		concurrencyLimitRejections,
+		retryBudgetExhausted,
+		queueDepth,
+		queueWaitSeconds,
+		adaptiveConcurrencyLimit,
+		providerRateLimitRemaining,
+		routeSuccessRatio,
+		routeErrorBudgetBurnRate,
+		smartRouterCategories,
 	)
 }
 
 // MetricsRecorder provides methods for recording proxy metrics
-type MetricsRecorder struct{}
+type MetricsRecorder struct {
+	healthCache *HealthCache
+	otlpMeter   *tracing.Meter
+	slo         *sloTracker
+	errorRates  *errorRateTracker
+}
 
 // NewMetricsRecorder creates a new metrics recorder
 func NewMetricsRecorder() *MetricsRecorder {
-	return &MetricsRecorder{}
+	return &MetricsRecorder{
+		slo:        newSLOTracker(DefaultSLOWindow),
+		errorRates: newErrorRateTracker(),
+	}
+}
+
+// SetOTLPMeter mirrors request, latency and cost metrics to an OTLP collector
+func (m *MetricsRecorder) SetOTLPMeter(meter *tracing.Meter) {
+	m.otlpMeter = meter
 }
 
 // RecordRequest records a completed request
@@ -146,11 +216,50 @@ func (m *MetricsRecorder) RecordRequest(route, backend string, statusCode int, d
 	status := strconv.Itoa(statusCode)
 	RequestsTotal.WithLabelValues(route, backend, status).Inc()
 	RequestDuration.WithLabelValues(route, backend).Observe(duration.Seconds())
+	m.slo.record(route, statusCode)
+	if m.otlpMeter != nil {
+		m.otlpMeter.RecordRequest(route, backend, statusCode, duration)
+	}
+}
+
+// SetSLOTarget sets the success ratio the route aims for, between 0 and 1,
+// which its error budget burn rate is measured against. 0 clears it.
+func (m *MetricsRecorder) SetSLOTarget(route string, target float64) {
+	m.slo.setTarget(route, target)
+}
+
+// RecordBackendStatus records the status of a completed request to the
+// backend in the namespace, for BackendErrorRate. 0 means no response.
+func (m *MetricsRecorder) RecordBackendStatus(namespace, backend string, statusCode int) {
+	m.errorRates.record(types.NamespacedName{Namespace: namespace, Name: backend}, statusCode)
+}
+
+// BackendErrorRate returns the share of the backend's requests that failed
+// over the window, up to MaxErrorRateWindow, and the number of requests it's
+// based on. Only requests served by this process are counted.
+func (m *MetricsRecorder) BackendErrorRate(namespace, backend string, window time.Duration) (float64, int64) {
+	return m.errorRates.errorRate(types.NamespacedName{Namespace: namespace, Name: backend}, window)
+}
+
+// SetHealthCache sets the cache that request errors are reported to
+func (m *MetricsRecorder) SetHealthCache(hc *HealthCache) {
+	m.healthCache = hc
+}
+
+// HealthCache returns the cache of recently failed backends, or nil if none is set
+func (m *MetricsRecorder) HealthCache() *HealthCache {
+	return m.healthCache
 }
 
 // RecordError records a request error
 func (m *MetricsRecorder) RecordError(route, backend, errorType string) {
 	RequestErrors.WithLabelValues(route, backend, errorType).Inc()
+	if m.otlpMeter != nil {
+		m.otlpMeter.RecordError(route, backend, errorType)
+	}
+	if m.healthCache != nil {
+		m.healthCache.MarkFailed(backend)
+	}
 }
 
 // SetBackendHealth sets the health status for a backend
@@ -172,9 +281,16 @@ func (m *MetricsRecorder) DecActiveRequests(backend string) {
 	ActiveRequests.WithLabelValues(backend).Dec()
 }
 
-// RecordRateLimitHit records a rate limit rejection
-func (m *MetricsRecorder) RecordRateLimitHit(route, user string) {
-	RateLimitHits.WithLabelValues(route, user).Inc()
+// RecordRateLimitHit records a rate limit rejection. The user is not a label:
+// identities are unbounded and may be derived from credentials.
+func (m *MetricsRecorder) RecordRateLimitHit(route string) {
+	RateLimitHits.WithLabelValues(route).Inc()
+}
+
+// RecordRateLimitMissingUser records a request to a per-user rate limited
+// route that had no user identity
+func (m *MetricsRecorder) RecordRateLimitMissingUser(route string) {
+	RateLimitMissingUser.WithLabelValues(route).Inc()
 }
 
 // RecordExperimentAssignment records an experiment variant assignment
@@ -182,18 +298,40 @@ func (m *MetricsRecorder) RecordExperimentAssignment(experiment, variant string)
 	ExperimentAssignments.WithLabelValues(experiment, variant).Inc()
 }
 
-// RecordCost records cost incurred for a request
-func (m *MetricsRecorder) RecordCost(route, backend string, cost float64) {
-	CostTotal.WithLabelValues(route, backend).Add(cost)
+// RecordExperimentFallback records a treatment request sent to control
+// because the treatment backend was unhealthy
+func (m *MetricsRecorder) RecordExperimentFallback(route, experiment string) {
+	ExperimentFallbacks.WithLabelValues(route, experiment).Inc()
+}
+
+// RecordExperimentOverride records an experiment sending a request to a
+// different backend than base selection chose
+func (m *MetricsRecorder) RecordExperimentOverride(route, fromBackend, toBackend, experiment string) {
+	ExperimentOverrides.WithLabelValues(route, fromBackend, toBackend, experiment).Inc()
+}
+
+// RecordCost records cost incurred for a request, in the given currency and
+// under the given cost tag, which is empty for untagged requests
+func (m *MetricsRecorder) RecordCost(route, backend, currency, tag string, cost float64) {
+	CostTotal.WithLabelValues(route, backend, currency, tag).Add(cost)
+	if m.otlpMeter != nil {
+		m.otlpMeter.RecordCost(route, backend, currency, tag, cost)
+	}
 }
 
 // RecordTokens records tokens processed
 func (m *MetricsRecorder) RecordTokens(route, backend string, inputTokens, outputTokens int64) {
 	if inputTokens > 0 {
 		TokensProcessed.WithLabelValues(route, backend, "input").Add(float64(inputTokens))
+		if m.otlpMeter != nil {
+			m.otlpMeter.RecordTokens(route, backend, "input", inputTokens)
+		}
 	}
 	if outputTokens > 0 {
 		TokensProcessed.WithLabelValues(route, backend, "output").Add(float64(outputTokens))
+		if m.otlpMeter != nil {
+			m.otlpMeter.RecordTokens(route, backend, "output", outputTokens)
+		}
 	}
 }
 
@@ -201,3 +339,43 @@ func (m *MetricsRecorder) RecordTokens(route, backend string, inputTokens, outpu
 func (m *MetricsRecorder) RecordFallback(route, fromBackend, toBackend string) {
 	FallbacksTriggered.WithLabelValues(route, fromBackend, toBackend).Inc()
 }
+
+// RecordTTFB records the time to first byte for a backend response
+func (m *MetricsRecorder) RecordTTFB(route, backend string, ttfb time.Duration) {
+	BackendTTFB.WithLabelValues(route, backend).Observe(ttfb.Seconds())
+}
+
+// DeleteRouteMetrics removes all series labeled with the route so that a
+// deleted route does not leave stale values behind
+func (m *MetricsRecorder) DeleteRouteMetrics(route string) {
+	labels := prometheus.Labels{"route": route}
+	RequestsTotal.DeletePartialMatch(labels)
+	RequestDuration.DeletePartialMatch(labels)
+	RequestErrors.DeletePartialMatch(labels)
+	RateLimitHits.DeletePartialMatch(labels)
+	RateLimitMissingUser.DeletePartialMatch(labels)
+	CostTotal.DeletePartialMatch(labels)
+	TokensProcessed.DeletePartialMatch(labels)
+	FallbacksTriggered.DeletePartialMatch(labels)
+	ExperimentFallbacks.DeletePartialMatch(labels)
+	ExperimentOverrides.DeletePartialMatch(labels)
+	BackendTTFB.DeletePartialMatch(labels)
+	m.slo.delete(route)
+}
+
+// DeleteBackendMetrics removes all series labeled with the backend so that a
+// deleted backend does not leave stale values behind
+func (m *MetricsRecorder) DeleteBackendMetrics(backend, namespace string) {
+	labels := prometheus.Labels{"backend": backend}
+	RequestsTotal.DeletePartialMatch(labels)
+	RequestDuration.DeletePartialMatch(labels)
+	RequestErrors.DeletePartialMatch(labels)
+	ActiveRequests.DeletePartialMatch(labels)
+	CostTotal.DeletePartialMatch(labels)
+	TokensProcessed.DeletePartialMatch(labels)
+	BackendTTFB.DeletePartialMatch(labels)
+	BackendHealth.DeleteLabelValues(backend, namespace)
+	FallbacksTriggered.DeletePartialMatch(prometheus.Labels{"from_backend": backend})
+	FallbacksTriggered.DeletePartialMatch(prometheus.Labels{"to_backend": backend})
+	m.errorRates.delete(types.NamespacedName{Namespace: namespace, Name: backend})
+}
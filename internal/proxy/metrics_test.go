@@ -0,0 +1,135 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// countSeries returns the number of registered series with the given label value
+func countSeries(t *testing.T, label, value string) int {
+	t.Helper()
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+
+	count := 0
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == label && pair.GetValue() == value {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
+func TestMetricsRecorder_DeleteRouteMetrics(t *testing.T) {
+	m := NewMetricsRecorder()
+
+	m.RecordRequest("deleted-route", "metrics-backend", 200, time.Second)
+	m.RecordError("deleted-route", "metrics-backend", "request_failed")
+	m.RecordCost("deleted-route", "metrics-backend", "USD", "", 0.5)
+	m.RecordTokens("deleted-route", "metrics-backend", 10, 20)
+	m.RecordRateLimitHit("deleted-route")
+	m.RecordFallback("deleted-route", "metrics-backend", "metrics-fallback")
+	m.RecordExperimentOverride("deleted-route", "metrics-backend", "metrics-treatment", "metrics-experiment")
+	m.RecordTTFB("deleted-route", "metrics-backend", time.Millisecond)
+	m.RecordRequest("kept-route", "metrics-backend", 200, time.Second)
+
+	if countSeries(t, "route", "deleted-route") == 0 {
+		t.Fatal("expected series for deleted-route before deletion")
+	}
+
+	m.DeleteRouteMetrics("deleted-route")
+
+	if n := countSeries(t, "route", "deleted-route"); n != 0 {
+		t.Errorf("expected no series for deleted-route, found %d", n)
+	}
+	if countSeries(t, "route", "kept-route") == 0 {
+		t.Error("expected series for kept-route to remain")
+	}
+}
+
+func TestMetricsRecorder_DeleteBackendMetrics(t *testing.T) {
+	m := NewMetricsRecorder()
+
+	m.RecordRequest("backend-metrics-route", "deleted-backend", 200, time.Second)
+	m.RecordError("backend-metrics-route", "deleted-backend", "request_failed")
+	m.RecordTokens("backend-metrics-route", "deleted-backend", 10, 20)
+	m.IncActiveRequests("deleted-backend")
+	m.SetBackendHealth("deleted-backend", "default", true)
+	m.RecordFallback("backend-metrics-route", "deleted-backend", "kept-backend")
+	m.RecordRequest("backend-metrics-route", "kept-backend", 200, time.Second)
+
+	m.DeleteBackendMetrics("deleted-backend", "default")
+
+	for _, label := range []string{"backend", "from_backend", "to_backend"} {
+		if n := countSeries(t, label, "deleted-backend"); n != 0 {
+			t.Errorf("expected no %s series for deleted-backend, found %d", label, n)
+		}
+	}
+	if countSeries(t, "backend", "kept-backend") == 0 {
+		t.Error("expected series for kept-backend to remain")
+	}
+}
+
+func TestMetricsRegisteredWithManagerRegistry(t *testing.T) {
+	// Vector metrics are only gathered once they have a series
+	admissionRejections.WithLabelValues("registry-test")
+	retryBudgetExhausted.WithLabelValues("registry-test")
+	queueDepth.WithLabelValues("registry-test")
+	queueWaitSeconds.WithLabelValues("registry-test")
+	adaptiveConcurrencyLimit.WithLabelValues("registry-test")
+	providerRateLimitRemaining.WithLabelValues("registry-test")
+	routeSuccessRatio.WithLabelValues("registry-test")
+	routeErrorBudgetBurnRate.WithLabelValues("registry-test")
+	smartRouterCategories.WithLabelValues("registry-test")
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	registered := make(map[string]bool)
+	for _, family := range families {
+		registered[family.GetName()] = true
+	}
+
+	for _, name := range []string{
+		"kortex_admission_rejected_total",
+		"kortex_concurrency_limit_rejected_total",
+		"kortex_retry_budget_exhausted_total",
+		"kortex_backend_queue_depth",
+		"kortex_backend_queue_wait_seconds",
+		"kortex_backend_concurrency_limit",
+		"kortex_provider_ratelimit_remaining",
+		"kortex_route_success_ratio",
+		"kortex_route_error_budget_burn_rate",
+		"kortex_smartrouter_category_total",
+	} {
+		if !registered[name] {
+			t.Errorf("expected %s to be served from the manager's registry", name)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+)
+
+// ModelsPath is the OpenAI-compatible path that lists available models
+const ModelsPath = "/v1/models"
+
+// Model is an entry in the OpenAI-compatible model list
+type Model struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// ModelList is the OpenAI-compatible response to GET /v1/models
+type ModelList struct {
+	Object string  `json:"object"`
+	Data   []Model `json:"data"`
+}
+
+// isModelsRequest reports whether the request lists models
+func isModelsRequest(req *http.Request) bool {
+	return req.URL.Path == ModelsPath &&
+		(req.Method == http.MethodGet || req.Method == http.MethodHead)
+}
+
+// ServeModels answers GET /v1/models with the union of the models served by
+// the healthy backends in the request's namespace (X-Namespace, or default)
+func (r *Router) ServeModels(w http.ResponseWriter, req *http.Request) {
+	namespace := req.Header.Get("X-Namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	seen := make(map[string]bool)
+	list := ModelList{Object: "list", Data: []Model{}}
+	for _, backend := range r.cache.ListHealthyBackendsInNamespace(namespace) {
+		if r.cache.IsCordoned(types.NamespacedName{Namespace: backend.Namespace, Name: backend.Name}) {
+			continue
+		}
+		id, ownedBy := backendModel(backend)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		list.Data = append(list.Data, Model{ID: id, Object: "model", OwnedBy: ownedBy})
+	}
+	sort.Slice(list.Data, func(i, j int) bool { return list.Data[i].ID < list.Data[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		r.log.V(1).Info("Failed to write model list", "error", err)
+	}
+}
+
+// backendModel returns the model a backend serves and who owns it, or an
+// empty model if the backend doesn't declare one
+func backendModel(backend *gatewayv1alpha1.InferenceBackend) (string, string) {
+	switch {
+	case backend.Spec.External != nil:
+		provider := backend.Spec.External.Provider
+		if provider == "" {
+			provider = "openai"
+		}
+		return backend.Spec.External.Model, provider
+	case backend.Spec.Kubernetes != nil:
+		return backend.Spec.Kubernetes.Model, "kortex"
+	case backend.Spec.KServe != nil:
+		// KServe serves the model under the InferenceService name
+		return backend.Spec.KServe.ServiceName, "kortex"
+	default:
+		return "", ""
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// newModelsTestServer creates a server with backends serving a mix of models
+func newModelsTestServer(serveModels bool) *Server {
+	store := cache.NewStore()
+	backends := []struct {
+		namespace string
+		name      string
+		health    string
+		spec      gatewayv1alpha1.InferenceBackendSpec
+	}{
+		{"default", "gpt-4", "Healthy", gatewayv1alpha1.InferenceBackendSpec{
+			External: &gatewayv1alpha1.ExternalBackend{Provider: "openai", Model: "gpt-4"},
+		}},
+		{"default", "gpt-4-backup", "Healthy", gatewayv1alpha1.InferenceBackendSpec{
+			External: &gatewayv1alpha1.ExternalBackend{Model: "gpt-4"},
+		}},
+		{"default", "claude", "Healthy", gatewayv1alpha1.InferenceBackendSpec{
+			External: &gatewayv1alpha1.ExternalBackend{Provider: "anthropic", Model: "claude-3-5-sonnet"},
+		}},
+		{"default", "llama", "Healthy", gatewayv1alpha1.InferenceBackendSpec{
+			Kubernetes: &gatewayv1alpha1.KubernetesBackend{ServiceName: "llama", Model: "llama-3-8b"},
+		}},
+		{"default", "mistral", "Unhealthy", gatewayv1alpha1.InferenceBackendSpec{
+			Kubernetes: &gatewayv1alpha1.KubernetesBackend{ServiceName: "mistral", Model: "mistral-7b"},
+		}},
+		{"other", "gemma", "Healthy", gatewayv1alpha1.InferenceBackendSpec{
+			Kubernetes: &gatewayv1alpha1.KubernetesBackend{ServiceName: "gemma", Model: "gemma-2b"},
+		}},
+	}
+	for _, b := range backends {
+		store.SetBackend(types.NamespacedName{Namespace: b.namespace, Name: b.name}, &gatewayv1alpha1.InferenceBackend{
+			ObjectMeta: metav1.ObjectMeta{Name: b.name, Namespace: b.namespace},
+			Spec:       b.spec,
+			Status:     gatewayv1alpha1.InferenceBackendStatus{Health: b.health},
+		})
+	}
+
+	cfg := DefaultConfig()
+	cfg.ServeModels = serveModels
+	return NewServer(cfg, store, nil, zap.New())
+}
+
+func TestServer_ModelsEndpoint(t *testing.T) {
+	server := newModelsTestServer(true)
+
+	req := httptest.NewRequest(http.MethodGet, ModelsPath, nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("expected JSON content type, got %q", got)
+	}
+
+	var list ModelList
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("failed to decode model list: %v", err)
+	}
+	if list.Object != "list" {
+		t.Errorf("expected object 'list', got %q", list.Object)
+	}
+
+	// Duplicates, unhealthy backends and other namespaces are left out
+	want := []Model{
+		{ID: "claude-3-5-sonnet", Object: "model", OwnedBy: "anthropic"},
+		{ID: "gpt-4", Object: "model", OwnedBy: "openai"},
+		{ID: "llama-3-8b", Object: "model", OwnedBy: "kortex"},
+	}
+	if len(list.Data) != len(want) {
+		t.Fatalf("expected %d models, got %v", len(want), list.Data)
+	}
+	for i := range want {
+		if list.Data[i] != want[i] {
+			t.Errorf("expected model %d to be %+v, got %+v", i, want[i], list.Data[i])
+		}
+	}
+}
+
+func TestServer_ModelsEndpoint_Namespace(t *testing.T) {
+	server := newModelsTestServer(true)
+
+	req := httptest.NewRequest(http.MethodGet, ModelsPath, nil)
+	req.Header.Set("X-Namespace", "other")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	var list ModelList
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("failed to decode model list: %v", err)
+	}
+	if len(list.Data) != 1 || list.Data[0].ID != "gemma-2b" {
+		t.Errorf("expected only gemma-2b in the other namespace, got %v", list.Data)
+	}
+}
+
+func TestServer_ModelsEndpoint_SkipsCordoned(t *testing.T) {
+	server := newModelsTestServer(true)
+	server.cache.CordonBackend(types.NamespacedName{Namespace: "default", Name: "llama"})
+
+	req := httptest.NewRequest(http.MethodGet, ModelsPath, nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	var list ModelList
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("failed to decode model list: %v", err)
+	}
+	for _, model := range list.Data {
+		if model.ID == "llama-3-8b" {
+			t.Error("expected the cordoned backend's model to be left out")
+		}
+	}
+}
+
+func TestServer_ModelsEndpoint_Disabled(t *testing.T) {
+	server := newModelsTestServer(false)
+
+	// Without a route the request is proxied and finds nothing to serve it
+	req := httptest.NewRequest(http.MethodGet, ModelsPath, nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected the request to be routed when disabled, got %d", rec.Code)
+	}
+}
+
+func TestIsModelsRequest(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{http.MethodGet, "/v1/models", true},
+		{http.MethodHead, "/v1/models", true},
+		{http.MethodPost, "/v1/models", false},
+		{http.MethodGet, "/v1/models/gpt-4", false},
+		{http.MethodGet, "/v1/chat/completions", false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if got := isModelsRequest(req); got != tt.want {
+			t.Errorf("%s %s: expected %v, got %v", tt.method, tt.path, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ParsePathPatterns parses a comma-separated list of path patterns for
+// AllowedPaths or DeniedPaths. Each pattern is a path prefix, or a glob such
+// as /v1/*/completions if it contains *, ? or [.
+func ParsePathPatterns(s string) ([]string, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(s, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if !strings.HasPrefix(pattern, "/") {
+			return nil, fmt.Errorf("path pattern %q must start with /", pattern)
+		}
+		if isGlob(pattern) {
+			if _, err := path.Match(pattern, "/"); err != nil {
+				return nil, fmt.Errorf("invalid path pattern %q: %w", pattern, err)
+			}
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// cleanPath resolves . and .. elements and repeated slashes in a request
+// path, keeping a trailing slash, so that the path lists match the path the
+// backend is sent. It reports false for paths that still contain "..".
+func cleanPath(requestPath string) (string, bool) {
+	if requestPath == "" {
+		return "/", true
+	}
+	if !strings.HasPrefix(requestPath, "/") {
+		requestPath = "/" + requestPath
+	}
+	cleaned := path.Clean(requestPath)
+	if strings.HasSuffix(requestPath, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned, !strings.Contains(cleaned, "..")
+}
+
+// pathAllowed reports whether the proxy serves the request path. Denied
+// paths take precedence; when AllowedPaths is empty, every path that isn't
+// denied is allowed.
+func (c Config) pathAllowed(requestPath string) bool {
+	for _, pattern := range c.DeniedPaths {
+		if matchPath(pattern, requestPath) {
+			return false
+		}
+	}
+	if len(c.AllowedPaths) == 0 {
+		return true
+	}
+	for _, pattern := range c.AllowedPaths {
+		if matchPath(pattern, requestPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath matches a glob pattern against the whole path, or a prefix
+// pattern against whole path segments, so /v1/chat doesn't match /v1/chatbot
+func matchPath(pattern, requestPath string) bool {
+	if isGlob(pattern) {
+		matched, err := path.Match(pattern, requestPath)
+		return err == nil && matched
+	}
+	prefix := strings.TrimSuffix(pattern, "/")
+	return requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/")
+}
+
+// isGlob reports whether the pattern uses glob syntax
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+func TestServer_AllowedAndDeniedPaths(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer backend.Close()
+
+	store := cache.NewStore()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "chat"}, newExternalTestBackend("chat", backend.URL))
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec:       gatewayv1alpha1.InferenceRouteSpec{DefaultBackend: &gatewayv1alpha1.BackendRef{Name: "chat"}},
+		Status:     gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	cfg := DefaultConfig()
+	cfg.AllowedPaths = []string{"/v1/chat/completions", "/v1/embeddings"}
+	cfg.DeniedPaths = []string{"/v1/embeddings/admin"}
+	server := NewServer(cfg, store, nil, zap.New())
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/v1/chat/completions", http.StatusOK},
+		{"/v1/embeddings", http.StatusOK},
+		{"/v1/fine_tuning/jobs", http.StatusNotFound},
+		{"/v1/embeddings/admin", http.StatusNotFound},
+		{"/v1//embeddings/admin", http.StatusNotFound},
+		{"/v1/./embeddings/admin", http.StatusNotFound},
+		{"/v1/chat/completions/../../embeddings/admin", http.StatusNotFound},
+		{"/v1/chat/completions/../../fine_tuning/jobs", http.StatusNotFound},
+		{"/v1/embeddings/%2e%2e/fine_tuning/jobs", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.path, nil))
+			if rec.Code != tt.want {
+				t.Errorf("expected %d for %s, got %d", tt.want, tt.path, rec.Code)
+			}
+		})
+	}
+}
+
+func TestServer_ForwardsCleanedPath(t *testing.T) {
+	var gotPath string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	}))
+	defer backend.Close()
+
+	store := cache.NewStore()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "chat"}, newExternalTestBackend("chat", backend.URL))
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec:       gatewayv1alpha1.InferenceRouteSpec{DefaultBackend: &gatewayv1alpha1.BackendRef{Name: "chat"}},
+		Status:     gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	cfg := DefaultConfig()
+	cfg.AllowedPaths = []string{"/v1/chat/completions"}
+	server := NewServer(cfg, store, nil, zap.New())
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1//chat/./completions", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if gotPath != "/v1/chat/completions" {
+		t.Errorf("expected the backend to be sent the cleaned path, got %q", gotPath)
+	}
+}
+
+func TestCleanPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"/v1/chat/completions", "/v1/chat/completions", true},
+		{"/v1//admin", "/v1/admin", true},
+		{"/v1/./files", "/v1/files", true},
+		{"/v1/chat/completions/../../files", "/v1/files", true},
+		{"/../../etc", "/etc", true},
+		{"/v1/models/", "/v1/models/", true},
+		{"v1/models", "/v1/models", true},
+		{"", "/", true},
+		{"/v1/a..b", "/v1/a..b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := cleanPath(tt.path)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("cleanPath(%q) = %q, %v, want %q, %v", tt.path, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestConfig_pathAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		denied  []string
+		path    string
+		want    bool
+	}{
+		{name: "no lists", path: "/anything", want: true},
+		{name: "prefix", allowed: []string{"/v1/chat"}, path: "/v1/chat/completions", want: true},
+		{name: "prefix matches whole segments", allowed: []string{"/v1/chat"}, path: "/v1/chatbot", want: false},
+		{name: "glob", allowed: []string{"/v1/*/completions"}, path: "/v1/chat/completions", want: true},
+		{name: "glob doesn't cross segments", allowed: []string{"/v1/*/completions"}, path: "/v1/a/b/completions", want: false},
+		{name: "denied without allow list", denied: []string{"/admin"}, path: "/admin/users", want: false},
+		{name: "deny wins", allowed: []string{"/v1"}, denied: []string{"/v1/files"}, path: "/v1/files", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{AllowedPaths: tt.allowed, DeniedPaths: tt.denied}
+			if got := cfg.pathAllowed(tt.path); got != tt.want {
+				t.Errorf("pathAllowed(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePathPatterns(t *testing.T) {
+	patterns, err := ParsePathPatterns(" /v1/chat/completions, /v1/*/embeddings ,,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(patterns) != 2 || patterns[0] != "/v1/chat/completions" || patterns[1] != "/v1/*/embeddings" {
+		t.Errorf("unexpected patterns %q", patterns)
+	}
+
+	for _, invalid := range []string{"v1/chat", "/v1/[chat"} {
+		if _, err := ParsePathPatterns(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// FailureReason classifies why a request could not be served by any backend
+type FailureReason string
+
+const (
+	// FailureAllUnhealthy means no backend in the chain was available to try
+	FailureAllUnhealthy FailureReason = "all_unhealthy"
+	// FailureAllErrored means every backend that was tried returned an error
+	FailureAllErrored FailureReason = "all_errored"
+	// FailureCircuitOpen means every backend was rejected by its circuit breaker
+	FailureCircuitOpen FailureReason = "circuit_open"
+	// FailureTimeout means the last backend attempt timed out, which is
+	// reported as a 504 rather than a 503
+	FailureTimeout FailureReason = "timeout"
+	// FailureSaturated means every backend was at its concurrency limit and
+	// the request could not be queued
+	FailureSaturated FailureReason = "saturated"
+	// FailureModelNotAllowed means the requested model isn't in the
+	// AllowedModels of any backend that could serve the request
+	FailureModelNotAllowed FailureReason = "model_not_allowed"
+)
+
+// problemContentType is the RFC 7807 media type
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details response. It intentionally carries
+// no backend URLs or raw errors; full detail is logged server-side.
+type Problem struct {
+	Type   string        `json:"type"`
+	Title  string        `json:"title"`
+	Status int           `json:"status"`
+	Detail string        `json:"detail,omitempty"`
+	Reason FailureReason `json:"reason,omitempty"`
+	Errors []string      `json:"errors,omitempty"`
+}
+
+// failureProblems maps each failure reason to its client-facing problem
+var failureProblems = map[FailureReason]Problem{
+	FailureAllUnhealthy: {
+		Title:  "No healthy backends",
+		Status: http.StatusServiceUnavailable,
+		Detail: "No healthy backend is available to serve this request.",
+	},
+	FailureAllErrored: {
+		Title:  "All backends failed",
+		Status: http.StatusServiceUnavailable,
+		Detail: "Every backend attempted for this request returned an error.",
+	},
+	FailureCircuitOpen: {
+		Title:  "Backends temporarily unavailable",
+		Status: http.StatusServiceUnavailable,
+		Detail: "All backends are temporarily rejecting requests after repeated failures.",
+	},
+	FailureTimeout: {
+		Title:  "Backend timeout",
+		Status: http.StatusGatewayTimeout,
+		Detail: "The backend did not respond in time.",
+	},
+	FailureSaturated: {
+		Title:  "Backends at capacity",
+		Status: http.StatusServiceUnavailable,
+		Detail: "All backends are at their concurrency limit and the request could not be queued.",
+	},
+	FailureModelNotAllowed: {
+		Title:  "Model not available",
+		Status: http.StatusBadRequest,
+		Detail: "The requested model is not served by any backend for this request.",
+	},
+}
+
+// writeFailure writes the problem response for a failure reason
+func writeFailure(w http.ResponseWriter, reason FailureReason) {
+	problem, ok := failureProblems[reason]
+	if !ok {
+		problem = failureProblems[FailureAllErrored]
+	}
+	problem.Type = "about:blank"
+	problem.Reason = reason
+	writeProblem(w, problem)
+}
+
+// writeProblem writes an RFC 7807 problem details response
+func writeProblem(w http.ResponseWriter, problem Problem) {
+	w.Header().Set("Content-Type", problemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// newExternalTestBackend creates a healthy external backend pointing at url
+func newExternalTestBackend(name, url string) *gatewayv1alpha1.InferenceBackend {
+	return &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type: gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{
+				URL:      url,
+				Provider: "openai",
+			},
+		},
+		Status: gatewayv1alpha1.InferenceBackendStatus{
+			Health: "Healthy",
+		},
+	}
+}
+
+// executeAndDecodeProblem runs the request and decodes the problem response
+func executeAndDecodeProblem(t *testing.T, handler *BackendHandler, primary string) (*httptest.ResponseRecorder, Problem) {
+	t.Helper()
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-route",
+			Namespace: "default",
+		},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: primary})
+
+	if ct := rec.Header().Get("Content-Type"); ct != problemContentType {
+		t.Errorf("expected Content-Type '%s', got '%s'", problemContentType, ct)
+	}
+
+	var problem Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to decode problem: %v (body: %s)", err, rec.Body.String())
+	}
+	return rec, problem
+}
+
+func TestExecuteWithFallback_Problem_AllUnhealthy(t *testing.T) {
+	store := cache.NewStore()
+	handler := NewBackendHandler(store, nil, zap.New(), nil, nil, nil)
+
+	rec, problem := executeAndDecodeProblem(t, handler, "missing-backend")
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", rec.Code)
+	}
+	if problem.Reason != FailureAllUnhealthy {
+		t.Errorf("expected reason '%s', got '%s'", FailureAllUnhealthy, problem.Reason)
+	}
+	if problem.Status != http.StatusServiceUnavailable {
+		t.Errorf("expected problem status 503, got %d", problem.Status)
+	}
+	if strings.Contains(rec.Body.String(), "missing-backend") {
+		t.Error("expected problem body not to leak backend names")
+	}
+}
+
+func TestExecuteWithFallback_Problem_AllErrored(t *testing.T) {
+	// A closed server refuses connections
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	upstreamURL := upstream.URL
+	upstream.Close()
+
+	store := cache.NewStore()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "refused"}, newExternalTestBackend("refused", upstreamURL))
+	handler := NewBackendHandler(store, nil, zap.New(), nil, nil, nil)
+
+	rec, problem := executeAndDecodeProblem(t, handler, "refused")
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", rec.Code)
+	}
+	if problem.Reason != FailureAllErrored {
+		t.Errorf("expected reason '%s', got '%s'", FailureAllErrored, problem.Reason)
+	}
+	if got := rec.Header().Get("X-Served-By"); got != "" {
+		t.Errorf("expected no X-Served-By for a refused connection, got %q", got)
+	}
+	if strings.Contains(rec.Body.String(), strings.TrimPrefix(upstreamURL, "http://")) {
+		t.Error("expected problem body not to leak backend URLs")
+	}
+}
+
+func TestExecuteWithFallback_Problem_CircuitOpen(t *testing.T) {
+	store := cache.NewStore()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "tripped"}, newExternalTestBackend("tripped", "http://127.0.0.1:1"))
+	handler := NewBackendHandler(store, nil, zap.New(), nil, nil, nil)
+
+	config := DefaultCircuitBreakerConfig()
+	config.FailureThreshold = 1
+	cb := NewCircuitBreakerManager(config, zap.New())
+	cb.RecordFailure("tripped")
+	handler.SetCircuitBreaker(cb)
+
+	rec, problem := executeAndDecodeProblem(t, handler, "tripped")
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", rec.Code)
+	}
+	if problem.Reason != FailureCircuitOpen {
+		t.Errorf("expected reason '%s', got '%s'", FailureCircuitOpen, problem.Reason)
+	}
+}
+
+func TestExecuteWithFallback_Problem_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer upstream.Close()
+	defer close(release)
+
+	store := cache.NewStore()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "slow"}, newExternalTestBackend("slow", upstream.URL))
+	handler := NewBackendHandler(store, nil, zap.New(), nil, nil, nil)
+	handler.SetProviderDefaults(map[string]ProviderDefaults{
+		"openai": {Timeout: 50 * time.Millisecond},
+	})
+
+	rec, problem := executeAndDecodeProblem(t, handler, "slow")
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("expected status 504, got %d", rec.Code)
+	}
+	if problem.Reason != FailureTimeout {
+		t.Errorf("expected reason '%s', got '%s'", FailureTimeout, problem.Reason)
+	}
+	if got := rec.Header().Get("X-Served-By"); got != "slow" {
+		t.Errorf("expected X-Served-By to name the backend that timed out, got %q", got)
+	}
+}
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name        string
+		attempted   int
+		circuitOpen int
+		unavailable int
+		saturated   int
+		err         error
+		expected    FailureReason
+	}{
+		{"nothing attempted", 0, 0, 1, 0, nil, FailureAllUnhealthy},
+		{"only circuits open", 0, 2, 0, 0, nil, FailureCircuitOpen},
+		{"circuits open and unhealthy", 0, 1, 1, 0, nil, FailureAllUnhealthy},
+		{"only saturated", 0, 0, 0, 2, ErrQueueFull, FailureSaturated},
+		{"saturated and unhealthy", 0, 0, 1, 1, nil, FailureAllUnhealthy},
+		{"attempt errored", 1, 0, 0, 0, errBackendUnreachable, FailureAllErrored},
+		{"attempt timed out", 1, 1, 0, 0, context.DeadlineExceeded, FailureTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyFailure(tt.attempted, tt.circuitOpen, tt.unavailable, tt.saturated, tt.err); got != tt.expected {
+				t.Errorf("expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
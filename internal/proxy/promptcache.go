@@ -0,0 +1,142 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+)
+
+// DefaultPromptCachePrefixLength is the number of prompt characters hashed
+// when a rule's prompt cache affinity doesn't set a prefix length
+const DefaultPromptCachePrefixLength = 1024
+
+// promptCacheBackend picks the backend for a rule with prompt cache affinity
+// by weighted rendezvous hashing the request's model and prompt prefix, so
+// requests sharing a prefix reach the backend whose cache already holds it.
+// Each backend gets a share of the prefixes in proportion to its weight, and
+// backends with a weight of zero get none. Only healthy backends are
+// considered; when one becomes unavailable, only the prefixes it served move
+// elsewhere.
+func (r *Router) promptCacheBackend(req *http.Request, namespace string, rule *gatewayv1alpha1.RouteRule, backends []gatewayv1alpha1.BackendRef) (gatewayv1alpha1.BackendRef, bool) {
+	if rule == nil || rule.PromptCacheAffinity == nil {
+		return gatewayv1alpha1.BackendRef{}, false
+	}
+
+	prefixLength := int(rule.PromptCacheAffinity.PrefixLength)
+	if prefixLength <= 0 {
+		prefixLength = DefaultPromptCachePrefixLength
+	}
+	key, ok := promptCacheKey(req, prefixLength)
+	if !ok {
+		return gatewayv1alpha1.BackendRef{}, false
+	}
+
+	backends = excludeZeroWeight(backends)
+
+	var selected gatewayv1alpha1.BackendRef
+	var best float64
+	found := false
+	for _, b := range backends {
+		if _, ok := r.availableCandidate(namespace, b.Name, backends); !ok {
+			continue
+		}
+		if score := rendezvousScore(key, b); !found || score > best {
+			selected, best, found = b, score, true
+		}
+	}
+	return selected, found
+}
+
+// rendezvousScore is the backend's weighted rendezvous score for a key,
+// -weight/ln(h) for a hash h in (0, 1). The highest score wins, which gives
+// each backend keys in proportion to its weight.
+func rendezvousScore(key string, b gatewayv1alpha1.BackendRef) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(b.Name))
+	unit := (float64(h.Sum64()>>11) + 0.5) / (1 << 53)
+	return -backendShare(b) / math.Log(unit)
+}
+
+// promptCacheKey returns the request's model and the first prefixLength
+// characters of its prompt text. The system prompt and messages are taken in
+// order, as providers cache them. It reports false if the body has no prompt.
+func promptCacheKey(req *http.Request, prefixLength int) (string, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", false
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return "", false
+	}
+	_ = req.Body.Close()
+	setRequestBody(req, body)
+
+	var fields struct {
+		Model    string `json:"model"`
+		System   any    `json:"system"`
+		Messages any    `json:"messages"`
+		Prompt   any    `json:"prompt"`
+		Input    any    `json:"input"`
+	}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return "", false
+	}
+
+	var prompt strings.Builder
+	for _, part := range []any{fields.System, fields.Messages, fields.Prompt, fields.Input} {
+		writePromptText(&prompt, part)
+	}
+	if prompt.Len() == 0 {
+		return "", false
+	}
+	text := prompt.String()
+	if runes := []rune(text); len(runes) > prefixLength {
+		text = string(runes[:prefixLength])
+	}
+
+	model := fields.Model
+	if model == "" {
+		model = req.Header.Get("X-Model")
+	}
+	return model + "\x00" + text, true
+}
+
+// writePromptText appends the text of a prompt field, which may be a string,
+// a list of messages or content blocks, or a message with a content field
+func writePromptText(b *strings.Builder, value any) {
+	switch v := value.(type) {
+	case string:
+		b.WriteString(v)
+		b.WriteByte('\n')
+	case []any:
+		for _, item := range v {
+			writePromptText(b, item)
+		}
+	case map[string]any:
+		writePromptText(b, v["text"])
+		writePromptText(b, v["content"])
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// newPromptCacheRouter creates a router whose route spreads traffic evenly
+// over four healthy backends, with prompt cache affinity on a 32 character
+// prefix
+func newPromptCacheRouter(t *testing.T) (*Router, *cache.Store) {
+	t.Helper()
+	store := cache.NewStore()
+	router := NewRouter(store, nil, zap.New())
+
+	var backends []gatewayv1alpha1.BackendRef
+	for i := 0; i < 4; i++ {
+		name := fmt.Sprintf("backend-%d", i)
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(upstream.Close)
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, newExternalTestBackend(name, upstream.URL))
+		backends = append(backends, gatewayv1alpha1.BackendRef{Name: name})
+	}
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "cached"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "cached", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Rules: []gatewayv1alpha1.RouteRule{{
+				Backends:            backends,
+				PromptCacheAffinity: &gatewayv1alpha1.PromptCacheAffinity{PrefixLength: 32},
+			}},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+	return router, store
+}
+
+// servePrompt sends a chat completion with the given system prompt and user
+// message and returns the backend that served it
+func servePrompt(router *Router, model, system, message string) string {
+	body := fmt.Sprintf(`{"model":%q,"messages":[{"role":"system","content":%q},{"role":"user","content":%q}]}`,
+		model, system, message)
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.HandleRequest(req.Context(), rec, req)
+	return rec.Header().Get("X-Served-By")
+}
+
+func TestRouter_PromptCacheAffinity_SharedPrefix(t *testing.T) {
+	router, _ := newPromptCacheRouter(t)
+
+	const system = "You are a helpful assistant for the billing team."
+	first := servePrompt(router, "gpt-4", system, "question 0")
+	if first == "" {
+		t.Fatal("expected the request to be served")
+	}
+
+	// Requests differing only after the prefix reach the same backend
+	for i := 1; i < 20; i++ {
+		if got := servePrompt(router, "gpt-4", system, fmt.Sprintf("question %d", i)); got != first {
+			t.Fatalf("expected requests sharing a prefix to reach %s, got %s", first, got)
+		}
+	}
+
+	// Different prefixes spread over the backends
+	served := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		served[servePrompt(router, "gpt-4", fmt.Sprintf("System prompt number %d for tenant", i), "hi")] = true
+	}
+	if len(served) < 2 {
+		t.Errorf("expected different prefixes to use more than one backend, got %v", served)
+	}
+}
+
+func TestRouter_PromptCacheAffinity_ModelAndHealth(t *testing.T) {
+	router, store := newPromptCacheRouter(t)
+
+	const system = "Summarize the following document in three bullets."
+
+	// The same prefix for another model is hashed separately
+	models := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		models[servePrompt(router, fmt.Sprintf("model-%d", i), system, "doc")] = true
+	}
+	if len(models) < 2 {
+		t.Errorf("expected the model to be part of the affinity key, got %v", models)
+	}
+
+	// An unhealthy backend's prefixes move to another backend
+	first := servePrompt(router, "gpt-4", system, "doc")
+	unhealthy := newExternalTestBackend(first, "http://127.0.0.1:1")
+	unhealthy.Status.Health = "Unhealthy"
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: first}, unhealthy)
+
+	second := servePrompt(router, "gpt-4", system, "doc")
+	if second == "" || second == first {
+		t.Errorf("expected the prefix to move off unhealthy %s, got '%s'", first, second)
+	}
+	if got := servePrompt(router, "gpt-4", system, "another doc"); got != second {
+		t.Errorf("expected the prefix to stay on %s, got %s", second, got)
+	}
+}
+
+// promptCacheShares sends prompts with many different prefixes through
+// promptCacheBackend and counts the backend each one is sent to
+func promptCacheShares(t *testing.T, backends []gatewayv1alpha1.BackendRef) map[string]int {
+	t.Helper()
+	store := cache.NewStore()
+	router := NewRouter(store, nil, zap.New())
+	for _, b := range backends {
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: b.Name}, newExternalTestBackend(b.Name, "http://127.0.0.1:1"))
+	}
+	rule := &gatewayv1alpha1.RouteRule{
+		Backends:            backends,
+		PromptCacheAffinity: &gatewayv1alpha1.PromptCacheAffinity{PrefixLength: 32},
+	}
+
+	shares := make(map[string]int)
+	for i := 0; i < 2000; i++ {
+		body := fmt.Sprintf(`{"model":"gpt-4","messages":[{"role":"system","content":"Tenant %d system prompt"}]}`, i)
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		if b, ok := router.promptCacheBackend(req, "default", rule, backends); ok {
+			shares[b.Name]++
+		}
+	}
+	return shares
+}
+
+func TestRouter_PromptCacheAffinity_ZeroWeight(t *testing.T) {
+	shares := promptCacheShares(t, []gatewayv1alpha1.BackendRef{
+		{Name: "stable", Weight: ptr.To[int32](100)},
+		{Name: "drained", Weight: ptr.To[int32](0)},
+	})
+	if shares["drained"] != 0 {
+		t.Errorf("expected a weight-0 backend to get no prompts, got %d", shares["drained"])
+	}
+	if shares["stable"] != 2000 {
+		t.Errorf("expected every prompt to reach the stable backend, got %d", shares["stable"])
+	}
+}
+
+func TestRouter_PromptCacheAffinity_SkewedWeights(t *testing.T) {
+	shares := promptCacheShares(t, []gatewayv1alpha1.BackendRef{
+		{Name: "stable", Weight: ptr.To[int32](95)},
+		{Name: "canary", Weight: ptr.To[int32](5)},
+	})
+	// A 5% canary gets around 100 of 2000 prefixes, not half of them
+	if canary := shares["canary"]; canary < 40 || canary > 200 {
+		t.Errorf("expected the canary to get about 5%% of prefixes, got %d of 2000", canary)
+	}
+}
+
+func TestPromptCacheKey(t *testing.T) {
+	body := `{"model":"claude","system":"Be brief.","messages":[{"role":"user","content":"hello there"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body))
+
+	key, ok := promptCacheKey(req, 12)
+	if !ok {
+		t.Fatal("expected a key for a request with a prompt")
+	}
+	if key != "claude\x00Be brief.\nhe" {
+		t.Errorf("expected the model and the first 12 prompt characters, got %q", key)
+	}
+
+	// The body is still readable by the backend
+	if data, _ := io.ReadAll(req.Body); string(data) != body {
+		t.Errorf("expected the body to be restored, got %q", data)
+	}
+
+	if _, ok := promptCacheKey(httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"x"}`)), 10); ok {
+		t.Error("expected no key for a request without a prompt")
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var providerRateLimitRemaining = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "kortex_provider_ratelimit_remaining",
+		Help: "Requests remaining in the provider's rate limit window, as last reported by the backend",
+	},
+	[]string{"backend"},
+)
+
+// ErrProviderRateLimited is returned when a backend's provider has reported
+// that its rate limit is almost exhausted
+var ErrProviderRateLimited = errors.New("provider rate limit nearly exhausted")
+
+// Rate limit headers, most specific first. OpenAI reports the reset as a
+// duration ("6m0s"), Anthropic as a timestamp, and others in seconds.
+var (
+	remainingRequestsHeaders = []string{
+		"x-ratelimit-remaining-requests",
+		"anthropic-ratelimit-requests-remaining",
+		"x-ratelimit-remaining",
+	}
+	resetRequestsHeaders = []string{
+		"x-ratelimit-reset-requests",
+		"anthropic-ratelimit-requests-reset",
+		"x-ratelimit-reset",
+	}
+)
+
+// ProviderRateLimitConfig configures throttling from provider rate limit headers
+type ProviderRateLimitConfig struct {
+	// MinRemaining is the number of requests left in the provider's window at
+	// which the gateway stops sending requests until the window resets. It
+	// leaves headroom for requests already in flight.
+	MinRemaining int
+
+	// DefaultReset is how long a reported budget applies when the provider
+	// doesn't say when its window resets
+	DefaultReset time.Duration
+}
+
+// DefaultProviderRateLimitConfig returns sensible defaults for provider rate limits
+func DefaultProviderRateLimitConfig() ProviderRateLimitConfig {
+	return ProviderRateLimitConfig{
+		MinRemaining: 1,
+		DefaultReset: time.Minute,
+	}
+}
+
+// ProviderRateLimiter tracks the request budget each backend's provider
+// reports in its response headers, and holds requests back from backends that
+// are about to be rate limited instead of waiting for them to return 429s
+type ProviderRateLimiter struct {
+	config ProviderRateLimitConfig
+
+	mu      sync.Mutex
+	budgets map[string]*providerBudget
+	now     func() time.Time
+}
+
+// providerBudget is a backend's remaining requests until its window resets
+type providerBudget struct {
+	remaining int
+	reset     time.Time
+}
+
+// NewProviderRateLimiter creates a provider rate limiter
+func NewProviderRateLimiter(config ProviderRateLimitConfig) *ProviderRateLimiter {
+	return &ProviderRateLimiter{
+		config:  config,
+		budgets: make(map[string]*providerBudget),
+		now:     time.Now,
+	}
+}
+
+// Observe records the rate limit headers of a backend response. Responses
+// without rate limit headers leave the budget unchanged.
+func (l *ProviderRateLimiter) Observe(backend string, header http.Header) {
+	if l == nil {
+		return
+	}
+	value := firstHeader(header, remainingRequestsHeaders)
+	if value == "" {
+		return
+	}
+	remaining, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return
+	}
+
+	now := l.now()
+	reset, ok := parseRateLimitReset(firstHeader(header, resetRequestsHeaders), now)
+	if !ok {
+		reset = now.Add(l.config.DefaultReset)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.budgets[backend] = &providerBudget{remaining: remaining, reset: reset}
+	providerRateLimitRemaining.WithLabelValues(backend).Set(float64(remaining))
+}
+
+// Allow reports whether a request can be sent to the backend, and counts it
+// against the backend's budget if so
+func (l *ProviderRateLimiter) Allow(backend string) bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	budget, ok := l.budgets[backend]
+	if !ok {
+		return true
+	}
+	if !l.now().Before(budget.reset) {
+		// The provider's window has reset
+		delete(l.budgets, backend)
+		return true
+	}
+	if budget.remaining <= l.config.MinRemaining {
+		return false
+	}
+
+	// Count the request until the provider reports its own number
+	budget.remaining--
+	providerRateLimitRemaining.WithLabelValues(backend).Set(float64(budget.remaining))
+	return true
+}
+
+// Remaining returns the backend's remaining requests, and false if there is
+// no current budget for it
+func (l *ProviderRateLimiter) Remaining(backend string) (int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	budget, ok := l.budgets[backend]
+	if !ok || !l.now().Before(budget.reset) {
+		return 0, false
+	}
+	return budget.remaining, true
+}
+
+// firstHeader returns the value of the first header that is set
+func firstHeader(header http.Header, names []string) string {
+	for _, name := range names {
+		if value := header.Get(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseRateLimitReset parses a reset header as a duration ("1m30s"), a
+// number of seconds, or an RFC 3339 timestamp
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(d), true
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return now.Add(time.Duration(seconds * float64(time.Second))), true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+func TestProviderRateLimiter_ParsesOpenAIHeaders(t *testing.T) {
+	limiter := NewProviderRateLimiter(DefaultProviderRateLimitConfig())
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	header := http.Header{}
+	header.Set("x-ratelimit-limit-requests", "500")
+	header.Set("x-ratelimit-remaining-requests", "42")
+	header.Set("x-ratelimit-reset-requests", "6m0s")
+	limiter.Observe("openai", header)
+
+	if got, ok := limiter.Remaining("openai"); !ok || got != 42 {
+		t.Errorf("expected 42 requests remaining, got %d (%v)", got, ok)
+	}
+	if gauge := testutil.ToFloat64(providerRateLimitRemaining.WithLabelValues("openai")); gauge != 42 {
+		t.Errorf("expected gauge to report 42, got %v", gauge)
+	}
+
+	// The budget lasts until the reported reset
+	now = now.Add(6 * time.Minute)
+	if _, ok := limiter.Remaining("openai"); ok {
+		t.Error("expected the budget to expire when the window resets")
+	}
+}
+
+func TestProviderRateLimiter_TightensWhenRemainingIsLow(t *testing.T) {
+	limiter := NewProviderRateLimiter(ProviderRateLimitConfig{MinRemaining: 1, DefaultReset: time.Minute})
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	header := http.Header{}
+	header.Set("x-ratelimit-remaining-requests", "3")
+	header.Set("x-ratelimit-reset-requests", "20s")
+	limiter.Observe("low", header)
+
+	// Requests are counted locally until only the headroom is left
+	for i := 0; i < 2; i++ {
+		if !limiter.Allow("low") {
+			t.Fatalf("expected request %d to be allowed", i+1)
+		}
+	}
+	if limiter.Allow("low") {
+		t.Error("expected the limiter to hold requests back once the budget is nearly exhausted")
+	}
+	if !limiter.Allow("other") {
+		t.Error("expected backends without a reported budget to be allowed")
+	}
+
+	now = now.Add(20 * time.Second)
+	if !limiter.Allow("low") {
+		t.Error("expected requests to be allowed again after the window resets")
+	}
+}
+
+func TestProviderRateLimiter_ResetFormats(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   time.Time
+	}{
+		{
+			name:   "duration",
+			header: map[string]string{"x-ratelimit-remaining-requests": "5", "x-ratelimit-reset-requests": "1m30s"},
+			want:   now.Add(90 * time.Second),
+		},
+		{
+			name:   "seconds",
+			header: map[string]string{"x-ratelimit-remaining": "5", "x-ratelimit-reset": "12"},
+			want:   now.Add(12 * time.Second),
+		},
+		{
+			name: "anthropic timestamp",
+			header: map[string]string{
+				"anthropic-ratelimit-requests-remaining": "5",
+				"anthropic-ratelimit-requests-reset":     "2025-01-01T12:00:45Z",
+			},
+			want: now.Add(45 * time.Second),
+		},
+		{
+			name:   "missing reset",
+			header: map[string]string{"x-ratelimit-remaining-requests": "5"},
+			want:   now.Add(time.Minute),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewProviderRateLimiter(DefaultProviderRateLimitConfig())
+			limiter.now = func() time.Time { return now }
+
+			header := http.Header{}
+			for key, value := range tt.header {
+				header.Set(key, value)
+			}
+			limiter.Observe("backend", header)
+
+			budget := limiter.budgets["backend"]
+			if budget == nil || budget.remaining != 5 {
+				t.Fatalf("expected 5 requests remaining, got %+v", budget)
+			}
+			if !budget.reset.Equal(tt.want) {
+				t.Errorf("expected reset at %v, got %v", tt.want, budget.reset)
+			}
+		})
+	}
+}
+
+func TestBackendHandler_ProviderRateLimitFallsBack(t *testing.T) {
+	store := cache.NewStore()
+	var primaryCalls, fallbackCalls atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryCalls.Add(1)
+		w.Header().Set("x-ratelimit-remaining-requests", "1")
+		w.Header().Set("x-ratelimit-reset-requests", "1m0s")
+		_, _ = w.Write([]byte(`{"id":"primary"}`))
+	}))
+	defer primary.Close()
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fallbackCalls.Add(1)
+		_, _ = w.Write([]byte(`{"id":"fallback"}`))
+	}))
+	defer fallback.Close()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "primary"}, newExternalTestBackend("primary", primary.URL))
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "fallback"}, newExternalTestBackend("fallback", fallback.URL))
+
+	handler := NewBackendHandler(store, nil, zap.New(), nil, nil, nil)
+	handler.SetProviderRateLimiter(NewProviderRateLimiter(DefaultProviderRateLimitConfig()))
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Fallback: &gatewayv1alpha1.FallbackChain{Backends: []string{"fallback"}},
+		},
+	}
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		rec := httptest.NewRecorder()
+		handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "primary"})
+		return rec
+	}
+
+	if rec := send(); rec.Header().Get("X-Served-By") != "primary" {
+		t.Fatalf("expected the first request to reach the primary, got %q", rec.Header().Get("X-Served-By"))
+	}
+
+	// The primary reported its last request, so the next goes to the fallback
+	rec := send()
+	if rec.Code != http.StatusOK || rec.Header().Get("X-Served-By") != "fallback" {
+		t.Errorf("expected the fallback to serve the request, got %d from %q", rec.Code, rec.Header().Get("X-Served-By"))
+	}
+	if primaryCalls.Load() != 1 || fallbackCalls.Load() != 1 {
+		t.Errorf("expected one call to each backend, got primary=%d fallback=%d", primaryCalls.Load(), fallbackCalls.Load())
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// Prometheus metrics for backend queues
+	queueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kortex_backend_queue_depth",
+			Help: "Number of requests waiting for a backend concurrency slot",
+		},
+		[]string{"backend"},
+	)
+
+	queueWaitSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kortex_backend_queue_wait_seconds",
+			Help:    "Time requests spent waiting in a backend queue, whether or not they got a slot",
+			Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		},
+		[]string{"backend"},
+	)
+)
+
+var (
+	// ErrQueueFull is returned when a backend is at capacity and its queue is full
+	ErrQueueFull = errors.New("backend queue is full")
+
+	// ErrQueueTimeout is returned when a request waited too long for a slot
+	ErrQueueTimeout = errors.New("timed out waiting in backend queue")
+)
+
+// QueueConfig configures per-backend request queuing
+type QueueConfig struct {
+	// MaxSize is the number of requests that can wait for each backend
+	// (0 = reject immediately when the backend is at capacity)
+	MaxSize int
+
+	// MaxWait is how long a request waits for a slot before it is rejected
+	MaxWait time.Duration
+}
+
+// DefaultQueueConfig returns sensible defaults for request queuing
+func DefaultQueueConfig() QueueConfig {
+	return QueueConfig{
+		MaxSize: 100,
+		MaxWait: 5 * time.Second,
+	}
+}
+
+// RequestQueue limits each backend to its MaxConcurrency in-flight requests.
+// Requests beyond the limit wait in a bounded FIFO queue.
+type RequestQueue struct {
+	config QueueConfig
+
+	mu       sync.Mutex
+	backends map[string]*backendQueue
+}
+
+// backendQueue tracks the slots in use and the waiters for one backend
+type backendQueue struct {
+	active  int
+	waiters *list.List // of *queueWaiter
+}
+
+// queueWaiter is a request waiting for a slot
+type queueWaiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// NewRequestQueue creates a request queue
+func NewRequestQueue(config QueueConfig) *RequestQueue {
+	return &RequestQueue{
+		config:   config,
+		backends: make(map[string]*backendQueue),
+	}
+}
+
+// Acquire waits for one of the backend's limit slots. The returned release
+// function must be called when the request completes and is safe to call more
+// than once. A limit of zero or less means unlimited.
+func (q *RequestQueue) Acquire(ctx context.Context, backend string, limit int) (func(), error) {
+	if limit <= 0 {
+		return func() {}, nil
+	}
+
+	q.mu.Lock()
+	bq, ok := q.backends[backend]
+	if !ok {
+		bq = &backendQueue{waiters: list.New()}
+		q.backends[backend] = bq
+	}
+
+	// Take a free slot unless others are already waiting for one
+	if bq.active < limit && bq.waiters.Len() == 0 {
+		bq.active++
+		q.mu.Unlock()
+		return q.releaseFunc(backend, limit), nil
+	}
+
+	if bq.waiters.Len() >= q.config.MaxSize {
+		q.mu.Unlock()
+		return nil, ErrQueueFull
+	}
+
+	waiter := &queueWaiter{ready: make(chan struct{})}
+	elem := bq.waiters.PushBack(waiter)
+	queueDepth.WithLabelValues(backend).Set(float64(bq.waiters.Len()))
+	q.mu.Unlock()
+
+	start := time.Now()
+	defer func() {
+		queueWaitSeconds.WithLabelValues(backend).Observe(time.Since(start).Seconds())
+	}()
+
+	var timeout <-chan time.Time
+	if q.config.MaxWait > 0 {
+		timer := time.NewTimer(q.config.MaxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var err error
+	select {
+	case <-waiter.ready:
+		return q.releaseFunc(backend, limit), nil
+	case <-timeout:
+		err = ErrQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if waiter.granted {
+		// A slot was handed over just as we gave up; use it
+		return q.releaseFunc(backend, limit), nil
+	}
+	bq.waiters.Remove(elem)
+	queueDepth.WithLabelValues(backend).Set(float64(bq.waiters.Len()))
+	return nil, err
+}
+
+// releaseFunc returns a function that frees a slot, handing it directly to
+// the oldest waiter if there is one
+func (q *RequestQueue) releaseFunc(backend string, limit int) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+
+			bq := q.backends[backend]
+			if front := bq.waiters.Front(); front != nil && bq.active <= limit {
+				waiter := bq.waiters.Remove(front).(*queueWaiter)
+				queueDepth.WithLabelValues(backend).Set(float64(bq.waiters.Len()))
+				waiter.granted = true
+				close(waiter.ready)
+				return
+			}
+			bq.active--
+		})
+	}
+}
+
+// Depth returns the number of requests waiting for the backend
+func (q *RequestQueue) Depth(backend string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if bq, ok := q.backends[backend]; ok {
+		return bq.waiters.Len()
+	}
+	return 0
+}
+
+// Active returns the number of in-flight requests holding a slot for the backend
+func (q *RequestQueue) Active(backend string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if bq, ok := q.backends[backend]; ok {
+		return bq.active
+	}
+	return 0
+}
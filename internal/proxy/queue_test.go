@@ -0,0 +1,406 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+func TestRequestQueue_ProceedsAsSlotsFree(t *testing.T) {
+	q := NewRequestQueue(QueueConfig{MaxSize: 2, MaxWait: time.Second})
+
+	release, err := q.Acquire(context.Background(), "backend", 1)
+	if err != nil {
+		t.Fatalf("expected first request to get a slot, got %v", err)
+	}
+
+	// Two requests queue behind the first, in order
+	order := make(chan int, 2)
+	for i := 1; i <= 2; i++ {
+		go func(id int) {
+			next, err := q.Acquire(context.Background(), "backend", 1)
+			if err != nil {
+				t.Errorf("queued request %d failed: %v", id, err)
+				return
+			}
+			order <- id
+			next()
+		}(i)
+
+		// Wait until the request is queued so the order is deterministic
+		waitFor(t, func() bool { return q.Depth("backend") == i })
+	}
+
+	if q.Active("backend") != 1 {
+		t.Errorf("expected 1 active request, got %d", q.Active("backend"))
+	}
+
+	release()
+	release() // releasing twice is a no-op
+
+	for want := 1; want <= 2; want++ {
+		select {
+		case got := <-order:
+			if got != want {
+				t.Errorf("expected request %d to proceed, got %d", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("queued request did not proceed")
+		}
+	}
+
+	waitFor(t, func() bool { return q.Active("backend") == 0 })
+}
+
+func TestRequestQueue_Overflow(t *testing.T) {
+	q := NewRequestQueue(QueueConfig{MaxSize: 0, MaxWait: time.Second})
+
+	release, err := q.Acquire(context.Background(), "backend", 1)
+	if err != nil {
+		t.Fatalf("expected first request to get a slot, got %v", err)
+	}
+	defer release()
+
+	if _, err := q.Acquire(context.Background(), "backend", 1); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("expected ErrQueueFull, got %v", err)
+	}
+
+	// Other backends and unlimited backends are unaffected
+	if _, err := q.Acquire(context.Background(), "other", 1); err != nil {
+		t.Errorf("expected other backend to get a slot, got %v", err)
+	}
+	if _, err := q.Acquire(context.Background(), "unlimited", 0); err != nil {
+		t.Errorf("expected unlimited backend to get a slot, got %v", err)
+	}
+}
+
+func TestRequestQueue_Timeout(t *testing.T) {
+	q := NewRequestQueue(QueueConfig{MaxSize: 1, MaxWait: 20 * time.Millisecond})
+
+	release, err := q.Acquire(context.Background(), "backend", 1)
+	if err != nil {
+		t.Fatalf("expected first request to get a slot, got %v", err)
+	}
+	defer release()
+
+	if _, err := q.Acquire(context.Background(), "backend", 1); !errors.Is(err, ErrQueueTimeout) {
+		t.Errorf("expected ErrQueueTimeout, got %v", err)
+	}
+	if q.Depth("backend") != 0 {
+		t.Errorf("expected timed out request to leave the queue, depth = %d", q.Depth("backend"))
+	}
+}
+
+func TestBackendHandler_ExecuteWithFallback_QueueOverflow(t *testing.T) {
+	store := cache.NewStore()
+	handler := NewBackendHandler(store, nil, zap.New(), nil, nil, nil)
+	queue := NewRequestQueue(QueueConfig{MaxSize: 0})
+	handler.SetRequestQueue(queue)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "limited"}, &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "limited", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type:           gatewayv1alpha1.BackendTypeExternal,
+			External:       &gatewayv1alpha1.ExternalBackend{URL: upstream.URL, Provider: "custom"},
+			MaxConcurrency: 1,
+		},
+		Status: gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+	})
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-route", Namespace: "default"},
+	}
+	primary := gatewayv1alpha1.BackendRef{Name: "limited"}
+
+	// Occupy the only slot
+	release, err := queue.Acquire(context.Background(), "limited", 1)
+	if err != nil {
+		t.Fatalf("failed to occupy slot: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	handler.ExecuteWithFallback(req.Context(), rec, req, route, nil, primary)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 at capacity, got %d", rec.Code)
+	}
+
+	// Once the slot is free the request is served and releases its slot
+	release()
+	req = httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	rec = httptest.NewRecorder()
+	handler.ExecuteWithFallback(req.Context(), rec, req, route, nil, primary)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200 with a free slot, got %d", rec.Code)
+	}
+	if queue.Active("limited") != 0 {
+		t.Errorf("expected slot to be released, active = %d", queue.Active("limited"))
+	}
+}
+
+// failingWriter fails every write, like a connection to a client that left
+type failingWriter struct {
+	header http.Header
+}
+
+func (f *failingWriter) Header() http.Header { return f.header }
+
+func (f *failingWriter) Write([]byte) (int, error) { return 0, errors.New("client disconnected") }
+
+func (f *failingWriter) WriteHeader(int) {}
+
+func TestBackendHandler_ExecuteWithFallback_ReleasesSlotOnAbort(t *testing.T) {
+	store := cache.NewStore()
+	handler := NewBackendHandler(store, nil, zap.New(), NewMetricsRecorder(), nil, nil)
+	queue := NewRequestQueue(QueueConfig{MaxSize: 0})
+	handler.SetRequestQueue(queue)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data: chunk\n\n"))
+	}))
+	defer upstream.Close()
+
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "limited"}, &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "limited", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type:           gatewayv1alpha1.BackendTypeExternal,
+			External:       &gatewayv1alpha1.ExternalBackend{URL: upstream.URL, Provider: "custom"},
+			MaxConcurrency: 1,
+		},
+		Status: gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+	})
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-route", Namespace: "default"},
+	}
+
+	// Under an http.Server, ReverseProxy aborts the handler when copying the
+	// response to the client fails
+	ctx := context.WithValue(context.Background(), http.ServerContextKey, &http.Server{})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil).WithContext(ctx)
+	func() {
+		defer func() {
+			if r := recover(); r != http.ErrAbortHandler {
+				t.Errorf("expected the handler to abort, got %v", r)
+			}
+		}()
+		handler.ExecuteWithFallback(ctx, &failingWriter{header: http.Header{}}, req, route, nil, gatewayv1alpha1.BackendRef{Name: "limited"})
+	}()
+
+	if queue.Active("limited") != 0 {
+		t.Errorf("expected the aborted request to release its slot, active = %d", queue.Active("limited"))
+	}
+}
+
+func TestRequestQueue_Metrics(t *testing.T) {
+	q := NewRequestQueue(QueueConfig{MaxSize: 5, MaxWait: time.Second})
+	const backend = "metrics-queue-backend"
+
+	readWaits := func() *dto.Histogram {
+		metric := &dto.Metric{}
+		if err := queueWaitSeconds.WithLabelValues(backend).(prometheus.Metric).Write(metric); err != nil {
+			t.Fatalf("failed to read queue wait histogram: %v", err)
+		}
+		return metric.GetHistogram()
+	}
+	before := readWaits()
+
+	release, err := q.Acquire(context.Background(), backend, 1)
+	if err != nil {
+		t.Fatalf("expected first request to get a slot, got %v", err)
+	}
+
+	// Queue two requests behind the slot holder
+	done := make(chan struct{}, 2)
+	for i := 1; i <= 2; i++ {
+		go func() {
+			next, err := q.Acquire(context.Background(), backend, 1)
+			if err == nil {
+				next()
+			}
+			done <- struct{}{}
+		}()
+		waitFor(t, func() bool { return q.Depth(backend) == i })
+	}
+
+	if got := testutil.ToFloat64(queueDepth.WithLabelValues(backend)); got != 2 {
+		t.Errorf("expected queue depth gauge 2, got %v", got)
+	}
+
+	const held = 20 * time.Millisecond
+	time.Sleep(held)
+	release()
+	<-done
+	<-done
+
+	if got := testutil.ToFloat64(queueDepth.WithLabelValues(backend)); got != 0 {
+		t.Errorf("expected queue depth gauge 0 once drained, got %v", got)
+	}
+
+	after := readWaits()
+	if got := after.GetSampleCount() - before.GetSampleCount(); got != 2 {
+		t.Fatalf("expected 2 queue wait observations, got %d", got)
+	}
+	if got := after.GetSampleSum() - before.GetSampleSum(); got < 2*held.Seconds() {
+		t.Errorf("expected total wait of at least %v, got %vs", 2*held, got)
+	}
+}
+
+// waitFor polls until cond is true or fails the test after a second
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRouter_HandleRequest_ConcurrencyAwareSelection(t *testing.T) {
+	store := cache.NewStore()
+	served := make(map[string]int)
+	for _, name := range []string{"busy", "free"} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			served[name]++
+		}))
+		defer server.Close()
+		backend := newExternalTestBackend(name, server.URL)
+		backend.Spec.MaxConcurrency = 1
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, backend)
+	}
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Rules: []gatewayv1alpha1.RouteRule{{
+				Backends: []gatewayv1alpha1.BackendRef{{Name: "busy"}, {Name: "free"}},
+			}},
+			SelectionMode: gatewayv1alpha1.SelectionModeConcurrencyAware,
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	}
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "chat"}, route)
+
+	queue := NewRequestQueue(QueueConfig{MaxSize: 10, MaxWait: 10 * time.Millisecond})
+	router := NewRouter(store, nil, zap.New(), WithRouterRequestQueue(queue))
+	send := func(n int) {
+		for i := 0; i < n; i++ {
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+			rec := httptest.NewRecorder()
+			router.HandleRequest(req.Context(), rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", rec.Code)
+			}
+		}
+	}
+
+	// While busy is at its limit, its share of traffic goes to free
+	release, err := queue.Acquire(context.Background(), "busy", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	send(20)
+	if served["busy"] != 0 || served["free"] != 20 {
+		t.Errorf("expected every request to skip the saturated backend, got %v", served)
+	}
+
+	// Once it has a free slot it is selected again
+	release()
+	send(50)
+	if served["busy"] == 0 {
+		t.Errorf("expected the backend to get traffic again once unsaturated, got %v", served)
+	}
+
+	// If every backend is saturated, weighted selection is unchanged and
+	// requests queue as before
+	releaseBusy, _ := queue.Acquire(context.Background(), "busy", 1)
+	releaseFree, _ := queue.Acquire(context.Background(), "free", 1)
+	defer releaseBusy()
+	defer releaseFree()
+	backends := route.Spec.Rules[0].Backends
+	if got := router.excludeSaturated("default", backends); len(got) != 2 {
+		t.Errorf("expected all backends to be kept when all are saturated, got %v", got)
+	}
+
+	// A backend with a weight of zero isn't an alternative to saturated ones
+	withDrained := append([]gatewayv1alpha1.BackendRef{{Name: "drained", Weight: ptr.To[int32](0)}}, backends...)
+	if got := router.excludeSaturated("default", withDrained); len(got) != 3 {
+		t.Errorf("expected all backends to be kept when only a weight-0 one is free, got %v", got)
+	}
+	route.Spec.Rules[0].Backends = withDrained
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "chat"}, route)
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	router.HandleRequest(req.Context(), rec, req)
+	if strings.Contains(rec.Body.String(), "weight of zero") {
+		t.Errorf("expected saturated backends to be reported as such, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestBackendHandler_Saturated(t *testing.T) {
+	backend := newExternalTestBackend("vllm", "http://vllm")
+	backend.Spec.MaxConcurrency = 1
+
+	handler := NewBackendHandler(cache.NewStore(), nil, zap.New(), nil, nil, nil)
+	if handler.saturated(backend) {
+		t.Error("expected no backend to be saturated without a request queue")
+	}
+
+	queue := NewRequestQueue(QueueConfig{MaxSize: 10, MaxWait: time.Second})
+	handler.SetRequestQueue(queue)
+	if handler.saturated(backend) {
+		t.Error("expected an idle backend not to be saturated")
+	}
+	release, _ := queue.Acquire(context.Background(), "vllm", 1)
+	if !handler.saturated(backend) {
+		t.Error("expected a backend at MaxConcurrency to be saturated")
+	}
+	release()
+
+	// Without a MaxConcurrency, a backend is never saturated
+	backend.Spec.MaxConcurrency = 0
+	release, _ = queue.Acquire(context.Background(), "vllm", 1)
+	defer release()
+	if handler.saturated(backend) {
+		t.Error("expected a backend without a limit not to be saturated")
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+)
+
+// minRampUpFactor is the share of its weight a backend gets the moment it
+// recovers, so that it still receives some traffic to warm up with
+const minRampUpFactor = 0.1
+
+// applyRecoveryRampUp scales down the share of traffic of backends that
+// became healthy within the ramp-up window, growing linearly from
+// minRampUpFactor to their full weight by the end of it. This keeps a cold
+// backend that just recovered, or was just added, from taking its full share
+// at once.
+func (r *Router) applyRecoveryRampUp(namespace string, backends []gatewayv1alpha1.BackendRef) []gatewayv1alpha1.BackendRef {
+	if r.rampUp <= 0 {
+		return backends
+	}
+
+	now := time.Now()
+	var ramped []gatewayv1alpha1.BackendRef
+	for i, b := range backends {
+		backend, ok := r.cache.GetBackendByName(namespace, b.Name)
+		if !ok {
+			continue
+		}
+		factor := rampUpFactor(backend, now, r.rampUp)
+		if factor >= 1 {
+			continue
+		}
+
+		if ramped == nil {
+			ramped = append([]gatewayv1alpha1.BackendRef(nil), backends...)
+		}
+		share := strconv.FormatFloat(backendShare(b)*factor, 'f', -1, 64)
+		ramped[i].Fraction = &share
+	}
+
+	if ramped == nil {
+		return backends
+	}
+	return ramped
+}
+
+// rampUpFactor returns the fraction of its weight a backend gets, from the
+// time its Healthy condition last became true. Backends that aren't healthy
+// or recovered before the window get their full weight.
+func rampUpFactor(backend *gatewayv1alpha1.InferenceBackend, now time.Time, window time.Duration) float64 {
+	condition := meta.FindStatusCondition(backend.Status.Conditions, "Healthy")
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		return 1
+	}
+
+	elapsed := now.Sub(condition.LastTransitionTime.Time)
+	if elapsed >= window {
+		return 1
+	}
+	return max(float64(elapsed)/float64(window), minRampUpFactor)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// newRecoveredBackend creates a healthy backend whose Healthy condition
+// became true at the given time
+func newRecoveredBackend(name string, recovered time.Time) *gatewayv1alpha1.InferenceBackend {
+	backend := newExternalTestBackend(name, "http://"+name)
+	backend.Status.Conditions = []metav1.Condition{{
+		Type:               "Healthy",
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(recovered),
+	}}
+	return backend
+}
+
+func TestRampUpFactor(t *testing.T) {
+	now := time.Now()
+	unhealthy := newRecoveredBackend("down", now)
+	unhealthy.Status.Conditions[0].Status = metav1.ConditionFalse
+
+	tests := []struct {
+		name    string
+		backend *gatewayv1alpha1.InferenceBackend
+		want    float64
+	}{
+		{"just recovered", newRecoveredBackend("b", now), minRampUpFactor},
+		{"halfway", newRecoveredBackend("b", now.Add(-30*time.Second)), 0.5},
+		{"window over", newRecoveredBackend("b", now.Add(-time.Minute)), 1},
+		{"not healthy", unhealthy, 1},
+		{"no condition", newExternalTestBackend("b", "http://b"), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rampUpFactor(tt.backend, now, time.Minute); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("expected factor %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRouter_RecoveryRampUp(t *testing.T) {
+	store := cache.NewStore()
+	router := NewRouter(store, nil, zap.New(),
+		WithRouterRecoveryRampUp(time.Minute),
+		WithRouterRand(rand.New(rand.NewSource(1))),
+	)
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "steady"}, newRecoveredBackend("steady", time.Now().Add(-time.Hour)))
+	backends := []gatewayv1alpha1.BackendRef{{Name: "steady"}, {Name: "recovered"}}
+
+	share := func(recovered time.Time) float64 {
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: "recovered"}, newRecoveredBackend("recovered", recovered))
+		ramped := router.applyRecoveryRampUp("default", backends)
+		if got := backendShare(ramped[0]); got != 1 {
+			t.Errorf("expected the steady backend to keep its full weight, got %v", got)
+		}
+		return backendShare(ramped[1])
+	}
+
+	// A just-recovered backend starts with a reduced weight that grows
+	early := share(time.Now().Add(-6 * time.Second))
+	later := share(time.Now().Add(-45 * time.Second))
+	if early >= later || later >= 1 {
+		t.Errorf("expected the weight to grow over the window, got %v then %v", early, later)
+	}
+	if done := share(time.Now().Add(-2 * time.Minute)); done != 1 {
+		t.Errorf("expected full weight after the window, got %v", done)
+	}
+	if backends[1].Fraction != nil {
+		t.Error("expected the route's backends not to be modified")
+	}
+
+	// Selection follows the reduced weight
+	share(time.Now())
+	picks := 0
+	for i := 0; i < 1000; i++ {
+		if router.selectWeightedBackend(router.applyRecoveryRampUp("default", backends)).Name == "recovered" {
+			picks++
+		}
+	}
+	if picks == 0 || picks > 200 {
+		t.Errorf("expected the recovered backend to get roughly 10%% of traffic, got %d of 1000", picks)
+	}
+}
@@ -40,7 +40,8 @@ type RateLimiter struct {
 	// routeLimiters stores per-route rate limiters
 	routeLimiters map[string]*rate.Limiter
 
-	// userLimiters stores per-user rate limiters (key: "route:user")
+	// userLimiters stores per-user rate limiters (key: "route:user", or
+	// "*:user" for limits shared across routes)
 	userLimiters map[string]*rate.Limiter
 
 	// cleanupInterval for removing stale user limiters
@@ -94,7 +95,7 @@ func (r *RateLimiter) Allow(routeName, userID string, config *gatewayv1alpha1.Ra
 
 	// Check per-user limit if enabled
 	if config.PerUser && userID != "" {
-		userKey := routeName + ":" + userID
+		userKey := userLimiterKey(routeName, userID, config)
 		limiter := r.getOrCreateLimiter(r.userLimiters, userKey, rps, burst)
 		r.lastAccess[userKey] = time.Now()
 
@@ -144,12 +145,26 @@ func (r *RateLimiter) Allow(routeName, userID string, config *gatewayv1alpha1.Ra
 	}
 }
 
-// getOrCreateLimiter gets an existing limiter or creates a new one
+// userLimiterKey returns the key of a user's limiter. Users limited across
+// all routes share a key that can't collide with a route name.
+func userLimiterKey(routeName, userID string, config *gatewayv1alpha1.RateLimitConfig) string {
+	if config.Scope == gatewayv1alpha1.RateLimitScopeGlobalUser {
+		return "*:" + userID
+	}
+	return routeName + ":" + userID
+}
+
+// getOrCreateLimiter gets an existing limiter or creates a new one. An
+// existing limiter follows changes to the configured rate, such as when a
+// route's limit is overridden or the override is removed.
 func (r *RateLimiter) getOrCreateLimiter(limiters map[string]*rate.Limiter, key string, rps float64, burst int) *rate.Limiter {
 	limiter, exists := limiters[key]
 	if !exists {
 		limiter = rate.NewLimiter(rate.Limit(rps), burst)
 		limiters[key] = limiter
+	} else if limiter.Limit() != rate.Limit(rps) || limiter.Burst() != burst {
+		limiter.SetLimit(rate.Limit(rps))
+		limiter.SetBurst(burst)
 	}
 	return limiter
 }
@@ -186,7 +201,8 @@ func (r *RateLimiter) RemoveRouteLimit(routeName string) {
 
 	delete(r.routeLimiters, routeName)
 
-	// Also remove all user limiters for this route
+	// Also remove all user limiters for this route. Limiters shared across
+	// routes are left to expire.
 	for key := range r.userLimiters {
 		if len(key) > len(routeName) && key[:len(routeName)+1] == routeName+":" {
 			delete(r.userLimiters, key)
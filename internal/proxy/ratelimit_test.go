@@ -94,6 +94,39 @@ func TestRateLimiter_Allow_DifferentRoutes(t *testing.T) {
 	}
 }
 
+func TestRateLimiter_Allow_GlobalUserScope(t *testing.T) {
+	rl := NewRateLimiter()
+	defer rl.Stop()
+	config := &gatewayv1alpha1.RateLimitConfig{
+		RequestsPerMinute: 2,
+		PerUser:           true,
+		Scope:             gatewayv1alpha1.RateLimitScopeGlobalUser,
+	}
+
+	// The user's quota is shared across both routes
+	if !rl.Allow("chat", "user1", config).Allowed {
+		t.Error("first request on chat should be allowed")
+	}
+	if !rl.Allow("embeddings", "user1", config).Allowed {
+		t.Error("first request on embeddings should be allowed")
+	}
+	if rl.Allow("chat", "user1", config).Allowed {
+		t.Error("third request across routes should exceed the shared quota")
+	}
+
+	// Other users have their own quota
+	if !rl.Allow("chat", "user2", config).Allowed {
+		t.Error("first request for user2 should be allowed")
+	}
+
+	// Route-scoped limits stay separate per route
+	routeScoped := &gatewayv1alpha1.RateLimitConfig{RequestsPerMinute: 1, PerUser: true}
+	rl.Allow("chat", "user3", routeScoped)
+	if !rl.Allow("embeddings", "user3", routeScoped).Allowed {
+		t.Error("route-scoped limit should not be shared across routes")
+	}
+}
+
 func TestRateLimiter_Allow_RetryAfterProvided(t *testing.T) {
 	rl := NewRateLimiter()
 	config := &gatewayv1alpha1.RateLimitConfig{
@@ -152,6 +185,35 @@ func TestRateLimiter_UpdateRouteLimit(t *testing.T) {
 	}
 }
 
+func TestRateLimiter_FollowsOverriddenLimit(t *testing.T) {
+	rl := NewRateLimiter()
+	spec := &gatewayv1alpha1.RateLimitConfig{RequestsPerMinute: 60}
+	override := &gatewayv1alpha1.RateLimitConfig{RequestsPerMinute: 2}
+
+	rl.Allow("tuned", "", spec)
+
+	// The override tightens the existing limiter
+	rl.UpdateRouteLimit("tuned", override)
+	for i := 0; i < 2; i++ {
+		rl.Allow("tuned", "", override)
+	}
+	if result := rl.Allow("tuned", "", override); result.Allowed || result.Limit != 2 {
+		t.Errorf("expected the overridden limit of 2 to deny the request, got %+v", result)
+	}
+
+	// Removing the override restores the spec's limit
+	result := rl.Allow("tuned", "", spec)
+	if result.Limit != 60 {
+		t.Errorf("expected the spec limit of 60, got %d", result.Limit)
+	}
+	rl.mu.RLock()
+	burst := rl.routeLimiters["tuned"].Burst()
+	rl.mu.RUnlock()
+	if burst != 60 {
+		t.Errorf("expected the limiter to return to a burst of 60, got %d", burst)
+	}
+}
+
 func TestRateLimiter_UpdateRouteLimit_NilConfig(t *testing.T) {
 	rl := NewRateLimiter()
 	config := &gatewayv1alpha1.RateLimitConfig{
@@ -0,0 +1,38 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// BackendReadinessCheck returns a readiness check that fails while fewer than
+// minHealthy backends are healthy across all namespaces, so Kubernetes stops
+// sending traffic to a proxy that can't serve any of it
+func BackendReadinessCheck(store *cache.Store, minHealthy int) healthz.Checker {
+	return func(_ *http.Request) error {
+		if healthy := store.CountHealthyBackends(); healthy < minHealthy {
+			return fmt.Errorf("%d of the %d required backends are healthy", healthy, minHealthy)
+		}
+		return nil
+	}
+}
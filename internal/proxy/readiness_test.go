@@ -0,0 +1,53 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+func TestBackendReadinessCheck(t *testing.T) {
+	store := cache.NewStore()
+	check := BackendReadinessCheck(store, 1)
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+	if err := check(req); err == nil {
+		t.Error("expected the check to fail with no backends")
+	}
+
+	unhealthy := newExternalTestBackend("down", "http://down.example.com")
+	unhealthy.Status.Health = "Unhealthy"
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "down"}, unhealthy)
+	if err := check(req); err == nil {
+		t.Error("expected the check to fail with only unhealthy backends")
+	}
+
+	store.SetBackend(types.NamespacedName{Namespace: "other", Name: "up"}, newExternalTestBackend("up", "http://up.example.com"))
+	if err := check(req); err != nil {
+		t.Errorf("expected the check to pass with a healthy backend: %v", err)
+	}
+
+	if err := BackendReadinessCheck(store, 2)(req); err == nil {
+		t.Error("expected the check to fail below the configured minimum")
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// RecheckPath is the admin path that re-probes every backend in a namespace
+const RecheckPath = "/v1/backends/recheck"
+
+// BackendRechecker triggers immediate health checks of the backends in a
+// namespace, returning how many were scheduled
+type BackendRechecker interface {
+	Recheck(ctx context.Context, namespace string) (int, error)
+}
+
+// RecheckResponse is the response to POST /v1/backends/recheck
+type RecheckResponse struct {
+	Namespace string `json:"namespace"`
+	Backends  int    `json:"backends"`
+}
+
+// recheckAuthorized reports whether the request carries the admin token as a
+// bearer token. Without a configured token every request is refused.
+func recheckAuthorized(req *http.Request, token string) bool {
+	if token == "" {
+		return false
+	}
+	presented, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1
+}
+
+// isRecheckRequest reports whether the request is for the recheck endpoint
+func isRecheckRequest(req *http.Request) bool {
+	return req.URL.Path == RecheckPath
+}
+
+// serveRecheck answers POST /v1/backends/recheck by scheduling a health check
+// of every backend in the request's namespace (X-Namespace, or default).
+// The endpoint shares the inference listener, so it requires the admin token.
+func (s *Server) serveRecheck(w http.ResponseWriter, req *http.Request) {
+	if !recheckAuthorized(req, s.recheckToken) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	namespace := req.Header.Get("X-Namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	count, err := s.rechecker.Recheck(req.Context(), namespace)
+	if err != nil {
+		s.log.Error(err, "Failed to schedule backend recheck", "namespace", namespace)
+		http.Error(w, "Failed to schedule backend recheck", http.StatusInternalServerError)
+		return
+	}
+	s.log.Info("Scheduled backend recheck", "namespace", namespace, "backends", count)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(RecheckResponse{Namespace: namespace, Backends: count}); err != nil {
+		s.log.V(1).Info("Failed to write recheck response", "error", err)
+	}
+}
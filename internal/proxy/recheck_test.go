@@ -0,0 +1,138 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+const testRecheckToken = "admin-token"
+
+// newRecheckRequest builds a recheck request carrying the admin token
+func newRecheckRequest(method string) *http.Request {
+	req := httptest.NewRequest(method, RecheckPath, nil)
+	req.Header.Set("Authorization", "Bearer "+testRecheckToken)
+	return req
+}
+
+// fakeRechecker records the namespaces it was asked to recheck
+type fakeRechecker struct {
+	namespaces []string
+	backends   int
+	err        error
+}
+
+func (f *fakeRechecker) Recheck(_ context.Context, namespace string) (int, error) {
+	f.namespaces = append(f.namespaces, namespace)
+	return f.backends, f.err
+}
+
+func TestServer_RecheckBackends(t *testing.T) {
+	rechecker := &fakeRechecker{backends: 3}
+	server := NewServer(DefaultConfig(), cache.NewStore(), nil, zap.New(), WithBackendRechecker(rechecker, testRecheckToken))
+
+	req := newRecheckRequest(http.MethodPost)
+	req.Header.Set("X-Namespace", "inference")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d", rec.Code)
+	}
+	var response RecheckResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Namespace != "inference" || response.Backends != 3 {
+		t.Errorf("expected 3 backends rechecked in inference, got %+v", response)
+	}
+
+	// The namespace defaults like the rest of the proxy
+	server.ServeHTTP(httptest.NewRecorder(), newRecheckRequest(http.MethodPost))
+	if len(rechecker.namespaces) != 2 || rechecker.namespaces[1] != "default" {
+		t.Errorf("expected rechecks of inference then default, got %v", rechecker.namespaces)
+	}
+}
+
+func TestServer_RecheckBackendsErrors(t *testing.T) {
+	rechecker := &fakeRechecker{err: errors.New("list failed")}
+	server := NewServer(DefaultConfig(), cache.NewStore(), nil, zap.New(), WithBackendRechecker(rechecker, testRecheckToken))
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, newRecheckRequest(http.MethodGet))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("expected 405 allowing POST, got %d %q", rec.Code, rec.Header().Get("Allow"))
+	}
+
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, newRecheckRequest(http.MethodPost))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 when the recheck fails, got %d", rec.Code)
+	}
+}
+
+func TestServer_RecheckBackendsUnauthorized(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+	}{
+		{"no credentials", testRecheckToken, ""},
+		{"wrong token", testRecheckToken, "Bearer wrong-token"},
+		{"not a bearer token", testRecheckToken, testRecheckToken},
+		{"no token configured", "", "Bearer "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rechecker := &fakeRechecker{backends: 3}
+			server := NewServer(DefaultConfig(), cache.NewStore(), nil, zap.New(), WithBackendRechecker(rechecker, tt.token))
+
+			req := httptest.NewRequest(http.MethodPost, RecheckPath, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("expected status 401, got %d", rec.Code)
+			}
+			if len(rechecker.namespaces) != 0 {
+				t.Errorf("expected no recheck, got %v", rechecker.namespaces)
+			}
+		})
+	}
+}
+
+func TestServer_RecheckDisabled(t *testing.T) {
+	server := NewServer(DefaultConfig(), cache.NewStore(), nil, zap.New())
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, RecheckPath, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected the path to be routed like any other without a rechecker, got %d", rec.Code)
+	}
+}
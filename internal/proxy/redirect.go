@@ -0,0 +1,186 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// errRedirectTargetNotPublic is returned when a redirect to another host
+// resolves to a private, loopback or link-local address
+var errRedirectTargetNotPublic = errors.New("redirect target is not a public address")
+
+// redirectTransport follows backend redirects server-side, so that clients
+// get the final response instead of a Location they usually can't reach.
+// The method and body are kept on every redirect except 303 See Other, since
+// an inference request that became a GET would be meaningless.
+//
+// Only redirects to the backend's own host or to an allowed host are
+// followed; others are returned to the client as is. Redirects to another
+// host are sent through external, which refuses to connect to anything but
+// public addresses, so a backend can't point the proxy at internal services.
+type redirectTransport struct {
+	base         http.RoundTripper
+	external     http.RoundTripper
+	maxHops      int
+	allowedHosts []string
+}
+
+// newRedirectTransport follows up to maxHops redirects to the backend's host
+// and the allowed hosts and their subdomains. After that the last redirect is
+// returned to the client as is.
+func newRedirectTransport(base http.RoundTripper, maxHops int, allowedHosts ...string) *redirectTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &redirectTransport{
+		base:         base,
+		external:     newPublicTransport(),
+		maxHops:      maxHops,
+		allowedHosts: allowedHosts,
+	}
+}
+
+// newPublicTransport creates a transport that only connects to public
+// addresses. The address is checked after DNS resolution, so a hostname can't
+// be rebound to an internal address between the check and the connection.
+func newPublicTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !isPublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", errRedirectTargetNotPublic, address)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// An HTTP proxy would connect to the target on our behalf, bypassing
+	// the address check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// isPublicAddr reports whether the address is routable on the internet
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() && !addr.IsPrivate() && !addr.IsLoopback() && !addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() && !addr.IsInterfaceLocalMulticast() && !addr.IsUnspecified()
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Buffer the body so it can be sent again to the redirect target
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	originalHost := req.URL.Hostname()
+	transport := t.base
+	for hop := 0; ; hop++ {
+		resp, err := transport.RoundTrip(req)
+		if err != nil || hop >= t.maxHops || !isRedirect(resp.StatusCode) {
+			return resp, err
+		}
+
+		location, err := resp.Location()
+		if err != nil || !t.allowed(location.Scheme, location.Hostname(), originalHost) {
+			// A redirect without a usable or allowed Location is passed through
+			return resp, nil
+		}
+		transport = t.base
+		if !strings.EqualFold(location.Hostname(), originalHost) {
+			transport = t.external
+		}
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		_ = resp.Body.Close()
+
+		next := req.Clone(req.Context())
+		next.URL = location
+		next.Host = location.Host
+		if resp.StatusCode == http.StatusSeeOther {
+			next.Method = http.MethodGet
+			next.Body = nil
+			next.ContentLength = 0
+			next.Header.Del("Content-Type")
+			next.Header.Del("Content-Length")
+			body = nil
+		} else if body != nil {
+			next.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		// Credentials are only sent on to the same host or its subdomains
+		if !sameOrSubdomain(location.Hostname(), originalHost) {
+			for _, header := range []string{"Authorization", "X-Api-Key", "Cookie"} {
+				next.Header.Del(header)
+			}
+		}
+		req = next
+	}
+}
+
+// allowed reports whether a redirect to the scheme and host may be followed
+func (t *redirectTransport) allowed(scheme, host, originalHost string) bool {
+	if scheme != "http" && scheme != "https" {
+		return false
+	}
+	if strings.EqualFold(host, originalHost) {
+		return true
+	}
+	for _, allowed := range t.allowedHosts {
+		if sameOrSubdomain(host, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRedirect reports whether the status is a redirect that can be followed
+func isRedirect(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	default:
+		return false
+	}
+}
+
+// sameOrSubdomain reports whether host is parent or one of its subdomains
+func sameOrSubdomain(host, parent string) bool {
+	host, parent = strings.ToLower(host), strings.ToLower(parent)
+	return host == parent || strings.HasSuffix(host, "."+parent)
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// executeRedirectTest sends a chat completion with a body to the backend
+func executeRedirectTest(handler *BackendHandler, backendURL string) *httptest.ResponseRecorder {
+	handler.cache.SetBackend(types.NamespacedName{Namespace: "default", Name: "regional"}, newExternalTestBackend("regional", backendURL))
+	route := &gatewayv1alpha1.InferenceRoute{ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"}}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"gpt-4o"}`))
+	rec := httptest.NewRecorder()
+	handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "regional"})
+	return rec
+}
+
+func TestBackendHandler_FollowsRedirects(t *testing.T) {
+	final := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || string(body) != `{"model":"gpt-4o"}` {
+			t.Errorf("expected the POST body to follow the redirect, got %s %q", r.Method, body)
+		}
+		_, _ = w.Write([]byte(`{"id":"final"}`))
+	}))
+	defer final.Close()
+	redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, final.URL+r.URL.Path, http.StatusFound)
+	}))
+	defer redirecting.Close()
+
+	handler := NewBackendHandler(cache.NewStore(), nil, zap.New(), nil, nil, nil)
+	handler.SetMaxRedirects(3)
+	rec := executeRedirectTest(handler, redirecting.URL)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected the final status 200, got %d", rec.Code)
+	}
+	if got := rec.Body.String(); got != `{"id":"final"}` {
+		t.Errorf("expected the final body, got %q", got)
+	}
+}
+
+func TestBackendHandler_RedirectsPassedThroughByDefault(t *testing.T) {
+	redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://internal.example/v1/chat/completions", http.StatusFound)
+	}))
+	defer redirecting.Close()
+
+	handler := NewBackendHandler(cache.NewStore(), nil, zap.New(), nil, nil, nil)
+	rec := executeRedirectTest(handler, redirecting.URL)
+
+	if rec.Code != http.StatusFound {
+		t.Errorf("expected the redirect to reach the client, got %d", rec.Code)
+	}
+}
+
+func TestRedirectTransport_HopLimit(t *testing.T) {
+	var hits atomic.Int32
+	var loop *httptest.Server
+	loop = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.Redirect(w, r, loop.URL, http.StatusTemporaryRedirect)
+	}))
+	defer loop.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, loop.URL, strings.NewReader("{}"))
+	resp, err := newRedirectTransport(nil, 2).RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTemporaryRedirect {
+		t.Errorf("expected the last redirect once the hop limit is reached, got %d", resp.StatusCode)
+	}
+	if got := hits.Load(); got != 3 {
+		t.Errorf("expected the original request and 2 redirects, got %d requests", got)
+	}
+}
+
+func TestRedirectTransport_DropsCredentialsForOtherHosts(t *testing.T) {
+	var authorization atomic.Value
+	final := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization.Store(r.Header.Get("Authorization"))
+	}))
+	defer final.Close()
+	// Redirect from 127.0.0.1 to localhost, which is a different host
+	otherHost := strings.Replace(final.URL, "127.0.0.1", "localhost", 1)
+	redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, otherHost, http.StatusFound)
+	}))
+	defer redirecting.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, redirecting.URL, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	transport := newRedirectTransport(nil, 1, "localhost")
+	// The test servers listen on loopback, which other hosts can't redirect to
+	transport.external = http.DefaultTransport
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if got := authorization.Load(); got != "" {
+		t.Errorf("expected the API key not to be sent to another host, got %q", got)
+	}
+}
+
+func TestRedirectTransport_OtherHostsNeedAllowing(t *testing.T) {
+	var followed atomic.Bool
+	final := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed.Store(true)
+	}))
+	defer final.Close()
+	for name, location := range map[string]string{
+		"other host": strings.Replace(final.URL, "127.0.0.1", "localhost", 1),
+		"scheme":     "file:///etc/passwd",
+	} {
+		redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, location, http.StatusFound)
+		}))
+
+		req, _ := http.NewRequest(http.MethodGet, redirecting.URL, nil)
+		resp, err := newRedirectTransport(nil, 1).RoundTrip(req)
+		redirecting.Close()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != http.StatusFound {
+			t.Errorf("%s: expected the redirect to be passed through, got %d", name, resp.StatusCode)
+		}
+	}
+	if followed.Load() {
+		t.Error("expected redirects that aren't allowed not to be followed")
+	}
+}
+
+func TestRedirectTransport_RefusesPrivateTargets(t *testing.T) {
+	var followed atomic.Bool
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed.Store(true)
+	}))
+	defer internal.Close()
+	// localhost is allowed, but resolves to a loopback address
+	otherHost := strings.Replace(internal.URL, "127.0.0.1", "localhost", 1)
+	redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, otherHost, http.StatusFound)
+	}))
+	defer redirecting.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, redirecting.URL, nil)
+	resp, err := newRedirectTransport(nil, 1, "localhost").RoundTrip(req)
+	if err == nil {
+		_ = resp.Body.Close()
+	}
+
+	if !errors.Is(err, errRedirectTargetNotPublic) {
+		t.Errorf("expected the redirect to a loopback address to be refused, got %v", err)
+	}
+	if followed.Load() {
+		t.Error("expected the internal server not to be reached")
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	for addr, public := range map[string]bool{
+		"8.8.8.8":         true,
+		"2606:4700::1111": true,
+		"10.0.0.1":        false,
+		"192.168.1.1":     false,
+		"127.0.0.1":       false,
+		"169.254.169.254": false,
+		"::1":             false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"0.0.0.0":         false,
+		"::ffff:10.0.0.1": false,
+	} {
+		if got := isPublicAddr(netip.MustParseAddr(addr)); got != public {
+			t.Errorf("isPublicAddr(%s) = %v, want %v", addr, got, public)
+		}
+	}
+}
@@ -58,6 +58,33 @@ var (
 	)
 )
 
+// BackoffStrategy determines how the delay between retries is computed
+type BackoffStrategy string
+
+const (
+	// BackoffExponential grows the delay by BackoffMultiplier each attempt with symmetric jitter
+	BackoffExponential BackoffStrategy = "Exponential"
+	// BackoffDecorrelatedJitter picks a random delay between InitialBackoff and
+	// three times the previous delay, spreading retries better under contention
+	BackoffDecorrelatedJitter BackoffStrategy = "DecorrelatedJitter"
+	// BackoffConstant always waits InitialBackoff
+	BackoffConstant BackoffStrategy = "Constant"
+)
+
+// JitterMode determines how randomness is applied to an exponential backoff
+type JitterMode string
+
+const (
+	// JitterSymmetric spreads the backoff by Jitter around its midpoint (the default)
+	JitterSymmetric JitterMode = "Symmetric"
+	// JitterFull waits random(0, backoff)
+	JitterFull JitterMode = "Full"
+	// JitterEqual waits backoff/2 + random(0, backoff/2)
+	JitterEqual JitterMode = "Equal"
+	// JitterNone always waits the exact backoff
+	JitterNone JitterMode = "None"
+)
+
 // RetryConfig holds configuration for retry behavior
 type RetryConfig struct {
 	// MaxRetries is the maximum number of retry attempts (0 = no retries)
@@ -72,9 +99,17 @@ type RetryConfig struct {
 	// BackoffMultiplier is multiplied to backoff after each retry
 	BackoffMultiplier float64
 
-	// Jitter adds randomness to backoff (0.0 = no jitter, 1.0 = full jitter)
+	// Jitter adds randomness to backoff (0.0 = no jitter, 1.0 = full jitter).
+	// Only used by JitterSymmetric.
 	Jitter float64
 
+	// JitterMode selects how jitter is applied to exponential backoff
+	// (defaults to JitterSymmetric)
+	JitterMode JitterMode
+
+	// BackoffStrategy selects the backoff algorithm (defaults to Exponential)
+	BackoffStrategy BackoffStrategy
+
 	// RetryableStatusCodes are HTTP status codes that should trigger a retry
 	RetryableStatusCodes []int
 
@@ -83,19 +118,27 @@ type RetryConfig struct {
 
 	// RetryOnTimeout retries on timeout errors
 	RetryOnTimeout bool
+
+	// Budget caps retries per backend to a fraction of original requests
+	// (nil = unlimited)
+	Budget *RetryBudgetConfig
 }
 
 // DefaultRetryConfig returns sensible defaults for retry configuration
 func DefaultRetryConfig() RetryConfig {
+	budget := DefaultRetryBudgetConfig()
 	return RetryConfig{
 		MaxRetries:             3,
 		InitialBackoff:         100 * time.Millisecond,
 		MaxBackoff:             10 * time.Second,
 		BackoffMultiplier:      2.0,
 		Jitter:                 0.3, // 30% jitter
+		BackoffStrategy:        BackoffExponential,
+		JitterMode:             JitterSymmetric,
 		RetryableStatusCodes:   []int{502, 503, 504}, // Bad Gateway, Service Unavailable, Gateway Timeout
 		RetryOnConnectionError: true,
 		RetryOnTimeout:         true,
+		Budget:                 &budget,
 	}
 }
 
@@ -104,15 +147,26 @@ type Retrier struct {
 	config RetryConfig
 	log    logr.Logger
 	rng    *rand.Rand
+	budget *RetryBudget
 }
 
 // NewRetrier creates a new Retrier with the given configuration
 func NewRetrier(config RetryConfig, log logr.Logger) *Retrier {
-	return &Retrier{
+	r := &Retrier{
 		config: config,
 		log:    log.WithName("retrier"),
 		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
+	if config.Budget != nil {
+		r.budget = NewRetryBudget(*config.Budget)
+	}
+	return r
+}
+
+// Budget returns the retry budget shared by all requests through this
+// retrier, or nil if retries are unlimited
+func (r *Retrier) Budget() *RetryBudget {
+	return r.budget
 }
 
 // RetryResult contains the result of a retry operation
@@ -138,6 +192,12 @@ type RetryableFunc func(ctx context.Context, attempt int) (statusCode int, err e
 func (r *Retrier) Do(ctx context.Context, backendName string, fn RetryableFunc) RetryResult {
 	start := time.Now()
 	result := RetryResult{}
+	var backoff time.Duration
+
+	// Every original request earns a fraction of a retry
+	if r.budget != nil {
+		r.budget.RecordRequest(backendName)
+	}
 
 	for attempt := 0; attempt <= r.config.MaxRetries; attempt++ {
 		result.Attempts = attempt + 1
@@ -169,8 +229,17 @@ func (r *Retrier) Do(ctx context.Context, backendName string, fn RetryableFunc)
 			break
 		}
 
+		// Don't retry once the backend's retry budget is spent
+		if r.budget != nil && !r.budget.TryRetry(backendName) {
+			r.log.V(1).Info("Retry budget exhausted, not retrying",
+				"backend", backendName,
+				"attempt", attempt+1,
+			)
+			break
+		}
+
 		// Calculate backoff
-		backoff := r.calculateBackoff(attempt)
+		backoff = r.nextBackoff(attempt, backoff)
 
 		r.log.V(1).Info("Retrying request",
 			"backend", backendName,
@@ -280,10 +349,54 @@ func (r *Retrier) isRetryableError(err error) bool {
 	return false
 }
 
+// nextBackoff calculates the backoff for an attempt using the configured strategy.
+// previous is the backoff used before the last attempt (zero for the first retry).
+func (r *Retrier) nextBackoff(attempt int, previous time.Duration) time.Duration {
+	switch r.config.BackoffStrategy {
+	case BackoffDecorrelatedJitter:
+		return r.decorrelatedBackoff(previous)
+	case BackoffConstant:
+		return r.config.InitialBackoff
+	default:
+		return r.calculateBackoff(attempt)
+	}
+}
+
+// decorrelatedBackoff implements decorrelated jitter:
+// min(MaxBackoff, random(InitialBackoff, previous*3))
+func (r *Retrier) decorrelatedBackoff(previous time.Duration) time.Duration {
+	base := r.config.InitialBackoff
+	if base <= 0 {
+		return 0
+	}
+	if previous < base {
+		previous = base
+	}
+
+	upper := previous * 3
+	backoff := base + time.Duration(r.rng.Int63n(int64(upper-base)+1))
+
+	if r.config.MaxBackoff > 0 && backoff > r.config.MaxBackoff {
+		backoff = r.config.MaxBackoff
+	}
+	return backoff
+}
+
 // calculateBackoff calculates the backoff duration for a given attempt
 func (r *Retrier) calculateBackoff(attempt int) time.Duration {
 	// Exponential backoff: initial * multiplier^attempt
 	backoff := float64(r.config.InitialBackoff) * math.Pow(r.config.BackoffMultiplier, float64(attempt))
+	maxBackoff := float64(r.config.MaxBackoff)
+
+	switch r.config.JitterMode {
+	case JitterFull:
+		return time.Duration(r.rng.Float64() * math.Min(backoff, maxBackoff))
+	case JitterEqual:
+		half := math.Min(backoff, maxBackoff) / 2
+		return time.Duration(half + r.rng.Float64()*half)
+	case JitterNone:
+		return time.Duration(math.Min(backoff, maxBackoff))
+	}
 
 	// Apply jitter
 	if r.config.Jitter > 0 {
@@ -292,8 +405,8 @@ func (r *Retrier) calculateBackoff(attempt int) time.Duration {
 	}
 
 	// Cap at max backoff
-	if backoff > float64(r.config.MaxBackoff) {
-		backoff = float64(r.config.MaxBackoff)
+	if backoff > maxBackoff {
+		backoff = maxBackoff
 	}
 
 	return time.Duration(backoff)
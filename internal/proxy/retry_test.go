@@ -235,6 +235,136 @@ func TestRetrier_CalculateBackoffWithJitter(t *testing.T) {
 	}
 }
 
+func TestRetrier_JitterModes(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	tests := []struct {
+		mode     JitterMode
+		min, max time.Duration
+	}{
+		// Attempt 2 has a backoff of 400ms
+		{JitterFull, 0, 400 * time.Millisecond},
+		{JitterEqual, 200 * time.Millisecond, 400 * time.Millisecond},
+		{JitterNone, 400 * time.Millisecond, 400 * time.Millisecond},
+		{JitterSymmetric, 300 * time.Millisecond, 500 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			retrier := NewRetrier(RetryConfig{
+				InitialBackoff:    100 * time.Millisecond,
+				MaxBackoff:        10 * time.Second,
+				BackoffMultiplier: 2.0,
+				Jitter:            0.5,
+				JitterMode:        tt.mode,
+			}, log)
+
+			var lowest, highest time.Duration
+			for i := 0; i < 1000; i++ {
+				backoff := retrier.calculateBackoff(2)
+				if backoff < tt.min || backoff > tt.max {
+					t.Fatalf("backoff %v outside [%v, %v]", backoff, tt.min, tt.max)
+				}
+				if i == 0 || backoff < lowest {
+					lowest = backoff
+				}
+				if backoff > highest {
+					highest = backoff
+				}
+			}
+
+			// The samples should cover most of the range
+			if spread := tt.max - tt.min; highest-lowest < spread*8/10 {
+				t.Errorf("expected backoffs to spread across [%v, %v], got [%v, %v]", tt.min, tt.max, lowest, highest)
+			}
+		})
+	}
+}
+
+func TestRetrier_JitterModesRespectMaxBackoff(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	for _, mode := range []JitterMode{JitterFull, JitterEqual, JitterNone} {
+		retrier := NewRetrier(RetryConfig{
+			InitialBackoff:    100 * time.Millisecond,
+			MaxBackoff:        time.Second,
+			BackoffMultiplier: 2.0,
+			JitterMode:        mode,
+		}, log)
+
+		for i := 0; i < 100; i++ {
+			if backoff := retrier.calculateBackoff(10); backoff > time.Second {
+				t.Fatalf("%s: backoff %v exceeds the 1s maximum", mode, backoff)
+			}
+		}
+	}
+}
+
+func TestRetrier_DecorrelatedJitterBackoff(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	config := RetryConfig{
+		MaxRetries:      5,
+		InitialBackoff:  100 * time.Millisecond,
+		MaxBackoff:      2 * time.Second,
+		BackoffStrategy: BackoffDecorrelatedJitter,
+	}
+	retrier := NewRetrier(config, log)
+
+	seen := make(map[time.Duration]bool)
+	var previous time.Duration
+	for attempt := 0; attempt < 50; attempt++ {
+		backoff := retrier.nextBackoff(attempt, previous)
+		if backoff < config.InitialBackoff || backoff > config.MaxBackoff {
+			t.Fatalf("backoff %v outside [%v, %v]", backoff, config.InitialBackoff, config.MaxBackoff)
+		}
+
+		// Each delay is bounded by three times the previous one
+		upper := 3 * previous
+		if upper < 3*config.InitialBackoff {
+			upper = 3 * config.InitialBackoff
+		}
+		if backoff > upper {
+			t.Fatalf("backoff %v exceeds 3x previous (%v)", backoff, previous)
+		}
+
+		seen[backoff] = true
+		previous = backoff
+	}
+
+	if len(seen) < 2 {
+		t.Error("expected decorrelated jitter to vary between calls")
+	}
+}
+
+func TestRetrier_ConstantBackoff(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	config := RetryConfig{
+		InitialBackoff:    100 * time.Millisecond,
+		MaxBackoff:        1 * time.Second,
+		BackoffMultiplier: 2.0,
+		BackoffStrategy:   BackoffConstant,
+	}
+	retrier := NewRetrier(config, log)
+
+	for attempt := 0; attempt < 5; attempt++ {
+		if backoff := retrier.nextBackoff(attempt, 0); backoff != 100*time.Millisecond {
+			t.Errorf("expected 100ms for attempt %d, got %v", attempt, backoff)
+		}
+	}
+}
+
+func TestRetrier_DefaultStrategyIsExponential(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	config := RetryConfig{
+		InitialBackoff:    100 * time.Millisecond,
+		MaxBackoff:        1 * time.Second,
+		BackoffMultiplier: 2.0,
+	}
+	retrier := NewRetrier(config, log)
+
+	if backoff := retrier.nextBackoff(2, 0); backoff != 400*time.Millisecond {
+		t.Errorf("expected 400ms for attempt 2, got %v", backoff)
+	}
+}
+
 func TestRetrier_IsRetryableStatusCode(t *testing.T) {
 	log := zap.New(zap.UseDevMode(true))
 	config := RetryConfig{
@@ -412,4 +542,85 @@ func TestDefaultRetryConfig(t *testing.T) {
 	if !config.RetryOnTimeout {
 		t.Error("expected RetryOnTimeout=true")
 	}
+	if config.BackoffStrategy != BackoffExponential {
+		t.Errorf("expected BackoffStrategy=Exponential, got %s", config.BackoffStrategy)
+	}
+	if config.Budget == nil || config.Budget.Ratio != 0.1 {
+		t.Errorf("expected a 10%% retry budget by default, got %+v", config.Budget)
+	}
+}
+
+func TestRetrier_RetryBudgetExhausted(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	config := RetryConfig{
+		MaxRetries:           3,
+		InitialBackoff:       time.Millisecond,
+		MaxBackoff:           time.Millisecond,
+		BackoffStrategy:      BackoffConstant,
+		RetryableStatusCodes: []int{503},
+		Budget:               &RetryBudgetConfig{Ratio: 0.5, MinRetries: 2},
+	}
+	retrier := NewRetrier(config, log)
+
+	failing := func(ctx context.Context, attempt int) (int, error) {
+		return http.StatusServiceUnavailable, nil
+	}
+
+	// The reserve of 2 retries is spent by the first failing request
+	result := retrier.Do(context.Background(), "budgeted", failing)
+	if result.Attempts != 3 {
+		t.Fatalf("expected 3 attempts while the budget lasts, got %d", result.Attempts)
+	}
+
+	// Further failures don't retry
+	result = retrier.Do(context.Background(), "budgeted", failing)
+	if result.Attempts != 1 {
+		t.Errorf("expected no retries once the budget is spent, got %d attempts", result.Attempts)
+	}
+
+	// Other backends have their own budget
+	result = retrier.Do(context.Background(), "other", failing)
+	if result.Attempts != 3 {
+		t.Errorf("expected other backend to retry, got %d attempts", result.Attempts)
+	}
+
+	// Successful requests refill the budget
+	retrier.Do(context.Background(), "budgeted", func(ctx context.Context, attempt int) (int, error) {
+		return http.StatusOK, nil
+	})
+	result = retrier.Do(context.Background(), "budgeted", failing)
+	if result.Attempts != 2 {
+		t.Errorf("expected one retry after the budget refilled, got %d attempts", result.Attempts)
+	}
+}
+
+func TestRetryBudget_Ratio(t *testing.T) {
+	budget := NewRetryBudget(RetryBudgetConfig{Ratio: 0.1, MinRetries: 1})
+
+	if !budget.TryRetry("backend") {
+		t.Fatal("expected the initial reserve to allow a retry")
+	}
+	if budget.TryRetry("backend") {
+		t.Fatal("expected the budget to be spent")
+	}
+
+	// 10 requests earn one retry
+	for i := 0; i < 9; i++ {
+		budget.RecordRequest("backend")
+	}
+	if budget.TryRetry("backend") {
+		t.Error("expected 9 requests not to earn a full retry")
+	}
+	budget.RecordRequest("backend")
+	if !budget.TryRetry("backend") {
+		t.Error("expected 10 requests to earn a retry")
+	}
+
+	// The budget doesn't grow beyond MinRetries
+	for i := 0; i < 100; i++ {
+		budget.RecordRequest("backend")
+	}
+	if got := budget.Available("backend"); got != 1 {
+		t.Errorf("expected budget capped at 1, got %v", got)
+	}
 }
@@ -0,0 +1,115 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var retryBudgetExhausted = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "kortex_retry_budget_exhausted_total",
+		Help: "Total retries skipped because the backend's retry budget was spent",
+	},
+	[]string{"backend"},
+)
+
+// RetryBudgetConfig limits retries to a fraction of original requests so that
+// retries can't amplify load during an outage
+type RetryBudgetConfig struct {
+	// Ratio is the number of retries allowed per original request (0.1 = 10%)
+	Ratio float64
+
+	// MinRetries is the budget available before any traffic has been seen and
+	// the most that can be saved up, so low-traffic backends can still retry
+	MinRetries int
+}
+
+// DefaultRetryBudgetConfig allows retries for 10% of requests, with a
+// reserve of 10 retries
+func DefaultRetryBudgetConfig() RetryBudgetConfig {
+	return RetryBudgetConfig{
+		Ratio:      0.1,
+		MinRetries: 10,
+	}
+}
+
+// RetryBudget is a token bucket per backend. Every original request deposits
+// Ratio tokens and every retry withdraws one.
+type RetryBudget struct {
+	config RetryBudgetConfig
+
+	mu      sync.Mutex
+	buckets map[string]float64
+}
+
+// NewRetryBudget creates a retry budget
+func NewRetryBudget(config RetryBudgetConfig) *RetryBudget {
+	return &RetryBudget{
+		config:  config,
+		buckets: make(map[string]float64),
+	}
+}
+
+// RecordRequest credits the backend's budget for an original request
+func (b *RetryBudget) RecordRequest(backend string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tokens := b.balanceLocked(backend) + b.config.Ratio
+	b.buckets[backend] = min(tokens, b.capacity())
+}
+
+// TryRetry withdraws a retry from the backend's budget. It returns false,
+// leaving the budget unchanged, if there isn't a full retry left.
+func (b *RetryBudget) TryRetry(backend string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Allow for rounding when fractional deposits add up to a whole retry
+	tokens := b.balanceLocked(backend)
+	if tokens < 1-1e-9 {
+		retryBudgetExhausted.WithLabelValues(backend).Inc()
+		return false
+	}
+	b.buckets[backend] = max(tokens-1, 0)
+	return true
+}
+
+// Available returns the number of retries the backend can currently make
+func (b *RetryBudget) Available(backend string) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.balanceLocked(backend)
+}
+
+// balanceLocked returns the backend's tokens, starting new backends with a
+// full reserve. The caller must hold b.mu.
+func (b *RetryBudget) balanceLocked(backend string) float64 {
+	tokens, ok := b.buckets[backend]
+	if !ok {
+		tokens = b.capacity()
+	}
+	return tokens
+}
+
+// capacity is the most tokens a backend can hold
+func (b *RetryBudget) capacity() float64 {
+	return float64(max(b.config.MinRetries, 1))
+}
@@ -18,10 +18,15 @@ package proxy
 
 import (
 	"context"
+	"errors"
 	"math/rand"
 	"net/http"
 	"path"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	"go.opentelemetry.io/otel/attribute"
@@ -34,6 +39,16 @@ import (
 	"github.com/judeoyovbaire/kortex/internal/tracing"
 )
 
+// BackendHintHeader lets clients suggest, but not force, a backend within the matched rule
+const BackendHintHeader = "X-Backend-Hint"
+
+// BackendPinHeader forces a request to a named backend, bypassing weights,
+// experiments and smart routing. Only allow-listed backends can be pinned.
+const BackendPinHeader = "X-Pin-Backend"
+
+// DefaultSessionCookieName is the session affinity cookie used when the route doesn't name one
+const DefaultSessionCookieName = "kortex-backend"
+
 // Router handles request routing to backends
 type Router struct {
 	cache       *cache.Store
@@ -44,6 +59,27 @@ type Router struct {
 	costTracker *CostTracker
 	tracer      *tracing.Tracer
 	smartRouter *SmartRouter
+	schemas     *schemaValidator
+	queue       *RequestQueue
+	adaptive    *AdaptiveLimiter
+	warmup      *warmup
+	audit       *AuditLogger
+	rateLimits  *ProviderRateLimiter
+	redirects   int
+	// redirectHosts are the hosts other than the backend's that redirects
+	// may lead to
+	redirectHosts []string
+
+	// pinnable is the allow-list of backends that X-Pin-Backend may name
+	pinnable map[string]bool
+
+	// rampUp is how long a recovered backend takes to reach its full weight
+	rampUp time.Duration
+
+	// rng drives weighted selection when set; rngMu guards it because
+	// *rand.Rand isn't safe for concurrent use
+	rng   *rand.Rand
+	rngMu sync.Mutex
 }
 
 // RouterOption is a functional option for configuring the router
@@ -84,11 +120,89 @@ func WithSmartRouter(sr *SmartRouter) RouterOption {
 	}
 }
 
+// WithRouterRequestQueue enforces backend MaxConcurrency with a request queue
+func WithRouterRequestQueue(q *RequestQueue) RouterOption {
+	return func(r *Router) {
+		r.queue = q
+	}
+}
+
+// WithRouterAdaptiveLimiter adjusts backend concurrency limits from observed latency
+func WithRouterAdaptiveLimiter(l *AdaptiveLimiter) RouterOption {
+	return func(r *Router) {
+		r.adaptive = l
+	}
+}
+
+// WithRouterAuditLogger captures request and response bodies on routes with audit sampling
+func WithRouterAuditLogger(a *AuditLogger) RouterOption {
+	return func(r *Router) {
+		r.audit = a
+	}
+}
+
+// WithRouterProviderRateLimiter throttles backends from their provider's rate limit headers
+func WithRouterProviderRateLimiter(l *ProviderRateLimiter) RouterOption {
+	return func(r *Router) {
+		r.rateLimits = l
+	}
+}
+
+// WithRouterMaxRedirects follows up to n backend redirects server-side, to
+// the backend's own host or the allowed hosts
+func WithRouterMaxRedirects(n int, allowedHosts ...string) RouterOption {
+	return func(r *Router) {
+		r.redirects = n
+		r.redirectHosts = allowedHosts
+	}
+}
+
+// WithRouterRand makes weighted backend selection draw from rng, so a fixed
+// seed gives a reproducible sequence of selections. A nil rng uses the global
+// source.
+func WithRouterRand(rng *rand.Rand) RouterOption {
+	return func(r *Router) {
+		r.rng = rng
+	}
+}
+
+// WithRouterPinnableBackends lets requests force one of the named backends
+// with the X-Pin-Backend header. Pinning is rejected when the list is empty.
+func WithRouterPinnableBackends(backends []string) RouterOption {
+	return func(r *Router) {
+		r.pinnable = make(map[string]bool, len(backends))
+		for _, name := range backends {
+			r.pinnable[name] = true
+		}
+	}
+}
+
+// WithRouterRecoveryRampUp gradually returns backends that just became
+// healthy to their full weight over the window. Zero disables the ramp-up.
+func WithRouterRecoveryRampUp(window time.Duration) RouterOption {
+	return func(r *Router) {
+		r.rampUp = window
+	}
+}
+
+// WithRouterWarmup answers unmatched requests with a 503 instead of a 404
+// until the cache is warm. A nil config disables the grace period.
+func WithRouterWarmup(cfg *WarmupConfig) RouterOption {
+	return func(r *Router) {
+		if cfg == nil {
+			r.warmup = nil
+			return
+		}
+		r.warmup = newWarmup(*cfg, r.cache)
+	}
+}
+
 // NewRouter creates a new router
 func NewRouter(store *cache.Store, k8sClient client.Client, log logr.Logger, opts ...RouterOption) *Router {
 	r := &Router{
-		cache: store,
-		log:   log.WithName("router"),
+		cache:   store,
+		log:     log.WithName("router"),
+		schemas: newSchemaValidator(),
 	}
 
 	// Apply options
@@ -98,6 +212,11 @@ func NewRouter(store *cache.Store, k8sClient client.Client, log logr.Logger, opt
 
 	// Create handler with metrics, cost tracker, and tracer
 	r.handler = NewBackendHandler(store, k8sClient, log, r.metrics, r.costTracker, r.tracer)
+	r.handler.SetRequestQueue(r.queue)
+	r.handler.SetAdaptiveLimiter(r.adaptive)
+	r.handler.SetAuditLogger(r.audit)
+	r.handler.SetProviderRateLimiter(r.rateLimits)
+	r.handler.SetMaxRedirects(r.redirects, r.redirectHosts...)
 
 	return r
 }
@@ -117,6 +236,10 @@ func (r *Router) HandleRequest(ctx context.Context, w http.ResponseWriter, req *
 			"namespace", req.Header.Get("X-Namespace"),
 			"route", req.Header.Get("X-Route"),
 		)
+		if r.warmup.cold() {
+			r.warmup.reject(w)
+			return
+		}
 		http.Error(w, "No matching route found", http.StatusNotFound)
 		return
 	}
@@ -139,28 +262,100 @@ func (r *Router) HandleRequest(ctx context.Context, w http.ResponseWriter, req *
 		return
 	}
 
+	// Requests for a model group fail over through the group's backends
+	if group := modelGroup(route, req); group != nil {
+		if pin := req.Header.Get(BackendPinHeader); pin != "" {
+			r.handlePinnedRequest(ctx, w, req, route, pin, group.Backends)
+			return
+		}
+		r.log.V(1).Info("Routing request to model group",
+			"route", route.Name,
+			"model", group.Model,
+			"backends", group.Backends,
+		)
+		r.handler.ExecuteModelGroup(ctx, w, req, route, group)
+		return
+	}
+
 	// Find matching rule within the route
 	rule := r.matchRule(route, req)
 
+	// Reject request bodies that don't satisfy the rule's schema
+	if rule != nil && rule.RequestSchema != "" && !r.validateRequestSchema(w, req, route, rule) {
+		return
+	}
+
 	// Determine which backends to use
 	var backends []gatewayv1alpha1.BackendRef
-	if rule != nil {
+	if rule != nil && len(rule.Backends) > 0 {
 		backends = rule.Backends
 	} else if route.Spec.DefaultBackend != nil {
 		backends = []gatewayv1alpha1.BackendRef{*route.Spec.DefaultBackend}
+	} else if rule != nil {
+		r.log.Info("Matched rule has no backends and the route has no default backend", "route", route.Name)
+		http.Error(w, "Route is misconfigured: the matched rule has no backends", http.StatusServiceUnavailable)
+		return
 	} else {
 		r.log.Info("No backend configured for route", "route", route.Name)
 		http.Error(w, "No backend configured for this route", http.StatusServiceUnavailable)
 		return
 	}
 
-	// Select backend - first try smart routing, then fall back to weighted selection
+	// A pinned request goes straight to the named backend, for debugging it,
+	// once it has passed the rule's checks
+	if pin := req.Header.Get(BackendPinHeader); pin != "" {
+		candidates := make([]string, 0, len(backends))
+		for _, b := range backends {
+			candidates = append(candidates, b.Name)
+		}
+		r.handlePinnedRequest(ctx, w, req, route, pin, candidates)
+		return
+	}
+
+	// Backends in maintenance are never selected
+	backends = r.excludeMaintenance(route.Namespace, backends)
+	if len(backends) == 0 {
+		r.log.Info("All backends are in maintenance", "route", route.Name)
+		http.Error(w, "All backends are in maintenance", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Cordoned backends are being drained and take no new requests
+	backends = r.excludeCordoned(route.Namespace, backends)
+	if len(backends) == 0 {
+		r.log.Info("All backends are cordoned", "route", route.Name)
+		http.Error(w, "All backends are cordoned", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Operator weight overrides only affect weighted selection
+	weighted := r.applyWeightOverrides(route, backends)
+	weighted = r.applyRecoveryRampUp(route.Namespace, weighted)
+	if route.Spec.SelectionMode == gatewayv1alpha1.SelectionModeConcurrencyAware {
+		weighted = r.excludeSaturated(route.Namespace, weighted)
+	}
+
+	// Select backend - a valid client hint wins, then session and prompt cache
+	// affinity, then smart routing, then weighted selection
 	var selectedBackend gatewayv1alpha1.BackendRef
 	var smartDecision *RouteDecision
 
-	if r.smartRouter != nil {
+	if hinted, ok := r.backendHint(req, route.Namespace, backends); ok {
+		selectedBackend = hinted
+		r.log.V(1).Info("Backend hint applied", "backend", hinted.Name)
+	} else if pinned, ok := r.sessionBackend(req, route, backends); ok {
+		selectedBackend = pinned
+		r.log.V(1).Info("Session affinity applied", "backend", pinned.Name)
+	} else if cached, ok := r.promptCacheBackend(req, route.Namespace, rule, weighted); ok {
+		selectedBackend = cached
+		r.log.V(1).Info("Prompt cache affinity applied", "backend", cached.Name)
+	} else if r.smartRouter != nil {
 		smartDecision = r.smartRouter.SelectBackend(req, route)
-		if smartDecision != nil && smartDecision.Backend != "" {
+		contextLimits := r.contextLimits(route.Namespace)
+		if smartDecision != nil && smartDecision.Backend != "" &&
+			!r.inMaintenance(route.Namespace, smartDecision.Backend) &&
+			!r.cache.IsCordoned(types.NamespacedName{Namespace: route.Namespace, Name: smartDecision.Backend}) &&
+			r.smartRouter.ContextLengthCapability(smartDecision.Backend, smartDecision.EstimatedTokens, contextLimits) {
 			// Smart router made a decision, use that backend
 			selectedBackend = gatewayv1alpha1.BackendRef{Name: smartDecision.Backend}
 
@@ -183,12 +378,22 @@ func (r *Router) HandleRequest(ctx context.Context, w http.ResponseWriter, req *
 				"reason", smartDecision.Reason,
 			)
 		} else {
-			// Smart router didn't make a decision, use weighted selection
-			selectedBackend = r.selectWeightedBackend(backends)
+			// Smart router didn't make a usable decision, use weighted
+			// selection among the backends the request fits
+			if smartDecision != nil {
+				weighted = r.excludeSmallContext(weighted, smartDecision.EstimatedTokens, contextLimits)
+			}
+			selectedBackend = r.selectWeightedBackend(weighted)
 		}
 	} else {
 		// No smart router configured, use weighted selection
-		selectedBackend = r.selectWeightedBackend(backends)
+		selectedBackend = r.selectWeightedBackend(weighted)
+	}
+
+	if selectedBackend.Name == "" {
+		r.log.Info("All backends have a weight of zero", "route", route.Name)
+		http.Error(w, "All backends have a weight of zero", http.StatusServiceUnavailable)
+		return
 	}
 
 	// Apply A/B experiment if configured
@@ -196,6 +401,10 @@ func (r *Router) HandleRequest(ctx context.Context, w http.ResponseWriter, req *
 	if len(route.Spec.Experiments) > 0 && r.experiments != nil {
 		newBackend, result := r.experiments.ApplyExperiment(route.Spec.Experiments, selectedBackend.Name, req)
 		if result != nil {
+			newBackend = r.experimentFallback(route, result, newBackend)
+			if newBackend != selectedBackend.Name && r.metrics != nil {
+				r.metrics.RecordExperimentOverride(route.Name, selectedBackend.Name, newBackend, result.Experiment)
+			}
 			selectedBackend.Name = newBackend
 			experimentResult = result
 			// Set experiment headers
@@ -203,15 +412,193 @@ func (r *Router) HandleRequest(ctx context.Context, w http.ResponseWriter, req *
 		}
 	}
 
+	r.setSessionCookie(w, route, selectedBackend.Name)
+
 	r.log.V(1).Info("Routing request",
 		"route", route.Name,
 		"backend", selectedBackend.Name,
 		"hasRule", rule != nil,
 		"experiment", experimentResult != nil,
+		"forcedVariant", experimentResult != nil && experimentResult.Forced,
 	)
 
 	// Execute request with fallback support
-	r.handler.ExecuteWithFallback(ctx, w, req, route, selectedBackend)
+	r.handler.ExecuteWithFallback(ctx, w, req, route, rule, selectedBackend)
+}
+
+// validateRequestSchema validates the request body against the rule's schema.
+// It writes the error response and returns false if the request is rejected.
+// An invalid schema is logged and does not block traffic.
+func (r *Router) validateRequestSchema(w http.ResponseWriter, req *http.Request, route *gatewayv1alpha1.InferenceRoute, rule *gatewayv1alpha1.RouteRule) bool {
+	violations, err := r.schemas.validateRequest(req, rule.RequestSchema)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return false
+		}
+		r.log.Error(err, "Skipping request schema validation", "route", route.Name)
+		return true
+	}
+
+	if len(violations) == 0 {
+		return true
+	}
+
+	r.log.V(1).Info("Request rejected by schema validation",
+		"route", route.Name,
+		"errors", violations,
+	)
+	writeProblem(w, Problem{
+		Type:   "about:blank",
+		Title:  "Invalid request body",
+		Status: http.StatusBadRequest,
+		Detail: "The request body does not match the schema required by this route.",
+		Errors: violations,
+	})
+	return false
+}
+
+// handlePinnedRequest sends the request to the backend named by the
+// X-Pin-Backend header without fallback. The backend must be allow-listed and
+// one of the candidates the request could be routed to. Other pins are
+// rejected rather than ignored, unlike a hint, so the client can't mistake a
+// normally routed response for the pinned backend's.
+func (r *Router) handlePinnedRequest(ctx context.Context, w http.ResponseWriter, req *http.Request, route *gatewayv1alpha1.InferenceRoute, backend string, candidates []string) {
+	if !r.pinnable[backend] {
+		r.log.V(1).Info("Rejecting backend pin", "route", route.Name, "backend", backend)
+		http.Error(w, "Backend pinning is not allowed for this backend", http.StatusForbidden)
+		return
+	}
+	if !slices.Contains(candidates, backend) {
+		r.log.V(1).Info("Rejecting pin to a backend the request can't be routed to", "route", route.Name, "backend", backend)
+		http.Error(w, "The pinned backend does not serve this request", http.StatusForbidden)
+		return
+	}
+
+	r.log.V(1).Info("Backend pin applied", "route", route.Name, "backend", backend)
+	r.handler.ExecutePinned(ctx, w, req, route, backend)
+}
+
+// backendHint returns the backend suggested by the X-Backend-Hint header if it
+// is one of the candidate backends and is currently healthy. Invalid hints
+// are ignored so that normal selection applies.
+func (r *Router) backendHint(req *http.Request, namespace string, backends []gatewayv1alpha1.BackendRef) (gatewayv1alpha1.BackendRef, bool) {
+	hint := req.Header.Get(BackendHintHeader)
+	if hint == "" {
+		return gatewayv1alpha1.BackendRef{}, false
+	}
+
+	if b, ok := r.availableCandidate(namespace, hint, backends); ok {
+		return b, true
+	}
+
+	r.log.V(1).Info("Ignoring backend hint", "backend", hint)
+	return gatewayv1alpha1.BackendRef{}, false
+}
+
+// sessionBackend returns the backend recorded in the route's session affinity
+// cookie if it is still a healthy candidate. Otherwise the client is routed
+// normally and gets a new cookie.
+func (r *Router) sessionBackend(req *http.Request, route *gatewayv1alpha1.InferenceRoute, backends []gatewayv1alpha1.BackendRef) (gatewayv1alpha1.BackendRef, bool) {
+	if route.Spec.SessionAffinity == nil {
+		return gatewayv1alpha1.BackendRef{}, false
+	}
+
+	cookie, err := req.Cookie(sessionCookieName(route.Spec.SessionAffinity))
+	if err != nil || cookie.Value == "" {
+		return gatewayv1alpha1.BackendRef{}, false
+	}
+
+	if b, ok := r.availableCandidate(route.Namespace, cookie.Value, backends); ok {
+		return b, true
+	}
+
+	r.log.V(1).Info("Ignoring session affinity cookie", "backend", cookie.Value)
+	return gatewayv1alpha1.BackendRef{}, false
+}
+
+// setSessionCookie records the selected backend in the route's session
+// affinity cookie. It does nothing if the route has no session affinity.
+func (r *Router) setSessionCookie(w http.ResponseWriter, route *gatewayv1alpha1.InferenceRoute, backend string) {
+	affinity := route.Spec.SessionAffinity
+	if affinity == nil || backend == "" {
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName(affinity),
+		Value:    backend,
+		Path:     "/",
+		MaxAge:   int(affinity.MaxAgeSeconds),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// sessionCookieName returns the cookie name for the session affinity config
+func sessionCookieName(affinity *gatewayv1alpha1.SessionAffinity) string {
+	if affinity.CookieName != "" {
+		return affinity.CookieName
+	}
+	return DefaultSessionCookieName
+}
+
+// availableCandidate returns the named backend if it is one of the candidate
+// backends, is healthy and doesn't have an open circuit
+func (r *Router) availableCandidate(namespace, name string, backends []gatewayv1alpha1.BackendRef) (gatewayv1alpha1.BackendRef, bool) {
+	for _, b := range backends {
+		if b.Name == name && r.backendAvailable(namespace, name) {
+			return b, true
+		}
+	}
+	return gatewayv1alpha1.BackendRef{}, false
+}
+
+// backendAvailable reports whether the named backend is healthy and doesn't
+// have an open circuit
+func (r *Router) backendAvailable(namespace, name string) bool {
+	backend, ok := r.cache.GetBackendByName(namespace, name)
+	if !ok || backend.Status.Health != "Healthy" {
+		return false
+	}
+	return r.handler == nil || r.handler.circuitBreaker == nil || !r.handler.circuitBreaker.IsOpen(name)
+}
+
+// experimentFallback returns the backend for an experiment assignment. Users
+// assigned to an unhealthy treatment are sent to control instead, unless the
+// experiment disables the fallback or they forced the variant.
+func (r *Router) experimentFallback(route *gatewayv1alpha1.InferenceRoute, result *ExperimentResult, backend string) string {
+	if result.Variant != VariantTreatment || result.Forced {
+		return backend
+	}
+	experiment := findExperiment(route.Spec.Experiments, result.Experiment)
+	if experiment == nil || !experiment.FallsBackToControl() || r.backendAvailable(route.Namespace, backend) {
+		return backend
+	}
+
+	r.log.V(1).Info("Treatment backend unavailable, sending request to control",
+		"route", route.Name,
+		"experiment", result.Experiment,
+		"treatment", backend,
+		"control", experiment.Control,
+	)
+	if r.metrics != nil {
+		r.metrics.RecordExperimentFallback(route.Name, result.Experiment)
+	}
+	result.Backend = experiment.Control
+	result.Variant = VariantControl
+	return experiment.Control
+}
+
+// findExperiment returns the named experiment, or nil if there is none
+func findExperiment(experiments []gatewayv1alpha1.ABExperiment, name string) *gatewayv1alpha1.ABExperiment {
+	for i := range experiments {
+		if experiments[i].Name == name {
+			return &experiments[i]
+		}
+	}
+	return nil
 }
 
 // findMatchingRoute finds the route that should handle this request
@@ -236,6 +623,11 @@ func (r *Router) findMatchingRoute(req *http.Request) (*gatewayv1alpha1.Inferenc
 		return nil, false
 	}
 
+	// Routes serving the request's hostname take precedence over the namespace
+	if route, ok := r.cache.GetRouteByHostname(req.Host); ok {
+		return route, true
+	}
+
 	// Otherwise, find any active route in the namespace
 	routes := r.cache.ListRoutesInNamespace(namespace)
 	for _, route := range routes {
@@ -260,6 +652,21 @@ func (r *Router) matchRule(route *gatewayv1alpha1.InferenceRoute, req *http.Requ
 	return nil
 }
 
+// modelGroup returns the route's model group for the model in the X-Model
+// header, or nil if there is none
+func modelGroup(route *gatewayv1alpha1.InferenceRoute, req *http.Request) *gatewayv1alpha1.ModelGroup {
+	model := req.Header.Get("X-Model")
+	if model == "" {
+		return nil
+	}
+	for i := range route.Spec.ModelGroups {
+		if group := &route.Spec.ModelGroups[i]; group.Model == model && len(group.Backends) > 0 {
+			return group
+		}
+	}
+	return nil
+}
+
 // ruleMatches checks if a rule matches the given request
 func (r *Router) ruleMatches(rule *gatewayv1alpha1.RouteRule, req *http.Request) bool {
 	// No match conditions means match all
@@ -283,6 +690,11 @@ func (r *Router) ruleMatches(rule *gatewayv1alpha1.RouteRule, req *http.Request)
 		}
 	}
 
+	// Check method matching
+	if len(match.Methods) > 0 && !methodMatches(match.Methods, req.Method) {
+		return false
+	}
+
 	// Check model pattern matching
 	// The model can be specified via X-Model header to avoid parsing request body
 	if match.ModelPattern != nil && *match.ModelPattern != "" {
@@ -298,45 +710,263 @@ func (r *Router) ruleMatches(rule *gatewayv1alpha1.RouteRule, req *http.Request)
 	return true
 }
 
-// selectWeightedBackend selects a backend from a list using weighted random selection
-func (r *Router) selectWeightedBackend(backends []gatewayv1alpha1.BackendRef) gatewayv1alpha1.BackendRef {
-	if len(backends) == 0 {
-		return gatewayv1alpha1.BackendRef{}
+// methodMatches reports whether the request method is one of the methods.
+// HEAD requests match GET, as they return the same headers.
+func methodMatches(methods []string, method string) bool {
+	for _, m := range methods {
+		if strings.EqualFold(m, method) ||
+			(method == http.MethodHead && strings.EqualFold(m, http.MethodGet)) {
+			return true
+		}
 	}
+	return false
+}
 
-	if len(backends) == 1 {
-		return backends[0]
+// applyWeightOverrides replaces the weights of backends that have an override
+// for the route. Backends overridden to zero are drained; if that would drain
+// every backend, the route's own weights are used.
+func (r *Router) applyWeightOverrides(route *gatewayv1alpha1.InferenceRoute, backends []gatewayv1alpha1.BackendRef) []gatewayv1alpha1.BackendRef {
+	overrides, ok := r.cache.GetWeightOverrides(types.NamespacedName{Namespace: route.Namespace, Name: route.Name})
+	if !ok {
+		return backends
 	}
 
-	// Calculate total weight
-	totalWeight := int32(0)
+	weighted := make([]gatewayv1alpha1.BackendRef, 0, len(backends))
 	for _, b := range backends {
-		weight := b.Weight
-		if weight == 0 {
-			weight = 100 // default weight
+		if weight, ok := overrides[b.Name]; ok {
+			if weight == 0 {
+				continue
+			}
+			b.Weight = &weight
+			b.Fraction = nil
 		}
-		totalWeight += weight
+		weighted = append(weighted, b)
 	}
 
-	if totalWeight == 0 {
-		return backends[0]
+	if len(weighted) == 0 {
+		return backends
 	}
+	return weighted
+}
 
-	// Random selection based on weight
-	target := rand.Int31n(totalWeight)
-	cumulative := int32(0)
+// excludeMaintenance removes backends that are in maintenance. Unlike the
+// other filters, it may return an empty list.
+func (r *Router) excludeMaintenance(namespace string, backends []gatewayv1alpha1.BackendRef) []gatewayv1alpha1.BackendRef {
+	available := make([]gatewayv1alpha1.BackendRef, 0, len(backends))
+	for _, b := range backends {
+		if !r.inMaintenance(namespace, b.Name) {
+			available = append(available, b)
+		}
+	}
+	return available
+}
+
+// inMaintenance reports whether the named backend is in maintenance
+func (r *Router) inMaintenance(namespace, name string) bool {
+	backend, ok := r.cache.GetBackendByName(namespace, name)
+	return ok && backend.Status.Health == cache.HealthStatusMaintenance
+}
 
+// excludeCordoned removes cordoned backends. Like excludeMaintenance, it may
+// return an empty list.
+func (r *Router) excludeCordoned(namespace string, backends []gatewayv1alpha1.BackendRef) []gatewayv1alpha1.BackendRef {
+	available := make([]gatewayv1alpha1.BackendRef, 0, len(backends))
 	for _, b := range backends {
-		weight := b.Weight
-		if weight == 0 {
-			weight = 100
+		if !r.cache.IsCordoned(types.NamespacedName{Namespace: namespace, Name: b.Name}) {
+			available = append(available, b)
 		}
-		cumulative += weight
+	}
+	return available
+}
+
+// contextLimits returns the context limit of each backend in the namespace
+// that declares one, keyed by backend name
+func (r *Router) contextLimits(namespace string) map[string]int {
+	limits := make(map[string]int)
+	for _, backend := range r.cache.ListBackendsInNamespace(namespace) {
+		if backend.Spec.MaxContextTokens > 0 {
+			limits[backend.Name] = int(backend.Spec.MaxContextTokens)
+		}
+	}
+	return limits
+}
+
+// excludeSmallContext removes backends whose context is too small for the
+// request. If no backend is large enough, the original list is returned
+// unchanged.
+func (r *Router) excludeSmallContext(backends []gatewayv1alpha1.BackendRef, estimatedTokens int, limits map[string]int) []gatewayv1alpha1.BackendRef {
+	available := make([]gatewayv1alpha1.BackendRef, 0, len(backends))
+	for _, b := range backends {
+		if r.smartRouter.ContextLengthCapability(b.Name, estimatedTokens, limits) {
+			available = append(available, b)
+		}
+	}
+
+	if len(available) == 0 {
+		return backends
+	}
+	return available
+}
+
+// excludeOpenCircuits removes backends whose circuit breaker is open. If every
+// backend's circuit is open, the original list is returned unchanged.
+func (r *Router) excludeOpenCircuits(backends []gatewayv1alpha1.BackendRef) []gatewayv1alpha1.BackendRef {
+	if r.handler == nil || r.handler.circuitBreaker == nil {
+		return backends
+	}
+
+	available := make([]gatewayv1alpha1.BackendRef, 0, len(backends))
+	for _, b := range backends {
+		if !r.handler.circuitBreaker.IsOpen(b.Name) {
+			available = append(available, b)
+		}
+	}
+
+	if len(available) == 0 {
+		return backends
+	}
+	return available
+}
+
+// excludeSaturated removes backends that are at their concurrency limit, so
+// their weight goes to the rest instead of their requests queueing. If every
+// backend with a positive weight is saturated, the original list is returned
+// unchanged. Backends with a weight of zero are never selected, so they don't
+// count as an alternative.
+func (r *Router) excludeSaturated(namespace string, backends []gatewayv1alpha1.BackendRef) []gatewayv1alpha1.BackendRef {
+	if r.handler == nil || r.handler.queue == nil {
+		return backends
+	}
+
+	available := make([]gatewayv1alpha1.BackendRef, 0, len(backends))
+	for _, b := range backends {
+		if backendShare(b) <= 0 {
+			continue
+		}
+		backend, ok := r.cache.GetBackendByName(namespace, b.Name)
+		if !ok || !r.handler.saturated(backend) {
+			available = append(available, b)
+		}
+	}
+
+	if len(available) == 0 {
+		return backends
+	}
+	return available
+}
+
+// excludeRecentFailures removes backends that failed a request within the
+// health cache TTL. If every backend failed recently, the original list is
+// returned unchanged.
+func (r *Router) excludeRecentFailures(backends []gatewayv1alpha1.BackendRef) []gatewayv1alpha1.BackendRef {
+	if r.metrics == nil || r.metrics.HealthCache() == nil {
+		return backends
+	}
+	healthCache := r.metrics.HealthCache()
+
+	available := make([]gatewayv1alpha1.BackendRef, 0, len(backends))
+	for _, b := range backends {
+		if !healthCache.RecentlyFailed(b.Name) {
+			available = append(available, b)
+		}
+	}
+
+	if len(available) == 0 {
+		return backends
+	}
+	return available
+}
+
+// defaultBackendWeight is the weight of a backend that doesn't set one
+const defaultBackendWeight int32 = 100
+
+// backendWeight returns the backend's weight, defaulting unset weights
+func backendWeight(b gatewayv1alpha1.BackendRef) int32 {
+	if b.Weight == nil {
+		return defaultBackendWeight
+	}
+	return *b.Weight
+}
+
+// backendShare returns the backend's unnormalized share of traffic: its
+// fraction if it has a valid one, otherwise its weight as a fraction of 100
+func backendShare(b gatewayv1alpha1.BackendRef) float64 {
+	if b.Fraction != nil {
+		if fraction, err := strconv.ParseFloat(*b.Fraction, 64); err == nil && fraction >= 0 {
+			return fraction
+		}
+	}
+	return float64(backendWeight(b)) / float64(defaultBackendWeight)
+}
+
+// normalizeWeights returns each backend's share of traffic, scaled so that
+// the shares add up to one
+func normalizeWeights(backends []gatewayv1alpha1.BackendRef) []float64 {
+	shares := make([]float64, len(backends))
+	total := 0.0
+	for i, b := range backends {
+		shares[i] = backendShare(b)
+		total += shares[i]
+	}
+	if total > 0 {
+		for i := range shares {
+			shares[i] /= total
+		}
+	}
+	return shares
+}
+
+// excludeZeroWeight removes backends whose weight or fraction is explicitly
+// zero. Unlike the other filters, it may return an empty list.
+func excludeZeroWeight(backends []gatewayv1alpha1.BackendRef) []gatewayv1alpha1.BackendRef {
+	weighted := make([]gatewayv1alpha1.BackendRef, 0, len(backends))
+	for _, b := range backends {
+		if backendShare(b) > 0 {
+			weighted = append(weighted, b)
+		}
+	}
+	return weighted
+}
+
+// selectWeightedBackend selects a backend from a list using weighted random selection.
+// Backends with a weight of zero are never selected, so the result is empty if
+// every backend has a weight of zero. Backends with an open circuit or a recent
+// failure are excluded from the pool while an alternative exists.
+func (r *Router) selectWeightedBackend(backends []gatewayv1alpha1.BackendRef) gatewayv1alpha1.BackendRef {
+	backends = excludeZeroWeight(backends)
+	if len(backends) == 0 {
+		return gatewayv1alpha1.BackendRef{}
+	}
+
+	backends = r.excludeOpenCircuits(backends)
+	backends = r.excludeRecentFailures(backends)
+
+	if len(backends) == 1 {
+		return backends[0]
+	}
+
+	// Random selection based on each backend's share of the traffic
+	shares := normalizeWeights(backends)
+	target := r.randFloat64()
+	cumulative := 0.0
+
+	for i, b := range backends {
+		cumulative += shares[i]
 		if target < cumulative {
 			return b
 		}
 	}
 
-	// Fallback to first backend (should not reach here)
-	return backends[0]
+	// Rounding can leave the cumulative share just below one
+	return backends[len(backends)-1]
+}
+
+// randFloat64 returns a number in [0, 1) from the router's source
+func (r *Router) randFloat64() float64 {
+	if r.rng == nil {
+		return rand.Float64()
+	}
+	r.rngMu.Lock()
+	defer r.rngMu.Unlock()
+	return r.rng.Float64()
 }
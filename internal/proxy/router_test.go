@@ -17,11 +17,21 @@ limitations under the License.
 package proxy
 
 import (
+	"math"
+	"math/rand"
+	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
@@ -156,6 +166,37 @@ func TestRouter_FindRoute_SkipsFailedRoutes(t *testing.T) {
 	}
 }
 
+func TestRouter_FindRoute_ByHostname(t *testing.T) {
+	store := cache.NewStore()
+	router := NewRouter(store, nil, zap.New())
+
+	for _, tenant := range []string{"tenant-a", "tenant-b"} {
+		store.SetRoute(types.NamespacedName{Namespace: tenant, Name: "chat"}, &gatewayv1alpha1.InferenceRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: tenant},
+			Spec:       gatewayv1alpha1.InferenceRouteSpec{Hostnames: []string{tenant + ".example.com"}},
+			Status:     gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+		})
+	}
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	req.Host = "tenant-a.example.com:8080"
+
+	found := router.FindRoute(req)
+	if found == nil {
+		t.Fatal("expected to find the route for the hostname")
+	}
+	if found.Namespace != "tenant-a" {
+		t.Errorf("expected the tenant-a route, got %s/%s", found.Namespace, found.Name)
+	}
+
+	// An explicit route header still takes precedence
+	req.Header.Set("X-Namespace", "tenant-b")
+	req.Header.Set("X-Route", "chat")
+	if found := router.FindRoute(req); found == nil || found.Namespace != "tenant-b" {
+		t.Errorf("expected X-Route to take precedence over the hostname, got %v", found)
+	}
+}
+
 func TestRouter_FindRoute_NotFound(t *testing.T) {
 	store := cache.NewStore()
 	log := zap.New()
@@ -178,7 +219,7 @@ func TestRouter_selectWeightedBackend_Single(t *testing.T) {
 	router := NewRouter(store, nil, log)
 
 	backends := []gatewayv1alpha1.BackendRef{
-		{Name: "only-backend", Weight: 100},
+		{Name: "only-backend", Weight: ptr.To[int32](100)},
 	}
 
 	selected := router.selectWeightedBackend(backends)
@@ -223,6 +264,60 @@ func TestRouter_selectWeightedBackend_DefaultWeight(t *testing.T) {
 	}
 }
 
+func TestRouter_selectWeightedBackend_ZeroWeightExcluded(t *testing.T) {
+	router := NewRouter(cache.NewStore(), nil, zap.New())
+
+	// An explicit zero disables a backend, while an unset weight defaults to 100
+	backends := []gatewayv1alpha1.BackendRef{
+		{Name: "disabled", Weight: ptr.To[int32](0)},
+		{Name: "unset"},
+	}
+
+	for i := 0; i < 100; i++ {
+		if selected := router.selectWeightedBackend(backends); selected.Name != "unset" {
+			t.Fatalf("expected only the unset backend to be selected, got %q", selected.Name)
+		}
+	}
+}
+
+func TestRouter_selectWeightedBackend_AllZeroWeight(t *testing.T) {
+	router := NewRouter(cache.NewStore(), nil, zap.New())
+
+	backends := []gatewayv1alpha1.BackendRef{
+		{Name: "backend-a", Weight: ptr.To[int32](0)},
+	}
+
+	if selected := router.selectWeightedBackend(backends); selected.Name != "" {
+		t.Errorf("expected no backend when every weight is zero, got %q", selected.Name)
+	}
+}
+
+func TestRouter_HandleRequest_AllZeroWeight(t *testing.T) {
+	store := cache.NewStore()
+	router := NewRouter(store, nil, zap.New())
+
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Rules: []gatewayv1alpha1.RouteRule{{
+				Backends: []gatewayv1alpha1.BackendRef{
+					{Name: "backend-a", Weight: ptr.To[int32](0)},
+					{Name: "backend-b", Weight: ptr.To[int32](0)},
+				},
+			}},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	router.HandleRequest(req.Context(), rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 when every backend has a weight of zero, got %d", rec.Code)
+	}
+}
+
 func TestRouter_selectWeightedBackend_WeightedDistribution(t *testing.T) {
 	store := cache.NewStore()
 	log := zap.New()
@@ -230,8 +325,8 @@ func TestRouter_selectWeightedBackend_WeightedDistribution(t *testing.T) {
 
 	// 90% to backend-a, 10% to backend-b
 	backends := []gatewayv1alpha1.BackendRef{
-		{Name: "backend-a", Weight: 90},
-		{Name: "backend-b", Weight: 10},
+		{Name: "backend-a", Weight: ptr.To[int32](90)},
+		{Name: "backend-b", Weight: ptr.To[int32](10)},
 	}
 
 	selections := make(map[string]int)
@@ -248,6 +343,331 @@ func TestRouter_selectWeightedBackend_WeightedDistribution(t *testing.T) {
 	}
 }
 
+func TestRouter_selectWeightedBackend_FixedSeed(t *testing.T) {
+	backends := []gatewayv1alpha1.BackendRef{
+		{Name: "backend-a", Weight: ptr.To[int32](50)},
+		{Name: "backend-b", Weight: ptr.To[int32](30)},
+		{Name: "backend-c", Weight: ptr.To[int32](20)},
+	}
+	sequence := func(seed int64) []string {
+		router := NewRouter(cache.NewStore(), nil, zap.New(), WithRouterRand(rand.New(rand.NewSource(seed))))
+		names := make([]string, 50)
+		for i := range names {
+			names[i] = router.selectWeightedBackend(backends).Name
+		}
+		return names
+	}
+
+	first := sequence(42)
+	if !slices.Equal(first, sequence(42)) {
+		t.Error("expected the same seed to produce the same selections")
+	}
+	if slices.Equal(first, sequence(7)) {
+		t.Error("expected a different seed to produce different selections")
+	}
+	if len(slices.Compact(slices.Sorted(slices.Values(first)))) != 3 {
+		t.Errorf("expected a seeded router to still spread traffic across backends, got %v", first)
+	}
+}
+
+func TestRouter_selectWeightedBackend_FractionalDistribution(t *testing.T) {
+	router := NewRouter(cache.NewStore(), nil, zap.New())
+
+	// Fractions win over the default weight and don't need to add up to one
+	backends := []gatewayv1alpha1.BackendRef{
+		{Name: "backend-a", Weight: ptr.To[int32](100), Fraction: ptr.To("0.35")},
+		{Name: "backend-b", Weight: ptr.To[int32](100), Fraction: ptr.To("0.15")},
+	}
+
+	selections := make(map[string]int)
+	iterations := 2000
+	for i := 0; i < iterations; i++ {
+		selections[router.selectWeightedBackend(backends).Name]++
+	}
+
+	// backend-a should be selected roughly 70% of the time
+	ratioA := float64(selections["backend-a"]) / float64(iterations)
+	if ratioA < 0.63 || ratioA > 0.77 {
+		t.Errorf("expected backend-a to be selected ~70%%, got %.1f%%", ratioA*100)
+	}
+}
+
+func TestNormalizeWeights(t *testing.T) {
+	tests := []struct {
+		name     string
+		backends []gatewayv1alpha1.BackendRef
+		expected []float64
+	}{
+		{
+			name: "fractions",
+			backends: []gatewayv1alpha1.BackendRef{
+				{Name: "a", Fraction: ptr.To("0.7")},
+				{Name: "b", Fraction: ptr.To("0.3")},
+			},
+			expected: []float64{0.7, 0.3},
+		},
+		{
+			name: "integer weights",
+			backends: []gatewayv1alpha1.BackendRef{
+				{Name: "a", Weight: ptr.To[int32](30)},
+				{Name: "b", Weight: ptr.To[int32](10)},
+			},
+			expected: []float64{0.75, 0.25},
+		},
+		{
+			name: "fraction mixed with weight",
+			backends: []gatewayv1alpha1.BackendRef{
+				{Name: "a", Fraction: ptr.To("0.5")},
+				{Name: "b", Weight: ptr.To[int32](50)},
+			},
+			expected: []float64{0.5, 0.5},
+		},
+		{
+			name: "invalid fraction uses the weight",
+			backends: []gatewayv1alpha1.BackendRef{
+				{Name: "a", Weight: ptr.To[int32](20), Fraction: ptr.To("lots")},
+				{Name: "b", Weight: ptr.To[int32](60)},
+			},
+			expected: []float64{0.25, 0.75},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalizeWeights(tt.backends)
+			for i, want := range tt.expected {
+				if math.Abs(got[i]-want) > 1e-9 {
+					t.Errorf("expected shares %v, got %v", tt.expected, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestRouter_selectWeightedBackend_ZeroFractionExcluded(t *testing.T) {
+	router := NewRouter(cache.NewStore(), nil, zap.New())
+	backends := []gatewayv1alpha1.BackendRef{
+		{Name: "backend-a", Fraction: ptr.To("0")},
+		{Name: "backend-b", Fraction: ptr.To("0.2")},
+	}
+
+	for i := 0; i < 50; i++ {
+		if got := router.selectWeightedBackend(backends).Name; got != "backend-b" {
+			t.Fatalf("expected a zero fraction never to be selected, got '%s'", got)
+		}
+	}
+}
+
+func TestRouter_applyWeightOverrides(t *testing.T) {
+	store := cache.NewStore()
+	router := NewRouter(store, nil, zap.New())
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+	}
+	backends := []gatewayv1alpha1.BackendRef{
+		{Name: "backend-a", Weight: ptr.To[int32](90)},
+		{Name: "backend-b", Weight: ptr.To[int32](10)},
+		{Name: "backend-c", Weight: ptr.To[int32](0)},
+	}
+
+	// Without overrides the route's weights are used
+	if got := router.applyWeightOverrides(route, backends); len(got) != 3 || backendWeight(got[0]) != 90 {
+		t.Errorf("expected route weights without overrides, got %v", got)
+	}
+
+	store.SetWeightOverrides(map[types.NamespacedName]map[string]int32{
+		{Namespace: "default", Name: "chat"}: {"backend-a": 0, "backend-b": 100},
+	})
+
+	got := router.applyWeightOverrides(route, backends)
+	if len(got) != 2 {
+		t.Fatalf("expected backend-a to be drained, got %v", got)
+	}
+	if got[0].Name != "backend-b" || backendWeight(got[0]) != 100 {
+		t.Errorf("expected backend-b with weight 100, got %v", got[0])
+	}
+	if got[1].Name != "backend-c" || backendWeight(got[1]) != 0 {
+		t.Errorf("expected backend-c to keep its route weight, got %v", got[1])
+	}
+
+	// The route's own backends are left untouched
+	if backendWeight(backends[0]) != 90 || backendWeight(backends[1]) != 10 {
+		t.Errorf("expected route backends to be unchanged, got %v", backends)
+	}
+}
+
+func TestRouter_applyWeightOverrides_AllDrained(t *testing.T) {
+	store := cache.NewStore()
+	router := NewRouter(store, nil, zap.New())
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+	}
+	backends := []gatewayv1alpha1.BackendRef{
+		{Name: "backend-a", Weight: ptr.To[int32](50)},
+		{Name: "backend-b", Weight: ptr.To[int32](50)},
+	}
+	store.SetWeightOverrides(map[types.NamespacedName]map[string]int32{
+		{Namespace: "default", Name: "chat"}: {"backend-a": 0, "backend-b": 0},
+	})
+
+	if got := router.applyWeightOverrides(route, backends); len(got) != 2 {
+		t.Errorf("expected route weights when every backend is drained, got %v", got)
+	}
+}
+
+func TestRouter_WeightOverridesChangeDistribution(t *testing.T) {
+	store := cache.NewStore()
+	router := NewRouter(store, nil, zap.New())
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+	}
+	backends := []gatewayv1alpha1.BackendRef{
+		{Name: "backend-a", Weight: ptr.To[int32](90)},
+		{Name: "backend-b", Weight: ptr.To[int32](10)},
+	}
+
+	// Flip the split from the config file without editing the route
+	store.SetWeightOverrides(map[types.NamespacedName]map[string]int32{
+		{Namespace: "default", Name: "chat"}: {"backend-a": 10, "backend-b": 90},
+	})
+
+	selections := make(map[string]int)
+	iterations := 1000
+	for i := 0; i < iterations; i++ {
+		selected := router.selectWeightedBackend(router.applyWeightOverrides(route, backends))
+		selections[selected.Name]++
+	}
+
+	ratioB := float64(selections["backend-b"]) / float64(iterations)
+	if ratioB < 0.80 || ratioB > 0.98 {
+		t.Errorf("expected backend-b to be selected ~90%%, got %.1f%%", ratioB*100)
+	}
+}
+
+func TestRouter_selectWeightedBackend_ExcludesOpenCircuit(t *testing.T) {
+	store := cache.NewStore()
+	log := zap.New()
+	router := NewRouter(store, nil, log)
+
+	config := DefaultCircuitBreakerConfig()
+	config.FailureThreshold = 1
+	cb := NewCircuitBreakerManager(config, log)
+	router.handler.SetCircuitBreaker(cb)
+	cb.RecordFailure("backend-a")
+
+	backends := []gatewayv1alpha1.BackendRef{
+		{Name: "backend-a", Weight: ptr.To[int32](90)},
+		{Name: "backend-b", Weight: ptr.To[int32](10)},
+	}
+
+	for i := 0; i < 100; i++ {
+		selected := router.selectWeightedBackend(backends)
+		if selected.Name != "backend-b" {
+			t.Fatalf("expected open-circuit backend-a to be excluded, got '%s'", selected.Name)
+		}
+	}
+}
+
+func TestRouter_selectWeightedBackend_AllCircuitsOpen(t *testing.T) {
+	store := cache.NewStore()
+	log := zap.New()
+	router := NewRouter(store, nil, log)
+
+	config := DefaultCircuitBreakerConfig()
+	config.FailureThreshold = 1
+	cb := NewCircuitBreakerManager(config, log)
+	router.handler.SetCircuitBreaker(cb)
+	cb.RecordFailure("backend-a")
+	cb.RecordFailure("backend-b")
+
+	backends := []gatewayv1alpha1.BackendRef{
+		{Name: "backend-a"},
+		{Name: "backend-b"},
+	}
+
+	// With every circuit open, selection falls back to the full pool
+	selections := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		selected := router.selectWeightedBackend(backends)
+		selections[selected.Name]++
+	}
+
+	if len(selections) < 2 {
+		t.Error("expected both backends to remain selectable when all circuits are open")
+	}
+}
+
+func TestRouter_selectWeightedBackend_DeprioritizesRecentFailure(t *testing.T) {
+	store := cache.NewStore()
+	log := zap.New()
+
+	now := time.Now()
+	healthCache := NewHealthCache(10 * time.Second)
+	healthCache.now = func() time.Time { return now }
+
+	metrics := NewMetricsRecorder()
+	metrics.SetHealthCache(healthCache)
+	router := NewRouter(store, nil, log, WithRouterMetrics(metrics))
+
+	// A request error marks the backend as recently failed
+	metrics.RecordError("test-route", "backend-a", "request_failed")
+
+	backends := []gatewayv1alpha1.BackendRef{
+		{Name: "backend-a", Weight: ptr.To[int32](90)},
+		{Name: "backend-b", Weight: ptr.To[int32](10)},
+	}
+
+	for i := 0; i < 100; i++ {
+		selected := router.selectWeightedBackend(backends)
+		if selected.Name != "backend-b" {
+			t.Fatalf("expected recently failed backend-a to be deprioritized, got '%s'", selected.Name)
+		}
+	}
+
+	// Once the TTL has elapsed, the backend is selectable again
+	now = now.Add(11 * time.Second)
+	selections := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		selected := router.selectWeightedBackend(backends)
+		selections[selected.Name]++
+	}
+	if selections["backend-a"] == 0 {
+		t.Error("expected backend-a to be selectable after the health cache TTL")
+	}
+}
+
+func TestRouter_selectWeightedBackend_AllRecentlyFailed(t *testing.T) {
+	store := cache.NewStore()
+	log := zap.New()
+
+	metrics := NewMetricsRecorder()
+	metrics.SetHealthCache(NewHealthCache(time.Minute))
+	router := NewRouter(store, nil, log, WithRouterMetrics(metrics))
+
+	metrics.RecordError("test-route", "backend-a", "request_failed")
+	metrics.RecordError("test-route", "backend-b", "request_failed")
+
+	backends := []gatewayv1alpha1.BackendRef{
+		{Name: "backend-a"},
+		{Name: "backend-b"},
+	}
+
+	// With every backend recently failed, selection falls back to the full pool
+	selections := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		selected := router.selectWeightedBackend(backends)
+		selections[selected.Name]++
+	}
+
+	if len(selections) < 2 {
+		t.Error("expected both backends to remain selectable when all failed recently")
+	}
+}
+
 func TestRouter_ruleMatches_NoMatchConditions(t *testing.T) {
 	store := cache.NewStore()
 	log := zap.New()
@@ -368,6 +788,43 @@ func TestRouter_ruleMatches_PathPrefix(t *testing.T) {
 	}
 }
 
+func TestRouter_ruleMatches_Methods(t *testing.T) {
+	router := NewRouter(cache.NewStore(), nil, zap.New())
+
+	rule := &gatewayv1alpha1.RouteRule{
+		Match: &gatewayv1alpha1.RouteMatch{
+			Methods: []string{"get", http.MethodPost},
+		},
+	}
+
+	tests := []struct {
+		method  string
+		matches bool
+	}{
+		{http.MethodPost, true},
+		{http.MethodGet, true},
+		{http.MethodHead, true}, // HEAD matches GET
+		{http.MethodDelete, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/v1/models", nil)
+			if result := router.ruleMatches(rule, req); result != tt.matches {
+				t.Errorf("expected %v for method %s, got %v", tt.matches, tt.method, result)
+			}
+		})
+	}
+
+	// HEAD only matches rules that allow GET
+	postOnly := &gatewayv1alpha1.RouteRule{
+		Match: &gatewayv1alpha1.RouteMatch{Methods: []string{http.MethodPost}},
+	}
+	if router.ruleMatches(postOnly, httptest.NewRequest(http.MethodHead, "/", nil)) {
+		t.Error("expected HEAD not to match a POST-only rule")
+	}
+}
+
 func TestRouter_ruleMatches_ModelPattern(t *testing.T) {
 	store := cache.NewStore()
 	log := zap.New()
@@ -548,6 +1005,628 @@ func TestRouter_matchRule_NoMatch(t *testing.T) {
 	}
 }
 
+func TestRouter_HandleRequest_BackendHint(t *testing.T) {
+	store := cache.NewStore()
+	log := zap.New()
+	router := NewRouter(store, nil, log)
+
+	for _, name := range []string{"backend-a", "backend-b"} {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer upstream.Close()
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, &gatewayv1alpha1.InferenceBackend{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: gatewayv1alpha1.InferenceBackendSpec{
+				Type:     gatewayv1alpha1.BackendTypeExternal,
+				External: &gatewayv1alpha1.ExternalBackend{URL: upstream.URL, Provider: "custom"},
+			},
+			Status: gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+		})
+	}
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "hinted"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "hinted", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Rules: []gatewayv1alpha1.RouteRule{{
+				Backends: []gatewayv1alpha1.BackendRef{
+					{Name: "backend-a", Weight: ptr.To[int32](100)},
+					{Name: "backend-b", Weight: ptr.To[int32](1)},
+				},
+			}},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	for i := 0; i < 20; i++ {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		req.Header.Set(BackendHintHeader, "backend-b")
+		rec := httptest.NewRecorder()
+
+		router.HandleRequest(req.Context(), rec, req)
+
+		if got := rec.Header().Get("X-Served-By"); got != "backend-b" {
+			t.Fatalf("expected hinted backend-b to serve the request, got '%s'", got)
+		}
+	}
+}
+
+func TestRouter_HandleRequest_RuleWithoutBackends(t *testing.T) {
+	store := cache.NewStore()
+	router := NewRouter(store, nil, zap.New())
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "default-backend"}, newExternalTestBackend("default-backend", upstream.URL))
+
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "empty-rule", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Rules: []gatewayv1alpha1.RouteRule{{}},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	}
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "empty-rule"}, route)
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	router.HandleRequest(req.Context(), rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "misconfigured") {
+		t.Errorf("expected the error to report a misconfiguration, got %q", rec.Body.String())
+	}
+
+	// The default backend serves requests matching an empty rule
+	withDefault := route.DeepCopy()
+	withDefault.Spec.DefaultBackend = &gatewayv1alpha1.BackendRef{Name: "default-backend"}
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "empty-rule"}, withDefault)
+
+	rec = httptest.NewRecorder()
+	router.HandleRequest(req.Context(), rec, req)
+
+	if got := rec.Header().Get("X-Served-By"); got != "default-backend" {
+		t.Errorf("expected the default backend to serve the request, got '%s'", got)
+	}
+}
+
+// newPinningRouter creates a router whose route always selects backend-a
+// over a weight-0 backend-b, with backend-b and backend-c, which isn't one of
+// the route's backends, allowed to be pinned
+func newPinningRouter(t *testing.T) *Router {
+	t.Helper()
+	store := cache.NewStore()
+	router := NewRouter(store, nil, zap.New(), WithRouterPinnableBackends([]string{"backend-b", "backend-c"}))
+
+	for _, name := range []string{"backend-a", "backend-b", "backend-c"} {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(upstream.Close)
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, &gatewayv1alpha1.InferenceBackend{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: gatewayv1alpha1.InferenceBackendSpec{
+				Type:     gatewayv1alpha1.BackendTypeExternal,
+				External: &gatewayv1alpha1.ExternalBackend{URL: upstream.URL, Provider: "custom"},
+			},
+			Status: gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+		})
+	}
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "pinned"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "pinned", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Rules: []gatewayv1alpha1.RouteRule{{
+				Backends: []gatewayv1alpha1.BackendRef{
+					{Name: "backend-a"},
+					{Name: "backend-b", Weight: ptr.To[int32](0)},
+				},
+			}},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+	return router
+}
+
+func TestRouter_HandleRequest_PinBackend(t *testing.T) {
+	router := newPinningRouter(t)
+
+	// backend-b is never selected by weight, but pinning forces it
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	req.Header.Set(BackendPinHeader, "backend-b")
+	rec := httptest.NewRecorder()
+	router.HandleRequest(req.Context(), rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("X-Served-By"); got != "backend-b" {
+		t.Errorf("expected pinned backend-b to serve the request, got '%s'", got)
+	}
+}
+
+func TestRouter_HandleRequest_PinBackendNotAllowed(t *testing.T) {
+	router := newPinningRouter(t)
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	req.Header.Set(BackendPinHeader, "backend-a")
+	rec := httptest.NewRecorder()
+	router.HandleRequest(req.Context(), rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403 for a backend that isn't allow-listed, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("X-Served-By"); got != "" {
+		t.Errorf("expected the request not to reach a backend, got '%s'", got)
+	}
+
+	// Without an allow-list, no backend can be pinned
+	unpinnable := NewRouter(router.cache, nil, zap.New())
+	rec = httptest.NewRecorder()
+	req.Header.Set(BackendPinHeader, "backend-b")
+	unpinnable.HandleRequest(req.Context(), rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403 when pinning isn't configured, got %d", rec.Code)
+	}
+
+	// Allow-listed backends the rule doesn't route to can't be pinned
+	rec = httptest.NewRecorder()
+	req.Header.Set(BackendPinHeader, "backend-c")
+	router.HandleRequest(req.Context(), rec, req)
+
+	if rec.Code != http.StatusForbidden || rec.Header().Get("X-Served-By") != "" {
+		t.Errorf("expected status 403 for a backend outside the rule, got %d from '%s'", rec.Code, rec.Header().Get("X-Served-By"))
+	}
+}
+
+func TestRouter_HandleRequest_PinBackendValidatesSchema(t *testing.T) {
+	router := newPinningRouter(t)
+	route, _ := router.cache.GetRouteByName("default", "pinned")
+	route.Spec.Rules[0].RequestSchema = `{"type":"object","required":["model"]}`
+	router.cache.SetRoute(types.NamespacedName{Namespace: "default", Name: "pinned"}, route)
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{"messages":[]}`))
+	req.Header.Set(BackendPinHeader, "backend-b")
+	rec := httptest.NewRecorder()
+	router.HandleRequest(req.Context(), rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected a pinned request to be validated against the rule's schema, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("X-Served-By"); got != "" {
+		t.Errorf("expected the invalid request not to reach a backend, got '%s'", got)
+	}
+}
+
+// newSessionAffinityRouter creates a router with a route that splits traffic
+// evenly between two healthy backends and has session affinity enabled
+func newSessionAffinityRouter(t *testing.T, affinity *gatewayv1alpha1.SessionAffinity) (*Router, *cache.Store) {
+	t.Helper()
+	store := cache.NewStore()
+	router := NewRouter(store, nil, zap.New())
+
+	for _, name := range []string{"backend-a", "backend-b"} {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(upstream.Close)
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, &gatewayv1alpha1.InferenceBackend{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: gatewayv1alpha1.InferenceBackendSpec{
+				Type:     gatewayv1alpha1.BackendTypeExternal,
+				External: &gatewayv1alpha1.ExternalBackend{URL: upstream.URL, Provider: "custom"},
+			},
+			Status: gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+		})
+	}
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "sticky"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "sticky", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Rules: []gatewayv1alpha1.RouteRule{{
+				Backends: []gatewayv1alpha1.BackendRef{
+					{Name: "backend-a", Weight: ptr.To[int32](50)},
+					{Name: "backend-b", Weight: ptr.To[int32](50)},
+				},
+			}},
+			SessionAffinity: affinity,
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+	return router, store
+}
+
+// sessionCookie returns the named cookie set on the response, or nil
+func sessionCookie(rec *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestRouter_HandleRequest_SessionAffinity(t *testing.T) {
+	router, _ := newSessionAffinityRouter(t, &gatewayv1alpha1.SessionAffinity{MaxAgeSeconds: 3600})
+
+	// The first response sets a cookie naming the backend that was selected
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	router.HandleRequest(req.Context(), rec, req)
+
+	cookie := sessionCookie(rec, DefaultSessionCookieName)
+	if cookie == nil {
+		t.Fatal("expected the first response to set the session cookie")
+	}
+	servedBy := rec.Header().Get("X-Served-By")
+	if cookie.Value != servedBy {
+		t.Errorf("expected cookie to name backend '%s', got '%s'", servedBy, cookie.Value)
+	}
+	if cookie.MaxAge != 3600 || !cookie.HttpOnly {
+		t.Errorf("expected an HttpOnly cookie with max age 3600, got %+v", cookie)
+	}
+
+	// Follow-up requests with the cookie go to the same backend
+	for i := 0; i < 20; i++ {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+		rec := httptest.NewRecorder()
+
+		router.HandleRequest(req.Context(), rec, req)
+
+		if got := rec.Header().Get("X-Served-By"); got != servedBy {
+			t.Fatalf("expected session to stay on '%s', served by '%s'", servedBy, got)
+		}
+	}
+}
+
+func TestRouter_sessionBackend(t *testing.T) {
+	router, store := newSessionAffinityRouter(t, &gatewayv1alpha1.SessionAffinity{CookieName: "session-backend"})
+
+	backend, _ := store.GetBackendByName("default", "backend-a")
+	backend.Status.Health = "Unhealthy"
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "backend-a"}, backend)
+
+	route, _ := store.GetRoute(types.NamespacedName{Namespace: "default", Name: "sticky"})
+	backends := route.Spec.Rules[0].Backends
+
+	tests := []struct {
+		name   string
+		cookie *http.Cookie
+		wantOK bool
+	}{
+		{"healthy backend", &http.Cookie{Name: "session-backend", Value: "backend-b"}, true},
+		{"unhealthy backend", &http.Cookie{Name: "session-backend", Value: "backend-a"}, false},
+		{"unknown backend", &http.Cookie{Name: "session-backend", Value: "backend-z"}, false},
+		{"other cookie", &http.Cookie{Name: DefaultSessionCookieName, Value: "backend-b"}, false},
+		{"no cookie", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+
+			selected, ok := router.sessionBackend(req, route, backends)
+			if ok != tt.wantOK {
+				t.Fatalf("expected ok=%v, got %v", tt.wantOK, ok)
+			}
+			if ok && selected.Name != tt.cookie.Value {
+				t.Errorf("expected '%s', got '%s'", tt.cookie.Value, selected.Name)
+			}
+		})
+	}
+}
+
+func TestRouter_HandleRequest_SessionAffinity_StaleCookie(t *testing.T) {
+	router, _ := newSessionAffinityRouter(t, &gatewayv1alpha1.SessionAffinity{})
+
+	// A cookie for a backend that is no longer in the route is replaced
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	req.AddCookie(&http.Cookie{Name: DefaultSessionCookieName, Value: "removed-backend"})
+	rec := httptest.NewRecorder()
+
+	router.HandleRequest(req.Context(), rec, req)
+
+	cookie := sessionCookie(rec, DefaultSessionCookieName)
+	if cookie == nil || cookie.Value != rec.Header().Get("X-Served-By") {
+		t.Errorf("expected the cookie to name the serving backend, got %v", cookie)
+	}
+}
+
+func TestRouter_HandleRequest_NoSessionAffinity(t *testing.T) {
+	router, _ := newSessionAffinityRouter(t, nil)
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	router.HandleRequest(req.Context(), rec, req)
+
+	if len(rec.Result().Cookies()) != 0 {
+		t.Errorf("expected no cookies without session affinity, got %v", rec.Result().Cookies())
+	}
+}
+
+func TestRouter_HandleRequest_SkipsMaintenance(t *testing.T) {
+	store := cache.NewStore()
+	log := zap.New()
+	router := NewRouter(store, nil, log)
+
+	statuses := map[string]string{"active": "Healthy", "maintained": cache.HealthStatusMaintenance}
+	for name, health := range statuses {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer upstream.Close()
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, &gatewayv1alpha1.InferenceBackend{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: gatewayv1alpha1.InferenceBackendSpec{
+				Type:        gatewayv1alpha1.BackendTypeExternal,
+				External:    &gatewayv1alpha1.ExternalBackend{URL: upstream.URL, Provider: "custom"},
+				Maintenance: &gatewayv1alpha1.MaintenanceConfig{Enabled: health == cache.HealthStatusMaintenance},
+			},
+			Status: gatewayv1alpha1.InferenceBackendStatus{Health: health},
+		})
+	}
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "maintenance"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "maintenance", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Rules: []gatewayv1alpha1.RouteRule{{
+				Backends: []gatewayv1alpha1.BackendRef{
+					{Name: "maintained", Weight: ptr.To[int32](100)},
+					{Name: "active", Weight: ptr.To[int32](1)},
+				},
+			}},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	for i := 0; i < 20; i++ {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		// Even an explicit hint must not select a backend in maintenance
+		req.Header.Set(BackendHintHeader, "maintained")
+		rec := httptest.NewRecorder()
+
+		router.HandleRequest(req.Context(), rec, req)
+
+		if got := rec.Header().Get("X-Served-By"); got != "active" {
+			t.Fatalf("expected backend in maintenance to be skipped, served by '%s'", got)
+		}
+	}
+}
+
+func TestRouter_HandleRequest_SkipsCordoned(t *testing.T) {
+	store := cache.NewStore()
+	log := zap.New()
+	router := NewRouter(store, nil, log)
+
+	for _, name := range []string{"active", "cordoned"} {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer upstream.Close()
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, &gatewayv1alpha1.InferenceBackend{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: gatewayv1alpha1.InferenceBackendSpec{
+				Type:     gatewayv1alpha1.BackendTypeExternal,
+				External: &gatewayv1alpha1.ExternalBackend{URL: upstream.URL, Provider: "custom"},
+			},
+			Status: gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+		})
+	}
+	store.CordonBackend(types.NamespacedName{Namespace: "default", Name: "cordoned"})
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "drain"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "drain", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Rules: []gatewayv1alpha1.RouteRule{{
+				Backends: []gatewayv1alpha1.BackendRef{
+					{Name: "cordoned", Weight: ptr.To[int32](100)},
+					{Name: "active", Weight: ptr.To[int32](1)},
+				},
+			}},
+			Fallback: &gatewayv1alpha1.FallbackChain{Backends: []string{"cordoned"}},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	for i := 0; i < 20; i++ {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		// Even an explicit hint must not select a cordoned backend
+		req.Header.Set(BackendHintHeader, "cordoned")
+		rec := httptest.NewRecorder()
+
+		router.HandleRequest(req.Context(), rec, req)
+
+		if got := rec.Header().Get("X-Served-By"); got != "active" {
+			t.Fatalf("expected cordoned backend to be skipped, served by '%s'", got)
+		}
+	}
+
+	// Uncordoning returns the backend to rotation
+	store.UncordonBackend(types.NamespacedName{Namespace: "default", Name: "cordoned"})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	req.Header.Set(BackendHintHeader, "cordoned")
+	rec := httptest.NewRecorder()
+
+	router.HandleRequest(req.Context(), rec, req)
+
+	if got := rec.Header().Get("X-Served-By"); got != "cordoned" {
+		t.Errorf("expected uncordoned backend to be selectable, served by '%s'", got)
+	}
+}
+
+func TestRouter_HandleRequest_ModelGroupFailover(t *testing.T) {
+	store := cache.NewStore()
+	router := NewRouter(store, nil, zap.New())
+
+	// A closed server refuses connections
+	refused := httptest.NewServer(http.NotFoundHandler())
+	refused.Close()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "primary"}, newExternalTestBackend("primary", refused.URL))
+
+	var secondaryHits atomic.Int32
+	for _, name := range []string{"secondary", "general"} {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if name == "secondary" {
+				secondaryHits.Add(1)
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer upstream.Close()
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, newExternalTestBackend(name, upstream.URL))
+	}
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "models"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "models", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			DefaultBackend: &gatewayv1alpha1.BackendRef{Name: "general"},
+			ModelGroups: []gatewayv1alpha1.ModelGroup{
+				{Model: "llama-3-70b", Backends: []string{"primary", "secondary"}},
+			},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	send := func(model string) string {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		if model != "" {
+			req.Header.Set("X-Model", model)
+		}
+		rec := httptest.NewRecorder()
+		router.HandleRequest(req.Context(), rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rec.Code)
+		}
+		return rec.Header().Get("X-Served-By")
+	}
+
+	// The unreachable primary fails over to the next backend in the group
+	if got := send("llama-3-70b"); got != "secondary" {
+		t.Errorf("expected the group to fail over to 'secondary', served by '%s'", got)
+	}
+	if got := secondaryHits.Load(); got != 1 {
+		t.Errorf("expected one request to reach 'secondary', got %d", got)
+	}
+
+	// Other models use the route's usual backends
+	if got := send("gpt-4o"); got != "general" {
+		t.Errorf("expected other models to use the default backend, served by '%s'", got)
+	}
+	if got := send(""); got != "general" {
+		t.Errorf("expected requests without a model to use the default backend, served by '%s'", got)
+	}
+}
+
+func TestRouter_HandleRequest_AllBackendsCordoned(t *testing.T) {
+	store := cache.NewStore()
+	log := zap.New()
+	router := NewRouter(store, nil, log)
+
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "cordoned"}, &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "cordoned", Namespace: "default"},
+		Status:     gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+	})
+	store.CordonBackend(types.NamespacedName{Namespace: "default", Name: "cordoned"})
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "drain"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "drain", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			DefaultBackend: &gatewayv1alpha1.BackendRef{Name: "cordoned"},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+
+	router.HandleRequest(req.Context(), rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", rec.Code)
+	}
+}
+
+func TestRouter_HandleRequest_AllBackendsInMaintenance(t *testing.T) {
+	store := cache.NewStore()
+	log := zap.New()
+	router := NewRouter(store, nil, log)
+
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "maintained"}, &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "maintained", Namespace: "default"},
+		Status:     gatewayv1alpha1.InferenceBackendStatus{Health: cache.HealthStatusMaintenance},
+	})
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "maintenance"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "maintenance", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			DefaultBackend: &gatewayv1alpha1.BackendRef{Name: "maintained"},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+
+	router.HandleRequest(req.Context(), rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", rec.Code)
+	}
+}
+
+func TestRouter_backendHint(t *testing.T) {
+	store := cache.NewStore()
+	log := zap.New()
+	router := NewRouter(store, nil, log)
+
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "healthy"}, &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "healthy", Namespace: "default"},
+		Status:     gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+	})
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "unhealthy"}, &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "unhealthy", Namespace: "default"},
+		Status:     gatewayv1alpha1.InferenceBackendStatus{Health: "Unhealthy"},
+	})
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "outside-rule"}, &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "outside-rule", Namespace: "default"},
+		Status:     gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+	})
+
+	backends := []gatewayv1alpha1.BackendRef{
+		{Name: "healthy", Weight: ptr.To[int32](50)},
+		{Name: "unhealthy", Weight: ptr.To[int32](50)},
+	}
+
+	tests := []struct {
+		name     string
+		hint     string
+		expected bool
+	}{
+		{"no hint", "", false},
+		{"healthy backend in rule", "healthy", true},
+		{"unhealthy backend in rule", "unhealthy", false},
+		{"healthy backend outside rule", "outside-rule", false},
+		{"unknown backend", "missing", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+			if tt.hint != "" {
+				req.Header.Set(BackendHintHeader, tt.hint)
+			}
+
+			selected, ok := router.backendHint(req, "default", backends)
+			if ok != tt.expected {
+				t.Fatalf("expected hint honored=%v, got %v", tt.expected, ok)
+			}
+			if ok && selected.Name != tt.hint {
+				t.Errorf("expected '%s', got '%s'", tt.hint, selected.Name)
+			}
+		})
+	}
+}
+
 func TestRouterOptions(t *testing.T) {
 	store := cache.NewStore()
 	log := zap.New()
@@ -572,3 +1651,112 @@ func TestRouterOptions(t *testing.T) {
 		t.Error("expected cost tracker to be set")
 	}
 }
+
+func TestRouter_HandleRequest_RecordsExperimentOverride(t *testing.T) {
+	store := cache.NewStore()
+	for _, name := range []string{"base", "candidate"} {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer upstream.Close()
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, newExternalTestBackend(name, upstream.URL))
+	}
+	// Every user is assigned the treatment
+	experiment := gatewayv1alpha1.ABExperiment{Name: "rollout", Control: "base", Treatment: "candidate", TrafficPercent: 100}
+	for route, backend := range map[string]string{"overridden": "base", "unchanged": "candidate"} {
+		store.SetRoute(types.NamespacedName{Namespace: "default", Name: route}, &gatewayv1alpha1.InferenceRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: route, Namespace: "default"},
+			Spec: gatewayv1alpha1.InferenceRouteSpec{
+				DefaultBackend: &gatewayv1alpha1.BackendRef{Name: backend},
+				Experiments:    []gatewayv1alpha1.ABExperiment{experiment},
+			},
+			Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+		})
+	}
+
+	router := NewRouter(store, nil, zap.New(),
+		WithRouterMetrics(NewMetricsRecorder()),
+		WithRouterExperiments(NewExperimentManager(nil)),
+	)
+	for _, route := range []string{"overridden", "unchanged"} {
+		for i := 0; i < 3; i++ {
+			req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+			req.Header.Set("X-Route", route)
+			rec := httptest.NewRecorder()
+			router.HandleRequest(req.Context(), rec, req)
+			if got := rec.Header().Get("X-Served-By"); got != "candidate" {
+				t.Fatalf("route %s: expected the treatment to serve the request, got '%s'", route, got)
+			}
+		}
+	}
+
+	if got := testutil.ToFloat64(ExperimentOverrides.WithLabelValues("overridden", "base", "candidate", "rollout")); got != 3 {
+		t.Errorf("expected 3 overrides from base to candidate, got %v", got)
+	}
+	// The experiment assigned the backend base selection already chose
+	if n := ExperimentOverrides.DeletePartialMatch(prometheus.Labels{"route": "unchanged"}); n != 0 {
+		t.Errorf("expected no overrides when the backend didn't change, found %d series", n)
+	}
+}
+
+func TestRouter_HandleRequest_UnhealthyTreatmentFallsBackToControl(t *testing.T) {
+	store := cache.NewStore()
+	for _, name := range []string{"stable", "canary"} {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer upstream.Close()
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, newExternalTestBackend(name, upstream.URL))
+	}
+	canary, _ := store.GetBackendByName("default", "canary")
+	canary = canary.DeepCopy()
+	canary.Status.Health = "Unhealthy"
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "canary"}, canary)
+
+	// Every user is assigned the treatment
+	experiment := gatewayv1alpha1.ABExperiment{Name: "canary-test", Control: "stable", Treatment: "canary", TrafficPercent: 100}
+	setRoute := func(experiment gatewayv1alpha1.ABExperiment) {
+		store.SetRoute(types.NamespacedName{Namespace: "default", Name: "experimental"}, &gatewayv1alpha1.InferenceRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: "experimental", Namespace: "default"},
+			Spec: gatewayv1alpha1.InferenceRouteSpec{
+				DefaultBackend: &gatewayv1alpha1.BackendRef{Name: "stable"},
+				Experiments:    []gatewayv1alpha1.ABExperiment{experiment},
+			},
+			Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+		})
+	}
+	setRoute(experiment)
+
+	router := NewRouter(store, nil, zap.New(),
+		WithRouterMetrics(NewMetricsRecorder()),
+		WithRouterExperiments(NewExperimentManager(nil)),
+	)
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		req.Header.Set("X-Route", "experimental")
+		rec := httptest.NewRecorder()
+		router.HandleRequest(req.Context(), rec, req)
+		return rec
+	}
+
+	rec := send()
+	if got := rec.Header().Get("X-Served-By"); got != "stable" {
+		t.Errorf("expected control to serve the request while the treatment is unhealthy, got '%s'", got)
+	}
+	if got := rec.Header().Get("X-Variant"); got != VariantControl {
+		t.Errorf("expected the response to report the control variant, got '%s'", got)
+	}
+	if got := testutil.ToFloat64(ExperimentFallbacks.WithLabelValues("experimental", "canary-test")); got != 1 {
+		t.Errorf("expected 1 experiment fallback, got %v", got)
+	}
+
+	// Without the fallback, the treatment is still tried
+	experiment.FallbackToControl = ptr.To(false)
+	setRoute(experiment)
+	if got := send().Header().Get("X-Served-By"); got != "canary" {
+		t.Errorf("expected the treatment to be tried with the fallback disabled, got '%s'", got)
+	}
+	if got := testutil.ToFloat64(ExperimentFallbacks.WithLabelValues("experimental", "canary-test")); got != 1 {
+		t.Errorf("expected no further fallbacks, got %v", got)
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// maxSchemaErrors bounds the number of validation errors returned to clients
+const maxSchemaErrors = 10
+
+// schemaValidator validates request bodies against route JSON schemas.
+// Compiled schemas are cached by their source document.
+type schemaValidator struct {
+	mu      sync.RWMutex
+	schemas map[string]*jsonschema.Schema
+}
+
+// newSchemaValidator creates an empty schema validator
+func newSchemaValidator() *schemaValidator {
+	return &schemaValidator{
+		schemas: make(map[string]*jsonschema.Schema),
+	}
+}
+
+// compile returns the compiled schema for a schema document
+func (v *schemaValidator) compile(document string) (*jsonschema.Schema, error) {
+	v.mu.RLock()
+	schema, ok := v.schemas[document]
+	v.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(document))
+	if err != nil {
+		return nil, fmt.Errorf("invalid request schema: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("request-schema.json", doc); err != nil {
+		return nil, fmt.Errorf("invalid request schema: %w", err)
+	}
+	schema, err = compiler.Compile("request-schema.json")
+	if err != nil {
+		return nil, fmt.Errorf("invalid request schema: %w", err)
+	}
+
+	v.mu.Lock()
+	v.schemas[document] = schema
+	v.mu.Unlock()
+	return schema, nil
+}
+
+// validateRequest checks the request body against the schema document and
+// restores the body for the backend. It returns the client-facing validation
+// errors, or an error if the schema itself or the body could not be read.
+func (v *schemaValidator) validateRequest(req *http.Request, document string) ([]string, error) {
+	schema, err := v.compile(document)
+	if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	if req.Body != nil {
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return []string{"request body is not valid JSON"}, nil
+	}
+
+	err = schema.Validate(instance)
+	if err == nil {
+		return nil, nil
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return nil, err
+	}
+
+	var messages []string
+	for _, unit := range validationErr.BasicOutput().Errors {
+		if unit.Error == nil {
+			continue
+		}
+		location := unit.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s", location, unit.Error.String()))
+		if len(messages) == maxSchemaErrors {
+			break
+		}
+	}
+	if len(messages) == 0 {
+		messages = []string{validationErr.Error()}
+	}
+	return messages, nil
+}
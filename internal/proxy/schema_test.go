@@ -0,0 +1,177 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+const chatRequestSchema = `{
+	"type": "object",
+	"required": ["model", "messages"],
+	"properties": {
+		"model": {"type": "string"},
+		"messages": {
+			"type": "array",
+			"minItems": 1,
+			"items": {
+				"type": "object",
+				"required": ["role", "content"],
+				"properties": {
+					"role": {"enum": ["system", "user", "assistant", "tool"]}
+				}
+			}
+		}
+	}
+}`
+
+// newSchemaTestRouter creates a router with a single schema-validated route
+// backed by an upstream that records the body it received
+func newSchemaTestRouter(t *testing.T, received *string) *Router {
+	t.Helper()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*received = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(upstream.Close)
+
+	store := cache.NewStore()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type:     gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{URL: upstream.URL, Provider: "custom"},
+		},
+		Status: gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+	})
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "validated"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "validated", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Rules: []gatewayv1alpha1.RouteRule{{
+				Backends:      []gatewayv1alpha1.BackendRef{{Name: "chat"}},
+				RequestSchema: chatRequestSchema,
+			}},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	return NewRouter(store, nil, zap.New())
+}
+
+func TestRouter_RequestSchema_ValidRequest(t *testing.T) {
+	var received string
+	router := newSchemaTestRouter(t, &received)
+
+	body := `{"model": "gpt-4", "messages": [{"role": "user", "content": "Hello"}]}`
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	router.HandleRequest(req.Context(), rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if received != body {
+		t.Errorf("expected backend to receive the original body, got %q", received)
+	}
+}
+
+func TestRouter_RequestSchema_MissingMessages(t *testing.T) {
+	var received string
+	router := newSchemaTestRouter(t, &received)
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{"model": "gpt-4"}`))
+	rec := httptest.NewRecorder()
+
+	router.HandleRequest(req.Context(), rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != problemContentType {
+		t.Errorf("expected Content-Type %q, got %q", problemContentType, ct)
+	}
+
+	var problem Problem
+	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+		t.Fatalf("failed to decode problem: %v", err)
+	}
+	if len(problem.Errors) == 0 || !strings.Contains(strings.Join(problem.Errors, " "), "messages") {
+		t.Errorf("expected validation errors to mention 'messages', got %v", problem.Errors)
+	}
+	if received != "" {
+		t.Error("expected the backend not to be called")
+	}
+}
+
+func TestSchemaValidator_InvalidRoleAndJSON(t *testing.T) {
+	validator := newSchemaValidator()
+
+	tests := []struct {
+		name  string
+		body  string
+		valid bool
+	}{
+		{"valid", `{"model": "m", "messages": [{"role": "user", "content": "hi"}]}`, true},
+		{"invalid role", `{"model": "m", "messages": [{"role": "robot", "content": "hi"}]}`, false},
+		{"empty messages", `{"model": "m", "messages": []}`, false},
+		{"not json", `model=m`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(tt.body))
+
+			violations, err := validator.validateRequest(req, chatRequestSchema)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (len(violations) == 0) != tt.valid {
+				t.Errorf("expected valid=%v, got violations %v", tt.valid, violations)
+			}
+
+			// The body is restored for the backend
+			restored, _ := io.ReadAll(req.Body)
+			if string(restored) != tt.body {
+				t.Errorf("expected body to be restored, got %q", restored)
+			}
+		})
+	}
+}
+
+func TestSchemaValidator_InvalidSchema(t *testing.T) {
+	validator := newSchemaValidator()
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{}`))
+
+	if _, err := validator.validateRequest(req, `{"type": 5}`); err == nil {
+		t.Error("expected an error for an invalid schema")
+	}
+}
@@ -18,15 +18,20 @@ package proxy
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"math/rand"
+	"net"
 	"net/http"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/logr"
 	"go.opentelemetry.io/otel/trace"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
 	"github.com/judeoyovbaire/kortex/internal/cache"
 	"github.com/judeoyovbaire/kortex/internal/tracing"
 )
@@ -50,6 +55,34 @@ type Config struct {
 
 	// MaxRequestBodySize is the maximum allowed request body size in bytes (0 = no limit)
 	MaxRequestBodySize int64
+
+	// MaxHeaderBytes is the maximum size of the request headers in bytes
+	// (0 = net/http's default of 1MB)
+	MaxHeaderBytes int
+
+	// MaxHeaderCount is the maximum number of request header lines
+	// (0 = no limit). Requests with more are rejected with 431.
+	MaxHeaderCount int
+
+	// MaxConcurrentRequests caps the requests the proxy handles at once
+	// (0 = no limit). Requests beyond it are rejected with 503 before any
+	// other processing.
+	MaxConcurrentRequests int
+
+	// TLS serves HTTPS when set (nil = plain HTTP)
+	TLS *TLSConfig
+
+	// ServeModels answers GET /v1/models from the backends in the cache
+	// instead of proxying it to a backend
+	ServeModels bool
+
+	// AllowedPaths restricts the paths the proxy serves to those matching a
+	// pattern (empty = all paths). Patterns are path prefixes, or globs when
+	// they contain *, ? or [.
+	AllowedPaths []string
+
+	// DeniedPaths are never served, even if they match AllowedPaths
+	DeniedPaths []string
 }
 
 // DefaultConfig returns the default proxy configuration
@@ -66,18 +99,38 @@ func DefaultConfig() Config {
 
 // Server is the embedded reverse proxy that routes inference requests
 type Server struct {
-	config      Config
-	cache       *cache.Store
-	router      *Router
-	httpServer  *http.Server
-	log         logr.Logger
-	client      client.Client
-	metrics     *MetricsRecorder
-	rateLimiter *RateLimiter
-	experiments *ExperimentManager
-	costTracker *CostTracker
-	tracer      *tracing.Tracer
-	smartRouter *SmartRouter
+	config        Config
+	cache         *cache.Store
+	router        *Router
+	httpServer    *http.Server
+	log           logr.Logger
+	client        client.Client
+	metrics       *MetricsRecorder
+	rateLimiter   *RateLimiter
+	experiments   *ExperimentManager
+	costTracker   *CostTracker
+	tracer        *tracing.Tracer
+	smartRouter   *SmartRouter
+	identity      *IdentityExtractor
+	admission     *AdmissionController
+	cors          *corsHandler
+	compression   *compressionHandler
+	queue         *RequestQueue
+	adaptive      *AdaptiveLimiter
+	idempotency   *idempotencyCache
+	warmup        *WarmupConfig
+	audit         *AuditLogger
+	rateLimits    *ProviderRateLimiter
+	rechecker     BackendRechecker
+	recheckToken  string
+	redirects     int
+	redirectHosts []string
+	rng           *rand.Rand
+	pinnable      []string
+	rampUp        time.Duration
+
+	// inFlight counts requests being handled, for MaxConcurrentRequests
+	inFlight atomic.Int64
 }
 
 // ServerOption is a functional option for configuring the server
@@ -125,6 +178,141 @@ func WithServerSmartRouter(sr *SmartRouter) ServerOption {
 	}
 }
 
+// WithIdentityExtractor sets the user identity extractor shared by rate limiting and experiments
+func WithIdentityExtractor(ie *IdentityExtractor) ServerOption {
+	return func(s *Server) {
+		s.identity = ie
+	}
+}
+
+// WithAdmissionController bounds in-flight requests, shedding low priority traffic first
+func WithAdmissionController(ac *AdmissionController) ServerOption {
+	return func(s *Server) {
+		s.admission = ac
+	}
+}
+
+// WithCORS enables CORS handling for browser clients. A nil config disables it.
+func WithCORS(cfg *CORSConfig) ServerOption {
+	return func(s *Server) {
+		if cfg == nil {
+			s.cors = nil
+			return
+		}
+		s.cors = newCORSHandler(*cfg)
+	}
+}
+
+// WithCompression gzips responses for clients that accept it. A nil config disables it.
+func WithCompression(cfg *CompressionConfig) ServerOption {
+	return func(s *Server) {
+		if cfg == nil {
+			s.compression = nil
+			return
+		}
+		s.compression = newCompressionHandler(*cfg)
+	}
+}
+
+// WithRequestQueue limits each backend to its MaxConcurrency, queuing requests beyond it
+func WithRequestQueue(q *RequestQueue) ServerOption {
+	return func(s *Server) {
+		s.queue = q
+	}
+}
+
+// WithAdaptiveConcurrency adjusts each backend's concurrency limit from observed
+// latency. It requires WithRequestQueue, which enforces the limit.
+func WithAdaptiveConcurrency(l *AdaptiveLimiter) ServerOption {
+	return func(s *Server) {
+		s.adaptive = l
+	}
+}
+
+// WithIdempotency replays cached responses for requests that repeat an
+// Idempotency-Key, so client retries don't reach the backend twice. A nil
+// config disables it.
+func WithIdempotency(cfg *IdempotencyConfig) ServerOption {
+	return func(s *Server) {
+		if cfg == nil {
+			s.idempotency = nil
+			return
+		}
+		s.idempotency = newIdempotencyCache(*cfg)
+	}
+}
+
+// WithAuditLogger writes the bodies of a sample of requests on routes with
+// audit sampling to the audit logger's sink
+func WithAuditLogger(a *AuditLogger) ServerOption {
+	return func(s *Server) {
+		s.audit = a
+	}
+}
+
+// WithBackendRechecker serves POST /v1/backends/recheck, which schedules an
+// immediate health check of every backend in a namespace. Callers must send
+// the token as a bearer token; an empty token rejects every call.
+func WithBackendRechecker(rc BackendRechecker, token string) ServerOption {
+	return func(s *Server) {
+		s.rechecker = rc
+		s.recheckToken = token
+	}
+}
+
+// WithProviderRateLimiter stops sending requests to backends whose provider
+// reports that its rate limit is almost exhausted, until the limit resets
+func WithProviderRateLimiter(l *ProviderRateLimiter) ServerOption {
+	return func(s *Server) {
+		s.rateLimits = l
+	}
+}
+
+// WithMaxBackendRedirects follows up to n redirects from backends, such as
+// to a provider's regional endpoint, and returns the final response instead
+// of the redirect. Redirects to hosts other than the backend's are only
+// followed to the allowed hosts and their subdomains, and never to private
+// addresses. Zero passes redirects through to the client.
+func WithMaxBackendRedirects(n int, allowedHosts ...string) ServerOption {
+	return func(s *Server) {
+		s.redirects = n
+		s.redirectHosts = allowedHosts
+	}
+}
+
+// WithSelectionRand draws weighted backend selections from rng, so a fixed
+// seed makes traffic splits reproducible
+func WithSelectionRand(rng *rand.Rand) ServerOption {
+	return func(s *Server) {
+		s.rng = rng
+	}
+}
+
+// WithPinnableBackends lets requests force one of the named backends with the
+// X-Pin-Backend header, for debugging a specific backend
+func WithPinnableBackends(backends []string) ServerOption {
+	return func(s *Server) {
+		s.pinnable = backends
+	}
+}
+
+// WithRecoveryRampUp gradually returns backends that just became healthy to
+// their full weight over the window, so a cold backend isn't flooded with
+// traffic the moment it recovers
+func WithRecoveryRampUp(window time.Duration) ServerOption {
+	return func(s *Server) {
+		s.rampUp = window
+	}
+}
+
+// WithWarmup answers unmatched requests with a 503 and Retry-After until the
+// controllers have loaded routes into the cache. A nil config disables it.
+func WithWarmup(cfg *WarmupConfig) ServerOption {
+	return func(s *Server) {
+		s.warmup = cfg
+	}
+}
+
 // NewServer creates a new proxy server
 func NewServer(cfg Config, store *cache.Store, k8sClient client.Client, log logr.Logger, opts ...ServerOption) *Server {
 	s := &Server{
@@ -139,6 +327,16 @@ func NewServer(cfg Config, store *cache.Store, k8sClient client.Client, log logr
 		opt(s)
 	}
 
+	// Share a single identity extractor so experiments and rate limits agree on the user
+	if s.identity == nil {
+		s.identity = NewIdentityExtractor(DefaultIdentitySources()...)
+	} else if s.experiments != nil {
+		s.experiments.SetIdentityExtractor(s.identity)
+	}
+	if s.costTracker != nil {
+		s.costTracker.SetIdentityExtractor(s.identity)
+	}
+
 	// Create the router with backend handler and optional features
 	s.router = NewRouter(store, k8sClient, log,
 		WithRouterMetrics(s.metrics),
@@ -146,15 +344,25 @@ func NewServer(cfg Config, store *cache.Store, k8sClient client.Client, log logr
 		WithRouterCostTracker(s.costTracker),
 		WithRouterTracer(s.tracer),
 		WithSmartRouter(s.smartRouter),
+		WithRouterRequestQueue(s.queue),
+		WithRouterAdaptiveLimiter(s.adaptive),
+		WithRouterWarmup(s.warmup),
+		WithRouterAuditLogger(s.audit),
+		WithRouterProviderRateLimiter(s.rateLimits),
+		WithRouterMaxRedirects(s.redirects, s.redirectHosts...),
+		WithRouterRand(s.rng),
+		WithRouterPinnableBackends(s.pinnable),
+		WithRouterRecoveryRampUp(s.rampUp),
 	)
 
 	// Create the HTTP server
 	s.httpServer = &http.Server{
-		Addr:         cfg.Addr,
-		Handler:      s,
-		ReadTimeout:  cfg.ReadTimeout,
-		WriteTimeout: cfg.WriteTimeout,
-		IdleTimeout:  cfg.IdleTimeout,
+		Addr:           cfg.Addr,
+		Handler:        s,
+		ReadTimeout:    cfg.ReadTimeout,
+		WriteTimeout:   cfg.WriteTimeout,
+		IdleTimeout:    cfg.IdleTimeout,
+		MaxHeaderBytes: cfg.MaxHeaderBytes,
 	}
 
 	return s
@@ -165,13 +373,83 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	ctx := r.Context()
 
+	// Protect the process from overload before doing any work for the request
+	if limit := int64(s.config.MaxConcurrentRequests); limit > 0 {
+		if inFlight := s.inFlight.Add(1); inFlight > limit {
+			s.inFlight.Add(-1)
+			concurrencyLimitRejections.Inc()
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Too many concurrent requests", http.StatusServiceUnavailable)
+			s.log.V(1).Info("Request shed by the concurrent request limit", "limit", limit)
+			return
+		}
+		defer s.inFlight.Add(-1)
+	}
+
+	// Paths the operator hasn't opened up don't exist as far as clients know.
+	// Nothing cleans the path before the handler, so it is cleaned here and
+	// the cleaned path is the one matched and forwarded.
+	requestPath, ok := cleanPath(r.URL.Path)
+	if !ok || !s.config.pathAllowed(requestPath) {
+		s.log.V(1).Info("Request rejected: path not allowed", "path", r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+	if requestPath != r.URL.Path {
+		r.URL.Path = requestPath
+		r.URL.RawPath = ""
+	}
+
+	// Reject requests padded with headers before doing any work for them
+	if s.config.MaxHeaderCount > 0 {
+		if count := headerCount(r.Header); count > s.config.MaxHeaderCount {
+			http.Error(w, "Too many request headers", http.StatusRequestHeaderFieldsTooLarge)
+			s.log.V(1).Info("Request rejected: too many headers",
+				"header_count", count,
+				"max_count", s.config.MaxHeaderCount,
+			)
+			return
+		}
+	}
+
+	// Find the route first for sampling and rate limiting
+	route := s.router.FindRoute(r)
+
 	// Start tracing span for the request
 	if s.tracer != nil {
 		var span trace.Span
-		ctx, span = s.tracer.StartRequestSpan(ctx, r)
+		ctx, span = s.tracer.StartRequestSpan(ctx, r, s.traceSpanOptions(route)...)
 		defer span.End()
 	}
 
+	// Answer CORS preflight requests directly and decorate actual responses
+	if s.cors != nil {
+		if isPreflight(r) {
+			s.cors.handlePreflight(w, r)
+			return
+		}
+		w = s.cors.wrap(w, r)
+	}
+
+	// Compress responses the backend didn't already compress
+	if s.compression != nil {
+		var closeCompression func()
+		w, closeCompression = s.compression.wrap(w, r)
+		defer closeCompression()
+	}
+
+	// List models from the cache without calling a backend
+	if s.config.ServeModels && isModelsRequest(r) {
+		s.router.ServeModels(w, r)
+		return
+	}
+
+	// Re-probe backends on demand, e.g. after an outage is fixed
+	if s.rechecker != nil && isRecheckRequest(r) {
+		s.serveRecheck(w, r)
+		return
+	}
+
 	// Check request body size limit
 	if s.config.MaxRequestBodySize > 0 && r.ContentLength > s.config.MaxRequestBodySize {
 		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
@@ -187,22 +465,38 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxRequestBodySize)
 	}
 
-	// Find the route first for rate limiting
-	route := s.router.FindRoute(r)
+	// Routes without their own rate limit inherit their namespace default
+	var rateLimit *gatewayv1alpha1.RateLimitConfig
+	if route != nil {
+		rateLimit = route.Spec.RateLimit
+		if rateLimit == nil {
+			rateLimit, _ = s.cache.GetNamespaceRateLimit(route.Namespace)
+		}
+	}
 
 	// Apply rate limiting if configured
-	if route != nil && route.Spec.RateLimit != nil && s.rateLimiter != nil {
-		userHeader := route.Spec.RateLimit.UserHeader
-		if userHeader == "" {
-			userHeader = "x-user-id"
+	if rateLimit != nil && s.rateLimiter != nil {
+		// The route's user header takes precedence over the shared identity sources
+		userID, identified := s.identity.UserWithHeader(r, rateLimit.UserHeader)
+
+		// Without a user, a per-user limit falls back to the client IP, if
+		// that is a configured source, or else to the route's limit
+		if rateLimit.PerUser && !identified {
+			if s.metrics != nil {
+				s.metrics.RecordRateLimitMissingUser(route.Name)
+			}
+			s.log.V(1).Info("Per-user rate limit applied without a user identity",
+				"route", route.Name,
+				"userHeader", rateLimit.UserHeader,
+				"limitedByClientIP", userID != "",
+			)
 		}
-		userID := r.Header.Get(userHeader)
 
-		result := s.rateLimiter.Allow(route.Name, userID, route.Spec.RateLimit)
+		result := s.rateLimiter.Allow(route.Name, userID, rateLimit)
 		if !result.Allowed {
 			// Record rate limit hit
 			if s.metrics != nil {
-				s.metrics.RecordRateLimitHit(route.Name, userID)
+				s.metrics.RecordRateLimitHit(route.Name)
 			}
 
 			// Set rate limit headers
@@ -224,8 +518,31 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
 	}
 
-	// Handle the request through the router with traced context
-	s.router.HandleRequest(ctx, w, r)
+	// Shed lower priority requests when the gateway is near capacity
+	if s.admission != nil {
+		priority := RequestPriority(r)
+		release, admitted := s.admission.Admit(priority)
+		if !admitted {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Gateway at capacity", http.StatusServiceUnavailable)
+			s.log.V(1).Info("Request shed by admission control",
+				"priority", priority,
+				"in_flight", s.admission.InFlight(),
+			)
+			return
+		}
+		defer release()
+	}
+
+	// Handle the request through the router with traced context, replaying
+	// the response to repeated idempotency keys
+	if key := r.Header.Get(IdempotencyKeyHeader); key != "" && s.idempotency != nil {
+		s.idempotency.serve(ctx, w, idempotencyKey(r, key, s.identity.Extract(r)), func(w http.ResponseWriter) {
+			s.router.HandleRequest(ctx, w, r)
+		})
+	} else {
+		s.router.HandleRequest(ctx, w, r)
+	}
 
 	// Log the request
 	duration := time.Since(start)
@@ -237,16 +554,65 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	)
 }
 
+// traceSpanOptions applies the route's trace sample rate, if it has one, to
+// the request span
+func (s *Server) traceSpanOptions(route *gatewayv1alpha1.InferenceRoute) []trace.SpanStartOption {
+	if route == nil || route.Spec.TraceSampleRate == nil {
+		return nil
+	}
+	rate, err := strconv.ParseFloat(*route.Spec.TraceSampleRate, 64)
+	if err != nil {
+		s.log.V(1).Info("Ignoring invalid trace sample rate",
+			"route", route.Name,
+			"traceSampleRate", *route.Spec.TraceSampleRate,
+		)
+		return nil
+	}
+	return []trace.SpanStartOption{tracing.WithSampleRate(rate)}
+}
+
+// headerCount returns the number of header lines in the request, counting
+// each value of a repeated header
+func headerCount(header http.Header) int {
+	count := 0
+	for _, values := range header {
+		count += len(values)
+	}
+	return count
+}
+
 // Start begins serving requests. This implements manager.Runnable.
 func (s *Server) Start(ctx context.Context) error {
-	s.log.Info("Starting inference proxy server", "addr", s.config.Addr)
+	s.log.Info("Starting inference proxy server", "addr", s.config.Addr, "tls", s.config.TLS != nil)
+
+	// Load the certificate before listening so misconfiguration fails fast
+	var tlsConfig *tls.Config
+	if s.config.TLS != nil {
+		var err error
+		if tlsConfig, err = newTLSConfig(s.config.TLS); err != nil {
+			return fmt.Errorf("failed to configure proxy TLS: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	// Keep the SLO gauges of idle routes from going stale
+	if s.metrics != nil {
+		go s.metrics.slo.refreshLoop(ctx)
+	}
 
 	// Channel for server errors
 	errCh := make(chan error, 1)
 
 	// Start the HTTP server in a goroutine
 	go func() {
-		if err := s.httpServer.ListenAndServe(); err != http.ErrServerClosed {
+		if err := s.httpServer.Serve(listener); err != http.ErrServerClosed {
 			errCh <- err
 		}
 	}()
@@ -286,6 +652,11 @@ func (s *Server) HealthHandler() http.HandlerFunc {
 	}
 }
 
+// SetProviderDefaults updates the per-provider defaults applied to external backends
+func (s *Server) SetProviderDefaults(defaults map[string]ProviderDefaults) {
+	s.router.handler.SetProviderDefaults(defaults)
+}
+
 // GetMetrics returns the metrics recorder
 func (s *Server) GetMetrics() *MetricsRecorder {
 	return s.metrics
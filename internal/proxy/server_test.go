@@ -0,0 +1,952 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+	"github.com/judeoyovbaire/kortex/internal/tracing"
+)
+
+func TestServer_RateLimitMissingUser(t *testing.T) {
+	store := cache.NewStore()
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "per-user"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "per-user", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			RateLimit: &gatewayv1alpha1.RateLimitConfig{RequestsPerMinute: 60, PerUser: true, UserHeader: "X-Tenant"},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	rl := NewRateLimiter()
+	defer rl.Stop()
+	identity := NewIdentityExtractor(IdentitySource{Type: IdentitySourceHeader, Name: "X-User-ID"})
+	server := NewServer(DefaultConfig(), store, nil, zap.New(),
+		WithRateLimiter(rl),
+		WithMetrics(NewMetricsRecorder()),
+		WithIdentityExtractor(identity),
+	)
+
+	counter := RateLimitMissingUser.WithLabelValues("per-user")
+	before := testutil.ToFloat64(counter)
+
+	send := func(tenant string) {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		req.Header.Set("X-Route", "per-user")
+		if tenant != "" {
+			req.Header.Set("X-Tenant", tenant)
+		}
+		server.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	send("tenant-a")
+	if got := testutil.ToFloat64(counter) - before; got != 0 {
+		t.Errorf("expected no increment for a request with a user, got %v", got)
+	}
+
+	send("")
+	send("")
+	if got := testutil.ToFloat64(counter) - before; got != 2 {
+		t.Errorf("expected 2 requests without a user to be counted, got %v", got)
+	}
+}
+
+func TestServer_RateLimitMissingUser_DefaultSources(t *testing.T) {
+	store := cache.NewStore()
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "per-user-default"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "per-user-default", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			RateLimit: &gatewayv1alpha1.RateLimitConfig{RequestsPerMinute: 60, PerUser: true},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	rl := NewRateLimiter()
+	defer rl.Stop()
+	server := NewServer(DefaultConfig(), store, nil, zap.New(),
+		WithRateLimiter(rl),
+		WithMetrics(NewMetricsRecorder()),
+	)
+
+	counter := RateLimitMissingUser.WithLabelValues("per-user-default")
+	before := testutil.ToFloat64(counter)
+
+	send := func(user string) {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		req.Header.Set("X-Route", "per-user-default")
+		if user != "" {
+			req.Header.Set(DefaultUserIDHeader, user)
+		}
+		server.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	send("user-a")
+	if got := testutil.ToFloat64(counter) - before; got != 0 {
+		t.Errorf("expected no increment for a request with a user, got %v", got)
+	}
+
+	// The default sources fall back to the client IP, which isn't a user
+	send("")
+	if got := testutil.ToFloat64(counter) - before; got != 1 {
+		t.Errorf("expected a request identified only by its IP to be counted, got %v", got)
+	}
+}
+
+func TestServer_TooManyHeaders(t *testing.T) {
+	var called bool
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer backend.Close()
+
+	store := cache.NewStore()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "chat"}, newExternalTestBackend("chat", backend.URL))
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			DefaultBackend: &gatewayv1alpha1.BackendRef{Name: "chat"},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	cfg := DefaultConfig()
+	cfg.MaxHeaderCount = 10
+	cfg.MaxHeaderBytes = 4096
+	server := NewServer(cfg, store, nil, zap.New())
+	if server.httpServer.MaxHeaderBytes != 4096 {
+		t.Errorf("expected MaxHeaderBytes to be passed to the HTTP server, got %d", server.httpServer.MaxHeaderBytes)
+	}
+
+	send := func(headers int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		for i := 0; i < headers; i++ {
+			// Repeated values count as separate headers
+			req.Header.Add("X-Padding", "x")
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := send(11); rec.Code != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("expected 431 for an oversized header set, got %d", rec.Code)
+	}
+	if called {
+		t.Error("expected the rejected request not to reach the backend")
+	}
+
+	if rec := send(5); rec.Code != http.StatusOK {
+		t.Errorf("expected a request within the limit to succeed, got %d", rec.Code)
+	}
+
+	// The limits are off by default so existing clients keep working
+	server = NewServer(DefaultConfig(), store, nil, zap.New())
+	if rec := send(200); rec.Code != http.StatusOK {
+		t.Errorf("expected no header limit by default, got %d", rec.Code)
+	}
+}
+
+func TestServer_MaxConcurrentRequests(t *testing.T) {
+	entered := make(chan struct{}, 10)
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+	}))
+	defer backend.Close()
+
+	store := cache.NewStore()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "chat"}, newExternalTestBackend("chat", backend.URL))
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			DefaultBackend: &gatewayv1alpha1.BackendRef{Name: "chat"},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	cfg := DefaultConfig()
+	cfg.MaxConcurrentRequests = 2
+	server := NewServer(cfg, store, nil, zap.New())
+	send := func() int {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil))
+		return rec.Code
+	}
+	before := testutil.ToFloat64(concurrencyLimitRejections)
+
+	// Fill the proxy to its limit with requests the backend holds open
+	var wg sync.WaitGroup
+	admitted := make([]int, 2)
+	for i := range admitted {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			admitted[i] = send()
+		}()
+	}
+	<-entered
+	<-entered
+
+	// Requests beyond the limit are shed at once
+	shed := make([]int, 3)
+	var shedWG sync.WaitGroup
+	for i := range shed {
+		shedWG.Add(1)
+		go func() {
+			defer shedWG.Done()
+			shed[i] = send()
+		}()
+	}
+	shedWG.Wait()
+	close(release)
+	wg.Wait()
+
+	for _, code := range shed {
+		if code != http.StatusServiceUnavailable {
+			t.Errorf("expected requests over the limit to get 503, got %v", shed)
+			break
+		}
+	}
+	for _, code := range admitted {
+		if code != http.StatusOK {
+			t.Errorf("expected requests within the limit to succeed, got %v", admitted)
+			break
+		}
+	}
+	if got := testutil.ToFloat64(concurrencyLimitRejections) - before; got != 3 {
+		t.Errorf("expected 3 rejections to be counted, got %v", got)
+	}
+
+	// Completed requests free their slots
+	if code := send(); code != http.StatusOK {
+		t.Errorf("expected a request after the others completed to succeed, got %d", code)
+	}
+}
+
+func TestServer_NamespaceDefaultRateLimit(t *testing.T) {
+	store := cache.NewStore()
+	store.SetRoute(types.NamespacedName{Namespace: "team-a", Name: "unlimited"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "unlimited", Namespace: "team-a"},
+		Status:     gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+	store.SetRoute(types.NamespacedName{Namespace: "team-a", Name: "explicit"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "explicit", Namespace: "team-a"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			RateLimit: &gatewayv1alpha1.RateLimitConfig{RequestsPerMinute: 5},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+	store.SetNamespaceRateLimits(map[string]*gatewayv1alpha1.RateLimitConfig{
+		"team-a": {RequestsPerMinute: 1},
+	})
+
+	rl := NewRateLimiter()
+	defer rl.Stop()
+	server := NewServer(DefaultConfig(), store, nil, zap.New(), WithRateLimiter(rl))
+
+	newRequest := func(route string) *http.Request {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		req.Header.Set("X-Namespace", "team-a")
+		req.Header.Set("X-Route", route)
+		return req
+	}
+
+	// A route without a rate limit inherits the namespace default
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, newRequest("unlimited"))
+	if rec.Code == http.StatusTooManyRequests {
+		t.Fatal("first request should not be rate limited")
+	}
+	if got := rec.Header().Get("X-RateLimit-Limit"); got != "1" {
+		t.Errorf("expected namespace default limit '1', got '%s'", got)
+	}
+
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, newRequest("unlimited"))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 from namespace default, got %d", rec.Code)
+	}
+
+	// An explicit route limit overrides the namespace default
+	for i := 0; i < 2; i++ {
+		rec = httptest.NewRecorder()
+		server.ServeHTTP(rec, newRequest("explicit"))
+		if rec.Code == http.StatusTooManyRequests {
+			t.Fatalf("request %d should not be limited by the namespace default", i+1)
+		}
+		if got := rec.Header().Get("X-RateLimit-Limit"); got != "5" {
+			t.Errorf("expected route limit '5', got '%s'", got)
+		}
+	}
+}
+
+func TestServer_NoNamespaceDefaultRateLimit(t *testing.T) {
+	store := cache.NewStore()
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "open"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "open", Namespace: "default"},
+		Status:     gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	rl := NewRateLimiter()
+	defer rl.Stop()
+	server := NewServer(DefaultConfig(), store, nil, zap.New(), WithRateLimiter(rl))
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	req.Header.Set("X-Route", "open")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("X-RateLimit-Limit"); got != "" {
+		t.Errorf("expected no rate limit headers, got limit '%s'", got)
+	}
+}
+
+func TestServer_AdmissionControlShedsLowPriority(t *testing.T) {
+	store := cache.NewStore()
+	ac := NewAdmissionController(AdmissionConfig{
+		MaxInFlight:            2,
+		NormalPriorityFraction: 1,
+		LowPriorityFraction:    0.5,
+	})
+	server := NewServer(DefaultConfig(), store, nil, zap.New(), WithAdmissionController(ac))
+
+	// Occupy the low priority share of capacity
+	release, ok := ac.Admit(PriorityNormal)
+	if !ok {
+		t.Fatal("expected setup request to be admitted")
+	}
+	defer release()
+
+	newRequest := func(priority string) *http.Request {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		req.Header.Set(PriorityHeader, priority)
+		return req
+	}
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, newRequest("low"))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected low priority request to be shed with 503, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header on shed request")
+	}
+
+	// High priority is admitted and reaches the router (no route configured)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, newRequest("high"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected high priority request to be admitted and routed, got %d", rec.Code)
+	}
+
+	if ac.InFlight() != 1 {
+		t.Errorf("expected admitted request to be released, in-flight = %d", ac.InFlight())
+	}
+}
+
+func TestServer_CORSPreflight(t *testing.T) {
+	store := cache.NewStore()
+	cors := DefaultCORSConfig("https://app.example.com")
+	server := NewServer(DefaultConfig(), store, nil, zap.New(), WithCORS(&cors))
+
+	req := httptest.NewRequest(http.MethodOptions, "/v1/chat/completions", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "Authorization, Content-Type")
+	rec := httptest.NewRecorder()
+
+	server.ServeHTTP(rec, req)
+
+	// Answered by the gateway: no route exists, so routing would have returned 404
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", rec.Code)
+	}
+
+	expected := map[string]string{
+		"Access-Control-Allow-Origin":  "https://app.example.com",
+		"Access-Control-Allow-Methods": "GET, POST, OPTIONS",
+		"Access-Control-Allow-Headers": "Authorization, Content-Type",
+		"Access-Control-Max-Age":       "600",
+	}
+	for header, want := range expected {
+		if got := rec.Header().Get(header); got != want {
+			t.Errorf("expected %s '%s', got '%s'", header, want, got)
+		}
+	}
+}
+
+func TestServer_CORSActualRequest(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The gateway's CORS policy replaces the backend's
+		w.Header().Set("Access-Control-Allow-Origin", "https://backend.example.com")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	store := cache.NewStore()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type:     gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{URL: upstream.URL, Provider: "custom"},
+		},
+		Status: gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+	})
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			DefaultBackend: &gatewayv1alpha1.BackendRef{Name: "chat"},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	cors := DefaultCORSConfig("https://app.example.com")
+	server := NewServer(DefaultConfig(), store, nil, zap.New(), WithCORS(&cors))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec := httptest.NewRecorder()
+
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Values("Access-Control-Allow-Origin"); len(got) != 1 || got[0] != "https://app.example.com" {
+		t.Errorf("expected a single allowed origin 'https://app.example.com', got %v", got)
+	}
+	if got := rec.Header().Get("Access-Control-Expose-Headers"); got == "" {
+		t.Error("expected exposed headers on the response")
+	}
+}
+
+// newCompressionTestServer creates a server with compression enabled whose
+// default route proxies to the given upstream handler
+func newCompressionTestServer(t *testing.T, upstream http.HandlerFunc) *Server {
+	t.Helper()
+	backend := httptest.NewServer(upstream)
+	t.Cleanup(backend.Close)
+
+	store := cache.NewStore()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type:     gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{URL: backend.URL, Provider: "custom"},
+		},
+		Status: gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+	})
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			DefaultBackend: &gatewayv1alpha1.BackendRef{Name: "chat"},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	compression := DefaultCompressionConfig()
+	return NewServer(DefaultConfig(), store, nil, zap.New(), WithCompression(&compression))
+}
+
+// largeJSON is a chat completion response well above the compression threshold
+var largeJSON = `{"choices":[{"message":{"content":"` + strings.Repeat("hello world ", 500) + `"}}]}`
+
+func TestServer_CompressesLargeJSON(t *testing.T) {
+	server := newCompressionTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, largeJSON)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	rec := httptest.NewRecorder()
+
+	server.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected gzip content encoding, got '%s'", got)
+	}
+	if rec.Body.Len() >= len(largeJSON) {
+		t.Errorf("expected compressed body smaller than %d bytes, got %d", len(largeJSON), rec.Body.Len())
+	}
+
+	reader, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("expected a valid gzip body: %v", err)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to decompress body: %v", err)
+	}
+	if string(body) != largeJSON {
+		t.Error("expected decompressed body to match the backend response")
+	}
+}
+
+func TestServer_CompressionPassthrough(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, _ = io.WriteString(gz, largeJSON)
+	_ = gz.Close()
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		upstream       http.HandlerFunc
+		wantBody       []byte
+		wantEncoding   string
+	}{
+		{
+			name: "client without gzip",
+			upstream: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, largeJSON)
+			},
+			wantBody: []byte(largeJSON),
+		},
+		{
+			name:           "client refusing gzip",
+			acceptEncoding: "gzip;q=0",
+			upstream: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, largeJSON)
+			},
+			wantBody: []byte(largeJSON),
+		},
+		{
+			name:           "already compressed by backend",
+			acceptEncoding: "gzip",
+			upstream: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Content-Encoding", "gzip")
+				_, _ = w.Write(compressed.Bytes())
+			},
+			wantBody:     compressed.Bytes(),
+			wantEncoding: "gzip",
+		},
+		{
+			name:           "streaming response",
+			acceptEncoding: "gzip",
+			upstream: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = io.WriteString(w, "data: "+largeJSON+"\n\n")
+			},
+			wantBody: []byte("data: " + largeJSON + "\n\n"),
+		},
+		{
+			name:           "small response",
+			acceptEncoding: "gzip",
+			upstream: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, `{"ok":true}`)
+			},
+			wantBody: []byte(`{"ok":true}`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newCompressionTestServer(t, tt.upstream)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+
+			server.ServeHTTP(rec, req)
+
+			if !bytes.Equal(rec.Body.Bytes(), tt.wantBody) {
+				t.Errorf("expected the backend body to pass through unchanged, got %d bytes", rec.Body.Len())
+			}
+			if got := rec.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("expected content encoding '%s', got '%s'", tt.wantEncoding, got)
+			}
+		})
+	}
+}
+
+// newTracingTestServer creates a server whose default route proxies to a
+// healthy backend, with extra server options applied
+func newTracingTestServer(t *testing.T, opts ...ServerOption) *Server {
+	t.Helper()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(upstream.Close)
+
+	store := cache.NewStore()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type:     gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{URL: upstream.URL, Provider: "custom"},
+		},
+		Status: gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+	})
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			DefaultBackend: &gatewayv1alpha1.BackendRef{Name: "chat"},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	return NewServer(DefaultConfig(), store, nil, zap.New(), opts...)
+}
+
+func TestServer_TracingSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	server := newTracingTestServer(t, WithTracer(tracing.NewTracerWithProvider(provider)))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	request, ok := spans["kortex.request"]
+	if !ok {
+		t.Fatalf("expected a request span from the server, got %v", spans)
+	}
+	router, ok := spans["kortex.router.route"]
+	if !ok {
+		t.Fatalf("expected a router span, got %v", spans)
+	}
+	backend, ok := spans["kortex.backend.request"]
+	if !ok {
+		t.Fatalf("expected a backend span, got %v", spans)
+	}
+
+	// The spans form a single trace: request -> router -> backend
+	if router.Parent().SpanID() != request.SpanContext().SpanID() {
+		t.Error("expected the router span to be a child of the request span")
+	}
+	if backend.Parent().SpanID() != router.SpanContext().SpanID() {
+		t.Error("expected the backend span to be a child of the router span")
+	}
+	if backend.SpanContext().TraceID() != request.SpanContext().TraceID() {
+		t.Error("expected all spans to share a trace ID")
+	}
+}
+
+func TestServer_RouteTraceSampleRate(t *testing.T) {
+	tests := []struct {
+		name       string
+		globalRate float64
+		routeRate  string
+		wantSpans  bool
+	}{
+		{name: "route rate 0 overrides global sampling", globalRate: 1.0, routeRate: "0", wantSpans: false},
+		{name: "route rate 1 overrides global sampling", globalRate: 0, routeRate: "1", wantSpans: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(
+				sdktrace.WithSpanProcessor(recorder),
+				sdktrace.WithSampler(tracing.NewSampler(tt.globalRate)),
+			)
+			server := newTracingTestServer(t, WithTracer(tracing.NewTracerWithProvider(provider)))
+
+			key := types.NamespacedName{Namespace: "default", Name: "chat"}
+			route, _ := server.cache.GetRoute(key)
+			route.Spec.TraceSampleRate = ptr.To(tt.routeRate)
+			server.cache.SetRoute(key, route)
+
+			for i := 0; i < 10; i++ {
+				req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+				server.ServeHTTP(httptest.NewRecorder(), req)
+			}
+
+			spans := recorder.Ended()
+			if !tt.wantSpans && len(spans) != 0 {
+				t.Errorf("expected no sampled spans, got %d", len(spans))
+			}
+			if tt.wantSpans {
+				// Child spans follow the request span's decision
+				names := make(map[string]int)
+				for _, span := range spans {
+					names[span.Name()]++
+				}
+				for _, name := range []string{"kortex.request", "kortex.router.route", "kortex.backend.request"} {
+					if names[name] != 10 {
+						t.Errorf("expected 10 sampled %s spans, got %d", name, names[name])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestServer_NoTracer(t *testing.T) {
+	server := newTracingTestServer(t)
+
+	if server.router.tracer != nil || server.router.handler.tracer != nil {
+		t.Error("expected no tracer on the router or backend handler")
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200 without tracing, got %d", rec.Code)
+	}
+}
+
+// newSmartRoutingTestServer creates a server whose route sends all weighted
+// traffic to "primary", with a separate healthy "fast" backend
+func newSmartRoutingTestServer(t *testing.T, opts ...ServerOption) *Server {
+	t.Helper()
+	store := cache.NewStore()
+	for _, name := range []string{"primary", "fast"} {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(upstream.Close)
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, &gatewayv1alpha1.InferenceBackend{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: gatewayv1alpha1.InferenceBackendSpec{
+				Type:     gatewayv1alpha1.BackendTypeExternal,
+				External: &gatewayv1alpha1.ExternalBackend{URL: upstream.URL, Provider: "custom"},
+			},
+			Status: gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+		})
+	}
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Rules: []gatewayv1alpha1.RouteRule{{
+				Backends: []gatewayv1alpha1.BackendRef{{Name: "primary", Weight: ptr.To[int32](100)}},
+			}},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	return NewServer(DefaultConfig(), store, nil, zap.New(), opts...)
+}
+
+func TestServer_SmartRouterSelectsBackend(t *testing.T) {
+	config := DefaultSmartRouterConfig()
+	config.FastModelBackend = "fast"
+	server := newSmartRoutingTestServer(t, WithServerSmartRouter(NewSmartRouter(config, zap.New())))
+
+	// A short prompt is below the fast-model threshold
+	body := `{"messages":[{"role":"user","content":"Hi"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("X-Served-By"); got != "fast" {
+		t.Errorf("expected the smart router to select 'fast', served by '%s'", got)
+	}
+}
+
+func TestSmartRouter_EstimationFailureFallback(t *testing.T) {
+	route := &gatewayv1alpha1.InferenceRoute{
+		Spec: gatewayv1alpha1.InferenceRouteSpec{DefaultBackend: &gatewayv1alpha1.BackendRef{Name: "default"}},
+	}
+	unreadable := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		req.Body = io.NopCloser(iotest.ErrReader(errors.New("connection reset")))
+		return req
+	}
+
+	config := DefaultSmartRouterConfig()
+	config.FastModelBackend = "fast"
+	decision := NewSmartRouter(config, zap.New()).SelectBackend(unreadable(), route)
+	if decision.Category != "unknown" || decision.Backend != "default" {
+		t.Errorf("expected an unreadable body to use the route default, got %s via %q", decision.Category, decision.Backend)
+	}
+
+	config.EstimationFailureBackend = "large"
+	decision = NewSmartRouter(config, zap.New()).SelectBackend(unreadable(), route)
+	if decision.Backend != "large" {
+		t.Errorf("expected the estimation failure backend, got %q", decision.Backend)
+	}
+
+	// A genuinely short request still goes to the fast model
+	short := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"messages":[{"content":"Hi"}]}`))
+	if decision := NewSmartRouter(config, zap.New()).SelectBackend(short, route); decision.Backend != "fast" {
+		t.Errorf("expected a short request to use the fast model, got %q", decision.Backend)
+	}
+}
+
+func TestSmartRouter_CategoryMetric(t *testing.T) {
+	router := NewSmartRouter(DefaultSmartRouterConfig(), zap.New())
+	route := &gatewayv1alpha1.InferenceRoute{}
+
+	tests := []struct {
+		category string
+		content  string
+	}{
+		{"short", "Hi"},
+		{"medium", strings.Repeat("word ", 1000)},
+		{"long", strings.Repeat("word ", 5000)},
+	}
+	for _, tt := range tests {
+		before := testutil.ToFloat64(smartRouterCategories.WithLabelValues(tt.category))
+		body := `{"messages":[{"content":"` + tt.content + `"}]}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+
+		if decision := router.SelectBackend(req, route); decision.Category != tt.category {
+			t.Fatalf("expected category %s, got %s", tt.category, decision.Category)
+		}
+		if got := testutil.ToFloat64(smartRouterCategories.WithLabelValues(tt.category)) - before; got != 1 {
+			t.Errorf("expected the %s counter to increase by 1, got %v", tt.category, got)
+		}
+	}
+}
+
+func TestServer_NoSmartRouter(t *testing.T) {
+	server := newSmartRoutingTestServer(t)
+
+	if server.router.smartRouter != nil {
+		t.Fatal("expected no smart router on the router")
+	}
+
+	body := `{"messages":[{"role":"user","content":"Hi"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("X-Served-By"); got != "primary" {
+		t.Errorf("expected weighted selection of 'primary', served by '%s'", got)
+	}
+}
+
+func TestServer_SmartRouterWithoutDecision(t *testing.T) {
+	// No backends configured for any category, so weighted selection applies
+	server := newSmartRoutingTestServer(t, WithServerSmartRouter(NewSmartRouter(DefaultSmartRouterConfig(), zap.New())))
+
+	body := `{"messages":[{"role":"user","content":"Hi"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("X-Served-By"); got != "primary" {
+		t.Errorf("expected weighted selection of 'primary', served by '%s'", got)
+	}
+}
+
+func TestServer_SmartRouterSkipsSmallContextBackend(t *testing.T) {
+	store := cache.NewStore()
+	for name, limit := range map[string]int32{"small": 8000, "large": 128000} {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(upstream.Close)
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, &gatewayv1alpha1.InferenceBackend{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: gatewayv1alpha1.InferenceBackendSpec{
+				Type:             gatewayv1alpha1.BackendTypeExternal,
+				External:         &gatewayv1alpha1.ExternalBackend{URL: upstream.URL, Provider: "custom"},
+				MaxContextTokens: limit,
+			},
+			Status: gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+		})
+	}
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceRouteSpec{
+			Rules: []gatewayv1alpha1.RouteRule{{
+				Backends: []gatewayv1alpha1.BackendRef{
+					{Name: "small", Weight: ptr.To[int32](90)},
+					{Name: "large", Weight: ptr.To[int32](10)},
+				},
+			}},
+		},
+		Status: gatewayv1alpha1.InferenceRouteStatus{Phase: "Active"},
+	})
+
+	// Roughly 25k tokens, far more than the small backend's context
+	large := `{"messages":[{"role":"user","content":"` + strings.Repeat("word ", 20000) + `"}]}`
+	small := `{"messages":[{"role":"user","content":"` + strings.Repeat("word ", 1000) + `"}]}`
+
+	tests := []struct {
+		name     string
+		config   SmartRouterConfig
+		body     string
+		expected string
+	}{
+		{
+			name:     "chosen backend too small",
+			config:   SmartRouterConfig{LongContextThreshold: 4000, LongContextBackend: "small"},
+			body:     large,
+			expected: "large",
+		},
+		{
+			name:     "weighted selection",
+			config:   SmartRouterConfig{LongContextThreshold: 4000},
+			body:     large,
+			expected: "large",
+		},
+		{
+			name:     "request fits",
+			config:   SmartRouterConfig{LongContextThreshold: 100, LongContextBackend: "small"},
+			body:     small,
+			expected: "small",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(DefaultConfig(), store, nil, zap.New(),
+				WithServerSmartRouter(NewSmartRouter(tt.config, zap.New())))
+
+			for i := 0; i < 10; i++ {
+				req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(tt.body))
+				rec := httptest.NewRecorder()
+				server.ServeHTTP(rec, req)
+
+				if got := rec.Header().Get("X-Served-By"); got != tt.expected {
+					t.Fatalf("expected the request to be served by '%s', got '%s'", tt.expected, got)
+				}
+			}
+		})
+	}
+}
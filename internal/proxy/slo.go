@@ -0,0 +1,234 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+)
+
+var (
+	routeSuccessRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kortex_route_success_ratio",
+			Help: "Share of requests per route that succeeded over the SLO window",
+		},
+		[]string{"route"},
+	)
+
+	routeErrorBudgetBurnRate = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kortex_route_error_budget_burn_rate",
+			Help: "How fast each route spends its error budget over the SLO window, where 1 spends it exactly at the SLO target",
+		},
+		[]string{"route"},
+	)
+)
+
+const (
+	// DefaultSLOWindow is the rolling window the success ratio is computed over
+	DefaultSLOWindow = 5 * time.Minute
+
+	// sloBuckets is the number of buckets the window is divided into
+	sloBuckets = 10
+)
+
+// sloTracker keeps a rolling success ratio per route. The window is split
+// into buckets so old requests age out without storing each one.
+type sloTracker struct {
+	window time.Duration
+
+	mu     sync.Mutex
+	routes map[string]*sloRoute
+	now    func() time.Time
+}
+
+// sloRoute is the rolling window and SLO target of one route
+type sloRoute struct {
+	target  float64
+	buckets [sloBuckets]sloBucket
+}
+
+// sloBucket counts the requests that started within one slice of the window
+type sloBucket struct {
+	start  time.Time
+	total  int64
+	failed int64
+}
+
+// newSLOTracker creates a tracker for the given window
+func newSLOTracker(window time.Duration) *sloTracker {
+	if window <= 0 {
+		window = DefaultSLOWindow
+	}
+	return &sloTracker{
+		window: window,
+		routes: make(map[string]*sloRoute),
+		now:    time.Now,
+	}
+}
+
+// setTarget sets the route's SLO target, a ratio between 0 and 1. A target
+// of 0 means the route has none and no burn rate is reported.
+func (t *sloTracker) setTarget(route string, target float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.routeLocked(route)
+	if state.target == target {
+		return
+	}
+	state.target = target
+	if target <= 0 || target >= 1 {
+		routeErrorBudgetBurnRate.DeleteLabelValues(route)
+		return
+	}
+	t.updateLocked(route, state)
+}
+
+// record adds a completed request to the route's window and updates its
+// gauges. Requests without a response or with a 5xx status count as failed.
+func (t *sloTracker) record(route string, statusCode int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.routeLocked(route)
+	width := t.window / sloBuckets
+	now := t.now()
+	start := now.Truncate(width)
+	bucket := &state.buckets[(now.UnixNano()/int64(width))%sloBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = sloBucket{start: start}
+	}
+	bucket.total++
+	if statusCode == 0 || statusCode >= http.StatusInternalServerError {
+		bucket.failed++
+	}
+	t.updateLocked(route, state)
+}
+
+// successRatio returns the route's success ratio over the window, and false
+// if it has no requests in the window
+func (t *sloTracker) successRatio(route string) (float64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.routes[route]
+	if !ok {
+		return 0, false
+	}
+	return t.ratioLocked(state)
+}
+
+// delete forgets the route and removes its gauges
+func (t *sloTracker) delete(route string) {
+	t.mu.Lock()
+	delete(t.routes, route)
+	t.mu.Unlock()
+
+	routeSuccessRatio.DeleteLabelValues(route)
+	routeErrorBudgetBurnRate.DeleteLabelValues(route)
+}
+
+// refresh re-evaluates the gauges of every route, so that a route that stops
+// receiving requests ages out of its window instead of keeping its last value
+func (t *sloTracker) refresh() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for route, state := range t.routes {
+		t.updateLocked(route, state)
+	}
+}
+
+// refreshLoop calls refresh once per bucket until the context is done
+func (t *sloTracker) refreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(t.window / sloBuckets)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.refresh()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// routeLocked returns the route's state, creating it if needed. The caller
+// must hold t.mu.
+func (t *sloTracker) routeLocked(route string) *sloRoute {
+	state, ok := t.routes[route]
+	if !ok {
+		state = &sloRoute{}
+		t.routes[route] = state
+	}
+	return state
+}
+
+// ratioLocked sums the buckets still inside the window. The caller must hold
+// t.mu.
+func (t *sloTracker) ratioLocked(state *sloRoute) (float64, bool) {
+	cutoff := t.now().Add(-t.window)
+	var total, failed int64
+	for _, bucket := range state.buckets {
+		if bucket.start.After(cutoff) {
+			total += bucket.total
+			failed += bucket.failed
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return float64(total-failed) / float64(total), true
+}
+
+// updateLocked refreshes the route's gauges, removing them once the window
+// has no requests. The caller must hold t.mu.
+func (t *sloTracker) updateLocked(route string, state *sloRoute) {
+	ratio, ok := t.ratioLocked(state)
+	if !ok {
+		routeSuccessRatio.DeleteLabelValues(route)
+		routeErrorBudgetBurnRate.DeleteLabelValues(route)
+		return
+	}
+	routeSuccessRatio.WithLabelValues(route).Set(ratio)
+	if state.target > 0 && state.target < 1 {
+		// The error budget is the share of requests the target allows to fail
+		routeErrorBudgetBurnRate.WithLabelValues(route).Set((1 - ratio) / (1 - state.target))
+	}
+}
+
+// routeSLOTarget returns the route's SLO target, or 0 if it has none
+func routeSLOTarget(route *gatewayv1alpha1.InferenceRoute) float64 {
+	if route == nil || route.Spec.SLOTarget == nil {
+		return 0
+	}
+	target, err := strconv.ParseFloat(*route.Spec.SLOTarget, 64)
+	if err != nil {
+		return 0
+	}
+	return target
+}
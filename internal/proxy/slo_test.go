@@ -0,0 +1,97 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsRecorder_SuccessRatio(t *testing.T) {
+	m := NewMetricsRecorder()
+	m.SetSLOTarget("slo-route", 0.9)
+
+	for i := 0; i < 8; i++ {
+		m.RecordRequest("slo-route", "slo-backend", 200, time.Millisecond)
+	}
+	m.RecordRequest("slo-route", "slo-backend", 500, time.Millisecond)
+	m.RecordError("slo-route", "slo-backend", "request_failed")
+	m.RecordRequest("slo-route", "slo-backend", 0, time.Millisecond)
+
+	if got := testutil.ToFloat64(routeSuccessRatio.WithLabelValues("slo-route")); got != 0.8 {
+		t.Errorf("expected a success ratio of 0.8, got %v", got)
+	}
+	// 20% of requests failed against a budget of 10%
+	if got := testutil.ToFloat64(routeErrorBudgetBurnRate.WithLabelValues("slo-route")); math.Abs(got-2) > 1e-9 {
+		t.Errorf("expected a burn rate of 2, got %v", got)
+	}
+
+	m.DeleteRouteMetrics("slo-route")
+	if n := countSeries(t, "route", "slo-route"); n != 0 {
+		t.Errorf("expected no series for the deleted route, found %d", n)
+	}
+}
+
+func TestSLOTracker_WindowExpires(t *testing.T) {
+	tracker := newSLOTracker(time.Minute)
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+
+	tracker.record("expiring", 500)
+	tracker.record("expiring", 503)
+	now = now.Add(30 * time.Second)
+	tracker.record("expiring", 200)
+
+	if ratio, _ := tracker.successRatio("expiring"); math.Abs(ratio-1.0/3) > 1e-9 {
+		t.Errorf("expected a success ratio of 1/3, got %v", ratio)
+	}
+
+	// The failures age out of the window before the success does
+	now = now.Add(45 * time.Second)
+	if ratio, ok := tracker.successRatio("expiring"); !ok || ratio != 1 {
+		t.Errorf("expected only the success to remain in the window, got %v (%v)", ratio, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := tracker.successRatio("expiring"); ok {
+		t.Error("expected no requests once the window has passed")
+	}
+}
+
+func TestSLOTracker_RefreshRemovesIdleRoutes(t *testing.T) {
+	tracker := newSLOTracker(time.Minute)
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+	defer tracker.delete("idle-route")
+
+	tracker.setTarget("idle-route", 0.9)
+	tracker.record("idle-route", 500)
+	tracker.record("idle-route", 200)
+	if got := testutil.ToFloat64(routeSuccessRatio.WithLabelValues("idle-route")); got != 0.5 {
+		t.Fatalf("expected a success ratio of 0.5, got %v", got)
+	}
+
+	// Without new requests the gauges only change when refreshed
+	now = now.Add(2 * time.Minute)
+	tracker.refresh()
+	if n := countSeries(t, "route", "idle-route"); n != 0 {
+		t.Errorf("expected the idle route's series to be removed, found %d", n)
+	}
+}
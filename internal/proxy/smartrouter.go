@@ -26,10 +26,19 @@ import (
 	"sync"
 
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
 
 	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
 )
 
+var smartRouterCategories = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "kortex_smartrouter_category_total",
+		Help: "Requests categorized by the smart router, by estimated size",
+	},
+	[]string{"category"},
+)
+
 // SmartRouterConfig holds configuration for smart routing decisions
 type SmartRouterConfig struct {
 	// LongContextThreshold is the token count above which requests are routed to long-context models
@@ -47,6 +56,10 @@ type SmartRouterConfig struct {
 	// DefaultBackend is the backend used when no smart routing rules match
 	DefaultBackend string
 
+	// EstimationFailureBackend is the backend for requests whose body can't
+	// be read to estimate tokens. Empty uses the route's default backend.
+	EstimationFailureBackend string
+
 	// EnableCostOptimization enables cost-based routing decisions
 	EnableCostOptimization bool
 }
@@ -87,6 +100,7 @@ func (s *SmartRouter) UpdateConfig(newConfig SmartRouterConfig) {
 		"fast_model_threshold", newConfig.FastModelThreshold,
 		"long_context_backend", newConfig.LongContextBackend,
 		"fast_model_backend", newConfig.FastModelBackend,
+		"estimation_failure_backend", newConfig.EstimationFailureBackend,
 		"cost_optimization", newConfig.EnableCostOptimization,
 	)
 }
@@ -109,14 +123,15 @@ type RouteDecision struct {
 	// EstimatedTokens is the estimated input token count
 	EstimatedTokens int
 
-	// Category is the request category (short, medium, long)
+	// Category is the request category (short, medium, long), or unknown if
+	// the tokens couldn't be estimated
 	Category string
 }
 
 // SelectBackend analyzes the request and returns a routing decision
 func (s *SmartRouter) SelectBackend(req *http.Request, route *gatewayv1alpha1.InferenceRoute) *RouteDecision {
 	// Try to extract and estimate tokens from the request body
-	estimatedTokens := s.estimateRequestTokens(req)
+	estimatedTokens, err := s.estimateRequestTokens(req)
 
 	decision := &RouteDecision{
 		EstimatedTokens: estimatedTokens,
@@ -124,6 +139,14 @@ func (s *SmartRouter) SelectBackend(req *http.Request, route *gatewayv1alpha1.In
 
 	// Determine category based on token count
 	switch {
+	case err != nil:
+		// Treating an unreadable body as short would send it to the fast model
+		decision.Category = "unknown"
+		if s.config.EstimationFailureBackend != "" {
+			decision.Backend = s.config.EstimationFailureBackend
+			decision.Reason = "Token estimation failed"
+		}
+
 	case estimatedTokens > s.config.LongContextThreshold:
 		decision.Category = "long"
 		if s.config.LongContextBackend != "" {
@@ -146,6 +169,8 @@ func (s *SmartRouter) SelectBackend(req *http.Request, route *gatewayv1alpha1.In
 		}
 	}
 
+	smartRouterCategories.WithLabelValues(decision.Category).Inc()
+
 	// If no backend was selected, fall back to default
 	if decision.Backend == "" {
 		if route.Spec.DefaultBackend != nil {
@@ -164,17 +189,18 @@ func (s *SmartRouter) SelectBackend(req *http.Request, route *gatewayv1alpha1.In
 	return decision
 }
 
-// estimateRequestTokens extracts message content and estimates token count
-func (s *SmartRouter) estimateRequestTokens(req *http.Request) int {
+// estimateRequestTokens extracts message content and estimates token count.
+// It returns an error if the body can't be read.
+func (s *SmartRouter) estimateRequestTokens(req *http.Request) (int, error) {
 	if req.Body == nil {
-		return 0
+		return 0, nil
 	}
 
 	// Read the body
 	bodyBytes, err := io.ReadAll(req.Body)
 	if err != nil {
 		s.log.V(1).Info("Failed to read request body for token estimation", "error", err)
-		return 0
+		return 0, err
 	}
 
 	// Replace the body so it can still be read by subsequent handlers
@@ -191,7 +217,7 @@ func (s *SmartRouter) estimateRequestTokens(req *http.Request) int {
 	if err := json.Unmarshal(bodyBytes, &chatReq); err != nil {
 		s.log.V(2).Info("Failed to parse request body as chat format", "error", err)
 		// Fall back to raw body token estimation
-		return estimateTokensFromText(string(bodyBytes))
+		return estimateTokensFromText(string(bodyBytes)), nil
 	}
 
 	// Aggregate all message content
@@ -206,7 +232,7 @@ func (s *SmartRouter) estimateRequestTokens(req *http.Request) int {
 		totalText.WriteString(chatReq.Prompt)
 	}
 
-	return estimateTokensFromText(totalText.String())
+	return estimateTokensFromText(totalText.String()), nil
 }
 
 // estimateTokensFromText provides a rough token estimate
@@ -0,0 +1,129 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// errStreamIdle is returned when a streaming backend sends nothing for longer
+// than the route's idle timeout
+var errStreamIdle = errors.New("stream idle timeout")
+
+// idleTimeoutBody wraps a streaming response body and fails the stream when
+// the backend goes silent for longer than the timeout. The full response may
+// legitimately take minutes, but a long gap between bytes means the backend
+// has stalled.
+type idleTimeoutBody struct {
+	body    io.ReadCloser
+	timeout time.Duration
+	chunks  chan heartbeatChunk
+	done    chan struct{}
+	once    sync.Once
+
+	// onStall is called when the stream times out while being copied to the
+	// client. ReverseProxy aborts the handler when the copy fails, so this is
+	// the last point the proxy can record it.
+	onStall func()
+
+	pending  []byte
+	err      error
+	timedOut bool
+}
+
+// newIdleTimeoutBody wraps a streaming response body. onStall, if not nil,
+// is called once if the stream stalls after its first byte.
+func newIdleTimeoutBody(body io.ReadCloser, timeout time.Duration, onStall func()) *idleTimeoutBody {
+	b := &idleTimeoutBody{
+		body:    body,
+		timeout: timeout,
+		chunks:  make(chan heartbeatChunk),
+		done:    make(chan struct{}),
+		onStall: onStall,
+	}
+	go b.readLoop()
+	return b
+}
+
+// readLoop reads from the backend so that Read can wait for data and the
+// idle timeout at the same time
+func (b *idleTimeoutBody) readLoop() {
+	for {
+		buf := make([]byte, 32*1024)
+		n, err := b.body.Read(buf)
+		select {
+		case b.chunks <- heartbeatChunk{data: buf[:n], err: err}:
+		case <-b.done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// awaitFirstByte waits for the backend's first data before anything is sent
+// to the client, so a backend that is silent from the start can fail over
+func (b *idleTimeoutBody) awaitFirstByte() error {
+	for len(b.pending) == 0 && b.err == nil {
+		b.wait()
+	}
+	if b.timedOut {
+		return b.err
+	}
+	return nil
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	for len(b.pending) == 0 && b.err == nil {
+		b.wait()
+		if b.timedOut && b.onStall != nil {
+			b.onStall()
+		}
+	}
+	if len(b.pending) > 0 {
+		n := copy(p, b.pending)
+		b.pending = b.pending[n:]
+		return n, nil
+	}
+	return 0, b.err
+}
+
+// wait blocks until the backend sends data or the idle timeout passes
+func (b *idleTimeoutBody) wait() {
+	timer := time.NewTimer(b.timeout)
+	defer timer.Stop()
+	select {
+	case chunk := <-b.chunks:
+		b.pending, b.err = chunk.data, chunk.err
+	case <-timer.C:
+		b.timedOut = true
+		b.err = errStreamIdle
+	}
+}
+
+func (b *idleTimeoutBody) Close() error {
+	var err error
+	b.once.Do(func() {
+		close(b.done)
+		err = b.body.Close()
+	})
+	return err
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+func TestIdleTimeoutBody_AbortsAfterStall(t *testing.T) {
+	upstream, writer := io.Pipe()
+	defer func() { _ = writer.Close() }()
+	body := newIdleTimeoutBody(upstream, 50*time.Millisecond, nil)
+	defer func() { _ = body.Close() }()
+
+	go func() { _, _ = writer.Write([]byte("data: first\n\n")) }()
+
+	start := time.Now()
+	data, err := io.ReadAll(body)
+	if !errors.Is(err, errStreamIdle) {
+		t.Fatalf("expected the stalled stream to fail with errStreamIdle, got %v", err)
+	}
+	if string(data) != "data: first\n\n" {
+		t.Errorf("expected the data sent before the stall, got %q", data)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the stream to abort after the idle timeout, took %v", elapsed)
+	}
+}
+
+func TestIdleTimeoutBody_AllowsLongSteadyStream(t *testing.T) {
+	upstream, writer := io.Pipe()
+	body := newIdleTimeoutBody(upstream, 50*time.Millisecond, nil)
+	defer func() { _ = body.Close() }()
+	wait := readStream(t, body)
+
+	// The whole stream takes longer than the idle timeout, but no gap does
+	for i := 0; i < 20; i++ {
+		_, _ = writer.Write([]byte("data: token\n\n"))
+		time.Sleep(10 * time.Millisecond)
+	}
+	_ = writer.Close()
+
+	if got := wait(); len(got) != 20*len("data: token\n\n") {
+		t.Errorf("expected the full stream, got %q", got)
+	}
+	if body.timedOut {
+		t.Error("expected a steady stream not to time out")
+	}
+}
+
+// newStreamIdleTestHandler returns a handler with the named backends and a
+// route that aborts streams idle for a second
+func newStreamIdleTestHandler(backends map[string]string) (*BackendHandler, *gatewayv1alpha1.InferenceRoute) {
+	store := cache.NewStore()
+	for name, url := range backends {
+		store.SetBackend(types.NamespacedName{Namespace: "default", Name: name}, newExternalTestBackend(name, url))
+	}
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec:       gatewayv1alpha1.InferenceRouteSpec{StreamIdleTimeoutSeconds: 1},
+	}
+	return NewBackendHandler(store, nil, zap.New(), nil, nil, nil), route
+}
+
+func TestBackendHandler_StreamIdleTimeoutAbortsStalledStream(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: first\n\n"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		_, _ = w.Write([]byte("data: second\n\n"))
+	}))
+	defer upstream.Close()
+
+	handler, route := newStreamIdleTestHandler(map[string]string{"stalls": upstream.URL})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "stalls"})
+
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("expected the stream to be aborted after the idle timeout, took %v", elapsed)
+	}
+	if got := rec.Body.String(); got != "data: first\n\n" {
+		t.Errorf("expected only the data sent before the stall, got %q", got)
+	}
+}
+
+func TestBackendHandler_StreamIdleTimeoutFailsOverBeforeFirstByte(t *testing.T) {
+	silent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer silent.Close()
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: backup\n\n"))
+	}))
+	defer backup.Close()
+
+	handler, route := newStreamIdleTestHandler(map[string]string{"silent": silent.URL, "backup": backup.URL})
+	route.Spec.Fallback = &gatewayv1alpha1.FallbackChain{Backends: []string{"backup"}}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	handler.ExecuteWithFallback(context.Background(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "silent"})
+
+	if got := rec.Header().Get("X-Served-By"); got != "backup" {
+		t.Errorf("expected the silent backend to fail over to backup, served by %q", got)
+	}
+	if got := rec.Body.String(); got != "data: backup\n\n" {
+		t.Errorf("expected the backup's stream, got %q", got)
+	}
+}
+
+func TestBackendHandler_StreamIdleTimeoutRecordedUnderServer(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: first\n\n"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer upstream.Close()
+
+	handler, route := newStreamIdleTestHandler(map[string]string{"stalls-served": upstream.URL})
+	handler.metrics = NewMetricsRecorder()
+	queue := NewRequestQueue(QueueConfig{MaxSize: 0})
+	handler.SetRequestQueue(queue)
+
+	// Under a real server, ReverseProxy aborts the handler when the stream
+	// fails after the response started
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ExecuteWithFallback(r.Context(), w, r, route, nil, gatewayv1alpha1.BackendRef{Name: "stalls-served"})
+	}))
+	defer proxy.Close()
+
+	resp, err := http.Post(proxy.URL+"/v1/chat/completions", "application/json", nil)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_, _ = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	counter := RequestErrors.WithLabelValues("chat", "stalls-served", "stream_idle_timeout")
+	waitFor(t, func() bool { return testutil.ToFloat64(counter) == 1 })
+	waitFor(t, func() bool { return queue.Active("stalls-served") == 0 })
+}
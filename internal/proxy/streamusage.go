@@ -0,0 +1,224 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+)
+
+// maxSSELineSize bounds a single SSE line read while scanning for usage
+const maxSSELineSize = 1024 * 1024
+
+// isEventStream reports whether the response is a server-sent event stream
+func isEventStream(resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "text/event-stream"
+}
+
+// streamUsageParser accumulates token usage from the lines of an SSE stream
+type streamUsageParser interface {
+	ParseLine(line []byte)
+	Usage() TokenUsage
+}
+
+// newStreamUsageParser returns the stream parser for a provider, or nil if
+// the provider's streams can't be parsed
+func newStreamUsageParser(provider string) streamUsageParser {
+	switch provider {
+	case "openai", "":
+		return &openAIStreamParser{}
+	case "anthropic":
+		return &anthropicStreamParser{}
+	default:
+		return nil
+	}
+}
+
+// openAIStreamParser reads token usage from an OpenAI SSE stream. Usage is
+// only sent, in the final chunk, when the request sets
+// stream_options.include_usage.
+type openAIStreamParser struct {
+	usage TokenUsage
+}
+
+// ParseLine processes a single line of the SSE stream
+func (p *openAIStreamParser) ParseLine(line []byte) {
+	data, ok := bytes.CutPrefix(line, []byte("data:"))
+	if !ok {
+		return
+	}
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("[DONE]")) {
+		return
+	}
+
+	if usage := parseOpenAIUsage(data); usage.InputTokens > 0 || usage.OutputTokens > 0 {
+		p.usage = usage
+	}
+}
+
+// Usage returns the token usage accumulated so far
+func (p *openAIStreamParser) Usage() TokenUsage {
+	return p.usage
+}
+
+// anthropicStreamParser accumulates token usage from an Anthropic SSE stream.
+// Input tokens are reported in the message_start event, and the cumulative
+// output token count is reported in the final message_delta event.
+type anthropicStreamParser struct {
+	usage TokenUsage
+}
+
+// anthropicStreamEvent is the subset of an Anthropic stream event used for usage
+type anthropicStreamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Usage struct {
+			InputTokens  tokenCount `json:"input_tokens"`
+			OutputTokens tokenCount `json:"output_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+	Usage struct {
+		InputTokens  tokenCount `json:"input_tokens"`
+		OutputTokens tokenCount `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// ParseLine processes a single line of the SSE stream
+func (p *anthropicStreamParser) ParseLine(line []byte) {
+	data, ok := bytes.CutPrefix(line, []byte("data:"))
+	if !ok {
+		return
+	}
+	data = bytes.TrimSpace(data)
+
+	var event anthropicStreamEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return
+	}
+
+	switch event.Type {
+	case "message_start":
+		p.usage.InputTokens = int64(event.Message.Usage.InputTokens)
+		p.usage.OutputTokens = int64(event.Message.Usage.OutputTokens)
+	case "message_delta":
+		// output_tokens in message_delta is cumulative, so the last one wins
+		if event.Usage.OutputTokens > 0 {
+			p.usage.OutputTokens = int64(event.Usage.OutputTokens)
+		}
+		if event.Usage.InputTokens > 0 {
+			p.usage.InputTokens = int64(event.Usage.InputTokens)
+		}
+	}
+}
+
+// Usage returns the token usage accumulated so far
+func (p *anthropicStreamParser) Usage() TokenUsage {
+	return p.usage
+}
+
+// ParseAnthropicStreamUsage extracts token usage from an Anthropic SSE stream.
+// Lines longer than maxSSELineSize are skipped rather than ending the parse.
+func ParseAnthropicStreamUsage(r io.Reader) TokenUsage {
+	var usage TokenUsage
+	body := newUsageTrackingBody(io.NopCloser(r), &anthropicStreamParser{},
+		func(u TokenUsage) { usage = u })
+	_, _ = io.Copy(io.Discard, body)
+	_ = body.Close()
+	return usage
+}
+
+// usageTrackingBody parses an SSE stream as the client reads it and reports
+// the accumulated usage once when the body is closed. The stream is passed
+// through unchanged. At most maxSSELineSize bytes of a line are buffered; a
+// longer line, such as a huge tool call argument, is forwarded but not parsed.
+type usageTrackingBody struct {
+	body    io.ReadCloser
+	parser  streamUsageParser
+	onClose func(TokenUsage)
+
+	// pending holds a partial line until its newline arrives
+	pending []byte
+	// discarding skips the rest of a line longer than maxSSELineSize
+	discarding bool
+	once       sync.Once
+}
+
+// newUsageTrackingBody wraps a streaming response body
+func newUsageTrackingBody(body io.ReadCloser, parser streamUsageParser, onClose func(TokenUsage)) *usageTrackingBody {
+	return &usageTrackingBody{
+		body:    body,
+		parser:  parser,
+		onClose: onClose,
+	}
+}
+
+func (b *usageTrackingBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.scan(p[:n])
+	return n, err
+}
+
+// scan feeds complete lines to the parser, buffering any partial line
+func (b *usageTrackingBody) scan(data []byte) {
+	for len(data) > 0 {
+		line, rest, found := bytes.Cut(data, []byte("\n"))
+		if !found {
+			if !b.discarding {
+				b.pending = append(b.pending, line...)
+				if len(b.pending) > maxSSELineSize {
+					b.pending = nil
+					b.discarding = true
+				}
+			}
+			return
+		}
+
+		if !b.discarding {
+			if len(b.pending) > 0 {
+				line = append(b.pending, line...)
+			}
+			b.parser.ParseLine(bytes.TrimSuffix(line, []byte("\r")))
+		}
+		b.pending = b.pending[:0]
+		b.discarding = false
+		data = rest
+	}
+}
+
+// Close closes the underlying body and reports the usage seen so far
+func (b *usageTrackingBody) Close() error {
+	err := b.body.Close()
+	b.once.Do(func() {
+		if len(b.pending) > 0 && !b.discarding {
+			b.parser.ParseLine(bytes.TrimSuffix(b.pending, []byte("\r")))
+		}
+		b.onClose(b.parser.Usage())
+	})
+	return err
+}
@@ -0,0 +1,351 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// anthropicSSEStream is a recorded Anthropic Messages API streaming response
+const anthropicSSEStream = `event: message_start
+data: {"type":"message_start","message":{"id":"msg_01","type":"message","role":"assistant","content":[],"model":"claude-3-5-sonnet","stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":25,"output_tokens":1}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: ping
+data: {"type": "ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"!"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn","stop_sequence":null},"usage":{"output_tokens":15}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+
+func TestParseAnthropicStreamUsage(t *testing.T) {
+	usage := ParseAnthropicStreamUsage(strings.NewReader(anthropicSSEStream))
+
+	if usage.InputTokens != 25 {
+		t.Errorf("expected 25 input tokens, got %d", usage.InputTokens)
+	}
+	if usage.OutputTokens != 15 {
+		t.Errorf("expected 15 output tokens, got %d", usage.OutputTokens)
+	}
+}
+
+func TestParseAnthropicStreamUsage_NoMessageDelta(t *testing.T) {
+	stream := `event: message_start
+data: {"type":"message_start","message":{"usage":{"input_tokens":40,"output_tokens":1}}}
+
+`
+	usage := ParseAnthropicStreamUsage(strings.NewReader(stream))
+
+	if usage.InputTokens != 40 {
+		t.Errorf("expected 40 input tokens, got %d", usage.InputTokens)
+	}
+	if usage.OutputTokens != 1 {
+		t.Errorf("expected 1 output token from message_start, got %d", usage.OutputTokens)
+	}
+}
+
+func TestParseAnthropicStreamUsage_InvalidEvents(t *testing.T) {
+	stream := "data: not-json\n\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":7}}\n\n"
+
+	usage := ParseAnthropicStreamUsage(strings.NewReader(stream))
+
+	if usage.InputTokens != 0 {
+		t.Errorf("expected 0 input tokens, got %d", usage.InputTokens)
+	}
+	if usage.OutputTokens != 7 {
+		t.Errorf("expected 7 output tokens, got %d", usage.OutputTokens)
+	}
+}
+
+func TestParseTokenUsage_Anthropic_Stream(t *testing.T) {
+	resp := &http.Response{
+		Header: make(http.Header),
+	}
+	resp.Header.Set("Content-Type", "text/event-stream; charset=utf-8")
+
+	usage := ParseTokenUsage("anthropic", resp, []byte(anthropicSSEStream))
+
+	if usage.InputTokens != 25 {
+		t.Errorf("expected 25 input tokens, got %d", usage.InputTokens)
+	}
+	if usage.OutputTokens != 15 {
+		t.Errorf("expected 15 output tokens, got %d", usage.OutputTokens)
+	}
+}
+
+func TestIsEventStream(t *testing.T) {
+	tests := []struct {
+		contentType string
+		expected    bool
+	}{
+		{"text/event-stream", true},
+		{"text/event-stream; charset=utf-8", true},
+		{"application/json", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{Header: make(http.Header)}
+		resp.Header.Set("Content-Type", tt.contentType)
+		if got := isEventStream(resp); got != tt.expected {
+			t.Errorf("isEventStream(%q) = %v, expected %v", tt.contentType, got, tt.expected)
+		}
+	}
+
+	if isEventStream(nil) {
+		t.Error("expected nil response not to be an event stream")
+	}
+}
+
+func TestBackendHandler_trackCosts_AnthropicStream(t *testing.T) {
+	costTracker := NewCostTracker(nil)
+	handler := &BackendHandler{costTracker: costTracker}
+
+	resp := &http.Response{
+		Header: make(http.Header),
+		Body:   io.NopCloser(strings.NewReader(anthropicSSEStream)),
+	}
+	resp.Header.Set("Content-Type", "text/event-stream")
+
+	backend := &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "claude", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Cost: &gatewayv1alpha1.CostConfig{
+				InputTokenCost:  "0.003",
+				OutputTokenCost: "0.015",
+			},
+		},
+	}
+	handler.trackCosts(resp, "chat", backend, "anthropic")
+
+	// The stream must still be readable by the client
+	forwarded, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unexpected error reading body: %v", err)
+	}
+	if string(forwarded) != anthropicSSEStream {
+		t.Error("expected stream to be forwarded unchanged")
+	}
+
+	// Usage is tracked once the stream ends
+	if stats := costTracker.GetRouteCosts("chat"); stats != nil && stats.TotalRequests > 0 {
+		t.Error("expected usage not to be tracked before the stream is closed")
+	}
+	_ = resp.Body.Close()
+
+	stats := costTracker.GetRouteCosts("chat")
+	if stats == nil {
+		t.Fatal("expected route cost stats")
+	}
+	if stats.TotalInputTokens != 25 {
+		t.Errorf("expected 25 input tokens, got %d", stats.TotalInputTokens)
+	}
+	if stats.TotalOutputTokens != 15 {
+		t.Errorf("expected 15 output tokens, got %d", stats.TotalOutputTokens)
+	}
+}
+
+// openAISSEStream is a recorded OpenAI chat completion stream with
+// stream_options.include_usage set
+const openAISSEStream = `data: {"id":"chatcmpl-1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"role":"assistant","content":""}}],"usage":null}
+
+data: {"id":"chatcmpl-1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"Hello"}}],"usage":null}
+
+data: {"id":"chatcmpl-1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":null}
+
+data: {"id":"chatcmpl-1","object":"chat.completion.chunk","choices":[],"usage":{"prompt_tokens":12,"completion_tokens":7,"total_tokens":19}}
+
+data: [DONE]
+
+`
+
+func TestUsageTrackingBody_SplitReads(t *testing.T) {
+	var tracked []TokenUsage
+	body := newUsageTrackingBody(
+		io.NopCloser(iotest.OneByteReader(strings.NewReader(openAISSEStream))),
+		newStreamUsageParser("openai"),
+		func(usage TokenUsage) { tracked = append(tracked, usage) },
+	)
+
+	forwarded, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("unexpected error reading body: %v", err)
+	}
+	if string(forwarded) != openAISSEStream {
+		t.Error("expected stream to be forwarded unchanged")
+	}
+
+	_ = body.Close()
+	_ = body.Close()
+
+	if len(tracked) != 1 {
+		t.Fatalf("expected usage to be reported once, got %d", len(tracked))
+	}
+	if tracked[0].InputTokens != 12 || tracked[0].OutputTokens != 7 {
+		t.Errorf("expected 12 input and 7 output tokens, got %+v", tracked[0])
+	}
+}
+
+func TestUsageTrackingBody_OversizedLine(t *testing.T) {
+	stream := "data: " + strings.Repeat("x", maxSSELineSize+1) + "\n" +
+		`data: {"type":"message_start","message":{"usage":{"input_tokens":3,"output_tokens":1}}}` + "\n"
+
+	var tracked TokenUsage
+	body := newUsageTrackingBody(io.NopCloser(strings.NewReader(stream)), newStreamUsageParser("anthropic"),
+		func(usage TokenUsage) { tracked = usage })
+	_, _ = io.Copy(io.Discard, body)
+	_ = body.Close()
+
+	if tracked.InputTokens != 3 {
+		t.Errorf("expected lines after an oversized line to be parsed, got %+v", tracked)
+	}
+}
+
+func TestParseAnthropicStreamUsage_OversizedEvent(t *testing.T) {
+	stream := `data: {"type":"message_start","message":{"usage":{"input_tokens":9,"output_tokens":1}}}` + "\n\n" +
+		`data: {"type":"content_block_delta","delta":{"partial_json":"` + strings.Repeat("x", maxSSELineSize) + `"}}` + "\n\n" +
+		`data: {"type":"message_delta","usage":{"output_tokens":4}}` + "\n\n"
+
+	usage := ParseAnthropicStreamUsage(strings.NewReader(stream))
+
+	if usage.InputTokens != 9 || usage.OutputTokens != 4 {
+		t.Errorf("expected parsing to continue past an oversized event, got %+v", usage)
+	}
+}
+
+func TestNewStreamUsageParser_UnknownProvider(t *testing.T) {
+	if parser := newStreamUsageParser("cohere"); parser != nil {
+		t.Errorf("expected no stream parser for cohere, got %T", parser)
+	}
+}
+
+func TestBackendHandler_StreamingCostTracking(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range strings.SplitAfter(openAISSEStream, "\n\n") {
+			_, _ = io.WriteString(w, event)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer upstream.Close()
+
+	store := cache.NewStore()
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "gpt"}, &gatewayv1alpha1.InferenceBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpt", Namespace: "default"},
+		Spec: gatewayv1alpha1.InferenceBackendSpec{
+			Type:     gatewayv1alpha1.BackendTypeExternal,
+			External: &gatewayv1alpha1.ExternalBackend{URL: upstream.URL, Provider: "openai"},
+			Cost: &gatewayv1alpha1.CostConfig{
+				InputTokenCost:  "0.001",
+				OutputTokenCost: "0.002",
+			},
+		},
+		Status: gatewayv1alpha1.InferenceBackendStatus{Health: "Healthy"},
+	})
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec:       gatewayv1alpha1.InferenceRouteSpec{CostTracking: true},
+	}
+
+	costTracker := NewCostTracker(nil)
+	handler := NewBackendHandler(store, nil, zap.New(), nil, costTracker, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	handler.ExecuteWithFallback(req.Context(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "gpt"})
+
+	if rec.Body.String() != openAISSEStream {
+		t.Error("expected the stream to reach the client unchanged")
+	}
+
+	routeStats := costTracker.GetRouteCosts("chat")
+	if routeStats == nil || routeStats.TotalInputTokens != 12 || routeStats.TotalOutputTokens != 7 {
+		t.Fatalf("expected streamed usage in route stats, got %+v", routeStats)
+	}
+	backendStats := costTracker.GetBackendCosts("gpt")
+	if backendStats == nil || backendStats.TotalRequests != 1 {
+		t.Errorf("expected one streamed request in backend stats, got %+v", backendStats)
+	}
+	if providerStats := costTracker.GetProviderCosts("openai"); providerStats == nil || providerStats.TotalRequests != 1 {
+		t.Errorf("expected one streamed request in openai provider stats, got %+v", providerStats)
+	}
+}
+
+func TestBackendHandler_StreamingOversizedEvent(t *testing.T) {
+	// A huge tool call argument sits between the content and the usage chunk
+	stream := `data: {"choices":[{"delta":{"tool_calls":[{"function":{"arguments":"` +
+		strings.Repeat("x", 2*maxSSELineSize) + `"}}]}}]}` + "\n\n" +
+		`data: {"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":7}}` + "\n\n" +
+		"data: [DONE]\n\n"
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, stream)
+	}))
+	defer upstream.Close()
+
+	store := cache.NewStore()
+	backend := newExternalTestBackend("gpt", upstream.URL)
+	backend.Spec.Cost = &gatewayv1alpha1.CostConfig{InputTokenCost: "0.001", OutputTokenCost: "0.002"}
+	store.SetBackend(types.NamespacedName{Namespace: "default", Name: "gpt"}, backend)
+	route := &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "oversized", Namespace: "default"},
+		Spec:       gatewayv1alpha1.InferenceRouteSpec{CostTracking: true},
+	}
+
+	costTracker := NewCostTracker(nil)
+	handler := NewBackendHandler(store, nil, zap.New(), nil, costTracker, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	handler.ExecuteWithFallback(req.Context(), rec, req, route, nil, gatewayv1alpha1.BackendRef{Name: "gpt"})
+
+	if rec.Body.String() != stream {
+		t.Errorf("expected the oversized event to be forwarded unchanged, got %d of %d bytes", rec.Body.Len(), len(stream))
+	}
+	routeStats := costTracker.GetRouteCosts("oversized")
+	if routeStats == nil || routeStats.TotalInputTokens != 12 || routeStats.TotalOutputTokens != 7 {
+		t.Errorf("expected usage after the oversized event to be tracked, got %+v", routeStats)
+	}
+}
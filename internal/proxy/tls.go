@@ -0,0 +1,116 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TLSConfig configures the proxy to serve HTTPS directly
+type TLSConfig struct {
+	// CertFile and KeyFile are paths to a PEM-encoded certificate and key,
+	// typically mounted from a Secret
+	CertFile string
+	KeyFile  string
+
+	// Certificate is used instead of CertFile and KeyFile when set
+	Certificate *tls.Certificate
+
+	// MinVersion is the lowest TLS version accepted (tls.VersionTLS12 if 0)
+	MinVersion uint16
+
+	// CipherSuites restricts the TLS 1.2 cipher suites offered. TLS 1.3 suites
+	// are not configurable. Go's defaults are used when empty.
+	CipherSuites []uint16
+
+	// TLSOpts are applied last, e.g. to disable HTTP/2
+	TLSOpts []func(*tls.Config)
+}
+
+// newTLSConfig builds the listener configuration, loading the certificate
+func newTLSConfig(cfg *TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:   cfg.MinVersion,
+		CipherSuites: cfg.CipherSuites,
+		// Offer HTTP/2 unless an option restricts the protocols
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	if tlsConfig.MinVersion == 0 {
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+
+	switch {
+	case cfg.Certificate != nil:
+		tlsConfig.Certificates = []tls.Certificate{*cfg.Certificate}
+	case cfg.CertFile != "" && cfg.KeyFile != "":
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	default:
+		return nil, errors.New("a TLS certificate and key are required")
+	}
+
+	for _, opt := range cfg.TLSOpts {
+		opt(tlsConfig)
+	}
+	return tlsConfig, nil
+}
+
+// ParseTLSVersion parses a TLS version such as "1.2" or "1.3"
+func ParseTLSVersion(version string) (uint16, error) {
+	switch strings.TrimPrefix(strings.TrimSpace(version), "TLS") {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unknown TLS version: %s", version)
+	}
+}
+
+// ParseCipherSuites parses a comma-separated list of cipher suite names such
+// as "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256". Suites Go considers insecure
+// are rejected.
+func ParseCipherSuites(spec string) ([]uint16, error) {
+	available := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		available[suite.Name] = suite.ID
+	}
+
+	var suites []uint16
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		id, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported cipher suite: %s", name)
+		}
+		suites = append(suites, id)
+	}
+	return suites, nil
+}
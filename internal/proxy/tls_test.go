@@ -0,0 +1,208 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// selfSignedCertificate creates a certificate for 127.0.0.1
+func selfSignedCertificate(t *testing.T) *tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "kortex-test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// freeAddr returns a local address that is free to listen on
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+	return addr
+}
+
+func TestServer_TLSMinVersion(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Addr = freeAddr(t)
+	cfg.TLS = &TLSConfig{
+		Certificate: selfSignedCertificate(t),
+		MinVersion:  tls.VersionTLS13,
+	}
+	server := NewServer(cfg, cache.NewStore(), nil, zap.New())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.Start(ctx) }()
+	defer func() {
+		cancel()
+		if err := <-errCh; err != nil {
+			t.Errorf("unexpected error stopping server: %v", err)
+		}
+	}()
+
+	dial := func(maxVersion uint16) (*tls.Conn, error) {
+		dialer := &net.Dialer{Timeout: time.Second}
+		// nolint:gosec
+		return tls.DialWithDialer(dialer, "tcp", cfg.Addr, &tls.Config{
+			InsecureSkipVerify: true,
+			MaxVersion:         maxVersion,
+		})
+	}
+
+	// Wait for the listener, negotiating the configured minimum
+	var conn *tls.Conn
+	var err error
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if conn, err = dial(tls.VersionTLS13); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("expected a TLS 1.3 handshake to succeed: %v", err)
+	}
+	if got := conn.ConnectionState().Version; got != tls.VersionTLS13 {
+		t.Errorf("expected TLS 1.3, negotiated %s", tls.VersionName(got))
+	}
+	_ = conn.Close()
+
+	// Clients limited to an older version are rejected
+	if conn, err := dial(tls.VersionTLS12); err == nil {
+		_ = conn.Close()
+		t.Error("expected a TLS 1.2 handshake to be rejected")
+	}
+}
+
+func TestServer_TLSMissingCertificate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Addr = freeAddr(t)
+	cfg.TLS = &TLSConfig{CertFile: "/nonexistent/tls.crt", KeyFile: "/nonexistent/tls.key"}
+	server := NewServer(cfg, cache.NewStore(), nil, zap.New())
+
+	if err := server.Start(context.Background()); err == nil {
+		t.Error("expected Start to fail without a loadable certificate")
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	cert := selfSignedCertificate(t)
+
+	tlsConfig, err := newTLSConfig(&TLSConfig{
+		Certificate:  cert,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+		TLSOpts: []func(*tls.Config){
+			func(c *tls.Config) { c.NextProtos = []string{"http/1.1"} },
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tlsConfig.MinVersion != tls.VersionTLS12 {
+		t.Errorf("expected default minimum TLS 1.2, got %s", tls.VersionName(tlsConfig.MinVersion))
+	}
+	if len(tlsConfig.CipherSuites) != 1 || tlsConfig.CipherSuites[0] != tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256 {
+		t.Errorf("expected configured cipher suites, got %v", tlsConfig.CipherSuites)
+	}
+	if len(tlsConfig.NextProtos) != 1 || tlsConfig.NextProtos[0] != "http/1.1" {
+		t.Errorf("expected options to disable HTTP/2, got %v", tlsConfig.NextProtos)
+	}
+
+	if _, err := newTLSConfig(&TLSConfig{}); err == nil {
+		t.Error("expected an error without a certificate")
+	}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    uint16
+		wantErr bool
+	}{
+		{"1.2", tls.VersionTLS12, false},
+		{"1.3", tls.VersionTLS13, false},
+		{"TLS1.3", tls.VersionTLS13, false},
+		{"1.4", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTLSVersion(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	suites, err := ParseCipherSuites("TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(suites) != 2 || suites[0] != tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 {
+		t.Errorf("expected two parsed suites, got %v", suites)
+	}
+
+	if suites, err := ParseCipherSuites(""); err != nil || len(suites) != 0 {
+		t.Errorf("expected no suites for an empty list, got %v, %v", suites, err)
+	}
+
+	// Insecure suites are rejected
+	if _, err := ParseCipherSuites("TLS_RSA_WITH_RC4_128_SHA"); err == nil {
+		t.Error("expected an insecure cipher suite to be rejected")
+	}
+	if _, err := ParseCipherSuites("NOT_A_SUITE"); err == nil {
+		t.Error("expected an unknown cipher suite to be rejected")
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// WarmupConfig configures the startup grace period in which the controllers
+// are still filling the cache. Unmatched requests get a 503 with Retry-After
+// during the grace period rather than a 404.
+type WarmupConfig struct {
+	// MinRoutes is the number of routes in the cache that ends the grace period
+	MinRoutes int
+
+	// Timeout ends the grace period even if MinRoutes is never reached, so a
+	// cluster with fewer routes still gets 404s eventually
+	Timeout time.Duration
+
+	// RetryAfter is the delay suggested to clients during the grace period
+	RetryAfter time.Duration
+}
+
+// DefaultWarmupConfig returns sensible defaults for the startup grace period
+func DefaultWarmupConfig() WarmupConfig {
+	return WarmupConfig{
+		MinRoutes:  1,
+		Timeout:    60 * time.Second,
+		RetryAfter: 5 * time.Second,
+	}
+}
+
+// warmup tracks whether the cache is still cold. Once warm, it stays warm.
+type warmup struct {
+	config  WarmupConfig
+	cache   *cache.Store
+	started time.Time
+	warm    atomic.Bool
+	now     func() time.Time
+}
+
+// newWarmup starts the grace period now
+func newWarmup(cfg WarmupConfig, store *cache.Store) *warmup {
+	defaults := DefaultWarmupConfig()
+	if cfg.MinRoutes <= 0 {
+		cfg.MinRoutes = defaults.MinRoutes
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	if cfg.RetryAfter <= 0 {
+		cfg.RetryAfter = defaults.RetryAfter
+	}
+	return &warmup{
+		config:  cfg,
+		cache:   store,
+		started: time.Now(),
+		now:     time.Now,
+	}
+}
+
+// cold reports whether the grace period is still running
+func (w *warmup) cold() bool {
+	if w == nil || w.warm.Load() {
+		return false
+	}
+	if w.cache.GetStats().RouteCount >= w.config.MinRoutes || w.now().Sub(w.started) >= w.config.Timeout {
+		w.warm.Store(true)
+		return false
+	}
+	return true
+}
+
+// reject tells the client to retry once the cache is warm
+func (w *warmup) reject(rw http.ResponseWriter) {
+	rw.Header().Set("Retry-After", strconv.Itoa(int(w.config.RetryAfter.Seconds())))
+	http.Error(rw, "Proxy is starting up, routes are not loaded yet", http.StatusServiceUnavailable)
+}
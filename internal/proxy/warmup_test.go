@@ -0,0 +1,90 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	gatewayv1alpha1 "github.com/judeoyovbaire/kortex/api/v1alpha1"
+	"github.com/judeoyovbaire/kortex/internal/cache"
+)
+
+// requestUnknownRoute sends a request that no route matches
+func requestUnknownRoute(server *Server) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("X-Route", "missing")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_WarmupColdCacheReturns503(t *testing.T) {
+	store := cache.NewStore()
+	cfg := WarmupConfig{MinRoutes: 1, Timeout: time.Hour, RetryAfter: 3 * time.Second}
+	server := NewServer(DefaultConfig(), store, nil, zap.New(), WithWarmup(&cfg))
+
+	rec := requestUnknownRoute(server)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 while the cache is cold, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "3" {
+		t.Errorf("expected Retry-After of 3, got %q", got)
+	}
+
+	// Once the controllers load a route, unmatched requests are genuine 404s
+	store.SetRoute(types.NamespacedName{Namespace: "default", Name: "chat"}, &gatewayv1alpha1.InferenceRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "default"},
+	})
+	if rec := requestUnknownRoute(server); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after warm-up, got %d", rec.Code)
+	}
+
+	// The cache stays warm even if routes are deleted
+	store.DeleteRoute(types.NamespacedName{Namespace: "default", Name: "chat"})
+	if rec := requestUnknownRoute(server); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 once warm, got %d", rec.Code)
+	}
+}
+
+func TestServer_WarmupTimeout(t *testing.T) {
+	cfg := DefaultWarmupConfig()
+	server := NewServer(DefaultConfig(), cache.NewStore(), nil, zap.New(), WithWarmup(&cfg))
+
+	if rec := requestUnknownRoute(server); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 while the cache is cold, got %d", rec.Code)
+	}
+
+	server.router.warmup.now = func() time.Time { return time.Now().Add(cfg.Timeout) }
+	if rec := requestUnknownRoute(server); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after the grace period, got %d", rec.Code)
+	}
+}
+
+func TestServer_WarmupDisabled(t *testing.T) {
+	server := NewServer(DefaultConfig(), cache.NewStore(), nil, zap.New())
+
+	if rec := requestUnknownRoute(server); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without a grace period, got %d", rec.Code)
+	}
+}
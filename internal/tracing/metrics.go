@@ -0,0 +1,174 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// MeterConfig holds OTLP metrics export configuration
+type MeterConfig struct {
+	// Endpoint is the OTLP collector endpoint, shared with tracing
+	Endpoint string
+
+	// Insecure disables TLS for the OTLP connection
+	Insecure bool
+
+	// ServiceName overrides the default service name
+	ServiceName string
+
+	// ServiceVersion is the version of the service
+	ServiceVersion string
+
+	// ExportInterval is how often metrics are pushed to the collector
+	ExportInterval time.Duration
+}
+
+// Meter pushes gateway request, latency and cost metrics to an OTLP collector
+type Meter struct {
+	provider        *sdkmetric.MeterProvider
+	requests        metric.Int64Counter
+	errors          metric.Int64Counter
+	requestDuration metric.Float64Histogram
+	cost            metric.Float64Counter
+	tokens          metric.Int64Counter
+}
+
+// NewMeter creates a Meter that exports to the configured OTLP endpoint
+func NewMeter(cfg MeterConfig) (*Meter, error) {
+	opts := []otlpmetricgrpc.Option{
+		otlpmetricgrpc.WithEndpoint(cfg.Endpoint),
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	}
+
+	exporter, err := otlpmetricgrpc.New(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var readerOpts []sdkmetric.PeriodicReaderOption
+	if cfg.ExportInterval > 0 {
+		readerOpts = append(readerOpts, sdkmetric.WithInterval(cfg.ExportInterval))
+	}
+
+	return newMeter(cfg, sdkmetric.NewPeriodicReader(exporter, readerOpts...))
+}
+
+// newMeter creates a Meter whose measurements are collected by reader
+func newMeter(cfg MeterConfig, reader sdkmetric.Reader) (*Meter, error) {
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = ServiceName
+	}
+
+	// Schemaless attributes merge cleanly with the SDK default resource,
+	// whose schema URL may differ from the semconv version used here
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewSchemaless(
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion(cfg.ServiceVersion),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithResource(res),
+	)
+	meter := provider.Meter(TracerName)
+
+	m := &Meter{provider: provider}
+	if m.requests, err = meter.Int64Counter("kortex.requests",
+		metric.WithDescription("Total number of inference requests processed")); err != nil {
+		return nil, err
+	}
+	if m.errors, err = meter.Int64Counter("kortex.request.errors",
+		metric.WithDescription("Total number of request errors")); err != nil {
+		return nil, err
+	}
+	if m.requestDuration, err = meter.Float64Histogram("kortex.request.duration",
+		metric.WithDescription("Request duration"),
+		metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if m.cost, err = meter.Float64Counter("kortex.cost",
+		metric.WithDescription("Total cost incurred, in the currency of the currency attribute")); err != nil {
+		return nil, err
+	}
+	if m.tokens, err = meter.Int64Counter("kortex.tokens",
+		metric.WithDescription("Total tokens processed")); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// RecordRequest records a completed request and its duration
+func (m *Meter) RecordRequest(route, backend string, statusCode int, duration time.Duration) {
+	ctx := context.Background()
+	routeAttr := attribute.String("route", route)
+	backendAttr := attribute.String("backend", backend)
+	m.requests.Add(ctx, 1, metric.WithAttributes(routeAttr, backendAttr, attribute.Int("status", statusCode)))
+	m.requestDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(routeAttr, backendAttr))
+}
+
+// RecordError records a request error
+func (m *Meter) RecordError(route, backend, errorType string) {
+	m.errors.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("route", route),
+		attribute.String("backend", backend),
+		attribute.String("error_type", errorType),
+	))
+}
+
+// RecordCost records cost incurred for a request, in the given currency and
+// under the given cost tag, which is empty for untagged requests
+func (m *Meter) RecordCost(route, backend, currency, tag string, cost float64) {
+	m.cost.Add(context.Background(), cost, metric.WithAttributes(
+		attribute.String("route", route),
+		attribute.String("backend", backend),
+		attribute.String("currency", currency),
+		attribute.String("tag", tag),
+	))
+}
+
+// RecordTokens records tokens processed, by type (input or output)
+func (m *Meter) RecordTokens(route, backend, tokenType string, count int64) {
+	m.tokens.Add(context.Background(), count, metric.WithAttributes(
+		attribute.String("route", route),
+		attribute.String("backend", backend),
+		attribute.String("type", tokenType),
+	))
+}
+
+// Shutdown flushes pending metrics and stops the exporter
+func (m *Meter) Shutdown(ctx context.Context) error {
+	return m.provider.Shutdown(ctx)
+}
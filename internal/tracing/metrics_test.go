@@ -0,0 +1,144 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// mockCollector is an exporter that keeps exported metrics in memory
+type mockCollector struct {
+	mu      sync.Mutex
+	exports []metricdata.ResourceMetrics
+}
+
+func (c *mockCollector) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	return sdkmetric.DefaultTemporalitySelector(kind)
+}
+
+func (c *mockCollector) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+func (c *mockCollector) Export(_ context.Context, rm *metricdata.ResourceMetrics) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.exports = append(c.exports, *rm)
+	return nil
+}
+
+func (c *mockCollector) ForceFlush(context.Context) error { return nil }
+
+func (c *mockCollector) Shutdown(context.Context) error { return nil }
+
+// find returns the last exported metric with the given name
+func (c *mockCollector) find(name string) (metricdata.Metrics, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := len(c.exports) - 1; i >= 0; i-- {
+		for _, sm := range c.exports[i].ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if m.Name == name {
+					return m, true
+				}
+			}
+		}
+	}
+	return metricdata.Metrics{}, false
+}
+
+func TestNewMeter(t *testing.T) {
+	meter, err := NewMeter(MeterConfig{
+		Endpoint:       "localhost:4317",
+		Insecure:       true,
+		ExportInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("failed to create meter: %v", err)
+	}
+	if meter.provider == nil {
+		t.Fatal("expected meter provider to be constructed")
+	}
+
+	// Nothing was recorded, so shutdown does not need to reach the collector
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_ = meter.Shutdown(ctx)
+}
+
+func TestMeter_ExportsToCollector(t *testing.T) {
+	collector := &mockCollector{}
+	meter, err := newMeter(MeterConfig{}, sdkmetric.NewPeriodicReader(collector, sdkmetric.WithInterval(time.Hour)))
+	if err != nil {
+		t.Fatalf("failed to create meter: %v", err)
+	}
+
+	meter.RecordRequest("test-route", "backend-a", 200, 250*time.Millisecond)
+	meter.RecordRequest("test-route", "backend-a", 200, 500*time.Millisecond)
+	meter.RecordCost("test-route", "backend-a", "USD", "", 0.25)
+	meter.RecordTokens("test-route", "backend-a", "input", 100)
+
+	if err := meter.provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("failed to flush metrics: %v", err)
+	}
+
+	requests, ok := collector.find("kortex.requests")
+	if !ok {
+		t.Fatal("expected kortex.requests to be exported")
+	}
+	sum, ok := requests.Data.(metricdata.Sum[int64])
+	if !ok || len(sum.DataPoints) != 1 {
+		t.Fatalf("expected a single request data point, got %#v", requests.Data)
+	}
+	if sum.DataPoints[0].Value != 2 {
+		t.Errorf("expected 2 requests, got %d", sum.DataPoints[0].Value)
+	}
+	if route, _ := sum.DataPoints[0].Attributes.Value("route"); route.AsString() != "test-route" {
+		t.Errorf("expected route attribute 'test-route', got '%s'", route.AsString())
+	}
+
+	duration, ok := collector.find("kortex.request.duration")
+	if !ok {
+		t.Fatal("expected kortex.request.duration to be exported")
+	}
+	histogram, ok := duration.Data.(metricdata.Histogram[float64])
+	if !ok || len(histogram.DataPoints) != 1 || histogram.DataPoints[0].Count != 2 {
+		t.Errorf("expected 2 duration observations, got %#v", duration.Data)
+	}
+
+	cost, ok := collector.find("kortex.cost")
+	if !ok {
+		t.Fatal("expected kortex.cost to be exported")
+	}
+	if costSum, ok := cost.Data.(metricdata.Sum[float64]); !ok || costSum.DataPoints[0].Value != 0.25 {
+		t.Errorf("expected cost 0.25, got %#v", cost.Data)
+	}
+
+	if _, ok := collector.find("kortex.tokens"); !ok {
+		t.Error("expected kortex.tokens to be exported")
+	}
+
+	if err := meter.Shutdown(context.Background()); err != nil {
+		t.Errorf("failed to shutdown meter: %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SampleRateAttribute is the span attribute that overrides the global sample
+// rate for a single trace
+const SampleRateAttribute = "kortex.trace.sample_rate"
+
+// WithSampleRate overrides the global sample rate for the span being started,
+// e.g. the request span of a route with its own sample rate
+func WithSampleRate(rate float64) trace.SpanStartOption {
+	return trace.WithAttributes(attribute.Float64(SampleRateAttribute, rate))
+}
+
+// NewSampler returns the sampler NewTracer installs: spans carrying
+// SampleRateAttribute are sampled at that rate, spans with a local parent
+// follow the parent's decision and all others are sampled at the global rate
+func NewSampler(rate float64) sdktrace.Sampler {
+	global := ratioSampler(rate)
+	return &overrideSampler{
+		global: sdktrace.ParentBased(global,
+			sdktrace.WithRemoteParentSampled(global),
+			sdktrace.WithRemoteParentNotSampled(global),
+		),
+	}
+}
+
+// ratioSampler samples the given fraction of traces
+func ratioSampler(rate float64) sdktrace.Sampler {
+	switch {
+	case rate >= 1.0:
+		return sdktrace.AlwaysSample()
+	case rate <= 0:
+		return sdktrace.NeverSample()
+	default:
+		return sdktrace.TraceIDRatioBased(rate)
+	}
+}
+
+// overrideSampler applies per-span sample rate overrides
+type overrideSampler struct {
+	global sdktrace.Sampler
+}
+
+// ShouldSample implements sdktrace.Sampler
+func (s *overrideSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	for _, attr := range p.Attributes {
+		if attr.Key == SampleRateAttribute {
+			return ratioSampler(attr.Value.AsFloat64()).ShouldSample(p)
+		}
+	}
+	return s.global.ShouldSample(p)
+}
+
+// Description implements sdktrace.Sampler
+func (s *overrideSampler) Description() string {
+	return fmt.Sprintf("KortexSampler{%s}", s.global.Description())
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestNewSampler_SampleRateOverride(t *testing.T) {
+	tests := []struct {
+		name        string
+		globalRate  float64
+		override    bool
+		overrideTo  float64
+		wantSampled bool
+	}{
+		{name: "global rate 1", globalRate: 1.0, wantSampled: true},
+		{name: "global rate 0", globalRate: 0, wantSampled: false},
+		{name: "override to 0", globalRate: 1.0, override: true, overrideTo: 0, wantSampled: false},
+		{name: "override to 1", globalRate: 0, override: true, overrideTo: 1.0, wantSampled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer := NewTracerWithProvider(sdktrace.NewTracerProvider(sdktrace.WithSampler(NewSampler(tt.globalRate))))
+			req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+
+			var ctx context.Context
+			if tt.override {
+				ctx, _ = tracer.StartRequestSpan(context.Background(), req, WithSampleRate(tt.overrideTo))
+			} else {
+				ctx, _ = tracer.StartRequestSpan(context.Background(), req)
+			}
+			_, child := tracer.StartRouterSpan(ctx, "chat")
+
+			if got := child.SpanContext().IsSampled(); got != tt.wantSampled {
+				t.Errorf("expected sampled=%v for the child span, got %v", tt.wantSampled, got)
+			}
+		})
+	}
+}
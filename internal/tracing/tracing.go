@@ -19,6 +19,8 @@ package tracing
 import (
 	"context"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -31,6 +33,7 @@ import (
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 const (
@@ -60,6 +63,10 @@ type Config struct {
 
 	// Insecure disables TLS for the OTLP connection
 	Insecure bool
+
+	// PropagateHeaders lists request headers, such as X-Tenant, whose values
+	// are added to request spans as http.request.header.<name> attributes
+	PropagateHeaders []string
 }
 
 // DefaultConfig returns the default tracing configuration
@@ -76,9 +83,21 @@ func DefaultConfig() Config {
 
 // Tracer wraps OpenTelemetry tracing functionality for Kortex
 type Tracer struct {
+	mu       sync.RWMutex
 	provider *sdktrace.TracerProvider
 	tracer   trace.Tracer
 	config   Config
+
+	// global is set when the tracer installed its provider as the global
+	// provider, so reloads replace the global provider too
+	global bool
+
+	// newProvider builds the provider for a configuration. Tests replace it
+	// to avoid connecting to a collector.
+	newProvider func(Config) (*sdktrace.TracerProvider, error)
+
+	// reloadMu serializes reloads
+	reloadMu sync.Mutex
 }
 
 // NewTracer creates a new Tracer instance
@@ -91,6 +110,31 @@ func NewTracer(cfg Config) (*Tracer, error) {
 		}, nil
 	}
 
+	provider, err := newOTLPProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set global trace provider
+	otel.SetTracerProvider(provider)
+
+	// Set global propagator for context propagation
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return &Tracer{
+		provider: provider,
+		tracer:   provider.Tracer(TracerName),
+		config:   cfg,
+		global:   true,
+	}, nil
+}
+
+// newOTLPProvider creates a provider that exports spans to the configured
+// OTLP collector
+func newOTLPProvider(cfg Config) (*sdktrace.TracerProvider, error) {
 	ctx := context.Background()
 
 	// Create OTLP exporter options
@@ -127,58 +171,129 @@ func NewTracer(cfg Config) (*Tracer, error) {
 		return nil, err
 	}
 
-	// Create sampler based on sample rate
-	var sampler sdktrace.Sampler
-	if cfg.SampleRate >= 1.0 {
-		sampler = sdktrace.AlwaysSample()
-	} else if cfg.SampleRate <= 0 {
-		sampler = sdktrace.NeverSample()
-	} else {
-		sampler = sdktrace.TraceIDRatioBased(cfg.SampleRate)
-	}
-
 	// Create trace provider
-	provider := sdktrace.NewTracerProvider(
+	return sdktrace.NewTracerProvider(
 		sdktrace.WithBatcher(exporter),
 		sdktrace.WithResource(res),
-		sdktrace.WithSampler(sampler),
-	)
-
-	// Set global trace provider
-	otel.SetTracerProvider(provider)
-
-	// Set global propagator for context propagation
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	))
+		sdktrace.WithSampler(NewSampler(cfg.SampleRate)),
+	), nil
+}
 
+// NewTracerWithProvider creates a Tracer that records spans with an existing
+// provider, such as one with an in-memory exporter. Unlike NewTracer it does
+// not change the global provider or propagator.
+func NewTracerWithProvider(provider *sdktrace.TracerProvider) *Tracer {
 	return &Tracer{
 		provider: provider,
 		tracer:   provider.Tracer(TracerName),
-		config:   cfg,
-	}, nil
+		config:   Config{Enabled: true, SampleRate: 1.0},
+	}
+}
+
+// Config returns the tracer's current configuration
+func (t *Tracer) Config() Config {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.config
+}
+
+// Reload applies a new configuration without a restart. When a setting the
+// provider is built from changes, such as the collector endpoint, a new
+// provider is swapped in and the old one is shut down, flushing the spans it
+// has buffered. Other settings apply to new spans immediately.
+func (t *Tracer) Reload(ctx context.Context, cfg Config) error {
+	t.reloadMu.Lock()
+	defer t.reloadMu.Unlock()
+
+	t.mu.RLock()
+	old := t.config
+	oldProvider := t.provider
+	t.mu.RUnlock()
+
+	if !providerChanged(old, cfg) {
+		t.mu.Lock()
+		t.config = cfg
+		t.mu.Unlock()
+		return nil
+	}
+
+	var provider *sdktrace.TracerProvider
+	var tracer trace.Tracer
+	if cfg.Enabled {
+		build := t.newProvider
+		if build == nil {
+			build = newOTLPProvider
+		}
+		var err error
+		if provider, err = build(cfg); err != nil {
+			return err
+		}
+		tracer = provider.Tracer(TracerName)
+	} else {
+		tracer = noop.NewTracerProvider().Tracer(TracerName)
+	}
+
+	t.mu.Lock()
+	t.provider = provider
+	t.tracer = tracer
+	t.config = cfg
+	t.mu.Unlock()
+
+	if t.global {
+		if provider != nil {
+			otel.SetTracerProvider(provider)
+		} else {
+			otel.SetTracerProvider(noop.NewTracerProvider())
+		}
+	}
+
+	if oldProvider == nil {
+		return nil
+	}
+	return oldProvider.Shutdown(ctx)
+}
+
+// providerChanged reports whether the provider must be rebuilt to apply cfg
+func providerChanged(old, cfg Config) bool {
+	return old.Enabled != cfg.Enabled ||
+		old.Endpoint != cfg.Endpoint ||
+		old.Insecure != cfg.Insecure ||
+		old.SampleRate != cfg.SampleRate ||
+		old.ServiceName != cfg.ServiceName ||
+		old.ServiceVersion != cfg.ServiceVersion
 }
 
 // Shutdown gracefully shuts down the tracer
 func (t *Tracer) Shutdown(ctx context.Context) error {
-	if t.provider == nil {
+	t.mu.RLock()
+	provider := t.provider
+	t.mu.RUnlock()
+	if provider == nil {
 		return nil
 	}
-	return t.provider.Shutdown(ctx)
+	return provider.Shutdown(ctx)
+}
+
+// current returns the tracer spans are started with and the configuration
+func (t *Tracer) current() (trace.Tracer, Config) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tracer, t.config
 }
 
 // StartSpan starts a new span with the given name
 func (t *Tracer) StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
-	return t.tracer.Start(ctx, name, opts...)
+	tracer, _ := t.current()
+	return tracer.Start(ctx, name, opts...)
 }
 
-// StartRequestSpan starts a span for an incoming HTTP request
-func (t *Tracer) StartRequestSpan(ctx context.Context, r *http.Request) (context.Context, trace.Span) {
+// StartRequestSpan starts a span for an incoming HTTP request. Options such
+// as WithSampleRate are applied to the span.
+func (t *Tracer) StartRequestSpan(ctx context.Context, r *http.Request, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
 	// Extract context from incoming request headers
 	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
 
-	ctx, span := t.tracer.Start(ctx, "kortex.request",
+	opts = append(opts,
 		trace.WithSpanKind(trace.SpanKindServer),
 		trace.WithAttributes(
 			semconv.HTTPMethod(r.Method),
@@ -189,6 +304,8 @@ func (t *Tracer) StartRequestSpan(ctx context.Context, r *http.Request) (context
 			attribute.String("http.remote_addr", r.RemoteAddr),
 		),
 	)
+	tracer, config := t.current()
+	ctx, span := tracer.Start(ctx, "kortex.request", opts...)
 
 	// Extract custom headers
 	if route := r.Header.Get("X-Route"); route != "" {
@@ -200,13 +317,19 @@ func (t *Tracer) StartRequestSpan(ctx context.Context, r *http.Request) (context
 	if userID := r.Header.Get("X-User-ID"); userID != "" {
 		span.SetAttributes(attribute.String("kortex.user_id", userID))
 	}
+	for _, name := range config.PropagateHeaders {
+		if values := r.Header.Values(name); len(values) > 0 {
+			span.SetAttributes(attribute.StringSlice("http.request.header."+strings.ToLower(name), values))
+		}
+	}
 
 	return ctx, span
 }
 
 // StartBackendSpan starts a span for a backend request
 func (t *Tracer) StartBackendSpan(ctx context.Context, backendName, backendType, targetURL string) (context.Context, trace.Span) {
-	ctx, span := t.tracer.Start(ctx, "kortex.backend.request",
+	tracer, _ := t.current()
+	ctx, span := tracer.Start(ctx, "kortex.backend.request",
 		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithAttributes(
 			attribute.String("kortex.backend.name", backendName),
@@ -219,7 +342,8 @@ func (t *Tracer) StartBackendSpan(ctx context.Context, backendName, backendType,
 
 // StartRouterSpan starts a span for routing decision
 func (t *Tracer) StartRouterSpan(ctx context.Context, routeName string) (context.Context, trace.Span) {
-	ctx, span := t.tracer.Start(ctx, "kortex.router.route",
+	tracer, _ := t.current()
+	ctx, span := tracer.Start(ctx, "kortex.router.route",
 		trace.WithAttributes(
 			attribute.String("kortex.route.name", routeName),
 		),
@@ -229,7 +353,8 @@ func (t *Tracer) StartRouterSpan(ctx context.Context, routeName string) (context
 
 // StartSmartRouterSpan starts a span for smart routing decisions
 func (t *Tracer) StartSmartRouterSpan(ctx context.Context, backend, category, reason string, estimatedTokens int) (context.Context, trace.Span) {
-	ctx, span := t.tracer.Start(ctx, "kortex.smartrouter.decision",
+	tracer, _ := t.current()
+	ctx, span := tracer.Start(ctx, "kortex.smartrouter.decision",
 		trace.WithAttributes(
 			attribute.String("kortex.smartrouter.backend", backend),
 			attribute.String("kortex.smartrouter.category", category),
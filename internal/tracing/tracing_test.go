@@ -0,0 +1,128 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartRequestSpan_PropagateHeaders(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := NewTracerWithProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	tracer.config.PropagateHeaders = []string{"X-Tenant", "X-Feature"}
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	req.Header.Set("X-Tenant", "acme")
+	req.Header.Set("X-Unlisted", "secret")
+
+	_, span := tracer.StartRequestSpan(context.Background(), req)
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range spans[0].Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+
+	if got, ok := attrs["http.request.header.x-tenant"]; !ok || len(got.AsStringSlice()) != 1 || got.AsStringSlice()[0] != "acme" {
+		t.Errorf("expected the configured header as an attribute, got %v", got.Emit())
+	}
+	if _, ok := attrs["http.request.header.x-feature"]; ok {
+		t.Error("expected no attribute for a configured header missing from the request")
+	}
+	if _, ok := attrs["http.request.header.x-unlisted"]; ok {
+		t.Error("expected no attribute for an unconfigured header")
+	}
+}
+
+func TestTracer_ReloadSwapsProvider(t *testing.T) {
+	oldRecorder := tracetest.NewSpanRecorder()
+	oldProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(oldRecorder))
+	tracer := NewTracerWithProvider(oldProvider)
+	tracer.config.Endpoint = "collector-a:4317"
+
+	var built []Config
+	newRecorder := tracetest.NewSpanRecorder()
+	tracer.newProvider = func(cfg Config) (*sdktrace.TracerProvider, error) {
+		built = append(built, cfg)
+		return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(newRecorder)), nil
+	}
+
+	// Settings the provider isn't built from don't reconnect
+	cfg := tracer.Config()
+	cfg.PropagateHeaders = []string{"X-Tenant"}
+	if err := tracer.Reload(context.Background(), cfg); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if len(built) != 0 {
+		t.Fatalf("expected no new provider, got %d", len(built))
+	}
+
+	cfg.Endpoint = "collector-b:4317"
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := tracer.Reload(ctx, cfg); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if len(built) != 1 || built[0].Endpoint != "collector-b:4317" {
+		t.Fatalf("expected one provider for the new endpoint, got %v", built)
+	}
+
+	// New spans go to the new provider
+	_, span := tracer.StartSpan(context.Background(), "after-reload")
+	span.End()
+	if len(newRecorder.Ended()) != 1 || len(oldRecorder.Ended()) != 0 {
+		t.Errorf("expected the span on the new provider, got %d new and %d old",
+			len(newRecorder.Ended()), len(oldRecorder.Ended()))
+	}
+
+	// The old provider was shut down, so it no longer records spans
+	_, oldSpan := oldProvider.Tracer("test").Start(context.Background(), "stale")
+	if oldSpan.IsRecording() {
+		t.Error("expected the old provider to be shut down")
+	}
+	if got := tracer.Config().PropagateHeaders; len(got) != 1 || got[0] != "X-Tenant" {
+		t.Errorf("expected the rest of the configuration to be kept, got %v", got)
+	}
+}
+
+func TestTracer_ReloadDisables(t *testing.T) {
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tracetest.NewSpanRecorder()))
+	tracer := NewTracerWithProvider(provider)
+
+	if err := tracer.Reload(context.Background(), Config{Enabled: false}); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+
+	_, span := tracer.StartSpan(context.Background(), "disabled")
+	if span.IsRecording() {
+		t.Error("expected spans not to be recorded once tracing is disabled")
+	}
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Errorf("expected shutdown without a provider to succeed, got %v", err)
+	}
+}